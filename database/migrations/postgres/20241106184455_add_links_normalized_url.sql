@@ -0,0 +1,73 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE links ADD COLUMN normalized_url VARCHAR(2048);
+
+-- normalize_url follows urlnorm.DefaultOptions for the links saved before the urls were normalized: the scheme
+-- and the host are lowercased, http is forced to https, the default port, the fragment, the repeated and trailing
+-- slashes and the tracking params are stripped and the query is sorted. The urls it cannot parse are kept as they are.
+-- The app normalizes them again with its own options by make normalize_links, e.g. once the options change
+CREATE FUNCTION pg_temp.normalize_url(raw TEXT) RETURNS TEXT AS $fn$
+DECLARE
+    parts TEXT[];
+    url_scheme TEXT;
+    url_host TEXT;
+    url_path TEXT;
+    url_query TEXT;
+BEGIN
+    parts := regexp_match(btrim(raw), '^([A-Za-z][A-Za-z0-9+.-]*)://([^/?#]+)([^?#]*)(\?[^#]*)?');
+    IF parts IS NULL THEN
+        RETURN raw;
+    END IF;
+
+    url_scheme := lower(parts[1]);
+    IF url_scheme = 'http' THEN
+        url_scheme := 'https';
+    END IF;
+
+    url_host := regexp_replace(lower(parts[2]), '\.(:[0-9]*)?$', '\1');
+    url_host := regexp_replace(url_host, ':443$', '');
+
+    url_path := rtrim(regexp_replace(parts[3], '/{2,}', '/', 'g'), '/');
+    IF url_path = '' THEN
+        url_path := '/';
+    END IF;
+
+    SELECT string_agg(param, '&' ORDER BY split_part(param, '=', 1) COLLATE "C", param COLLATE "C")
+    INTO url_query
+    FROM unnest(string_to_array(substr(parts[4], 2), '&')) AS param
+    WHERE param <> ''
+        AND lower(split_part(param, '=', 1)) NOT IN ('fbclid', 'gclid', 'dclid', 'gbraid', 'wbraid', 'msclkid', 'yclid',
+            'twclid', 'igshid', 'mc_cid', 'mc_eid', '_hsenc', '_hsmi', '_ga', '_gl', 'mkt_tok', 'vero_id', 'oly_anon_id',
+            'oly_enc_id')
+        AND lower(split_part(param, '=', 1)) NOT LIKE 'utm\_%';
+
+    RETURN url_scheme || '://' || url_host || url_path || coalesce('?' || nullif(url_query, ''), '');
+END
+$fn$ LANGUAGE plpgsql IMMUTABLE;
+
+UPDATE links SET normalized_url = pg_temp.normalize_url(url);
+
+-- the links of a user having the same normalized url are merged into the oldest one, which gets the tags of all of them
+CREATE TEMPORARY TABLE link_duplicates AS
+SELECT id, first_value(id) OVER (PARTITION BY user_id, normalized_url ORDER BY created_at, id) AS kept_id FROM links;
+
+INSERT INTO link_tags (link_id, tag_id, created_at)
+SELECT d.kept_id, lt.tag_id, lt.created_at FROM link_tags lt JOIN link_duplicates d ON d.id = lt.link_id
+WHERE d.id <> d.kept_id
+ON CONFLICT DO NOTHING;
+
+DELETE FROM links WHERE id IN (SELECT id FROM link_duplicates WHERE id <> kept_id);
+
+DROP TABLE link_duplicates;
+DROP FUNCTION pg_temp.normalize_url(TEXT);
+
+ALTER TABLE links ALTER COLUMN normalized_url SET NOT NULL;
+
+CREATE UNIQUE INDEX links_user_id_normalized_url_key ON links (user_id, normalized_url);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS links_user_id_normalized_url_key;
+ALTER TABLE links DROP COLUMN IF EXISTS normalized_url;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE lists DROP CONSTRAINT IF EXISTS lists_title_key;
+ALTER TABLE lists ADD COLUMN id uuid DEFAULT uuid_generate_v4() PRIMARY KEY;
+ALTER TABLE lists ADD COLUMN updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now();
+ALTER TABLE lists ADD CONSTRAINT lists_user_id_title_key UNIQUE (user_id, title);
+
+CREATE TABLE list_links (
+    list_id uuid NOT NULL,
+    link_id uuid NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (list_id, link_id),
+    FOREIGN KEY (list_id) REFERENCES lists(id) ON DELETE CASCADE,
+    FOREIGN KEY (link_id) REFERENCES links(id) ON DELETE CASCADE
+);
+
+CREATE INDEX list_links_link_id_idx ON list_links (link_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS list_links;
+ALTER TABLE lists DROP CONSTRAINT IF EXISTS lists_user_id_title_key;
+ALTER TABLE lists DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE lists DROP COLUMN IF EXISTS id;
+ALTER TABLE lists ADD CONSTRAINT lists_title_key UNIQUE (title);
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE reading_states (
+    link_id uuid PRIMARY KEY,
+    user_id uuid NOT NULL,
+    percentage REAL NOT NULL DEFAULT 0 CHECK (percentage >= 0 AND percentage <= 100),
+    char_offset INTEGER NOT NULL DEFAULT 0 CHECK (char_offset >= 0),
+    progress_updated_at TIMESTAMP WITH TIME ZONE,
+    last_opened_at TIMESTAMP WITH TIME ZONE,
+    read_at TIMESTAMP WITH TIME ZONE,
+    read_updated_at TIMESTAMP WITH TIME ZONE,
+    FOREIGN KEY (link_id) REFERENCES links(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX reading_states_user_id_last_opened_at_idx ON reading_states (user_id, last_opened_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS reading_states;
+-- +goose StatementEnd
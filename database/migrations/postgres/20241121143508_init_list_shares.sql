@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- password_hash is the bcrypt hash of the password of the share, empty if the share is public
+CREATE TABLE list_shares (
+    list_id uuid PRIMARY KEY,
+    user_id uuid NOT NULL,
+    slug VARCHAR(32) NOT NULL UNIQUE,
+    password_hash TEXT NOT NULL DEFAULT '',
+    expires_at TIMESTAMP WITH TIME ZONE,
+    views BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    FOREIGN KEY (list_id) REFERENCES lists(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS list_shares;
+-- +goose StatementEnd
@@ -0,0 +1,51 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE list_members (
+    list_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    role VARCHAR(16) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (list_id, user_id),
+    FOREIGN KEY (list_id) REFERENCES lists(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX list_members_user_id_idx ON list_members(user_id);
+
+CREATE TABLE list_invitations (
+    id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+    list_id uuid NOT NULL,
+    inviter_id uuid NOT NULL,
+    email VARCHAR(256) NOT NULL,
+    role VARCHAR(16) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    accepted_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    FOREIGN KEY (list_id) REFERENCES lists(id) ON DELETE CASCADE,
+    FOREIGN KEY (inviter_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE list_activities (
+    id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+    list_id uuid NOT NULL,
+    actor_id uuid,
+    action VARCHAR(32) NOT NULL,
+    link_id uuid,
+    link_url TEXT NOT NULL DEFAULT '',
+    link_title TEXT NOT NULL DEFAULT '',
+    member_id uuid,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    FOREIGN KEY (list_id) REFERENCES lists(id) ON DELETE CASCADE,
+    FOREIGN KEY (actor_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX list_activities_list_id_created_at_idx ON list_activities(list_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS list_activities;
+DROP TABLE IF EXISTS list_invitations;
+DROP TABLE IF EXISTS list_members;
+-- +goose StatementEnd
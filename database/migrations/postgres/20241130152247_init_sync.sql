@@ -0,0 +1,147 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE links ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE lists ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+
+-- sync_sequences holds the last change sequence number of each user. Taking the next one locks the row
+-- until the transaction ends, so the changes of a user become visible in the order of their numbers
+CREATE TABLE sync_sequences (
+    user_id uuid PRIMARY KEY,
+    seq BIGINT NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- sync_changes holds the last change of each entity, the deleted ones are kept as tombstones
+CREATE TABLE sync_changes (
+    user_id uuid NOT NULL,
+    entity_type VARCHAR(16) NOT NULL,
+    entity_id uuid NOT NULL,
+    seq BIGINT NOT NULL,
+    version BIGINT NOT NULL,
+    deleted BOOLEAN NOT NULL DEFAULT false,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, entity_type, entity_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX sync_changes_user_id_seq_idx ON sync_changes (user_id, seq);
+
+CREATE FUNCTION bump_version() RETURNS trigger AS $$
+BEGIN
+    NEW.version := OLD.version + 1;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- record_sync_change records the change of the row of the entity type passed as the argument
+CREATE FUNCTION record_sync_change() RETURNS trigger AS $$
+DECLARE
+    entity RECORD;
+    next_seq BIGINT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        entity := OLD;
+    ELSE
+        entity := NEW;
+    END IF;
+
+    -- the user is being deleted along with the entity
+    IF NOT EXISTS (SELECT 1 FROM users WHERE id = entity.user_id) THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO sync_sequences(user_id, seq) VALUES (entity.user_id, 1)
+    ON CONFLICT (user_id) DO UPDATE SET seq = sync_sequences.seq + 1
+    RETURNING seq INTO next_seq;
+
+    INSERT INTO sync_changes(user_id, entity_type, entity_id, seq, version, deleted, changed_at)
+    VALUES (entity.user_id, TG_ARGV[0], entity.id, next_seq, entity.version, TG_OP = 'DELETE', now())
+    ON CONFLICT (user_id, entity_type, entity_id) DO UPDATE
+    SET seq = EXCLUDED.seq, version = EXCLUDED.version, deleted = EXCLUDED.deleted, changed_at = EXCLUDED.changed_at;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- the tags of a link and the links of a list are synced as a part of them,
+-- so changing these touches the link or the list to record its change
+CREATE FUNCTION touch_tagged_link() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE links SET version = version WHERE id = OLD.link_id;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        UPDATE links SET version = version WHERE id = NEW.link_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION touch_renamed_tag_links() RETURNS trigger AS $$
+BEGIN
+    UPDATE links SET version = version WHERE id IN (SELECT link_id FROM link_tags WHERE tag_id = NEW.id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION touch_list() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE lists SET version = version WHERE id = OLD.list_id;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        UPDATE lists SET version = version WHERE id = NEW.list_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER links_bump_version BEFORE UPDATE ON links
+    FOR EACH ROW EXECUTE FUNCTION bump_version();
+CREATE TRIGGER links_record_sync_change AFTER INSERT OR UPDATE OR DELETE ON links
+    FOR EACH ROW EXECUTE FUNCTION record_sync_change('link');
+
+CREATE TRIGGER lists_bump_version BEFORE UPDATE ON lists
+    FOR EACH ROW EXECUTE FUNCTION bump_version();
+CREATE TRIGGER lists_record_sync_change AFTER INSERT OR UPDATE OR DELETE ON lists
+    FOR EACH ROW EXECUTE FUNCTION record_sync_change('list');
+
+CREATE TRIGGER link_tags_touch_link AFTER INSERT OR UPDATE OR DELETE ON link_tags
+    FOR EACH ROW EXECUTE FUNCTION touch_tagged_link();
+CREATE TRIGGER tags_touch_links AFTER UPDATE OF name ON tags
+    FOR EACH ROW EXECUTE FUNCTION touch_renamed_tag_links();
+CREATE TRIGGER list_links_touch_list AFTER INSERT OR UPDATE OR DELETE ON list_links
+    FOR EACH ROW EXECUTE FUNCTION touch_list();
+
+-- the existing data is synced as created
+INSERT INTO sync_sequences(user_id, seq)
+SELECT user_id, count(*) FROM (SELECT user_id FROM links UNION ALL SELECT user_id FROM lists) e GROUP BY user_id;
+
+INSERT INTO sync_changes(user_id, entity_type, entity_id, seq, version)
+SELECT user_id, entity_type, id, row_number() OVER (PARTITION BY user_id ORDER BY created_at, id), 1
+FROM (SELECT user_id, 'link' AS entity_type, id, created_at FROM links
+      UNION ALL
+      SELECT user_id, 'list', id, created_at FROM lists) e;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS list_links_touch_list ON list_links;
+DROP TRIGGER IF EXISTS tags_touch_links ON tags;
+DROP TRIGGER IF EXISTS link_tags_touch_link ON link_tags;
+DROP TRIGGER IF EXISTS lists_record_sync_change ON lists;
+DROP TRIGGER IF EXISTS lists_bump_version ON lists;
+DROP TRIGGER IF EXISTS links_record_sync_change ON links;
+DROP TRIGGER IF EXISTS links_bump_version ON links;
+
+DROP FUNCTION IF EXISTS touch_list();
+DROP FUNCTION IF EXISTS touch_renamed_tag_links();
+DROP FUNCTION IF EXISTS touch_tagged_link();
+DROP FUNCTION IF EXISTS record_sync_change();
+DROP FUNCTION IF EXISTS bump_version();
+
+DROP TABLE IF EXISTS sync_changes;
+DROP TABLE IF EXISTS sync_sequences;
+
+ALTER TABLE lists DROP COLUMN IF EXISTS version;
+ALTER TABLE links DROP COLUMN IF EXISTS version;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE feeds (
+    id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+    user_id uuid NOT NULL,
+    list_id uuid,
+    tag_id uuid,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    accessed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (list_id) REFERENCES lists(id) ON DELETE CASCADE,
+    FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE,
+    CHECK ((list_id IS NULL) <> (tag_id IS NULL))
+);
+
+CREATE UNIQUE INDEX feeds_user_id_list_id_idx ON feeds (user_id, list_id);
+CREATE UNIQUE INDEX feeds_user_id_tag_id_idx ON feeds (user_id, tag_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS feeds;
+-- +goose StatementEnd
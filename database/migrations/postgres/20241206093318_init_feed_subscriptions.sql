@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE feed_subscriptions (
+    id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+    user_id uuid NOT NULL,
+    url TEXT NOT NULL,
+    title VARCHAR(512) NOT NULL DEFAULT '',
+    list_id uuid,
+    tags JSONB NOT NULL DEFAULT '[]',
+    active BOOLEAN NOT NULL DEFAULT true,
+    etag TEXT NOT NULL DEFAULT '',
+    last_modified TEXT NOT NULL DEFAULT '',
+    failures INT NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    next_fetch_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    fetched_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    UNIQUE (user_id, url),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (list_id) REFERENCES lists(id) ON DELETE SET NULL
+);
+
+CREATE INDEX feed_subscriptions_due_idx ON feed_subscriptions(next_fetch_at) WHERE active;
+
+-- feed_subscription_items holds the hashes of the items already seen in each feed, so they are saved once
+CREATE TABLE feed_subscription_items (
+    subscription_id uuid NOT NULL,
+    item_hash CHAR(64) NOT NULL,
+    seen_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (subscription_id, item_hash),
+    FOREIGN KEY (subscription_id) REFERENCES feed_subscriptions(id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS feed_subscription_items;
+DROP TABLE IF EXISTS feed_subscriptions;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- jobs holds the queued and running jobs, the running ones having run_at set to the end of their lease.
+-- The completed jobs are deleted, the ones out of attempts are kept as dead
+CREATE TABLE jobs (
+    id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+    kind VARCHAR(64) NOT NULL,
+    payload BYTEA NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempt INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL,
+    run_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    unique_key TEXT NOT NULL DEFAULT '',
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX jobs_due_idx ON jobs(kind, run_at) WHERE status <> 'dead';
+CREATE UNIQUE INDEX jobs_unique_key_idx ON jobs(unique_key) WHERE unique_key <> '' AND status <> 'dead';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS jobs;
+-- +goose StatementEnd
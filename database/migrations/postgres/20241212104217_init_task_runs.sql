@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+-- task_runs is the history of the scheduled tasks. The scheduled runs are unique by the time they are due at,
+-- so each of them is run by one instance, the manual ones having no scheduled_at
+CREATE TABLE task_runs (
+    id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+    task VARCHAR(64) NOT NULL,
+    triggered_by VARCHAR(16) NOT NULL,
+    scheduled_at TIMESTAMP WITH TIME ZONE,
+    instance VARCHAR(256) NOT NULL DEFAULT '',
+    status VARCHAR(16) NOT NULL,
+    processed BIGINT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    finished_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE (task, scheduled_at)
+);
+
+CREATE INDEX task_runs_task_started_at_idx ON task_runs(task, started_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS task_runs;
+-- +goose StatementEnd
@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+-- link_health holds the outcome of the latest check of each link. It is kept apart from links,
+-- so the checks do not bump the versions of the links nor show up as their changes in the sync
+CREATE TABLE link_health (
+    link_id uuid PRIMARY KEY,
+    status_code INT NOT NULL DEFAULT 0,
+    final_url TEXT NOT NULL DEFAULT '',
+    response_time_ms BIGINT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    failures INT NOT NULL DEFAULT 0,
+    broken BOOLEAN NOT NULL DEFAULT false,
+    checked_at TIMESTAMP WITH TIME ZONE,
+    next_check_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    FOREIGN KEY (link_id) REFERENCES links(id) ON DELETE CASCADE
+);
+
+CREATE INDEX link_health_next_check_at_idx ON link_health(next_check_at);
+CREATE INDEX link_health_broken_idx ON link_health(link_id) WHERE broken;
+
+-- link_checks is the history of the checks, only the latest ones of each link are kept
+CREATE TABLE link_checks (
+    id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+    link_id uuid NOT NULL,
+    status_code INT NOT NULL,
+    final_url TEXT NOT NULL DEFAULT '',
+    response_time_ms BIGINT NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    ok BOOLEAN NOT NULL,
+    checked_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    FOREIGN KEY (link_id) REFERENCES links(id) ON DELETE CASCADE
+);
+
+CREATE INDEX link_checks_link_id_checked_at_idx ON link_checks(link_id, checked_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS link_checks;
+DROP TABLE IF EXISTS link_health;
+-- +goose StatementEnd
@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+-- snapshot_blobs are the files of the snapshots in the blob store, shared by the snapshots of the same content.
+-- The ones no snapshot refers to are deleted along with their files once they have not been touched for a while
+CREATE TABLE snapshot_blobs (
+    key TEXT PRIMARY KEY,
+    size BIGINT NOT NULL,
+    touched_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX snapshot_blobs_touched_at_idx ON snapshot_blobs(touched_at);
+
+CREATE TABLE snapshots (
+    id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+    user_id uuid NOT NULL,
+    link_id uuid NOT NULL,
+    format TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    content_hash TEXT NOT NULL DEFAULT '',
+    blob_key TEXT,
+    title TEXT NOT NULL DEFAULT '',
+    size BIGINT NOT NULL DEFAULT 0,
+    resources INT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    captured_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE (link_id, format),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (link_id) REFERENCES links(id) ON DELETE CASCADE,
+    FOREIGN KEY (blob_key) REFERENCES snapshot_blobs(key)
+);
+
+CREATE INDEX snapshots_user_id_idx ON snapshots(user_id);
+CREATE INDEX snapshots_blob_key_idx ON snapshots(blob_key);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS snapshots;
+DROP TABLE IF EXISTS snapshot_blobs;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- request_id is the id of the request the job has been queued by, empty for the ones queued by the workers
+ALTER TABLE jobs ADD COLUMN request_id VARCHAR(128) NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE jobs DROP COLUMN IF EXISTS request_id;
+-- +goose StatementEnd
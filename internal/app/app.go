@@ -5,25 +5,56 @@ import (
 	"errors"
 	"fmt"
 	"github.com/adanyl0v/go-pocket-link/internal/config"
+	grpcv1 "github.com/adanyl0v/go-pocket-link/internal/delivery/grpc/v1"
 	delivhttp "github.com/adanyl0v/go-pocket-link/internal/delivery/http"
 	httpv1 "github.com/adanyl0v/go-pocket-link/internal/delivery/http/v1"
 	"github.com/adanyl0v/go-pocket-link/internal/repository"
 	pgrep "github.com/adanyl0v/go-pocket-link/internal/repository/postgres"
 	redisrep "github.com/adanyl0v/go-pocket-link/internal/repository/redis"
 	"github.com/adanyl0v/go-pocket-link/internal/service"
+	"github.com/adanyl0v/go-pocket-link/pkg/archiver"
 	"github.com/adanyl0v/go-pocket-link/pkg/auth/jwt"
+	"github.com/adanyl0v/go-pocket-link/pkg/blob"
+	fsblob "github.com/adanyl0v/go-pocket-link/pkg/blob/fs"
+	s3blob "github.com/adanyl0v/go-pocket-link/pkg/blob/s3"
 	redisdb "github.com/adanyl0v/go-pocket-link/pkg/cache/redis"
 	"github.com/adanyl0v/go-pocket-link/pkg/crypto/hash"
 	pgdb "github.com/adanyl0v/go-pocket-link/pkg/database/postgres"
+	"github.com/adanyl0v/go-pocket-link/pkg/feed"
+	"github.com/adanyl0v/go-pocket-link/pkg/jobs"
+	pgjobs "github.com/adanyl0v/go-pocket-link/pkg/jobs/postgres"
+	redisjobs "github.com/adanyl0v/go-pocket-link/pkg/jobs/redis"
+	"github.com/adanyl0v/go-pocket-link/pkg/linkcheck"
+	"github.com/adanyl0v/go-pocket-link/pkg/logctx"
+	"github.com/adanyl0v/go-pocket-link/pkg/mail"
+	"github.com/adanyl0v/go-pocket-link/pkg/safehttp"
+	"github.com/adanyl0v/go-pocket-link/pkg/scheduler"
+	pgscheduler "github.com/adanyl0v/go-pocket-link/pkg/scheduler/postgres"
+	redisscheduler "github.com/adanyl0v/go-pocket-link/pkg/scheduler/redis"
+	"github.com/adanyl0v/go-pocket-link/pkg/tracing"
+	"github.com/adanyl0v/go-pocket-link/pkg/urlnorm"
 	"github.com/adanyl0v/go-pocket-link/pkg/validator"
+	"github.com/adanyl0v/go-pocket-link/pkg/webhook"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	sloggin "github.com/samber/slog-gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
 	"log"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
+	"time"
 )
 
 const (
@@ -37,43 +68,159 @@ func Run(configPath string) {
 	slog.Info("read config", "path", configPath)
 	slog.Info("set up logger", "env", cfg.Env)
 
+	shutdownTracing := mustSetupTracing(cfg)
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("failed to flush spans", logError, err)
+		}
+	}()
+
 	postgresDB := mustConnectToPostgres(cfg)
 	defer func() { _ = postgresDB.Close() }()
 
 	redisDB := mustConnectToRedis(cfg)
 	defer func() { _ = redisDB.Close() }()
 
+	prometheus.MustRegister(postgresDB.Collector(cfg.Storage.Postgres.Name), redisDB.Collector())
+
 	repos := &repository.Repositories{
-		Users:  pgrep.NewUsersRepository(postgresDB),
-		Tokens: redisrep.NewTokensRepository(redisDB),
+		Users:         pgrep.NewUsersRepository(postgresDB),
+		Tokens:        redisrep.NewTokensRepository(redisDB),
+		Links:         pgrep.NewLinksRepository(postgresDB),
+		Lists:         pgrep.NewListsRepository(postgresDB),
+		Members:       pgrep.NewListMembersRepository(postgresDB),
+		Invitations:   pgrep.NewListInvitationsRepository(postgresDB),
+		Activities:    pgrep.NewListActivitiesRepository(postgresDB),
+		Shares:        pgrep.NewListSharesRepository(postgresDB),
+		Feeds:         pgrep.NewFeedsRepository(postgresDB),
+		Tags:          pgrep.NewTagsRepository(postgresDB),
+		Highlights:    pgrep.NewHighlightsRepository(postgresDB),
+		Notes:         pgrep.NewNotesRepository(postgresDB),
+		Reading:       pgrep.NewReadingStatesRepository(postgresDB),
+		Webhooks:      pgrep.NewWebhooksRepository(postgresDB),
+		Deliveries:    pgrep.NewWebhookDeliveriesRepository(postgresDB),
+		Subscriptions: pgrep.NewSubscriptionsRepository(postgresDB),
+		LinkChecks:    pgrep.NewLinkChecksRepository(postgresDB),
+		Snapshots:     pgrep.NewSnapshotsRepository(postgresDB),
+		EventLog:      redisrep.NewEventLogRepository(redisDB, cfg.Events.LogSize, cfg.Events.LogTTL),
+		Sync:          pgrep.NewSyncRepository(postgresDB),
+		Imports:       pgrep.NewImportsRepository(postgresDB),
+		Exports:       pgrep.NewExportsRepository(postgresDB),
 	}
 	slog.Info("initialized repositories")
 
+	queue := mustCreateJobQueue(cfg, postgresDB, redisDB)
+	blobs, blobSigner := mustCreateBlobStore(cfg)
+	mailer := service.NewMailQueue(queue, newMailSender(cfg))
+
+	hasher := hash.NewSHA1Hasher(cfg.Hash.Salt)
+	events := service.NewEventBus()
 	services := service.Services{
-		Users: service.NewUsersService(repos.Users, hash.NewSHA1Hasher(cfg.Hash.Salt), validator.NewCredentialsValidator()),
+		Users: service.NewUsersService(repos.Users, hasher, validator.NewCredentialsValidator(), mustParseAdmins(cfg)),
 		Tokens: service.NewTokensService(repos.Tokens, jwt.NewTokenManager(cfg.Auth.AccessSecret, cfg.Auth.RefreshSecret,
 			jwt.StaticClaims{Issuer: "https://pocketlink.com", Audience: "https://api.pocketlink.com"}),
 			cfg.Auth.AccessTokenTTL, cfg.Auth.RefreshTokenTTL),
+		Links:       service.NewLinksService(repos.Links, repos.Tags, newURLNormalizer(cfg), events),
+		Lists:       service.NewListsService(repos.Lists, repos.Tags, repos.Members, repos.Activities, events),
+		Shares:      service.NewSharesService(repos.Shares, repos.Lists, repos.Tags, hash.NewBcryptHasher(bcrypt.DefaultCost)),
+		Tags:        service.NewTagsService(repos.Tags, events),
+		Annotations: service.NewAnnotationsService(repos.Highlights, repos.Notes, repos.Links),
+		Reading:     service.NewReadingService(repos.Reading, repos.Tags, events),
 	}
+	services.Members = service.NewMembersService(repos.Members, repos.Invitations, repos.Activities, repos.Users,
+		services.Lists, mailer, cfg.Lists.Invitations.TTL, cfg.Lists.Invitations.AcceptURL)
+	services.Feeds = service.NewFeedsService(repos.Feeds, repos.Tags, services.Lists, service.FeedsOptions{
+		BaseURL:    cfg.Feeds.BaseURL,
+		HomeURL:    cfg.Feeds.HomeURL,
+		ItemsLimit: cfg.Feeds.ItemsLimit,
+	})
+	services.Sync = service.NewSyncService(repos.Sync, repos.Links, repos.Lists, services.Links, services.Lists, services.Tags)
+	services.Imports = service.NewImportsService(repos.Imports, services.Links, services.Lists, services.Reading, blobs, queue)
+	services.Exports = service.NewExportsService(repos.Exports, repos.Users, repos.Links, repos.Lists, repos.Tags,
+		repos.Highlights, repos.Notes, blobs, cfg.Export.TTL, queue)
+	services.Webhooks = newWebhooksService(cfg, repos)
+	services.Subscriptions = newSubscriptionsService(cfg, repos, services)
+	services.LinkChecks = newLinkChecksService(cfg, repos)
+	services.Snapshots = newSnapshotsService(cfg, repos, blobs, queue)
+	services.Blobs = service.NewBlobsService(blobs, blobSigner)
+	services.Streams = service.NewStreamsService(repos.EventLog, service.StreamsOptions{BufferSize: cfg.Events.BufferSize})
+	tasks := mustCreateScheduler(cfg, postgresDB, redisDB, services)
+	services.Tasks = service.NewTasksService(tasks, queue)
+	events.Subscribe(services.Webhooks)
+	events.Subscribe(services.Streams)
 	slog.Info("initialized services")
 
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	worker := jobs.NewWorker(queue, jobs.WorkerOptions{
+		Concurrency:     cfg.Jobs.Concurrency,
+		PollInterval:    cfg.Jobs.PollInterval,
+		Lease:           cfg.Jobs.Lease,
+		BaseDelay:       cfg.Jobs.BaseDelay,
+		MaxDelay:        cfg.Jobs.MaxDelay,
+		ShutdownTimeout: cfg.Jobs.ShutdownTimeout,
+	})
+	worker.Handle(service.JobSendMail, mailer.HandleJob)
+	worker.Handle(service.JobRunImport, services.Imports.HandleJob)
+	worker.Handle(service.JobRunExport, services.Exports.HandleJob)
+	worker.Handle(service.JobRunTask, services.Tasks.HandleJob)
+	worker.Handle(service.JobCaptureSnapshot, services.Snapshots.HandleJob)
+
+	var workers sync.WaitGroup
+	workers.Add(4)
+	go func() {
+		defer workers.Done()
+		worker.Run(ctx)
+	}()
+	go func() {
+		defer workers.Done()
+		services.Webhooks.Run(ctx)
+	}()
+	go func() {
+		defer workers.Done()
+		tasks.Run(ctx)
+	}()
+	go func() {
+		defer workers.Done()
+		services.Streams.Run(ctx)
+	}()
+	slog.Info("started workers")
+
 	router := gin.New()
-	router.Use(gin.Recovery())
+	// the handlers pass the gin contexts to the services, which must see the values of the request contexts
+	router.ContextWithFallback = true
+	// the metrics and the spans come first to cover the requests the recovery answers
+	router.Use(delivhttp.UseMetrics, otelgin.Middleware(cfg.Tracing.ServiceName), gin.Recovery(),
+		delivhttp.UseRequestID)
 
 	mustSetupRouterLogger(router, cfg.Env)
 	//TODO: how about adding ELK support?
 
-	delivhttp.InitRouter(router, httpv1.NewHandler(&services))
+	v1Handler := httpv1.NewHandler(&services)
+	delivhttp.InitRouter(router, v1Handler)
 	slog.Info("initialized router")
 
 	server := http.Server{
 		Addr:         fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port),
-		Handler:      router,
+		Handler:      delivhttp.WithResponseController(router),
 		ReadTimeout:  cfg.Server.ReadTimeout,
 		WriteTimeout: cfg.Server.WriteTimeout,
 		IdleTimeout:  cfg.Server.IdleTimeout,
 	}
-	mustListenAndServe(&server)
+
+	grpcHandler := grpcv1.NewHandler(&services)
+	grpcServer := grpc.NewServer(grpcHandler.ServerOptions()...)
+	grpcHandler.Register(grpcServer)
+	if cfg.GRPC.Reflection {
+		reflection.Register(grpcServer)
+	}
+	slog.Info("initialized grpc server")
+
+	grpcAddr := fmt.Sprintf("%s:%d", cfg.GRPC.Host, cfg.GRPC.Port)
+	mustListenAndServe(ctx, &server, newMetricsServer(cfg), grpcServer, grpcAddr, &workers)
 }
 
 func mustReadConfig(reader config.Reader) *config.Config {
@@ -86,45 +233,47 @@ func mustReadConfig(reader config.Reader) *config.Config {
 }
 
 func mustSetupLogger(env string) {
-	var logger *slog.Logger
+	var handler slog.Handler
 
 	switch env {
 	case config.EnvLocal:
-		logger = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		handler = slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
 			AddSource: true,
 			Level:     slog.LevelDebug,
-		}))
+		})
 	case config.EnvProd:
-		logger = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		handler = slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
 			AddSource: true,
 			Level:     slog.LevelWarn,
-		}))
+		})
 	case config.EnvDev:
-		logger = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		handler = slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
 			AddSource: true,
 			Level:     slog.LevelDebug,
-		}))
+		})
 	default:
 		log.Fatalln("unknown env", env)
 	}
 
-	slog.SetDefault(logger)
+	// the lines logged with the contexts of the requests are tagged with their request and user ids
+	slog.SetDefault(slog.New(logctx.NewHandler(handler)))
 }
 
 func mustSetupRouterLogger(router *gin.Engine, env string) {
-	var logger *slog.Logger
+	var handler slog.Handler
 
 	switch env {
 	case config.EnvLocal:
-		logger = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug,
-			AddSource: true})).With("env", env).With("mode", os.Getenv(gin.EnvGinMode))
+		handler = slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug, AddSource: true,
+			ReplaceAttr: delivhttp.RedactQuery})
 	case config.EnvProd:
-		logger = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo,
-			AddSource: true})).With("env", env).With("mode", os.Getenv(gin.EnvGinMode))
+		handler = slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo, AddSource: true,
+			ReplaceAttr: delivhttp.RedactQuery})
 	case config.EnvDev:
-		logger = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug,
-			AddSource: true})).With("env", env).With("mode", os.Getenv(gin.EnvGinMode))
+		handler = slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug, AddSource: true,
+			ReplaceAttr: delivhttp.RedactQuery})
 	}
+	logger := slog.New(logctx.NewHandler(handler)).With("env", env).With("mode", os.Getenv(gin.EnvGinMode))
 
 	loggerConfig := sloggin.Config{
 		DefaultLevel:     slog.LevelDebug,
@@ -138,6 +287,29 @@ func mustSetupRouterLogger(router *gin.Engine, env string) {
 	router.Use(sloggin.NewWithConfig(logger, loggerConfig))
 }
 
+// mustSetupTracing exports the spans if the tracing is enabled, returning the shutdown flushing them.
+// The trace context of the requests is passed on either way, so the traces of the callers are not broken
+func mustSetupTracing(cfg *config.Config) func(context.Context) error {
+	otel.SetTextMapPropagator(tracing.Propagator())
+	if !cfg.Tracing.Enabled {
+		return func(context.Context) error { return nil }
+	}
+
+	shutdown, err := tracing.Setup(context.Background(), tracing.Options{
+		ServiceName: cfg.Tracing.ServiceName,
+		Environment: cfg.Env,
+		Protocol:    cfg.Tracing.Protocol,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	slog.Info("set up tracing", "endpoint", cfg.Tracing.Endpoint, "sample_ratio", cfg.Tracing.SampleRatio)
+	return shutdown
+}
+
 func mustConnectToPostgres(cfg *config.Config) *pgdb.DB {
 	dsn := fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s",
 		cfg.Storage.Postgres.User, cfg.Storage.Postgres.Password,
@@ -175,10 +347,229 @@ func mustConnectToRedis(cfg *config.Config) *redisdb.DB {
 	return db
 }
 
-func mustListenAndServe(server *http.Server) {
-	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-	defer cancel()
+func newURLNormalizer(cfg *config.Config) *urlnorm.Normalizer {
+	opts := urlnorm.DefaultOptions()
+	opts.StripWWW = cfg.Links.Normalization.StripWWW
+	opts.ForceHTTPS = cfg.Links.Normalization.ForceHTTPS
+	opts.TrackingParams = append(opts.TrackingParams, cfg.Links.Normalization.TrackingParams...)
+	return urlnorm.New(opts)
+}
+
+func newMailSender(cfg *config.Config) mail.Sender {
+	if cfg.Mail.Host == "" {
+		slog.Warn("mail host is not set, mails will only be logged")
+		return mail.NewLogSender()
+	}
+	return mail.NewSMTPSender(cfg.Mail.Host, cfg.Mail.Port, cfg.Mail.Username, cfg.Mail.Password, cfg.Mail.From)
+}
+
+func mustCreateJobQueue(cfg *config.Config, postgresDB *pgdb.DB, redisDB *redisdb.DB) jobs.Queue {
+	switch cfg.Jobs.Backend {
+	case config.JobsBackendPostgres:
+		return pgjobs.NewQueue(postgresDB)
+	case config.JobsBackendRedis:
+		return redisjobs.NewQueue(redisDB, "jobs:")
+	default:
+		log.Fatalf("unknown jobs backend %q", cfg.Jobs.Backend)
+		return nil
+	}
+}
+
+// mustCreateBlobStore makes the store of the blobs the config tells, along with the signer of the urls the app
+// serves its blobs from, nil if the store signs them itself or no secret is set
+func mustCreateBlobStore(cfg *config.Config) (blob.Store, *blob.URLSigner) {
+	switch cfg.Blob.Backend {
+	case config.BlobBackendFS:
+		var signer *blob.URLSigner
+		if cfg.Blob.URLSecret != "" {
+			signer = blob.NewURLSigner(cfg.Blob.BaseURL, cfg.Blob.URLSecret)
+		}
+		return fsblob.New(cfg.Blob.Dir, signer), signer
+	case config.BlobBackendS3:
+		// the timeout of the client would cut the long downloads, so only the headers are timed out
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.ResponseHeaderTimeout = cfg.Blob.S3.Timeout
+		store, err := s3blob.New(instrumented(&http.Client{Transport: transport}), s3blob.Options{
+			Endpoint:        cfg.Blob.S3.Endpoint,
+			Region:          cfg.Blob.S3.Region,
+			Bucket:          cfg.Blob.S3.Bucket,
+			AccessKeyID:     cfg.Blob.S3.AccessKeyID,
+			SecretAccessKey: cfg.Blob.S3.SecretAccessKey,
+			PathStyle:       cfg.Blob.S3.PathStyle,
+		})
+		if err != nil {
+			log.Fatalf("failed to create s3 blob store: %v", err)
+		}
+		return store, nil
+	default:
+		log.Fatalf("unknown blob backend %q", cfg.Blob.Backend)
+		return nil, nil
+	}
+}
+
+// instrumented makes the client trace its requests and pass the request ids and the trace context
+// of their contexts on with them
+func instrumented(client *http.Client) *http.Client {
+	client.Transport = otelhttp.NewTransport(logctx.NewTransport(client.Transport))
+	return client
+}
+
+func newWebhooksService(cfg *config.Config, repos *repository.Repositories) *service.WebhooksService {
+	httpClient := &http.Client{Timeout: cfg.Webhooks.Timeout}
+	if !cfg.Webhooks.AllowPrivateNetworks {
+		httpClient = safehttp.NewPublicClient(cfg.Webhooks.Timeout)
+	}
+
+	return service.NewWebhooksService(repos.Webhooks, repos.Deliveries, webhook.NewClient(instrumented(httpClient)),
+		service.WebhookDeliveryOptions{
+			PollInterval: cfg.Webhooks.PollInterval,
+			BatchSize:    cfg.Webhooks.BatchSize,
+			MaxAttempts:  cfg.Webhooks.MaxAttempts,
+			BaseDelay:    cfg.Webhooks.BaseDelay,
+			MaxDelay:     cfg.Webhooks.MaxDelay,
+			Lease:        cfg.Webhooks.Timeout + time.Minute,
+		})
+}
+
+func newSubscriptionsService(cfg *config.Config, repos *repository.Repositories, services service.Services) *service.SubscriptionsService {
+	httpClient := &http.Client{Timeout: cfg.Subscriptions.Timeout}
+	if !cfg.Subscriptions.AllowPrivateNetworks {
+		httpClient = safehttp.NewPublicClient(cfg.Subscriptions.Timeout)
+	}
+
+	return service.NewSubscriptionsService(repos.Subscriptions, services.Links, services.Lists, feed.NewFetcher(instrumented(httpClient)),
+		service.SubscriptionsOptions{
+			FetchInterval: cfg.Subscriptions.FetchInterval,
+			MaxDelay:      cfg.Subscriptions.MaxDelay,
+			BatchSize:     cfg.Subscriptions.BatchSize,
+			ItemsLimit:    cfg.Subscriptions.ItemsLimit,
+			Lease:         cfg.Subscriptions.Timeout + time.Minute,
+		})
+}
+
+func newLinkChecksService(cfg *config.Config, repos *repository.Repositories) *service.LinkChecksService {
+	httpClient := &http.Client{Timeout: cfg.LinkChecks.Timeout}
+	if !cfg.LinkChecks.AllowPrivateNetworks {
+		httpClient = safehttp.NewPublicClient(cfg.LinkChecks.Timeout)
+	}
+
+	checker := linkcheck.New(instrumented(httpClient), linkcheck.Options{
+		UserAgent:    cfg.LinkChecks.UserAgent,
+		HostInterval: cfg.LinkChecks.HostInterval,
+		RobotsTTL:    cfg.LinkChecks.RobotsTTL,
+	})
+	return service.NewLinkChecksService(repos.LinkChecks, repos.Links, checker, service.LinkChecksOptions{
+		CheckInterval: cfg.LinkChecks.CheckInterval,
+		RetryInterval: cfg.LinkChecks.RetryInterval,
+		BrokenAfter:   cfg.LinkChecks.BrokenAfter,
+		BatchSize:     cfg.LinkChecks.BatchSize,
+		Concurrency:   cfg.LinkChecks.Concurrency,
+		HistorySize:   cfg.LinkChecks.HistorySize,
+	})
+}
+
+func newSnapshotsService(cfg *config.Config, repos *repository.Repositories, store blob.Store,
+	queue jobs.Queue) *service.SnapshotsService {
+	httpClient := &http.Client{Timeout: cfg.Snapshots.Timeout}
+	if !cfg.Snapshots.AllowPrivateNetworks {
+		httpClient = safehttp.NewPublicClient(cfg.Snapshots.Timeout)
+	}
+
+	pages := archiver.New(instrumented(httpClient), archiver.Options{
+		UserAgent:    cfg.Snapshots.UserAgent,
+		MaxPageSize:  cfg.Snapshots.MaxPageSize,
+		MaxAssetSize: cfg.Snapshots.MaxAssetSize,
+		MaxAssets:    cfg.Snapshots.MaxAssets,
+		MaxTotalSize: cfg.Snapshots.MaxTotalSize,
+		Concurrency:  cfg.Snapshots.Concurrency,
+	})
+	return service.NewSnapshotsService(repos.Snapshots, repos.Links, pages, store, queue, service.SnapshotsOptions{
+		Quota:       cfg.Snapshots.Quota,
+		OrphanTTL:   cfg.Snapshots.OrphanTTL,
+		OrphanBatch: 100,
+	})
+}
+
+func mustParseAdmins(cfg *config.Config) []uuid.UUID {
+	admins := make([]uuid.UUID, 0, len(cfg.Admin.UserIDs))
+	for _, raw := range cfg.Admin.UserIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			log.Fatalf("invalid admin user id %q: %v", raw, err)
+		}
+		admins = append(admins, id)
+	}
+	return admins
+}
+
+// mustCreateScheduler makes the scheduler of the maintenance tasks, locking them where the config tells
+func mustCreateScheduler(cfg *config.Config, postgresDB *pgdb.DB, redisDB *redisdb.DB, services service.Services) *scheduler.Scheduler {
+	var locker scheduler.Locker
+	switch cfg.Scheduler.Lock {
+	case config.SchedulerLockPostgres:
+		locker = pgscheduler.NewLocker(postgresDB)
+	case config.SchedulerLockRedis:
+		locker = redisscheduler.NewLocker(redisDB, "scheduler:lock:", time.Minute)
+	default:
+		log.Fatalf("unknown scheduler lock %q", cfg.Scheduler.Lock)
+	}
+
+	location, err := time.LoadLocation(cfg.Scheduler.Timezone)
+	if err != nil {
+		log.Fatalf("invalid scheduler timezone %q: %v", cfg.Scheduler.Timezone, err)
+	}
+	instance, _ := os.Hostname()
+
+	tasks := scheduler.New(locker, pgscheduler.NewHistory(postgresDB), scheduler.Options{
+		Instance:   instance,
+		Location:   location,
+		HistoryTTL: cfg.Scheduler.HistoryTTL,
+	})
+
+	schedules := cfg.Scheduler.Tasks
+	for _, task := range []struct {
+		name     string
+		schedule string
+		fn       scheduler.Func
+	}{
+		{service.TaskPurgeAccounts, schedules.PurgeAccounts, func(ctx context.Context) (int64, error) {
+			return services.Users.Purge(ctx, time.Now().Add(-cfg.Scheduler.AccountsRetention))
+		}},
+		{service.TaskRefreshSubscriptions, schedules.RefreshSubscriptions, services.Subscriptions.RefreshDue},
+		{service.TaskCleanShares, schedules.CleanShares, func(ctx context.Context) (int64, error) {
+			return services.Shares.DeleteExpired(ctx, time.Now())
+		}},
+		{service.TaskCheckLinks, schedules.CheckLinks, services.LinkChecks.CheckDue},
+		{service.TaskCleanSnapshots, schedules.CleanSnapshots, services.Snapshots.DeleteOrphans},
+		{service.TaskCleanExports, schedules.CleanExports, services.Exports.DeleteExpired},
+	} {
+		if err = tasks.Add(task.name, task.schedule, cfg.Scheduler.TaskTimeout, task.fn); err != nil {
+			log.Fatal(err)
+		}
+	}
+	return tasks
+}
 
+// newMetricsServer makes the admin server of the metrics, nil if they are disabled
+func newMetricsServer(cfg *config.Config) *http.Server {
+	if !cfg.Metrics.Enabled {
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(cfg.Metrics.Path, promhttp.Handler())
+	return &http.Server{
+		Addr:        fmt.Sprintf("%s:%d", cfg.Metrics.Host, cfg.Metrics.Port),
+		Handler:     mux,
+		ReadTimeout: cfg.Server.ReadTimeout,
+		IdleTimeout: cfg.Server.IdleTimeout,
+	}
+}
+
+// mustListenAndServe serves both apis and the metrics, unless the metrics server is nil, until the context
+// is canceled, then shuts the servers down and waits for the workers to finish their running jobs
+func mustListenAndServe(ctx context.Context, server, metricsServer *http.Server, grpcServer *grpc.Server, grpcAddr string,
+	workers *sync.WaitGroup) {
 	go func() {
 		slog.Info("listening...", "addr", server.Addr)
 		err := server.ListenAndServe()
@@ -188,11 +579,42 @@ func mustListenAndServe(server *http.Server) {
 		}
 	}()
 
+	if metricsServer != nil {
+		go func() {
+			slog.Info("listening metrics...", "addr", metricsServer.Addr)
+			err := metricsServer.ListenAndServe()
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("", logError, err)
+				os.Exit(1)
+			}
+		}()
+	}
+
+	listener, err := net.Listen("tcp", grpcAddr)
+	if err != nil {
+		slog.Error("", logError, err)
+		os.Exit(1)
+	}
+	go func() {
+		slog.Info("listening grpc...", "addr", grpcAddr)
+		if err := grpcServer.Serve(listener); err != nil {
+			slog.Error("", logError, err)
+			os.Exit(1)
+		}
+	}()
+
 	<-ctx.Done()
 	slog.Info("shutting down server...")
-	if err := server.Shutdown(context.Background()); err != nil {
+	if err = server.Shutdown(context.Background()); err != nil {
 		slog.Error("", logError, err)
 		os.Exit(1)
 	}
+	grpcServer.GracefulStop()
+	if metricsServer != nil {
+		_ = metricsServer.Shutdown(context.Background())
+	}
+
+	slog.Info("stopping workers...")
+	workers.Wait()
 	slog.Info("shut down gracefully")
 }
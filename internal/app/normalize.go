@@ -0,0 +1,33 @@
+package app
+
+import (
+	"context"
+	"github.com/adanyl0v/go-pocket-link/internal/config"
+	pgrep "github.com/adanyl0v/go-pocket-link/internal/repository/postgres"
+	"github.com/adanyl0v/go-pocket-link/internal/service"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// NormalizeLinks normalizes the urls of the saved links again with the normalization options of the config,
+// e.g. once the options change, since the migration adding the normalized urls only knows the default ones
+func NormalizeLinks(configPath string) {
+	cfg := mustReadConfig(config.NewFileReader(configPath))
+	mustSetupLogger(cfg.Env)
+
+	postgresDB := mustConnectToPostgres(cfg)
+	defer func() { _ = postgresDB.Close() }()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	links := service.NewLinksService(pgrep.NewLinksRepository(postgresDB), nil, newURLNormalizer(cfg), nil)
+	updated, merged, err := links.NormalizeURLs(ctx)
+	if err != nil {
+		slog.Error("failed to normalize links", "updated", updated, "merged", merged, logError, err)
+		os.Exit(1)
+	}
+	slog.Info("normalized links", "updated", updated, "merged", merged)
+}
@@ -8,6 +8,21 @@ const (
 	EnvDev   = "dev"
 )
 
+const (
+	JobsBackendPostgres = "postgres"
+	JobsBackendRedis    = "redis"
+)
+
+const (
+	SchedulerLockPostgres = "postgres"
+	SchedulerLockRedis    = "redis"
+)
+
+const (
+	BlobBackendFS = "fs"
+	BlobBackendS3 = "s3"
+)
+
 type Reader interface {
 	Read() (*Config, error)
 }
@@ -21,6 +36,31 @@ type Config struct {
 		WriteTimeout time.Duration `yaml:"write_timeout" env-required:"true"`
 		IdleTimeout  time.Duration `yaml:"idle_timeout" env-required:"true"`
 	} `yaml:"server" env-required:"true"`
+	// GRPC is the listener of the gRPC api, served next to the http one
+	GRPC struct {
+		Host string `yaml:"host" env-required:"true"`
+		Port int    `yaml:"port" env-default:"9090"`
+		// Reflection lets the tools like grpcurl discover the services
+		Reflection bool `yaml:"reflection" env-default:"false"`
+	} `yaml:"grpc" env-required:"true"`
+	// Metrics is the admin listener serving the prometheus metrics, apart from the apis so they are not exposed with them
+	Metrics struct {
+		Enabled bool   `yaml:"enabled" env-default:"true"`
+		Host    string `yaml:"host" env-default:"localhost"`
+		Port    int    `yaml:"port" env-default:"9100"`
+		Path    string `yaml:"path" env-default:"/metrics"`
+	} `yaml:"metrics"`
+	// Tracing exports the spans of the requests, queries and commands to an OTLP collector
+	Tracing struct {
+		Enabled     bool   `yaml:"enabled" env:"TRACING_ENABLED" env-default:"false"`
+		ServiceName string `yaml:"service_name" env-default:"pocket-link"`
+		// Protocol is how the spans are sent to the collector at Endpoint, either grpc or http
+		Protocol string `yaml:"protocol" env:"TRACING_PROTOCOL" env-default:"grpc"`
+		Endpoint string `yaml:"endpoint" env:"TRACING_ENDPOINT" env-default:"localhost:4317"`
+		Insecure bool   `yaml:"insecure" env:"TRACING_INSECURE" env-default:"false"`
+		// SampleRatio is the share of the new traces which are recorded, from 0 to 1
+		SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO" env-default:"1"`
+	} `yaml:"tracing"`
 	Storage struct {
 		Postgres struct {
 			Host            string        `env:"POSTGRES_HOST" env-required:"true"`
@@ -49,4 +89,158 @@ type Config struct {
 		AccessTokenTTL  time.Duration `yaml:"access_token_ttl" env-required:"true"`
 		RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl" env-required:"true"`
 	} `yaml:"auth" env-required:"true"`
+	Links struct {
+		Normalization struct {
+			StripWWW   bool `yaml:"strip_www" env-default:"false"`
+			ForceHTTPS bool `yaml:"force_https" env-default:"true"`
+			// TrackingParams are removed in addition to urlnorm.DefaultTrackingParams
+			TrackingParams []string `yaml:"tracking_params"`
+		} `yaml:"normalization"`
+	} `yaml:"links"`
+	Lists struct {
+		Invitations struct {
+			TTL time.Duration `yaml:"ttl" env-default:"168h"`
+			// AcceptURL is the page the invitation emails link to, with the token appended
+			AcceptURL string `yaml:"accept_url" env-default:"https://pocketlink.com/invitations/"`
+		} `yaml:"invitations"`
+	} `yaml:"lists"`
+	Feeds struct {
+		// BaseURL is where the feeds are served, with their tokens and formats appended
+		BaseURL    string `yaml:"base_url" env-default:"https://api.pocketlink.com/api/v1/feeds/"`
+		HomeURL    string `yaml:"home_url" env-default:"https://pocketlink.com"`
+		ItemsLimit int    `yaml:"items_limit" env-default:"50"`
+	} `yaml:"feeds"`
+	Mail struct {
+		// Host is empty when the mails are only logged
+		Host     string `env:"MAIL_HOST"`
+		Port     int    `env:"MAIL_PORT" env-default:"587"`
+		Username string `env:"MAIL_USERNAME"`
+		Password string `env:"MAIL_PASSWORD"`
+		From     string `yaml:"from" env:"MAIL_FROM" env-default:"noreply@pocketlink.com"`
+	} `yaml:"mail"`
+	Webhooks struct {
+		PollInterval time.Duration `yaml:"poll_interval" env-default:"5s"`
+		BatchSize    int           `yaml:"batch_size" env-default:"20"`
+		MaxAttempts  int           `yaml:"max_attempts" env-default:"8"`
+		BaseDelay    time.Duration `yaml:"base_delay" env-default:"30s"`
+		MaxDelay     time.Duration `yaml:"max_delay" env-default:"6h"`
+		Timeout      time.Duration `yaml:"timeout" env-default:"10s"`
+		// AllowPrivateNetworks lets the webhooks be sent to loopback and private addresses, e.g. in development
+		AllowPrivateNetworks bool `yaml:"allow_private_networks" env-default:"false"`
+	} `yaml:"webhooks"`
+	Subscriptions struct {
+		// FetchInterval is how often each feed is fetched, MaxDelay bounds the retries of the failing ones
+		FetchInterval time.Duration `yaml:"fetch_interval" env-default:"30m"`
+		MaxDelay      time.Duration `yaml:"max_delay" env-default:"24h"`
+		BatchSize     int           `yaml:"batch_size" env-default:"10"`
+		// ItemsLimit is how many new items of a feed are saved at once
+		ItemsLimit int           `yaml:"items_limit" env-default:"50"`
+		Timeout    time.Duration `yaml:"timeout" env-default:"15s"`
+		// AllowPrivateNetworks lets the feeds be fetched from loopback and private addresses, e.g. in development
+		AllowPrivateNetworks bool `yaml:"allow_private_networks" env-default:"false"`
+	} `yaml:"subscriptions"`
+	LinkChecks struct {
+		// UserAgent is sent with the checks, robots.txt naming the agent by its product token
+		UserAgent string `yaml:"user_agent" env-default:"PocketLinkBot/1.0 (+https://pocketlink.com/bot)"`
+		// CheckInterval is how often each link is checked, RetryInterval is the first delay before rechecking a failed one
+		CheckInterval time.Duration `yaml:"check_interval" env-default:"168h"`
+		RetryInterval time.Duration `yaml:"retry_interval" env-default:"1h"`
+		// BrokenAfter is how many checks in a row must fail for the link to be flagged as broken
+		BrokenAfter int `yaml:"broken_after" env-default:"3"`
+		BatchSize   int `yaml:"batch_size" env-default:"100"`
+		Concurrency int `yaml:"concurrency" env-default:"8"`
+		// HistorySize is how many latest checks of each link are kept
+		HistorySize int `yaml:"history_size" env-default:"20"`
+		// HostInterval is the least time between two requests to a host
+		HostInterval time.Duration `yaml:"host_interval" env-default:"1s"`
+		RobotsTTL    time.Duration `yaml:"robots_ttl" env-default:"24h"`
+		Timeout      time.Duration `yaml:"timeout" env-default:"15s"`
+		// AllowPrivateNetworks lets the links on loopback and private addresses be checked, e.g. in development
+		AllowPrivateNetworks bool `yaml:"allow_private_networks" env-default:"false"`
+	} `yaml:"link_checks"`
+	Snapshots struct {
+		UserAgent string `yaml:"user_agent" env-default:"PocketLinkBot/1.0 (+https://pocketlink.com/bot)"`
+		// Quota is how many bytes the snapshots of each user may take, 0 for no limit
+		Quota int64 `yaml:"quota" env:"SNAPSHOTS_QUOTA" env-default:"104857600"`
+		// MaxPageSize bounds the pages, MaxAssetSize each of their assets, and MaxTotalSize all assets of a page
+		MaxPageSize  int64 `yaml:"max_page_size" env-default:"5242880"`
+		MaxAssetSize int64 `yaml:"max_asset_size" env-default:"5242880"`
+		MaxTotalSize int64 `yaml:"max_total_size" env-default:"20971520"`
+		MaxAssets    int   `yaml:"max_assets" env-default:"100"`
+		// Concurrency is how many assets of a page are fetched in parallel
+		Concurrency int           `yaml:"concurrency" env-default:"4"`
+		Timeout     time.Duration `yaml:"timeout" env-default:"30s"`
+		// OrphanTTL is how long the files no snapshot refers to are kept before they are deleted
+		OrphanTTL time.Duration `yaml:"orphan_ttl" env-default:"1h"`
+		// AllowPrivateNetworks lets the pages on loopback and private addresses be captured, e.g. in development
+		AllowPrivateNetworks bool `yaml:"allow_private_networks" env-default:"false"`
+	} `yaml:"snapshots"`
+	Blob struct {
+		// Backend is where the blobs, e.g. the files of the snapshots and the export archives, are stored,
+		// either fs or s3
+		Backend string `yaml:"backend" env:"BLOB_BACKEND" env-default:"fs"`
+		// Dir is the directory of the fs backend
+		Dir string `yaml:"dir" env:"BLOB_DIR" env-default:"./data/blobs"`
+		// URLSecret signs the urls the app serves the blobs of the fs backend from, which are not given out if it is empty.
+		// BaseURL is where they are served, with the keys appended
+		URLSecret string `env:"BLOB_URL_SECRET"`
+		BaseURL   string `yaml:"base_url" env-default:"https://api.pocketlink.com/api/v1/blobs/"`
+		S3        struct {
+			Endpoint        string `yaml:"endpoint" env:"S3_ENDPOINT" env-default:"https://s3.amazonaws.com"`
+			Region          string `yaml:"region" env:"S3_REGION" env-default:"us-east-1"`
+			Bucket          string `yaml:"bucket" env:"S3_BUCKET"`
+			AccessKeyID     string `env:"S3_ACCESS_KEY_ID"`
+			SecretAccessKey string `env:"S3_SECRET_ACCESS_KEY"`
+			// PathStyle puts the bucket in the path instead of the host, which the stand-ins like MinIO expect
+			PathStyle bool `yaml:"path_style" env:"S3_PATH_STYLE" env-default:"false"`
+			// Timeout is how long the responses are waited for, not counting the reading of their bodies
+			Timeout time.Duration `yaml:"timeout" env-default:"1m"`
+		} `yaml:"s3"`
+	} `yaml:"blob"`
+	Jobs struct {
+		// Backend is the storage of the job queue, either postgres or redis
+		Backend      string        `yaml:"backend" env:"JOBS_BACKEND" env-default:"postgres"`
+		Concurrency  int           `yaml:"concurrency" env-default:"4"`
+		PollInterval time.Duration `yaml:"poll_interval" env-default:"1s"`
+		// Lease is how long a running job is kept from the other workers, it is extended while the job runs
+		Lease     time.Duration `yaml:"lease" env-default:"1m"`
+		BaseDelay time.Duration `yaml:"base_delay" env-default:"10s"`
+		MaxDelay  time.Duration `yaml:"max_delay" env-default:"1h"`
+		// ShutdownTimeout is how long the running jobs are waited for on shutdown before they are interrupted
+		ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env-default:"30s"`
+	} `yaml:"jobs"`
+	Scheduler struct {
+		// Lock is where the tasks are locked while running, either postgres or redis
+		Lock string `yaml:"lock" env:"SCHEDULER_LOCK" env-default:"postgres"`
+		// Timezone is the one the cron expressions are matched in
+		Timezone    string        `yaml:"timezone" env-default:"UTC"`
+		TaskTimeout time.Duration `yaml:"task_timeout" env-default:"30m"`
+		HistoryTTL  time.Duration `yaml:"history_ttl" env-default:"720h"`
+		// Tasks are the cron expressions of the tasks, "-" for the ones only run when triggered
+		Tasks struct {
+			PurgeAccounts        string `yaml:"purge_accounts" env-default:"0 3 * * *"`
+			RefreshSubscriptions string `yaml:"refresh_subscriptions" env-default:"@every 30s"`
+			CleanShares          string `yaml:"clean_shares" env-default:"0 * * * *"`
+			CheckLinks           string `yaml:"check_links" env-default:"*/10 * * * *"`
+			CleanSnapshots       string `yaml:"clean_snapshots" env-default:"15 * * * *"`
+			CleanExports         string `yaml:"clean_exports" env-default:"45 * * * *"`
+		} `yaml:"tasks"`
+		// AccountsRetention is how long the deleted accounts are kept before they are purged
+		AccountsRetention time.Duration `yaml:"accounts_retention" env-default:"720h"`
+	} `yaml:"scheduler"`
+	Admin struct {
+		// UserIDs are the users allowed to manage the instance, e.g. to trigger the scheduled tasks
+		UserIDs []string `yaml:"user_ids" env:"ADMIN_USER_IDS" env-separator:","`
+	} `yaml:"admin"`
+	Events struct {
+		// LogSize is about how many of the latest events of each user are kept for the reconnecting clients
+		LogSize int64         `yaml:"log_size" env-default:"1000"`
+		LogTTL  time.Duration `yaml:"log_ttl" env-default:"72h"`
+		// BufferSize is how many events a stream may lag behind before it is closed
+		BufferSize int `yaml:"buffer_size" env-default:"64"`
+	} `yaml:"events"`
+	Export struct {
+		// TTL is how long the archives of the async exports are kept in the blob store
+		TTL time.Duration `yaml:"ttl" env-default:"24h"`
+	} `yaml:"export"`
 }
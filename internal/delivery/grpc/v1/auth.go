@@ -0,0 +1,106 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/internal/service"
+	pb "github.com/adanyl0v/go-pocket-link/pkg/api/pocketlink/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"log/slog"
+)
+
+// authServer hands out both tokens of the pair, since there are no cookies to keep the refresh one in
+type authServer struct {
+	pb.UnimplementedAuthServiceServer
+	*Handler
+}
+
+func (s *authServer) SignUp(ctx context.Context, req *pb.SignUpRequest) (*pb.TokenPair, error) {
+	if err := validateCredentials(s.services.Users, req.GetName(), req.GetEmail(), req.GetPassword()); err != nil {
+		return nil, newError(codes.InvalidArgument, err.Error(), err)
+	}
+
+	user := domain.User{
+		Name:     req.GetName(),
+		Email:    req.GetEmail(),
+		Password: req.GetPassword(),
+	}
+	if err := s.services.Users.Save(ctx, &user); err != nil {
+		return nil, newError(codes.Internal, "failed to save user", err)
+	}
+
+	tokens, err := s.newTokenPair(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.DebugContext(ctx, "signed up", "id", user.ID)
+	return tokens, nil
+}
+
+func (s *authServer) SignIn(ctx context.Context, req *pb.SignInRequest) (*pb.TokenPair, error) {
+	if err := validateEmailAndPassword(s.services.Users, req.GetEmail(), req.GetPassword()); err != nil {
+		return nil, newError(codes.InvalidArgument, err.Error(), err)
+	}
+
+	user, err := s.services.Users.GetByCredentials(ctx, req.GetEmail(), req.GetPassword())
+	if errors.Is(err, domain.ErrInvalidCredentials) {
+		return nil, newError(codes.Unauthenticated, err.Error(), err)
+	} else if err != nil {
+		return nil, newError(codes.Internal, "failed to get user", err)
+	}
+
+	tokens, err := s.newTokenPair(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.DebugContext(ctx, "signed in", "id", user.ID)
+	return tokens, nil
+}
+
+func (s *authServer) LogOut(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.services.Tokens.InvalidateUser(ctx, userID); err != nil {
+		return nil, newError(codes.Internal, "failed to invalidate user", err)
+	}
+
+	slog.DebugContext(ctx, "invalidated user", "id", userID)
+	return &emptypb.Empty{}, nil
+}
+
+func (s *authServer) newTokenPair(ctx context.Context, user domain.User) (*pb.TokenPair, error) {
+	tokens, err := s.services.Tokens.NewTokenPair(user.ID)
+	if err != nil {
+		return nil, newError(codes.Internal, "failed to create token pair", err)
+	}
+
+	if err = s.services.Tokens.SaveRefreshTokenFromString(ctx, tokens.RefreshToken); err != nil {
+		return nil, newError(codes.Internal, "failed to save refresh token", err)
+	}
+
+	return &pb.TokenPair{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+	}, nil
+}
+
+func validateCredentials(s *service.UsersService, name, email, password string) error {
+	if err := s.ValidateName(name); err != nil {
+		return err
+	}
+	return validateEmailAndPassword(s, email, password)
+}
+
+func validateEmailAndPassword(s *service.UsersService, email, password string) error {
+	if err := s.ValidateEmail(email); err != nil {
+		return err
+	}
+	return s.ValidatePassword(password)
+}
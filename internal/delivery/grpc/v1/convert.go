@@ -0,0 +1,131 @@
+package v1
+
+import (
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	pb "github.com/adanyl0v/go-pocket-link/pkg/api/pocketlink/v1"
+	"github.com/adanyl0v/go-pocket-link/pkg/pagination"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"net/url"
+	"strconv"
+)
+
+func parseID(name, raw string) (uuid.UUID, error) {
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return uuid.Nil, newError(codes.InvalidArgument, "invalid "+name, err)
+	}
+	return id, nil
+}
+
+func parseIDs(name string, raw []string) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, 0, len(raw))
+	for _, r := range raw {
+		id, err := parseID(name, r)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// required checks the fields the http api binds with the required tag
+func required(name, value string) error {
+	if value == "" {
+		return newError(codes.InvalidArgument, name+" is required", nil)
+	}
+	return nil
+}
+
+// parsePageParams parses the page request as the query of a collection endpoint, so the same spec applies
+func parsePageParams(req *pb.PageRequest, spec pagination.Spec) (pagination.Params, error) {
+	values := url.Values{}
+	if req != nil {
+		if req.GetLimit() != 0 {
+			values.Set(pagination.ParamLimit, strconv.Itoa(int(req.GetLimit())))
+		}
+		if req.GetSort() != "" {
+			values.Set(pagination.ParamSort, req.GetSort())
+		}
+		if req.GetAfter() != "" {
+			values.Set(pagination.ParamAfter, req.GetAfter())
+		}
+		if req.GetBefore() != "" {
+			values.Set(pagination.ParamBefore, req.GetBefore())
+		}
+		for field, value := range req.GetFilters() {
+			values.Set(field, value)
+		}
+	}
+
+	params, err := spec.Parse(values)
+	if err != nil {
+		return pagination.Params{}, newError(codes.InvalidArgument, err.Error(), err)
+	}
+	return params, nil
+}
+
+func newPageInfo(info pagination.Info) *pb.PageInfo {
+	return &pb.PageInfo{
+		Limit:      int32(info.Limit),
+		HasNext:    info.HasNext,
+		HasPrev:    info.HasPrev,
+		NextCursor: info.NextCursor,
+		PrevCursor: info.PrevCursor,
+	}
+}
+
+func newUser(user domain.User) *pb.User {
+	return &pb.User{
+		Id:        user.ID.String(),
+		Name:      user.Name,
+		Email:     user.Email,
+		CreatedAt: timestamppb.New(user.CreatedAt),
+		UpdatedAt: timestamppb.New(user.UpdatedAt),
+	}
+}
+
+func newLink(link domain.Link) *pb.Link {
+	return &pb.Link{
+		Id:            link.ID.String(),
+		UserId:        link.UserID.String(),
+		Url:           link.URL,
+		NormalizedUrl: link.NormalizedURL,
+		Title:         link.Title,
+		Tags:          link.Tags,
+		Version:       link.Version,
+		CreatedAt:     timestamppb.New(link.CreatedAt),
+		UpdatedAt:     timestamppb.New(link.UpdatedAt),
+	}
+}
+
+func newLinks(links []domain.Link) []*pb.Link {
+	result := make([]*pb.Link, 0, len(links))
+	for _, link := range links {
+		result = append(result, newLink(link))
+	}
+	return result
+}
+
+func newList(list domain.List) *pb.List {
+	return &pb.List{
+		Id:        list.ID.String(),
+		UserId:    list.UserID.String(),
+		Title:     list.Title,
+		Version:   list.Version,
+		CreatedAt: timestamppb.New(list.CreatedAt),
+		UpdatedAt: timestamppb.New(list.UpdatedAt),
+	}
+}
+
+func newTag(tag domain.TagUsage) *pb.Tag {
+	return &pb.Tag{
+		Id:         tag.ID.String(),
+		UserId:     tag.UserID.String(),
+		Name:       tag.Name,
+		CreatedAt:  timestamppb.New(tag.CreatedAt),
+		LinksCount: int32(tag.LinksCount),
+	}
+}
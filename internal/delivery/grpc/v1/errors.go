@@ -0,0 +1,40 @@
+package v1
+
+import (
+	"errors"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/pkg/pagination"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log/slog"
+)
+
+func newError(code codes.Code, message string, err error) error {
+	if err == nil {
+		slog.Error(message)
+	} else {
+		slog.Error(message, logError, err)
+	}
+	return status.Error(code, message)
+}
+
+// newServiceError turns an error returned by a service into a status, choosing the code
+// by its domain error kind
+func newServiceError(message string, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput), errors.Is(err, pagination.ErrInvalidParams):
+		return newError(codes.InvalidArgument, err.Error(), err)
+	case errors.Is(err, domain.ErrNotFound):
+		return newError(codes.NotFound, err.Error(), err)
+	case errors.Is(err, domain.ErrAlreadyExists):
+		return newError(codes.AlreadyExists, err.Error(), err)
+	case errors.Is(err, domain.ErrConflict):
+		return newError(codes.Aborted, err.Error(), err)
+	case errors.Is(err, domain.ErrForbidden):
+		return newError(codes.PermissionDenied, err.Error(), err)
+	case errors.Is(err, domain.ErrUnavailable):
+		return newError(codes.Unavailable, err.Error(), err)
+	default:
+		return newError(codes.Internal, message, err)
+	}
+}
@@ -0,0 +1,129 @@
+package v1
+
+import (
+	"context"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/internal/service"
+	pb "github.com/adanyl0v/go-pocket-link/pkg/api/pocketlink/v1"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"log/slog"
+)
+
+type linksServer struct {
+	pb.UnimplementedLinksServiceServer
+	*Handler
+}
+
+func (s *linksServer) SaveLink(ctx context.Context, req *pb.SaveLinkRequest) (*pb.SaveLinkResponse, error) {
+	if err := required("url", req.GetUrl()); err != nil {
+		return nil, err
+	}
+
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	link := domain.Link{
+		UserID: userID,
+		URL:    req.GetUrl(),
+		Title:  req.GetTitle(),
+		Tags:   req.GetTags(),
+	}
+	created, err := s.services.Links.Save(ctx, &link)
+	if err != nil {
+		return nil, newServiceError("failed to save link", err)
+	}
+
+	slog.DebugContext(ctx, "saved link", "id", link.ID, "created", created)
+	return &pb.SaveLinkResponse{Link: newLink(link), Created: created}, nil
+}
+
+func (s *linksServer) GetLink(ctx context.Context, req *pb.GetLinkRequest) (*pb.Link, error) {
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := parseID("id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	link, err := s.services.Links.Get(ctx, userID, id)
+	if err != nil {
+		return nil, newServiceError("failed to get link", err)
+	}
+
+	slog.DebugContext(ctx, "got link", "id", link.ID)
+	return newLink(link), nil
+}
+
+func (s *linksServer) ListLinks(ctx context.Context, req *pb.ListLinksRequest) (*pb.ListLinksResponse, error) {
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := parsePageParams(req.GetPage(), service.LinksPageSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := s.services.Links.GetPage(ctx, userID, params)
+	if err != nil {
+		return nil, newServiceError("failed to get links", err)
+	}
+
+	slog.DebugContext(ctx, "got links", "count", len(page.Data))
+	return &pb.ListLinksResponse{Links: newLinks(page.Data), Page: newPageInfo(page.Page)}, nil
+}
+
+func (s *linksServer) UpdateLink(ctx context.Context, req *pb.UpdateLinkRequest) (*pb.Link, error) {
+	if err := required("url", req.GetUrl()); err != nil {
+		return nil, err
+	}
+
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := parseID("id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	link, err := s.services.Links.Get(ctx, userID, id)
+	if err != nil {
+		return nil, newServiceError("failed to get link", err)
+	}
+
+	link.URL = req.GetUrl()
+	link.Title = req.GetTitle()
+	if err = s.services.Links.Update(ctx, &link); err != nil {
+		return nil, newServiceError("failed to update link", err)
+	}
+
+	slog.DebugContext(ctx, "updated link", "id", link.ID)
+	return newLink(link), nil
+}
+
+func (s *linksServer) DeleteLink(ctx context.Context, req *pb.DeleteLinkRequest) (*emptypb.Empty, error) {
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := parseID("id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.services.Links.Delete(ctx, userID, id); err != nil {
+		return nil, newServiceError("failed to delete link", err)
+	}
+
+	slog.DebugContext(ctx, "deleted link", "id", id)
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,209 @@
+package v1
+
+import (
+	"context"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/internal/service"
+	pb "github.com/adanyl0v/go-pocket-link/pkg/api/pocketlink/v1"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"log/slog"
+)
+
+type listsServer struct {
+	pb.UnimplementedListsServiceServer
+	*Handler
+}
+
+func (s *listsServer) CreateList(ctx context.Context, req *pb.CreateListRequest) (*pb.List, error) {
+	if err := required("title", req.GetTitle()); err != nil {
+		return nil, err
+	}
+
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list := domain.List{
+		UserID: userID,
+		Title:  req.GetTitle(),
+	}
+	if err = s.services.Lists.Save(ctx, &list); err != nil {
+		return nil, newServiceError("failed to save list", err)
+	}
+
+	slog.DebugContext(ctx, "saved list", "id", list.ID)
+	return newList(list), nil
+}
+
+func (s *listsServer) GetList(ctx context.Context, req *pb.GetListRequest) (*pb.List, error) {
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := parseID("id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := s.services.Lists.Get(ctx, userID, id)
+	if err != nil {
+		return nil, newServiceError("failed to get list", err)
+	}
+
+	slog.DebugContext(ctx, "got list", "id", list.ID)
+	return newList(list), nil
+}
+
+func (s *listsServer) ListLists(ctx context.Context, req *pb.ListListsRequest) (*pb.ListListsResponse, error) {
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := parsePageParams(req.GetPage(), service.ListsPageSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := s.services.Lists.GetPage(ctx, userID, params)
+	if err != nil {
+		return nil, newServiceError("failed to get lists", err)
+	}
+
+	lists := make([]*pb.List, 0, len(page.Data))
+	for _, list := range page.Data {
+		lists = append(lists, newList(list))
+	}
+
+	slog.DebugContext(ctx, "got lists", "count", len(page.Data))
+	return &pb.ListListsResponse{Lists: lists, Page: newPageInfo(page.Page)}, nil
+}
+
+func (s *listsServer) UpdateList(ctx context.Context, req *pb.UpdateListRequest) (*pb.List, error) {
+	if err := required("title", req.GetTitle()); err != nil {
+		return nil, err
+	}
+
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := parseID("id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := s.services.Lists.Get(ctx, userID, id)
+	if err != nil {
+		return nil, newServiceError("failed to get list", err)
+	}
+
+	list.Title = req.GetTitle()
+	if err = s.services.Lists.Update(ctx, userID, &list); err != nil {
+		return nil, newServiceError("failed to update list", err)
+	}
+
+	slog.DebugContext(ctx, "updated list", "id", list.ID)
+	return newList(list), nil
+}
+
+func (s *listsServer) DeleteList(ctx context.Context, req *pb.DeleteListRequest) (*emptypb.Empty, error) {
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := parseID("id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.services.Lists.Delete(ctx, userID, id); err != nil {
+		return nil, newServiceError("failed to delete list", err)
+	}
+
+	slog.DebugContext(ctx, "deleted list", "id", id)
+	return &emptypb.Empty{}, nil
+}
+
+func (s *listsServer) ListListLinks(ctx context.Context, req *pb.ListListLinksRequest) (*pb.ListLinksResponse, error) {
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := parseID("id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := parsePageParams(req.GetPage(), service.LinksPageSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := s.services.Lists.GetLinksPage(ctx, userID, id, params)
+	if err != nil {
+		return nil, newServiceError("failed to get list links", err)
+	}
+
+	slog.DebugContext(ctx, "got list links", "id", id, "count", len(page.Data))
+	return &pb.ListLinksResponse{Links: newLinks(page.Data), Page: newPageInfo(page.Page)}, nil
+}
+
+func (s *listsServer) AddListLinks(ctx context.Context, req *pb.AddListLinksRequest) (*emptypb.Empty, error) {
+	if len(req.GetLinkIds()) == 0 {
+		return nil, newError(codes.InvalidArgument, "link_ids are required", nil)
+	}
+
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := parseID("id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	linkIDs, err := parseIDs("link_ids", req.GetLinkIds())
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.services.Lists.AddLinks(ctx, userID, id, linkIDs); err != nil {
+		return nil, newServiceError("failed to add links to list", err)
+	}
+
+	slog.DebugContext(ctx, "added links to list", "id", id, "count", len(linkIDs))
+	return &emptypb.Empty{}, nil
+}
+
+func (s *listsServer) RemoveListLink(ctx context.Context, req *pb.RemoveListLinkRequest) (*emptypb.Empty, error) {
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := parseID("id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	linkID, err := parseID("link_id", req.GetLinkId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.services.Lists.RemoveLinks(ctx, userID, id, []uuid.UUID{linkID}); err != nil {
+		return nil, newServiceError("failed to remove link from list", err)
+	}
+
+	slog.DebugContext(ctx, "removed link from list", "id", id, "link_id", linkID)
+	return &emptypb.Empty{}, nil
+}
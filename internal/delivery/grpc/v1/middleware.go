@@ -0,0 +1,123 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	pb "github.com/adanyl0v/go-pocket-link/pkg/api/pocketlink/v1"
+	"github.com/adanyl0v/go-pocket-link/pkg/auth/jwt"
+	"github.com/adanyl0v/go-pocket-link/pkg/logctx"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"log/slog"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+const (
+	metadataAuthorization = "authorization"
+	metadataRequestID     = "x-request-id"
+)
+
+type contextKey int
+
+const contextUserID contextKey = iota
+
+// publicMethods are called without an access token
+var publicMethods = map[string]bool{
+	pb.AuthService_SignUp_FullMethodName: true,
+	pb.AuthService_SignIn_FullMethodName: true,
+}
+
+// authUnary validates the access token of the authorization metadata the same way as the http api does,
+// putting the id of the user into the context
+func (h *Handler) authUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if publicMethods[info.FullMethod] {
+		return handler(ctx, req)
+	}
+
+	token, err := parseAuthMetadata(ctx)
+	if err != nil {
+		return nil, newError(codes.Unauthenticated, err.Error(), nil)
+	}
+
+	accessTokenClaims, err := h.services.Tokens.ValidateAccessToken(token)
+	if err != nil {
+		return nil, newError(codes.Unauthenticated, "invalid access token claims", err)
+	}
+
+	rawUserID, ok := accessTokenClaims[jwt.ClaimsSubject].(string)
+	if !ok {
+		return nil, newError(codes.Unauthenticated, "missed user id", nil)
+	}
+
+	userID, err := uuid.Parse(rawUserID)
+	if err != nil {
+		return nil, newError(codes.Unauthenticated, "failed to parse user id", err)
+	}
+	ctx = logctx.WithUserID(context.WithValue(ctx, contextUserID, userID), rawUserID)
+	return handler(ctx, req)
+}
+
+// requestIDUnary tags the call with the request id of its metadata, or a new one, and answers with it
+// in the header, the way the http api does
+func requestIDUnary(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	var id string
+	if values := metadata.ValueFromIncomingContext(ctx, metadataRequestID); len(values) > 0 {
+		id = values[0]
+	}
+	if !logctx.ValidRequestID(id) {
+		id = logctx.NewRequestID()
+	}
+
+	if err := grpc.SetHeader(ctx, metadata.Pairs(metadataRequestID, id)); err != nil {
+		slog.WarnContext(ctx, "failed to set request id header", logError, err)
+	}
+	return handler(logctx.WithRequestID(ctx, id), req)
+}
+
+// logUnary logs the calls like the http logger does the requests
+func logUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	slog.InfoContext(ctx, "grpc call", "method", info.FullMethod, "code", status.Code(err).String(),
+		"latency", time.Since(start))
+	return resp, err
+}
+
+// recoverUnary keeps a panicking call from bringing the server down
+func recoverUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.ErrorContext(ctx, "grpc call panicked", "method", info.FullMethod, "panic", r, "stack", string(debug.Stack()))
+			err = status.Error(codes.Internal, "internal error")
+		}
+	}()
+	return handler(ctx, req)
+}
+
+func parseAuthMetadata(ctx context.Context) (string, error) {
+	values := metadata.ValueFromIncomingContext(ctx, metadataAuthorization)
+	if len(values) == 0 {
+		return "", fmt.Errorf("no %s metadata", metadataAuthorization)
+	}
+
+	parts := strings.Split(values[0], " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return "", fmt.Errorf("invalid %s metadata", metadataAuthorization)
+	}
+
+	return parts[1], nil
+}
+
+// getUserID retrieves the user id set by authUnary
+func getUserID(ctx context.Context) (uuid.UUID, error) {
+	userID, ok := ctx.Value(contextUserID).(uuid.UUID)
+	if !ok {
+		return uuid.Nil, newError(codes.Unauthenticated, "no user id in context", nil)
+	}
+	return userID, nil
+}
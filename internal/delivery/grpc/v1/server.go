@@ -0,0 +1,33 @@
+package v1
+
+import (
+	"github.com/adanyl0v/go-pocket-link/internal/service"
+	pb "github.com/adanyl0v/go-pocket-link/pkg/api/pocketlink/v1"
+	"google.golang.org/grpc"
+)
+
+const logError = "error"
+
+// Handler serves the gRPC api on top of the same services as the http one
+type Handler struct {
+	services *service.Services
+}
+
+func NewHandler(services *service.Services) *Handler {
+	return &Handler{services: services}
+}
+
+// ServerOptions returns the interceptors the services registered by Register expect
+func (h *Handler) ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(requestIDUnary, recoverUnary, logUnary, h.authUnary),
+	}
+}
+
+func (h *Handler) Register(server *grpc.Server) {
+	pb.RegisterAuthServiceServer(server, &authServer{Handler: h})
+	pb.RegisterUsersServiceServer(server, &usersServer{Handler: h})
+	pb.RegisterLinksServiceServer(server, &linksServer{Handler: h})
+	pb.RegisterListsServiceServer(server, &listsServer{Handler: h})
+	pb.RegisterTagsServiceServer(server, &tagsServer{Handler: h})
+}
@@ -0,0 +1,136 @@
+package v1
+
+import (
+	"context"
+	"github.com/adanyl0v/go-pocket-link/internal/service"
+	pb "github.com/adanyl0v/go-pocket-link/pkg/api/pocketlink/v1"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"log/slog"
+)
+
+type tagsServer struct {
+	pb.UnimplementedTagsServiceServer
+	*Handler
+}
+
+func (s *tagsServer) ListTags(ctx context.Context, req *pb.ListTagsRequest) (*pb.ListTagsResponse, error) {
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := parsePageParams(req.GetPage(), service.TagsPageSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := s.services.Tags.GetPage(ctx, userID, params)
+	if err != nil {
+		return nil, newServiceError("failed to get tags", err)
+	}
+
+	tags := make([]*pb.Tag, 0, len(page.Data))
+	for _, tag := range page.Data {
+		tags = append(tags, newTag(tag))
+	}
+
+	slog.DebugContext(ctx, "got tags", "count", len(page.Data))
+	return &pb.ListTagsResponse{Tags: tags, Page: newPageInfo(page.Page)}, nil
+}
+
+func (s *tagsServer) AttachTags(ctx context.Context, req *pb.TagsOnLinksRequest) (*emptypb.Empty, error) {
+	userID, linkIDs, err := parseTagsOnLinks(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.services.Tags.AddToLinks(ctx, userID, linkIDs, req.GetTags()); err != nil {
+		return nil, newServiceError("failed to attach tags", err)
+	}
+
+	slog.DebugContext(ctx, "attached tags", "links", len(linkIDs), "tags", req.GetTags())
+	return &emptypb.Empty{}, nil
+}
+
+func (s *tagsServer) DetachTags(ctx context.Context, req *pb.TagsOnLinksRequest) (*emptypb.Empty, error) {
+	userID, linkIDs, err := parseTagsOnLinks(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.services.Tags.RemoveFromLinks(ctx, userID, linkIDs, req.GetTags()); err != nil {
+		return nil, newServiceError("failed to detach tags", err)
+	}
+
+	slog.DebugContext(ctx, "detached tags", "links", len(linkIDs), "tags", req.GetTags())
+	return &emptypb.Empty{}, nil
+}
+
+func (s *tagsServer) RenameTag(ctx context.Context, req *pb.RenameTagRequest) (*emptypb.Empty, error) {
+	if err := required("new_name", req.GetNewName()); err != nil {
+		return nil, err
+	}
+
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.services.Tags.Rename(ctx, userID, req.GetName(), req.GetNewName()); err != nil {
+		return nil, newServiceError("failed to rename tag", err)
+	}
+
+	slog.DebugContext(ctx, "renamed tag", "from", req.GetName(), "to", req.GetNewName())
+	return &emptypb.Empty{}, nil
+}
+
+func (s *tagsServer) MergeTag(ctx context.Context, req *pb.MergeTagRequest) (*emptypb.Empty, error) {
+	if err := required("into", req.GetInto()); err != nil {
+		return nil, err
+	}
+
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.services.Tags.Merge(ctx, userID, req.GetName(), req.GetInto()); err != nil {
+		return nil, newServiceError("failed to merge tags", err)
+	}
+
+	slog.DebugContext(ctx, "merged tags", "source", req.GetName(), "target", req.GetInto())
+	return &emptypb.Empty{}, nil
+}
+
+func (s *tagsServer) DeleteTag(ctx context.Context, req *pb.DeleteTagRequest) (*emptypb.Empty, error) {
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.services.Tags.Delete(ctx, userID, req.GetName()); err != nil {
+		return nil, newServiceError("failed to delete tag", err)
+	}
+
+	slog.DebugContext(ctx, "deleted tag", "name", req.GetName())
+	return &emptypb.Empty{}, nil
+}
+
+func parseTagsOnLinks(ctx context.Context, req *pb.TagsOnLinksRequest) (uuid.UUID, []uuid.UUID, error) {
+	if len(req.GetLinkIds()) == 0 || len(req.GetTags()) == 0 {
+		return uuid.Nil, nil, newError(codes.InvalidArgument, "link_ids and tags are required", nil)
+	}
+
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return uuid.Nil, nil, err
+	}
+
+	linkIDs, err := parseIDs("link_ids", req.GetLinkIds())
+	if err != nil {
+		return uuid.Nil, nil, err
+	}
+	return userID, linkIDs, nil
+}
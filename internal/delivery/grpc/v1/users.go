@@ -0,0 +1,91 @@
+package v1
+
+import (
+	"context"
+	pb "github.com/adanyl0v/go-pocket-link/pkg/api/pocketlink/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"log/slog"
+)
+
+type usersServer struct {
+	pb.UnimplementedUsersServiceServer
+	*Handler
+}
+
+func (s *usersServer) GetUser(ctx context.Context, _ *emptypb.Empty) (*pb.User, error) {
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.services.Users.Get(ctx, userID)
+	if err != nil {
+		return nil, newError(codes.Internal, "failed to get user", err)
+	}
+
+	slog.DebugContext(ctx, "got user", "id", user.ID)
+	return newUser(user), nil
+}
+
+func (s *usersServer) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.User, error) {
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.services.Users.Get(ctx, userID)
+	if err != nil {
+		return nil, newError(codes.Internal, "failed to get user", err)
+	}
+
+	if err = validateCredentials(s.services.Users, req.GetName(), req.GetEmail(), req.GetPassword()); err != nil {
+		return nil, newError(codes.InvalidArgument, err.Error(), err)
+	}
+
+	if !s.services.Users.ComparePasswordAndHash(req.GetCurrentPassword(), user.Password) {
+		return nil, newError(codes.InvalidArgument, "incorrect current password", nil)
+	}
+
+	user.Name = req.GetName()
+	user.Email = req.GetEmail()
+	user.Password = req.GetPassword()
+	if err = s.services.Users.Update(ctx, &user); err != nil {
+		return nil, newError(codes.Internal, "failed to update user", err)
+	}
+
+	slog.DebugContext(ctx, "updated user", "id", user.ID)
+	return newUser(user), nil
+}
+
+// DeleteUser deletes the account once the password is confirmed, logging it out everywhere
+func (s *usersServer) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*emptypb.Empty, error) {
+	if err := required("password", req.GetPassword()); err != nil {
+		return nil, err
+	}
+
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.services.Users.Get(ctx, userID)
+	if err != nil {
+		return nil, newError(codes.Internal, "failed to get user", err)
+	}
+
+	if !s.services.Users.ComparePasswordAndHash(req.GetPassword(), user.Password) {
+		return nil, newError(codes.InvalidArgument, "incorrect password", nil)
+	}
+
+	if err = s.services.Users.Delete(ctx, userID); err != nil {
+		return nil, newError(codes.Internal, "failed to delete user", err)
+	}
+
+	if err = s.services.Tokens.InvalidateUser(ctx, userID); err != nil {
+		return nil, newError(codes.Internal, "failed to invalidate user", err)
+	}
+
+	slog.DebugContext(ctx, "deleted user", "id", userID)
+	return &emptypb.Empty{}, nil
+}
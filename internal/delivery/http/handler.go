@@ -1,9 +1,27 @@
 package http
 
 import (
+	"context"
+	"errors"
+	"github.com/adanyl0v/go-pocket-link/pkg/logctx"
 	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
 )
 
+type contextKey int
+
+const contextResponseController contextKey = iota
+
+const redacted = "REDACTED"
+
+// secretQueryParams are the query parameters whose values are not logged
+var secretQueryParams = []string{"access_token"}
+
+var errNoResponseController = errors.New("no response controller in request context")
+
 type EndpointsInitializer interface {
 	InitEndpoints(group *gin.RouterGroup)
 }
@@ -12,3 +30,59 @@ func InitRouter(router *gin.Engine, v1Handler EndpointsInitializer) {
 	v1Group := router.Group("/api/v1")
 	v1Handler.InitEndpoints(v1Group)
 }
+
+// UseRequestID tags the request with the id it has come with, or a new one if it has none, and answers with it,
+// so the logs of the request can be found by the id the client reports. The engine must fall back to the context
+// of the request for the id to reach the services
+func UseRequestID(c *gin.Context) {
+	id := c.GetHeader(logctx.HeaderRequestID)
+	if !logctx.ValidRequestID(id) {
+		id = logctx.NewRequestID()
+	}
+
+	c.Header(logctx.HeaderRequestID, id)
+	c.Request = c.Request.WithContext(logctx.WithRequestID(c.Request.Context(), id))
+}
+
+// WithResponseController keeps the controller of the response writer of the server in the contexts of the requests,
+// since the middlewares wrapping the writer hide it from the handlers
+func WithResponseController(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextResponseController, http.NewResponseController(w))))
+	})
+}
+
+// LiftWriteDeadline lifts the write timeout of the server for the request, e.g. for the event streams staying open
+// as long as their clients are connected. The request must have been served through WithResponseController
+func LiftWriteDeadline(ctx context.Context) error {
+	return SetWriteDeadline(ctx, time.Time{})
+}
+
+// SetWriteDeadline replaces the write timeout of the server for the request, e.g. for the downloads taking longer
+// than the other responses. The request must have been served through WithResponseController
+func SetWriteDeadline(ctx context.Context, deadline time.Time) error {
+	rc, ok := ctx.Value(contextResponseController).(*http.ResponseController)
+	if !ok {
+		return errNoResponseController
+	}
+	return rc.SetWriteDeadline(deadline)
+}
+
+// RedactQuery hides the values of the secret query parameters, e.g. the access tokens of the event streams,
+// in the query logged by the router logger. It is meant as slog.HandlerOptions.ReplaceAttr
+func RedactQuery(groups []string, a slog.Attr) slog.Attr {
+	if a.Key != "query" || len(groups) == 0 || groups[len(groups)-1] != "request" {
+		return a
+	}
+
+	query, err := url.ParseQuery(a.Value.String())
+	if err != nil {
+		return slog.String(a.Key, redacted)
+	}
+	for _, param := range secretQueryParams {
+		if query.Has(param) {
+			query.Set(param, redacted)
+		}
+	}
+	return slog.String(a.Key, query.Encode())
+}
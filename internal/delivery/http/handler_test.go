@@ -0,0 +1,53 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSetWriteDeadlineOutlastsServerTimeout(t *testing.T) {
+	const writeTimeout = 50 * time.Millisecond
+
+	for _, tt := range []struct {
+		name     string
+		deadline func() time.Time
+	}{
+		{"extended", func() time.Time { return time.Now().Add(time.Second) }},
+		{"lifted", func() time.Time { return time.Time{} }},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewUnstartedServer(WithResponseController(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := SetWriteDeadline(r.Context(), tt.deadline()); err != nil {
+					t.Error(err)
+				}
+				// the response is written after the write timeout of the server has passed
+				time.Sleep(2 * writeTimeout)
+				_, _ = io.WriteString(w, "archive")
+			})))
+			server.Config.WriteTimeout = writeTimeout
+			server.Start()
+			defer server.Close()
+
+			resp, err := server.Client().Get(server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, err := io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+			if err != nil || string(body) != "archive" {
+				t.Errorf("got %q, %v", body, err)
+			}
+		})
+	}
+}
+
+func TestWriteDeadlineWithoutResponseController(t *testing.T) {
+	if err := LiftWriteDeadline(context.Background()); !errors.Is(err, errNoResponseController) {
+		t.Errorf("LiftWriteDeadline = %v, want errNoResponseController", err)
+	}
+}
@@ -0,0 +1,54 @@
+package v1
+
+import (
+	"github.com/adanyl0v/go-pocket-link/internal/service"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net/http"
+)
+
+func (h *Handler) handleGetTasks(c *gin.Context) {
+	page, err := h.services.Tasks.GetAll(c)
+	if err != nil {
+		writeServiceError(c, "failed to get tasks", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+	slog.DebugContext(c, "got tasks", "count", len(page.Data))
+}
+
+func (h *Handler) handleGetTaskRuns(c *gin.Context) {
+	params, ok := parsePageParams(c, service.TaskRunsPageSpec)
+	if !ok {
+		return
+	}
+
+	page, err := h.services.Tasks.GetRuns(c, c.Param("name"), params)
+	if err != nil {
+		writeServiceError(c, "failed to get task runs", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+	slog.DebugContext(c, "got task runs", "task", c.Param("name"), "count", len(page.Data))
+}
+
+// taskTriggerOutput is the job the triggered task is run by
+type taskTriggerOutput struct {
+	Task  string `json:"task"`
+	JobID string `json:"job_id"`
+}
+
+// handleTriggerTask queues the task to run right away, the run showing up in its history once it starts
+func (h *Handler) handleTriggerTask(c *gin.Context) {
+	name := c.Param("name")
+	jobID, err := h.services.Tasks.Trigger(c, name)
+	if err != nil {
+		writeServiceError(c, "failed to trigger task", err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, taskTriggerOutput{Task: name, JobID: jobID})
+	slog.InfoContext(c, "triggered task", "task", name, "job_id", jobID)
+}
@@ -0,0 +1,335 @@
+package v1
+
+import (
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+const mimeMarkdown = "text/markdown; charset=utf-8"
+
+type highlightInput struct {
+	Exact  string `json:"exact" binding:"required"`
+	Prefix string `json:"prefix"`
+	Suffix string `json:"suffix"`
+	Start  int    `json:"start" binding:"min=0"`
+	End    int    `json:"end" binding:"required"`
+	Color  string `json:"color"`
+}
+
+func (h *Handler) handleSaveHighlight(c *gin.Context) {
+	var input highlightInput
+	if err := bindInput(c, &input); err != nil {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	linkID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	highlight := domain.Highlight{
+		UserID: userID,
+		LinkID: linkID,
+		Exact:  input.Exact,
+		Prefix: input.Prefix,
+		Suffix: input.Suffix,
+		Start:  input.Start,
+		End:    input.End,
+		Color:  input.Color,
+	}
+	if err := h.services.Annotations.SaveHighlight(c, &highlight); err != nil {
+		writeServiceError(c, "failed to save highlight", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, highlight)
+	slog.DebugContext(c, "saved highlight", "id", highlight.ID, "link_id", linkID)
+}
+
+func (h *Handler) handleGetHighlights(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	linkID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	params, ok := parsePageParams(c, service.HighlightsPageSpec)
+	if !ok {
+		return
+	}
+
+	page, err := h.services.Annotations.GetHighlightsPage(c, userID, linkID, params)
+	if err != nil {
+		writeServiceError(c, "failed to get highlights", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+	slog.DebugContext(c, "got highlights", "link_id", linkID, "count", len(page.Data))
+}
+
+func (h *Handler) handleUpdateHighlight(c *gin.Context) {
+	var input highlightInput
+	if err := bindInput(c, &input); err != nil {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	linkID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "highlight_id")
+	if !ok {
+		return
+	}
+
+	highlight, err := h.services.Annotations.GetHighlight(c, userID, linkID, id)
+	if err != nil {
+		writeServiceError(c, "failed to get highlight", err)
+		return
+	}
+
+	highlight.Exact = input.Exact
+	highlight.Prefix = input.Prefix
+	highlight.Suffix = input.Suffix
+	highlight.Start = input.Start
+	highlight.End = input.End
+	highlight.Color = input.Color
+	if err = h.services.Annotations.UpdateHighlight(c, &highlight); err != nil {
+		writeServiceError(c, "failed to update highlight", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, highlight)
+	slog.DebugContext(c, "updated highlight", "id", highlight.ID)
+}
+
+func (h *Handler) handleDeleteHighlight(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	linkID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "highlight_id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Annotations.DeleteHighlight(c, userID, linkID, id); err != nil {
+		writeServiceError(c, "failed to delete highlight", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	slog.DebugContext(c, "deleted highlight", "id", id)
+}
+
+type reanchorInput struct {
+	Text string `json:"text" binding:"required"`
+}
+
+type reanchorOutput struct {
+	Orphans []domain.Highlight `json:"orphans"`
+}
+
+// handleReanchorHighlights moves the highlights of the link to their positions in the re-extracted text
+// and responds with the ones that are not in the text anymore
+func (h *Handler) handleReanchorHighlights(c *gin.Context) {
+	var input reanchorInput
+	if err := bindInput(c, &input); err != nil {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	linkID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	orphans, err := h.services.Annotations.ReanchorHighlights(c, userID, linkID, input.Text)
+	if err != nil {
+		writeServiceError(c, "failed to reanchor highlights", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reanchorOutput{Orphans: orphans})
+	slog.DebugContext(c, "reanchored highlights", "link_id", linkID, "orphans", len(orphans))
+}
+
+type saveNoteInput struct {
+	Body        string     `json:"body" form:"body" binding:"required"`
+	HighlightID *uuid.UUID `json:"highlight_id" form:"highlight_id"`
+}
+
+func (h *Handler) handleSaveNote(c *gin.Context) {
+	var input saveNoteInput
+	if err := bindInput(c, &input); err != nil {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	linkID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	note := domain.Note{
+		UserID:      userID,
+		LinkID:      linkID,
+		HighlightID: input.HighlightID,
+		Body:        input.Body,
+	}
+	if err := h.services.Annotations.SaveNote(c, &note); err != nil {
+		writeServiceError(c, "failed to save note", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, note)
+	slog.DebugContext(c, "saved note", "id", note.ID, "link_id", linkID)
+}
+
+func (h *Handler) handleGetNotes(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	linkID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	params, ok := parsePageParams(c, service.NotesPageSpec)
+	if !ok {
+		return
+	}
+
+	page, err := h.services.Annotations.GetNotesPage(c, userID, linkID, params)
+	if err != nil {
+		writeServiceError(c, "failed to get notes", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+	slog.DebugContext(c, "got notes", "link_id", linkID, "count", len(page.Data))
+}
+
+type updateNoteInput struct {
+	Body string `json:"body" form:"body" binding:"required"`
+}
+
+func (h *Handler) handleUpdateNote(c *gin.Context) {
+	var input updateNoteInput
+	if err := bindInput(c, &input); err != nil {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	linkID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "note_id")
+	if !ok {
+		return
+	}
+
+	note, err := h.services.Annotations.GetNote(c, userID, linkID, id)
+	if err != nil {
+		writeServiceError(c, "failed to get note", err)
+		return
+	}
+
+	note.Body = input.Body
+	if err = h.services.Annotations.UpdateNote(c, &note); err != nil {
+		writeServiceError(c, "failed to update note", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, note)
+	slog.DebugContext(c, "updated note", "id", note.ID)
+}
+
+func (h *Handler) handleDeleteNote(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	linkID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "note_id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Annotations.DeleteNote(c, userID, linkID, id); err != nil {
+		writeServiceError(c, "failed to delete note", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	slog.DebugContext(c, "deleted note", "id", id)
+}
+
+func (h *Handler) handleGetAnnotationsMarkdown(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	linkID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var sb strings.Builder
+	if err := h.services.Annotations.WriteMarkdown(c, userID, linkID, &sb); err != nil {
+		writeServiceError(c, "failed to export annotations", err)
+		return
+	}
+
+	c.Data(http.StatusOK, mimeMarkdown, []byte(sb.String()))
+	slog.DebugContext(c, "exported annotations", "link_id", linkID)
+}
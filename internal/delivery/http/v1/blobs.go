@@ -0,0 +1,54 @@
+package v1
+
+import (
+	"fmt"
+	"github.com/adanyl0v/go-pocket-link/pkg/blob"
+	"github.com/gin-gonic/gin"
+	"io"
+	"log/slog"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// blobPolicy keeps the blobs, whatever they are, from running anything on the origin of the api
+const blobPolicy = "sandbox; default-src 'none'"
+
+// handleGetBlob serves the blobs of the urls signed by the app, which are downloaded without credentials
+func (h *Handler) handleGetBlob(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	object, err := h.services.Blobs.Open(c, key, c.Request.URL.Query())
+	if err != nil {
+		writeServiceError(c, "failed to open blob", err)
+		return
+	}
+	defer func() { _ = object.Close() }()
+
+	c.Header("Content-Type", object.ContentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, path.Base(key)))
+	c.Header("Content-Security-Policy", blobPolicy)
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Cache-Control", "private")
+	serveBlob(c, object, object.ModifiedAt)
+	slog.DebugContext(c, "got blob", "key", key)
+}
+
+// serveBlob writes the blob, answering the range and conditional requests if the store can seek it
+func serveBlob(c *gin.Context, object *blob.Object, modifiedAt time.Time) {
+	if rs, ok := object.Body.(io.ReadSeeker); ok {
+		http.ServeContent(c.Writer, c.Request, "", modifiedAt, rs)
+		return
+	}
+
+	if !modifiedAt.IsZero() {
+		c.Header("Last-Modified", modifiedAt.UTC().Format(http.TimeFormat))
+	}
+	c.Header("Content-Length", fmt.Sprint(object.Size))
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, object.Body); err != nil {
+		// the headers are already sent, so the client only gets a truncated blob
+		_ = c.Error(err)
+		slog.ErrorContext(c, "failed to write blob", "key", object.Key, logError, err)
+	}
+}
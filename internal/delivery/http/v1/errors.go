@@ -1,16 +1,63 @@
 package v1
 
 import (
+	"errors"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/pkg/logctx"
+	"github.com/adanyl0v/go-pocket-link/pkg/openapi"
+	"github.com/adanyl0v/go-pocket-link/pkg/validator"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	playground "github.com/go-playground/validator/v10"
 	"log/slog"
+	"net/http"
+	"reflect"
+	"strings"
 )
 
+const (
+	mimeProblem = "application/problem+json"
+
+	// problemTypeBlank is the type of the problems described by their status alone
+	problemTypeBlank = "about:blank"
+)
+
+// problem is the RFC 7807 body of the error responses. Errors are the invalid fields of the request,
+// if they have caused the problem
+type problem struct {
+	Type      string         `json:"type"`
+	Title     string         `json:"title"`
+	Status    int            `json:"status"`
+	Detail    string         `json:"detail,omitempty"`
+	Instance  string         `json:"instance,omitempty"`
+	RequestID string         `json:"request_id,omitempty"`
+	Errors    []problemField `json:"errors,omitempty"`
+}
+
+// problemField is an invalid field of the request, Code naming the rule it has failed
+type problemField struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// writeError writes the problem of the status, the message being its detail. The invalid fields are taken
+// from the error, the err being only logged otherwise
 func writeError(c *gin.Context, status int, message string, err error) {
-	c.JSON(status, gin.H{logError: message})
+	c.Header("Content-Type", mimeProblem)
+	c.JSON(status, problem{
+		Type:      problemTypeBlank,
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    message,
+		Instance:  c.Request.URL.Path,
+		RequestID: logctx.RequestID(c),
+		Errors:    problemFields(err),
+	})
 	if err == nil {
-		slog.Error(message)
+		slog.ErrorContext(c, message)
 	} else {
-		slog.Error(message, logError, err)
+		slog.ErrorContext(c, message, logError, err)
 	}
 }
 
@@ -18,3 +65,83 @@ func writeAbort(c *gin.Context, status int, message string, err error) {
 	writeError(c, status, message, err)
 	c.Abort()
 }
+
+// writeServiceError writes an error returned by a service, choosing the status
+// by its domain error kind
+func writeServiceError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		writeError(c, http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, domain.ErrNotFound):
+		writeError(c, http.StatusNotFound, err.Error(), err)
+	case errors.Is(err, domain.ErrAlreadyExists), errors.Is(err, domain.ErrConflict):
+		writeError(c, http.StatusConflict, err.Error(), err)
+	case errors.Is(err, domain.ErrForbidden):
+		writeError(c, http.StatusForbidden, err.Error(), err)
+	case errors.Is(err, domain.ErrUnavailable):
+		writeError(c, http.StatusServiceUnavailable, err.Error(), err)
+	default:
+		writeError(c, http.StatusInternalServerError, message, err)
+	}
+}
+
+// problemFields lists the invalid fields of the error, which are found by the spec, the validator
+// of the credentials or the binding of the input
+func problemFields(err error) []problemField {
+	var (
+		specErrs    openapi.ValidationErrors
+		fieldErrs   validator.Errors
+		fieldErr    *validator.FieldError
+		bindingErrs playground.ValidationErrors
+	)
+	var fields []problemField
+	switch {
+	case errors.As(err, &specErrs):
+		for _, e := range specErrs {
+			fields = append(fields, problemField{Field: e.Path, Code: e.Code, Message: e.Message})
+		}
+	case errors.As(err, &fieldErrs):
+		for _, e := range fieldErrs {
+			fields = append(fields, problemField{Field: e.Field, Code: e.Code, Message: e.Message})
+		}
+	case errors.As(err, &fieldErr):
+		fields = append(fields, problemField{Field: fieldErr.Field, Code: fieldErr.Code, Message: fieldErr.Message})
+	case errors.As(err, &bindingErrs):
+		for _, e := range bindingErrs {
+			// the namespace starts with the name of the input struct, e.g. signUpInput.email
+			_, field, _ := strings.Cut(e.Namespace(), ".")
+			fields = append(fields, problemField{Field: field, Code: e.Tag(), Message: bindingMessage(e)})
+		}
+	}
+	return fields
+}
+
+func bindingMessage(e playground.FieldError) string {
+	switch e.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		return "must be at least " + e.Param()
+	case "max":
+		return "must be at most " + e.Param()
+	case "oneof":
+		return "must be one of " + strings.Join(strings.Fields(e.Param()), ", ")
+	default:
+		return "must satisfy the " + e.Tag() + " rule"
+	}
+}
+
+// useJSONFieldNames names the fields of the binding errors by their json names, the ones the clients send
+func useJSONFieldNames() {
+	v, ok := binding.Validator.Engine().(*playground.Validate)
+	if !ok {
+		return
+	}
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+}
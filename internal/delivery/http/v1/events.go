@@ -0,0 +1,76 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const (
+	headerLastEventID = "Last-Event-ID"
+
+	eventsHeartbeatInterval = 25 * time.Second
+	eventsRetry             = 3 * time.Second
+
+	// eventReset tells the client that it missed the events and must fetch its data again
+	eventReset = "reset"
+)
+
+func (h *Handler) handleStreamEvents(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	lastEventID := c.GetHeader(headerLastEventID)
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+
+	events, reset, err := h.services.Streams.Subscribe(c.Request.Context(), userID, lastEventID)
+	if err != nil {
+		writeServiceError(c, "failed to subscribe to events", err)
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	_, _ = fmt.Fprintf(c.Writer, "retry: %d\n\n", eventsRetry.Milliseconds())
+	if reset {
+		_, _ = fmt.Fprintf(c.Writer, "event: %s\ndata: {}\n\n", eventReset)
+	}
+	c.Writer.Flush()
+	slog.DebugContext(c, "subscribed to events", "user_id", userID, "last_event_id", lastEventID, "reset", reset)
+
+	heartbeat := time.NewTicker(eventsHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+
+			data, err := json.Marshal(event.Event)
+			if err != nil {
+				slog.ErrorContext(c, "failed to encode event", "event_id", event.Event.ID, logError, err)
+				return true
+			}
+			_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Event.Type, data)
+			return err == nil
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": ping\n\n")
+			return err == nil
+		}
+	})
+	slog.DebugContext(c, "unsubscribed from events", "user_id", userID)
+}
@@ -0,0 +1,124 @@
+package v1
+
+import (
+	"fmt"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+const mimeZip = "application/zip"
+
+type exportResponse struct {
+	domain.Export
+	DownloadURL string `json:"download_url,omitempty"`
+}
+
+// handleExport streams the archive while it is being generated
+func (h *Handler) handleExport(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	c.Header("Content-Type", mimeZip)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, exportFileName(time.Now())))
+	c.Status(http.StatusOK)
+
+	if err := h.services.Exports.Write(c, userID, c.Writer); err != nil {
+		// the headers are already sent, so the client only gets a truncated archive
+		_ = c.Error(err)
+		slog.ErrorContext(c, "failed to write export", logError, err)
+		return
+	}
+	slog.DebugContext(c, "exported user data", "user_id", userID)
+}
+
+func (h *Handler) handleStartExport(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	export, err := h.services.Exports.Start(c, userID)
+	if err != nil {
+		writeServiceError(c, "failed to start export", err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, newExportResponse(c, export))
+	slog.DebugContext(c, "started export", "id", export.ID)
+}
+
+func (h *Handler) handleGetExport(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	export, err := h.services.Exports.Get(c, userID, id)
+	if err != nil {
+		writeServiceError(c, "failed to get export", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newExportResponse(c, export))
+	slog.DebugContext(c, "got export", "id", export.ID, "status", export.Status)
+}
+
+func (h *Handler) handleDownloadExport(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	// the archive is downloaded from the blob store itself if it can sign the urls
+	signedURL, err := h.services.Exports.SignedURL(c, userID, id)
+	if err != nil {
+		writeServiceError(c, "failed to sign export url", err)
+		return
+	} else if signedURL != "" {
+		c.Redirect(http.StatusFound, signedURL)
+		slog.DebugContext(c, "redirected export download", "id", id)
+		return
+	}
+
+	object, export, err := h.services.Exports.Open(c, userID, id)
+	if err != nil {
+		writeServiceError(c, "failed to open export", err)
+		return
+	}
+	defer func() { _ = object.Close() }()
+
+	c.Header("Content-Type", mimeZip)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, exportFileName(export.CreatedAt)))
+	serveBlob(c, object, *export.FinishedAt)
+	slog.DebugContext(c, "downloaded export", "id", export.ID)
+}
+
+func newExportResponse(c *gin.Context, export domain.Export) exportResponse {
+	response := exportResponse{Export: export}
+	if export.Status == domain.ExportStatusCompleted {
+		// both /export/ and /export/:id are resolved to /export/<id>/download
+		response.DownloadURL = path.Join(strings.TrimSuffix(c.FullPath(), "/:id"), export.ID.String(), "download")
+	}
+	return response
+}
+
+func exportFileName(t time.Time) string {
+	return fmt.Sprintf("pocket-link-export-%s.zip", t.Format("2006-01-02"))
+}
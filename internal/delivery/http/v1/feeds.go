@@ -0,0 +1,178 @@
+package v1
+
+import (
+	"fmt"
+	"github.com/adanyl0v/go-pocket-link/pkg/feed"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+func (h *Handler) handleEnableListFeed(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	f, err := h.services.Feeds.EnableListFeed(c, userID, id)
+	if err != nil {
+		writeServiceError(c, "failed to enable list feed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, f)
+	slog.DebugContext(c, "enabled list feed", "id", id, "feed_id", f.ID)
+}
+
+func (h *Handler) handleGetListFeed(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	f, err := h.services.Feeds.GetListFeed(c, userID, id)
+	if err != nil {
+		writeServiceError(c, "failed to get list feed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, f)
+	slog.DebugContext(c, "got list feed", "id", id, "feed_id", f.ID)
+}
+
+func (h *Handler) handleDisableListFeed(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Feeds.DisableListFeed(c, userID, id); err != nil {
+		writeServiceError(c, "failed to disable list feed", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	slog.DebugContext(c, "disabled list feed", "id", id)
+}
+
+func (h *Handler) handleEnableTagFeed(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	f, err := h.services.Feeds.EnableTagFeed(c, userID, c.Param("name"))
+	if err != nil {
+		writeServiceError(c, "failed to enable tag feed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, f)
+	slog.DebugContext(c, "enabled tag feed", "name", c.Param("name"), "feed_id", f.ID)
+}
+
+func (h *Handler) handleGetTagFeed(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	f, err := h.services.Feeds.GetTagFeed(c, userID, c.Param("name"))
+	if err != nil {
+		writeServiceError(c, "failed to get tag feed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, f)
+	slog.DebugContext(c, "got tag feed", "name", c.Param("name"), "feed_id", f.ID)
+}
+
+func (h *Handler) handleDisableTagFeed(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.services.Feeds.DisableTagFeed(c, userID, c.Param("name")); err != nil {
+		writeServiceError(c, "failed to disable tag feed", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	slog.DebugContext(c, "disabled tag feed", "name", c.Param("name"))
+}
+
+// handleGetFeed serves the feed to the readers, which authenticate with the token in its url
+func (h *Handler) handleGetFeed(c *gin.Context) {
+	format := feed.Format(c.Param("format"))
+	if !slices.Contains(feed.Formats, format) {
+		writeError(c, http.StatusNotFound, "unknown feed format", nil)
+		return
+	}
+
+	token := c.Param("token")
+	f, modifiedAt, err := h.services.Feeds.Resolve(c, token)
+	if err != nil {
+		writeServiceError(c, "failed to get feed", err)
+		return
+	}
+
+	// the validators only depend on the time the content last changed, so the unchanged feeds are not built
+	etag := fmt.Sprintf(`"%x-%s"`, modifiedAt.UnixNano(), format)
+	c.Header("ETag", etag)
+	c.Header("Last-Modified", modifiedAt.UTC().Format(http.TimeFormat))
+	c.Header("Cache-Control", "private, no-cache")
+	if isNotModified(c.Request, etag, modifiedAt) {
+		c.Status(http.StatusNotModified)
+		slog.DebugContext(c, "feed not modified", "feed_id", f.ID)
+		return
+	}
+
+	out, err := h.services.Feeds.Build(c, token, f, format, modifiedAt)
+	if err != nil {
+		writeServiceError(c, "failed to build feed", err)
+		return
+	}
+
+	c.Header("Content-Type", format.ContentType())
+	c.Status(http.StatusOK)
+	if err = feed.Write(c.Writer, format, out); err != nil {
+		slog.ErrorContext(c, "failed to write feed", "feed_id", f.ID, logError, err)
+		return
+	}
+	slog.DebugContext(c, "got feed", "feed_id", f.ID, "format", format, "count", len(out.Items))
+}
+
+// isNotModified evaluates the conditional GET headers, If-None-Match taking precedence over If-Modified-Since
+func isNotModified(r *http.Request, etag string, modifiedAt time.Time) bool {
+	if header := r.Header.Get("If-None-Match"); header != "" {
+		for _, candidate := range strings.Split(header, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == etag || candidate == "*" {
+				return true
+			}
+		}
+		return false
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	return err == nil && !modifiedAt.Truncate(time.Second).After(since)
+}
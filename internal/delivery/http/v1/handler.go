@@ -1,8 +1,11 @@
 package v1
 
 import (
+	"errors"
 	"github.com/adanyl0v/go-pocket-link/internal/domain"
 	"github.com/adanyl0v/go-pocket-link/internal/service"
+	"github.com/adanyl0v/go-pocket-link/pkg/openapi"
+	"github.com/adanyl0v/go-pocket-link/pkg/pagination"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"log/slog"
@@ -14,13 +17,32 @@ import (
 const (
 	PublicSignIn = "/sign-in"
 	PublicSignUp = "/sign-up"
+	PublicShare  = "/s/:slug"
+	PublicFeed   = "/feeds/:token/:format"
+	PublicBlob   = "/blobs/*key"
 
 	ApiPing   = "/ping"
 	ApiSignIn = "/sign-in"
 	ApiSignUp = "/sign-up"
 	ApiLogOut = "/log-out"
+	ApiEvents = "/events"
+	ApiSpec   = "/openapi.json"
+	ApiDocs   = "/docs"
 
-	GroupUser = "/user"
+	GroupUser   = "/user"
+	GroupImport = "/import"
+	GroupExport = "/export"
+	GroupLinks  = "/links"
+	GroupLists  = "/lists"
+	GroupTags   = "/tags"
+
+	GroupWebhooks      = "/webhooks"
+	GroupSubscriptions = "/subscriptions"
+	GroupSync          = "/sync"
+
+	GroupInvitations = "/invitations"
+
+	GroupAdmin = "/admin"
 )
 
 const (
@@ -31,6 +53,11 @@ const (
 
 type Handler struct {
 	services *service.Services
+
+	// spec describes the endpoints, its operations being looked up by the method and the full path of their routes
+	basePath   string
+	spec       *openapi.Document
+	operations map[string]*openapi.Operation
 }
 
 func NewHandler(services *service.Services) *Handler {
@@ -38,11 +65,25 @@ func NewHandler(services *service.Services) *Handler {
 }
 
 func (h *Handler) InitEndpoints(routerGroup *gin.RouterGroup) {
-	routerGroup.GET(ApiPing, h.handlePing)
-	routerGroup.POST(ApiSignUp, h.handleSignUp)
-	routerGroup.POST(ApiSignIn, h.handleSignIn)
+	useJSONFieldNames()
+	h.basePath = routerGroup.BasePath()
+	h.spec, h.operations = newSpec(h.basePath)
 
-	protectedGroup := routerGroup.Group("/", h.useAuth)
+	publicGroup := routerGroup.Group("/", h.useRequestValidation)
+	{
+		publicGroup.GET(ApiPing, h.handlePing)
+		publicGroup.GET(ApiSpec, h.handleGetSpec)
+		publicGroup.GET(ApiDocs, h.handleGetDocs)
+		publicGroup.POST(ApiSignUp, h.handleSignUp)
+		publicGroup.POST(ApiSignIn, h.handleSignIn)
+		publicGroup.GET(PublicShare, h.handleViewShare)
+		publicGroup.GET(PublicFeed, h.handleGetFeed)
+		publicGroup.GET(PublicBlob, h.useDownloadWriteDeadline, h.handleGetBlob)
+		publicGroup.GET(ApiEvents, h.useQueryAccessToken, h.useAuth, h.useStreamWriteDeadline, h.handleStreamEvents)
+	}
+
+	// the requests are validated once they are authenticated, so the anonymous clients learn nothing of the inputs
+	protectedGroup := routerGroup.Group("/", h.useAuth, h.useRequestValidation)
 	{
 		protectedGroup.POST(ApiLogOut, h.handleLogOut)
 
@@ -51,23 +92,163 @@ func (h *Handler) InitEndpoints(routerGroup *gin.RouterGroup) {
 			// operations with user id retrieved from jwt access token
 			usersGroup.GET("/", h.handleGetUser)
 			usersGroup.PUT("/", h.handleUpdateUser)
+			usersGroup.DELETE("/", h.handleDeleteUser)
+			usersGroup.GET("/snapshots/usage", h.handleGetSnapshotUsage)
+		}
+
+		linksGroup := protectedGroup.Group(GroupLinks)
+		{
+			linksGroup.POST("/", h.handleSaveLink)
+			linksGroup.GET("/", h.handleGetLinks)
+			linksGroup.GET("/continue", h.handleContinueReading)
+			linksGroup.GET("/:id", h.handleGetLink)
+			linksGroup.PUT("/:id", h.handleUpdateLink)
+			linksGroup.DELETE("/:id", h.handleDeleteLink)
+			linksGroup.GET("/:id/health", h.handleGetLinkHealth)
+
+			linksGroup.GET("/:id/snapshot", h.useDownloadWriteDeadline, h.handleGetSnapshot)
+			linksGroup.POST("/:id/snapshot", h.handleCaptureSnapshot)
+			linksGroup.DELETE("/:id/snapshot", h.handleDeleteSnapshots)
+			linksGroup.GET("/:id/snapshots", h.handleGetSnapshots)
+
+			linksGroup.GET("/:id/highlights", h.handleGetHighlights)
+			linksGroup.POST("/:id/highlights", h.handleSaveHighlight)
+			linksGroup.POST("/:id/highlights/reanchor", h.handleReanchorHighlights)
+			linksGroup.PUT("/:id/highlights/:highlight_id", h.handleUpdateHighlight)
+			linksGroup.DELETE("/:id/highlights/:highlight_id", h.handleDeleteHighlight)
+
+			linksGroup.GET("/:id/notes", h.handleGetNotes)
+			linksGroup.POST("/:id/notes", h.handleSaveNote)
+			linksGroup.PUT("/:id/notes/:note_id", h.handleUpdateNote)
+			linksGroup.DELETE("/:id/notes/:note_id", h.handleDeleteNote)
+
+			linksGroup.GET("/:id/annotations.md", h.handleGetAnnotationsMarkdown)
+
+			linksGroup.GET("/:id/progress", h.handleGetReadingState)
+			linksGroup.PUT("/:id/progress", h.handleUpdateProgress)
+			linksGroup.POST("/:id/open", h.handleOpenLink)
+			linksGroup.PUT("/:id/read", h.handleSetRead)
+		}
+
+		listsGroup := protectedGroup.Group(GroupLists)
+		{
+			listsGroup.POST("/", h.handleSaveList)
+			listsGroup.GET("/", h.handleGetLists)
+			listsGroup.GET("/:id", h.handleGetList)
+			listsGroup.PUT("/:id", h.handleUpdateList)
+			listsGroup.DELETE("/:id", h.handleDeleteList)
+			listsGroup.GET("/:id/links", h.handleGetListLinks)
+			listsGroup.POST("/:id/links", h.handleAddListLinks)
+			listsGroup.DELETE("/:id/links/:link_id", h.handleRemoveListLink)
+
+			listsGroup.GET("/:id/activity", h.handleGetListActivity)
+
+			listsGroup.GET("/:id/members", h.handleGetListMembers)
+			listsGroup.PUT("/:id/members/:user_id", h.handleUpdateListMember)
+			listsGroup.DELETE("/:id/members/:user_id", h.handleRemoveListMember)
+
+			listsGroup.GET("/:id/invitations", h.handleGetListInvitations)
+			listsGroup.POST("/:id/invitations", h.handleInviteToList)
+			listsGroup.DELETE("/:id/invitations/:invitation_id", h.handleCancelListInvitation)
+
+			listsGroup.GET("/:id/share", h.handleGetShare)
+			listsGroup.PUT("/:id/share", h.handlePublishList)
+			listsGroup.POST("/:id/share/regenerate", h.handleRegenerateShare)
+			listsGroup.DELETE("/:id/share", h.handleRevokeShare)
+
+			listsGroup.GET("/:id/feed", h.handleGetListFeed)
+			listsGroup.PUT("/:id/feed", h.handleEnableListFeed)
+			listsGroup.DELETE("/:id/feed", h.handleDisableListFeed)
+		}
+
+		invitationsGroup := protectedGroup.Group(GroupInvitations)
+		{
+			invitationsGroup.POST("/:token/accept", h.handleAcceptListInvitation)
+		}
+
+		webhooksGroup := protectedGroup.Group(GroupWebhooks)
+		{
+			webhooksGroup.POST("/", h.handleCreateWebhook)
+			webhooksGroup.GET("/", h.handleGetWebhooks)
+			webhooksGroup.GET("/:id", h.handleGetWebhook)
+			webhooksGroup.PUT("/:id", h.handleUpdateWebhook)
+			webhooksGroup.DELETE("/:id", h.handleDeleteWebhook)
+			webhooksGroup.POST("/:id/secret", h.handleRotateWebhookSecret)
+			webhooksGroup.POST("/:id/test", h.handleSendTestWebhook)
+			webhooksGroup.GET("/:id/deliveries", h.handleGetWebhookDeliveries)
+			webhooksGroup.GET("/:id/deliveries/:delivery_id", h.handleGetWebhookDelivery)
+			webhooksGroup.POST("/:id/deliveries/:delivery_id/retry", h.handleRedeliverWebhook)
+		}
+
+		subscriptionsGroup := protectedGroup.Group(GroupSubscriptions)
+		{
+			subscriptionsGroup.POST("/", h.handleCreateSubscription)
+			subscriptionsGroup.GET("/", h.handleGetSubscriptions)
+			subscriptionsGroup.GET("/:id", h.handleGetSubscription)
+			subscriptionsGroup.PUT("/:id", h.handleUpdateSubscription)
+			subscriptionsGroup.DELETE("/:id", h.handleDeleteSubscription)
+			subscriptionsGroup.POST("/:id/refresh", h.handleRefreshSubscription)
+		}
+
+		syncGroup := protectedGroup.Group(GroupSync)
+		{
+			syncGroup.GET("/", h.handleGetSyncChanges)
+			syncGroup.POST("/", h.handlePushSyncMutations)
+		}
+
+		importGroup := protectedGroup.Group(GroupImport)
+		{
+			importGroup.POST("/", h.handleImport)
+			importGroup.GET("/:id", h.handleGetImport)
+		}
+
+		exportGroup := protectedGroup.Group(GroupExport)
+		{
+			exportGroup.GET("/", h.useDownloadWriteDeadline, h.handleExport)
+			exportGroup.POST("/", h.handleStartExport)
+			exportGroup.GET("/:id", h.handleGetExport)
+			exportGroup.GET("/:id/download", h.useDownloadWriteDeadline, h.handleDownloadExport)
+		}
 
-			//TODO: implement me
-			//usersGroup.DELETE("/") // [?](add email verification)
+		tagsGroup := protectedGroup.Group(GroupTags)
+		{
+			tagsGroup.GET("/", h.handleGetTags)
+			tagsGroup.POST("/attach", h.handleAttachTags)
+			tagsGroup.POST("/detach", h.handleDetachTags)
+			tagsGroup.PUT("/:name", h.handleRenameTag)
+			tagsGroup.POST("/:name/merge", h.handleMergeTag)
+			tagsGroup.DELETE("/:name", h.handleDeleteTag)
+
+			tagsGroup.GET("/:name/feed", h.handleGetTagFeed)
+			tagsGroup.PUT("/:name/feed", h.handleEnableTagFeed)
+			tagsGroup.DELETE("/:name/feed", h.handleDisableTagFeed)
+		}
+
+		adminGroup := protectedGroup.Group(GroupAdmin, h.useAdmin)
+		{
+			adminGroup.GET("/tasks", h.handleGetTasks)
+			adminGroup.GET("/tasks/:name/runs", h.handleGetTaskRuns)
+			adminGroup.POST("/tasks/:name/run", h.handleTriggerTask)
 		}
 	}
 }
 
+type pingOutput struct {
+	Message string `json:"message"`
+}
+
 func (h *Handler) handlePing(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "pong"})
+	c.JSON(http.StatusOK, pingOutput{Message: "pong"})
+}
+
+type signUpInput struct {
+	Name     string `json:"name" form:"name" binding:"required"`
+	Email    string `json:"email" form:"email" binding:"required"`
+	Password string `json:"password" form:"password" binding:"required"`
 }
 
 func (h *Handler) handleSignUp(c *gin.Context) {
-	var input struct {
-		Name     string `json:"name" form:"name" binding:"required"`
-		Email    string `json:"email" form:"email" binding:"required"`
-		Password string `json:"password" form:"password" binding:"required"`
-	}
+	var input signUpInput
 	if err := bindInput(c, &input); err != nil {
 		return
 	}
@@ -103,16 +284,18 @@ func (h *Handler) handleSignUp(c *gin.Context) {
 	setRefreshTokenCookies(c, tokens.RefreshToken, h.services.Tokens.RefreshTokenTTL)
 
 	c.JSON(http.StatusCreated, tokens.AccessToken)
-	slog.Debug("signed up", "id", user.ID, "jwt", tokens)
+	slog.DebugContext(c, "signed up", "id", user.ID, "jwt", tokens)
 
 	//TODO: notify user by email
 }
 
+type signInInput struct {
+	Email    string `json:"email" form:"email" binding:"required"`
+	Password string `json:"password" form:"password" binding:"required"`
+}
+
 func (h *Handler) handleSignIn(c *gin.Context) {
-	var input struct {
-		Email    string `json:"email" form:"email" binding:"required"`
-		Password string `json:"password" form:"password" binding:"required"`
-	}
+	var input signInInput
 	if err := bindInput(c, &input); err != nil {
 		return
 	}
@@ -124,7 +307,10 @@ func (h *Handler) handleSignIn(c *gin.Context) {
 	}
 
 	user, err := h.services.Users.GetByCredentials(c, input.Email, input.Password)
-	if err != nil {
+	if errors.Is(err, domain.ErrInvalidCredentials) {
+		writeError(c, http.StatusUnauthorized, err.Error(), err)
+		return
+	} else if err != nil {
 		writeError(c, http.StatusInternalServerError, "failed to get user", err)
 		return
 	}
@@ -143,7 +329,7 @@ func (h *Handler) handleSignIn(c *gin.Context) {
 	setRefreshTokenCookies(c, tokens.RefreshToken, h.services.Tokens.RefreshTokenTTL)
 
 	c.JSON(http.StatusOK, tokens.AccessToken)
-	slog.Debug("signed in", "id", user.ID, "jwt", tokens)
+	slog.DebugContext(c, "signed in", "id", user.ID, "jwt", tokens)
 
 	//TODO: notify user by email, rollback changes if user hasn't logged in
 }
@@ -163,7 +349,7 @@ func (h *Handler) handleLogOut(c *gin.Context) {
 		writeError(c, http.StatusInternalServerError, "failed to invalidate user", err)
 		return
 	}
-	slog.Debug("invalidated user")
+	slog.DebugContext(c, "invalidated user")
 
 	//TODO: don't forget to remove me if the frontend will make the redirections
 	//c.Redirect(http.StatusTemporaryRedirect, PublicSignIn)
@@ -191,6 +377,39 @@ func getRawUserIDFromContext(c *gin.Context) (string, bool) {
 	return raw.(string), true
 }
 
+// getUserID retrieves and parses the user id set by useAuth
+func getUserID(c *gin.Context) (uuid.UUID, bool) {
+	rawUserID, exists := getRawUserIDFromContext(c)
+	if !exists {
+		return uuid.Nil, false
+	}
+
+	userID, err := parseRawUserID(c, rawUserID)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+func parseIDParam(c *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		writeError(c, http.StatusBadRequest, "invalid "+name, err)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// parsePageParams parses the pagination, sorting and filtering parameters of a collection endpoint
+func parsePageParams(c *gin.Context, spec pagination.Spec) (pagination.Params, bool) {
+	params, err := spec.Parse(c.Request.URL.Query())
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err.Error(), err)
+		return pagination.Params{}, false
+	}
+	return params, true
+}
+
 func parseRawUserID(c *gin.Context, raw string) (uuid.UUID, error) {
 	id, err := uuid.Parse(raw)
 	if err != nil {
@@ -0,0 +1,49 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/internal/repository"
+	"github.com/adanyl0v/go-pocket-link/internal/service"
+	"github.com/adanyl0v/go-pocket-link/pkg/crypto/hash"
+	"github.com/adanyl0v/go-pocket-link/pkg/validator"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeUsersRepository finds no user by the credentials
+type fakeUsersRepository struct {
+	repository.UsersRepository
+}
+
+func (r *fakeUsersRepository) GetByCredentials(context.Context, string, string) (domain.User, error) {
+	return domain.User{}, domain.ErrInvalidCredentials
+}
+
+func TestSignInRejectsWrongCredentials(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	users := service.NewUsersService(&fakeUsersRepository{}, hash.NewSHA1Hasher("salt"), validator.NewCredentialsValidator(), nil)
+	NewHandler(&service.Services{Users: users}).InitEndpoints(router.Group("/api/v1"))
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1"+ApiSignIn,
+		strings.NewReader(`{"email": "john@example.com", "password": "Passw0rd"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status %d, want 401: %s", rec.Code, rec.Body)
+	}
+	var body problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Status != http.StatusUnauthorized || body.Detail != domain.ErrInvalidCredentials.Error() {
+		t.Errorf("problem = %+v", body)
+	}
+}
@@ -0,0 +1,80 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+const (
+	maxImportSize = 32 << 20 // 32 MiB
+
+	formImportFile = "file"
+)
+
+// handleImport accepts the exported file either as a multipart form file or as the raw request body.
+// The format query parameter is optional and is detected by the contents if omitted
+func (h *Handler) handleImport(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	data, err := readImportFile(c)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, "failed to read import file", err)
+		return
+	}
+
+	imp, err := h.services.Imports.Start(c, userID, c.Query("format"), data)
+	if err != nil {
+		writeServiceError(c, "failed to start import", err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, imp)
+	slog.DebugContext(c, "started import", "id", imp.ID, "format", imp.Format)
+}
+
+func (h *Handler) handleGetImport(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	imp, err := h.services.Imports.Get(c, userID, id)
+	if err != nil {
+		writeServiceError(c, "failed to get import", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, imp)
+	slog.DebugContext(c, "got import", "id", imp.ID, "status", imp.Status)
+}
+
+func readImportFile(c *gin.Context) ([]byte, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+
+	if c.ContentType() != gin.MIMEMultipartPOSTForm {
+		return io.ReadAll(c.Request.Body)
+	}
+
+	header, err := c.FormFile(formImportFile)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	return io.ReadAll(file)
+}
@@ -0,0 +1,169 @@
+package v1
+
+import (
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/internal/service"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net/http"
+)
+
+type saveLinkInput struct {
+	URL   string   `json:"url" form:"url" binding:"required"`
+	Title string   `json:"title" form:"title"`
+	Tags  []string `json:"tags" form:"tags"`
+}
+
+func (h *Handler) handleSaveLink(c *gin.Context) {
+	var input saveLinkInput
+	if err := bindInput(c, &input); err != nil {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	link := domain.Link{
+		UserID: userID,
+		URL:    input.URL,
+		Title:  input.Title,
+		Tags:   input.Tags,
+	}
+	created, err := h.services.Links.Save(c, &link)
+	if err != nil {
+		writeServiceError(c, "failed to save link", err)
+		return
+	}
+
+	if !created {
+		c.JSON(http.StatusOK, link)
+		slog.DebugContext(c, "link already saved", "id", link.ID)
+		return
+	}
+
+	c.JSON(http.StatusCreated, link)
+	slog.DebugContext(c, "saved link", "id", link.ID)
+}
+
+func (h *Handler) handleGetLinks(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	params, ok := parsePageParams(c, service.LinksPageSpec)
+	if !ok {
+		return
+	}
+
+	page, err := h.services.Links.GetPage(c, userID, params)
+	if err != nil {
+		writeServiceError(c, "failed to get links", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+	slog.DebugContext(c, "got links", "count", len(page.Data))
+}
+
+func (h *Handler) handleGetLink(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	link, err := h.services.Links.Get(c, userID, id)
+	if err != nil {
+		writeServiceError(c, "failed to get link", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, link)
+	slog.DebugContext(c, "got link", "id", link.ID)
+}
+
+type updateLinkInput struct {
+	URL   string `json:"url" form:"url" binding:"required"`
+	Title string `json:"title" form:"title"`
+}
+
+func (h *Handler) handleUpdateLink(c *gin.Context) {
+	var input updateLinkInput
+	if err := bindInput(c, &input); err != nil {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	link, err := h.services.Links.Get(c, userID, id)
+	if err != nil {
+		writeServiceError(c, "failed to get link", err)
+		return
+	}
+
+	link.URL = input.URL
+	link.Title = input.Title
+	if err = h.services.Links.Update(c, &link); err != nil {
+		writeServiceError(c, "failed to update link", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, link)
+	slog.DebugContext(c, "updated link", "id", link.ID)
+}
+
+func (h *Handler) handleDeleteLink(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Links.Delete(c, userID, id); err != nil {
+		writeServiceError(c, "failed to delete link", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	slog.DebugContext(c, "deleted link", "id", id)
+}
+
+func (h *Handler) handleGetLinkHealth(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	report, err := h.services.LinkChecks.Get(c, userID, id)
+	if err != nil {
+		writeServiceError(c, "failed to get link health", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+	slog.DebugContext(c, "got link health", "id", id, "checks", len(report.Checks))
+}
@@ -0,0 +1,212 @@
+package v1
+
+import (
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"log/slog"
+	"net/http"
+)
+
+type listInput struct {
+	Title string `json:"title" form:"title" binding:"required"`
+}
+
+func (h *Handler) handleSaveList(c *gin.Context) {
+	var input listInput
+	if err := bindInput(c, &input); err != nil {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	list := domain.List{
+		UserID: userID,
+		Title:  input.Title,
+	}
+	if err := h.services.Lists.Save(c, &list); err != nil {
+		writeServiceError(c, "failed to save list", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, list)
+	slog.DebugContext(c, "saved list", "id", list.ID)
+}
+
+func (h *Handler) handleGetLists(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	params, ok := parsePageParams(c, service.ListsPageSpec)
+	if !ok {
+		return
+	}
+
+	page, err := h.services.Lists.GetPage(c, userID, params)
+	if err != nil {
+		writeServiceError(c, "failed to get lists", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+	slog.DebugContext(c, "got lists", "count", len(page.Data))
+}
+
+func (h *Handler) handleGetList(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	list, err := h.services.Lists.Get(c, userID, id)
+	if err != nil {
+		writeServiceError(c, "failed to get list", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+	slog.DebugContext(c, "got list", "id", list.ID)
+}
+
+func (h *Handler) handleUpdateList(c *gin.Context) {
+	var input listInput
+	if err := bindInput(c, &input); err != nil {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	list, err := h.services.Lists.Get(c, userID, id)
+	if err != nil {
+		writeServiceError(c, "failed to get list", err)
+		return
+	}
+
+	list.Title = input.Title
+	if err = h.services.Lists.Update(c, userID, &list); err != nil {
+		writeServiceError(c, "failed to update list", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+	slog.DebugContext(c, "updated list", "id", list.ID)
+}
+
+func (h *Handler) handleDeleteList(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Lists.Delete(c, userID, id); err != nil {
+		writeServiceError(c, "failed to delete list", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	slog.DebugContext(c, "deleted list", "id", id)
+}
+
+func (h *Handler) handleGetListLinks(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	params, ok := parsePageParams(c, service.LinksPageSpec)
+	if !ok {
+		return
+	}
+
+	page, err := h.services.Lists.GetLinksPage(c, userID, id, params)
+	if err != nil {
+		writeServiceError(c, "failed to get list links", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+	slog.DebugContext(c, "got list links", "id", id, "count", len(page.Data))
+}
+
+type listLinksInput struct {
+	LinkIDs []uuid.UUID `json:"link_ids" binding:"required,min=1"`
+}
+
+func (h *Handler) handleAddListLinks(c *gin.Context) {
+	var input listLinksInput
+	if err := bindInput(c, &input); err != nil {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Lists.AddLinks(c, userID, id, input.LinkIDs); err != nil {
+		writeServiceError(c, "failed to add links to list", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	slog.DebugContext(c, "added links to list", "id", id, "count", len(input.LinkIDs))
+}
+
+func (h *Handler) handleRemoveListLink(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	linkID, ok := parseIDParam(c, "link_id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Lists.RemoveLinks(c, userID, id, []uuid.UUID{linkID}); err != nil {
+		writeServiceError(c, "failed to remove link from list", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	slog.DebugContext(c, "removed link from list", "id", id, "link_id", linkID)
+}
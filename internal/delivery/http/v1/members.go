@@ -0,0 +1,227 @@
+package v1
+
+import (
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/internal/service"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net/http"
+)
+
+func (h *Handler) handleGetListMembers(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	params, ok := parsePageParams(c, service.ListMembersPageSpec)
+	if !ok {
+		return
+	}
+
+	page, err := h.services.Members.GetPage(c, userID, id, params)
+	if err != nil {
+		writeServiceError(c, "failed to get list members", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+	slog.DebugContext(c, "got list members", "id", id, "count", len(page.Data))
+}
+
+type memberRoleInput struct {
+	Role domain.ListRole `json:"role" form:"role" binding:"required"`
+}
+
+func (h *Handler) handleUpdateListMember(c *gin.Context) {
+	var input memberRoleInput
+	if err := bindInput(c, &input); err != nil {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	memberID, ok := parseIDParam(c, "user_id")
+	if !ok {
+		return
+	}
+
+	member := domain.ListMember{
+		ListID: id,
+		UserID: memberID,
+		Role:   input.Role,
+	}
+	if err := h.services.Members.UpdateRole(c, userID, &member); err != nil {
+		writeServiceError(c, "failed to update list member", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	slog.DebugContext(c, "updated list member", "id", id, "user_id", memberID, "role", member.Role)
+}
+
+func (h *Handler) handleRemoveListMember(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	memberID, ok := parseIDParam(c, "user_id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Members.Remove(c, userID, id, memberID); err != nil {
+		writeServiceError(c, "failed to remove list member", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	slog.DebugContext(c, "removed list member", "id", id, "user_id", memberID)
+}
+
+type invitationInput struct {
+	Email string          `json:"email" form:"email" binding:"required"`
+	Role  domain.ListRole `json:"role" form:"role" binding:"required"`
+}
+
+func (h *Handler) handleInviteToList(c *gin.Context) {
+	var input invitationInput
+	if err := bindInput(c, &input); err != nil {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	invitation := domain.ListInvitation{
+		ListID: id,
+		Email:  input.Email,
+		Role:   input.Role,
+	}
+	if err := h.services.Members.Invite(c, userID, &invitation); err != nil {
+		writeServiceError(c, "failed to invite to list", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitation)
+	slog.DebugContext(c, "invited to list", "id", id, "invitation_id", invitation.ID)
+}
+
+func (h *Handler) handleGetListInvitations(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	params, ok := parsePageParams(c, service.ListInvitationsPageSpec)
+	if !ok {
+		return
+	}
+
+	page, err := h.services.Members.GetPendingInvitationsPage(c, userID, id, params)
+	if err != nil {
+		writeServiceError(c, "failed to get list invitations", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+	slog.DebugContext(c, "got list invitations", "id", id, "count", len(page.Data))
+}
+
+func (h *Handler) handleCancelListInvitation(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	invitationID, ok := parseIDParam(c, "invitation_id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Members.CancelInvitation(c, userID, id, invitationID); err != nil {
+		writeServiceError(c, "failed to cancel list invitation", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	slog.DebugContext(c, "canceled list invitation", "id", id, "invitation_id", invitationID)
+}
+
+func (h *Handler) handleAcceptListInvitation(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	invitation, err := h.services.Members.AcceptInvitation(c, userID, c.Param("token"))
+	if err != nil {
+		writeServiceError(c, "failed to accept list invitation", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invitation)
+	slog.DebugContext(c, "accepted list invitation", "invitation_id", invitation.ID, "list_id", invitation.ListID)
+}
+
+func (h *Handler) handleGetListActivity(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	params, ok := parsePageParams(c, service.ListActivityPageSpec)
+	if !ok {
+		return
+	}
+
+	page, err := h.services.Lists.GetActivityPage(c, userID, id, params)
+	if err != nil {
+		writeServiceError(c, "failed to get list activity", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+	slog.DebugContext(c, "got list activity", "id", id, "count", len(page.Data))
+}
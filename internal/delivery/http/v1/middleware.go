@@ -2,16 +2,26 @@ package v1
 
 import (
 	"fmt"
+	delivhttp "github.com/adanyl0v/go-pocket-link/internal/delivery/http"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
 	"github.com/adanyl0v/go-pocket-link/pkg/auth/jwt"
+	"github.com/adanyl0v/go-pocket-link/pkg/logctx"
 	"github.com/gin-gonic/gin"
+	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 )
 
 const (
 	contextUserID = "user_id"
 
 	headerAuthorization = "Authorization"
+
+	queryAccessToken = "access_token"
+
+	// downloadWriteTimeout bounds the streamed downloads instead of the write timeout of the server
+	downloadWriteTimeout = time.Hour
 )
 
 func (h *Handler) useAuth(c *gin.Context) {
@@ -34,6 +44,46 @@ func (h *Handler) useAuth(c *gin.Context) {
 	}
 
 	c.Set(contextUserID, rawUserID)
+	c.Request = c.Request.WithContext(logctx.WithUserID(c.Request.Context(), rawUserID))
+}
+
+// useAdmin lets only the admins through, it must follow useAuth
+func (h *Handler) useAdmin(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		c.Abort()
+		return
+	}
+
+	if !h.services.Users.IsAdmin(userID) {
+		writeAbort(c, http.StatusForbidden, domain.ErrAdminRequired.Error(), nil)
+		return
+	}
+}
+
+// useQueryAccessToken lets the access token be passed in the query for the endpoints
+// browsers cannot send headers to, like the event stream
+func (h *Handler) useQueryAccessToken(c *gin.Context) {
+	if token := c.Query(queryAccessToken); token != "" && c.GetHeader(headerAuthorization) == "" {
+		c.Request.Header.Set(headerAuthorization, "Bearer "+token)
+	}
+}
+
+// useStreamWriteDeadline lifts the write timeout of the server for the event stream, which stays open
+// as long as the client is connected. It must follow useAuth, so only the signed in users hold the connections
+func (h *Handler) useStreamWriteDeadline(c *gin.Context) {
+	if err := delivhttp.LiftWriteDeadline(c.Request.Context()); err != nil {
+		slog.WarnContext(c, "failed to lift write deadline of event stream", "error", err)
+	}
+}
+
+// useDownloadWriteDeadline extends the write timeout of the server for the downloads, like the exports and
+// the snapshots, which are streamed and take longer than the other responses for the large accounts.
+// They are still bounded, so the clients reading slowly do not hold the connections forever
+func (h *Handler) useDownloadWriteDeadline(c *gin.Context) {
+	if err := delivhttp.SetWriteDeadline(c.Request.Context(), time.Now().Add(downloadWriteTimeout)); err != nil {
+		slog.WarnContext(c, "failed to extend write deadline of download", "error", err)
+	}
 }
 
 func parseAuthHeader(c *gin.Context) (string, error) {
@@ -0,0 +1,596 @@
+package v1
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/internal/service"
+	"github.com/adanyl0v/go-pocket-link/pkg/bookmarks"
+	"github.com/adanyl0v/go-pocket-link/pkg/feed"
+	"github.com/adanyl0v/go-pocket-link/pkg/openapi"
+	"github.com/adanyl0v/go-pocket-link/pkg/pagination"
+	"github.com/adanyl0v/go-pocket-link/pkg/scheduler"
+	"github.com/gin-gonic/gin"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	specVersion = "1.0.0"
+
+	securityBearer = "bearer"
+
+	// docsPolicy lets the docs page run its own script, which only fetches the spec
+	docsPolicy = "default-src 'none'; script-src 'unsafe-inline'; style-src 'unsafe-inline'; connect-src 'self'"
+)
+
+//go:embed docs.html
+var docsPage []byte
+
+// specTags group the operations in the docs, in the order they are shown
+var specTags = []openapi.Tag{
+	{Name: "auth", Description: "Signing up, in and out"},
+	{Name: "users", Description: "The account of the user"},
+	{Name: "links", Description: "Saved links and their health"},
+	{Name: "snapshots", Description: "Archived copies of the pages of the links"},
+	{Name: "annotations", Description: "Highlights and notes on the links"},
+	{Name: "reading", Description: "Reading progress synced between the devices"},
+	{Name: "lists", Description: "Lists of links"},
+	{Name: "members", Description: "Collaborators of the lists and their invitations"},
+	{Name: "shares", Description: "Lists published by a link"},
+	{Name: "feeds", Description: "Atom, RSS and JSON feeds of the lists and the tags"},
+	{Name: "tags", Description: "Tags of the links"},
+	{Name: "webhooks", Description: "Webhooks notified of the events and their deliveries"},
+	{Name: "subscriptions", Description: "Feeds the links are saved from"},
+	{Name: "sync", Description: "Offline-first sync of the links and the lists"},
+	{Name: "transfer", Description: "Imports and exports of the bookmarks"},
+	{Name: "events", Description: "Server-sent events of the user"},
+	{Name: "admin", Description: "Scheduled maintenance tasks, for the admins only"},
+	{Name: "meta", Description: "The API itself"},
+}
+
+// specRoute describes a route of InitEndpoints in the spec. The path parameters are derived from the path,
+// the ones named id or ending with _id being uuids, unless params declare them
+type specRoute struct {
+	method  string
+	path    string
+	id      string
+	tag     string
+	summary string
+	public  bool
+	params  []*openapi.Parameter
+	// page adds the pagination, sorting and filtering parameters of the collection
+	page *pagination.Spec
+	// input is the JSON body, or body the body of the other media types
+	input         any
+	optionalInput bool
+	body          *openapi.RequestBody
+	// output is the JSON response of the status, or content the response of the other media types.
+	// The response has no content if both are nil
+	output  any
+	content map[string]openapi.MediaType
+	status  int
+	// others are the other statuses of the success, the 2xx ones sharing the content of the status
+	others []int
+}
+
+var specRoutes = []specRoute{
+	{method: http.MethodGet, path: ApiPing, id: "ping", tag: "meta", summary: "Check that the API is up", public: true,
+		status: http.StatusOK, output: pingOutput{}},
+	{method: http.MethodGet, path: ApiSpec, id: "getSpec", tag: "meta", summary: "Get this OpenAPI document", public: true,
+		status: http.StatusOK, content: jsonContent(&openapi.Schema{Type: openapi.Types{openapi.TypeObject}})},
+	{method: http.MethodGet, path: ApiDocs, id: "getDocs", tag: "meta", summary: "Browse this OpenAPI document", public: true,
+		status: http.StatusOK, content: binaryContent("text/html")},
+
+	{method: http.MethodPost, path: ApiSignUp, id: "signUp", tag: "auth", summary: "Sign up, getting an access token", public: true,
+		input: signUpInput{}, status: http.StatusCreated, output: ""},
+	{method: http.MethodPost, path: ApiSignIn, id: "signIn", tag: "auth", summary: "Sign in, getting an access token", public: true,
+		input: signInInput{}, status: http.StatusOK, output: ""},
+	{method: http.MethodPost, path: ApiLogOut, id: "logOut", tag: "auth", summary: "Log out of all the devices",
+		status: http.StatusOK},
+
+	{method: http.MethodGet, path: PublicShare, id: "viewShare", tag: "shares", summary: "View a published list", public: true,
+		params: []*openapi.Parameter{
+			headerParam(headerSharePassword, openapi.String(), "Password of the list, if it has one"),
+		},
+		page: &service.LinksPageSpec, status: http.StatusOK, output: sharedListOutput{}},
+	{method: http.MethodGet, path: PublicFeed, id: "getFeed", tag: "feeds", summary: "Get a feed", public: true,
+		params: []*openapi.Parameter{pathParam("format", feedFormatSchema())},
+		status: http.StatusOK, content: feedContent(), others: []int{http.StatusNotModified}},
+	{method: http.MethodGet, path: PublicBlob, id: "getBlob", tag: "transfer", summary: "Download a file by its signed url", public: true,
+		params: []*openapi.Parameter{
+			queryParam("expires", openapi.Integer(), "Unix time the url expires at"),
+			queryParam("signature", openapi.String(), "Signature of the key and the expiration"),
+		},
+		status: http.StatusOK, content: binaryContent("application/octet-stream"), others: []int{http.StatusNotModified}},
+	{method: http.MethodGet, path: ApiEvents, id: "streamEvents", tag: "events", summary: "Stream the events of the user",
+		params: []*openapi.Parameter{
+			headerParam(headerLastEventID, openapi.String(), "Id of the last event received, to resume the stream after it"),
+			queryParam("last_event_id", openapi.String(), "Id of the last event received, for the clients which cannot send the header"),
+			queryParam(queryAccessToken, openapi.String(), "Access token, for the clients which cannot send the header"),
+		},
+		status: http.StatusOK, content: binaryContent("text/event-stream")},
+
+	{method: http.MethodGet, path: GroupUser + "/", id: "getUser", tag: "users", summary: "Get the user",
+		status: http.StatusOK, output: domain.User{}},
+	{method: http.MethodPut, path: GroupUser + "/", id: "updateUser", tag: "users", summary: "Update the user",
+		input: updateUserInput{}, status: http.StatusOK},
+	{method: http.MethodDelete, path: GroupUser + "/", id: "deleteUser", tag: "users", summary: "Delete the account after a grace period",
+		input: deleteUserInput{}, status: http.StatusNoContent},
+	{method: http.MethodGet, path: GroupUser + "/snapshots/usage", id: "getSnapshotUsage", tag: "snapshots", summary: "Get the storage used by the snapshots",
+		status: http.StatusOK, output: domain.SnapshotUsage{}},
+
+	{method: http.MethodPost, path: GroupLinks + "/", id: "saveLink", tag: "links", summary: "Save a link, or get it if it is saved already",
+		input: saveLinkInput{}, status: http.StatusCreated, output: domain.Link{}, others: []int{http.StatusOK}},
+	{method: http.MethodGet, path: GroupLinks + "/", id: "getLinks", tag: "links", summary: "Get the links",
+		page: &service.LinksPageSpec, status: http.StatusOK, output: pagination.Page[domain.Link]{}},
+	{method: http.MethodGet, path: GroupLinks + "/continue", id: "continueReading", tag: "reading", summary: "Get the links being read",
+		page: &service.ContinueReadingPageSpec, status: http.StatusOK, output: pagination.Page[domain.LinkWithReadingState]{}},
+	{method: http.MethodGet, path: GroupLinks + "/:id", id: "getLink", tag: "links", summary: "Get a link",
+		status: http.StatusOK, output: domain.Link{}},
+	{method: http.MethodPut, path: GroupLinks + "/:id", id: "updateLink", tag: "links", summary: "Update a link",
+		input: updateLinkInput{}, status: http.StatusOK, output: domain.Link{}},
+	{method: http.MethodDelete, path: GroupLinks + "/:id", id: "deleteLink", tag: "links", summary: "Delete a link",
+		status: http.StatusNoContent},
+	{method: http.MethodGet, path: GroupLinks + "/:id/health", id: "getLinkHealth", tag: "links", summary: "Get the health of a link and its latest checks",
+		status: http.StatusOK, output: domain.LinkHealthReport{}},
+
+	{method: http.MethodGet, path: GroupLinks + "/:id/snapshot", id: "getSnapshot", tag: "snapshots", summary: "Download the snapshot of a link",
+		params: []*openapi.Parameter{queryParam("format", snapshotFormatSchema(), "Format of the snapshot, html by default")},
+		status: http.StatusOK, content: snapshotContent(), others: []int{http.StatusNotModified}},
+	{method: http.MethodPost, path: GroupLinks + "/:id/snapshot", id: "captureSnapshot", tag: "snapshots", summary: "Capture a snapshot of a link",
+		input: snapshotFormatInput{}, optionalInput: true, status: http.StatusAccepted, output: domain.Snapshot{}},
+	{method: http.MethodDelete, path: GroupLinks + "/:id/snapshot", id: "deleteSnapshots", tag: "snapshots", summary: "Delete the snapshots of a link",
+		status: http.StatusNoContent},
+	{method: http.MethodGet, path: GroupLinks + "/:id/snapshots", id: "getSnapshots", tag: "snapshots", summary: "Get the snapshots of a link",
+		page: &service.SnapshotsPageSpec, status: http.StatusOK, output: pagination.Page[domain.Snapshot]{}},
+
+	{method: http.MethodGet, path: GroupLinks + "/:id/highlights", id: "getHighlights", tag: "annotations", summary: "Get the highlights of a link",
+		page: &service.HighlightsPageSpec, status: http.StatusOK, output: pagination.Page[domain.Highlight]{}},
+	{method: http.MethodPost, path: GroupLinks + "/:id/highlights", id: "saveHighlight", tag: "annotations", summary: "Highlight a passage of a link",
+		input: highlightInput{}, status: http.StatusCreated, output: domain.Highlight{}},
+	{method: http.MethodPost, path: GroupLinks + "/:id/highlights/reanchor", id: "reanchorHighlights", tag: "annotations", summary: "Anchor the highlights in the changed text of a link",
+		input: reanchorInput{}, status: http.StatusOK, output: reanchorOutput{}},
+	{method: http.MethodPut, path: GroupLinks + "/:id/highlights/:highlight_id", id: "updateHighlight", tag: "annotations", summary: "Update a highlight",
+		input: highlightInput{}, status: http.StatusOK, output: domain.Highlight{}},
+	{method: http.MethodDelete, path: GroupLinks + "/:id/highlights/:highlight_id", id: "deleteHighlight", tag: "annotations", summary: "Delete a highlight",
+		status: http.StatusNoContent},
+	{method: http.MethodGet, path: GroupLinks + "/:id/notes", id: "getNotes", tag: "annotations", summary: "Get the notes of a link",
+		page: &service.NotesPageSpec, status: http.StatusOK, output: pagination.Page[domain.Note]{}},
+	{method: http.MethodPost, path: GroupLinks + "/:id/notes", id: "saveNote", tag: "annotations", summary: "Add a note to a link",
+		input: saveNoteInput{}, status: http.StatusCreated, output: domain.Note{}},
+	{method: http.MethodPut, path: GroupLinks + "/:id/notes/:note_id", id: "updateNote", tag: "annotations", summary: "Update a note",
+		input: updateNoteInput{}, status: http.StatusOK, output: domain.Note{}},
+	{method: http.MethodDelete, path: GroupLinks + "/:id/notes/:note_id", id: "deleteNote", tag: "annotations", summary: "Delete a note",
+		status: http.StatusNoContent},
+	{method: http.MethodGet, path: GroupLinks + "/:id/annotations.md", id: "getAnnotationsMarkdown", tag: "annotations", summary: "Export the annotations of a link as markdown",
+		status: http.StatusOK, content: binaryContent("text/markdown")},
+
+	{method: http.MethodGet, path: GroupLinks + "/:id/progress", id: "getReadingState", tag: "reading", summary: "Get the reading state of a link",
+		status: http.StatusOK, output: domain.ReadingState{}},
+	{method: http.MethodPut, path: GroupLinks + "/:id/progress", id: "updateProgress", tag: "reading", summary: "Update the reading progress of a link",
+		input: progressInput{}, status: http.StatusOK, output: readingStateOutput{}},
+	{method: http.MethodPost, path: GroupLinks + "/:id/open", id: "openLink", tag: "reading", summary: "Mark a link as opened",
+		input: openLinkInput{}, optionalInput: true, status: http.StatusOK, output: domain.ReadingState{}},
+	{method: http.MethodPut, path: GroupLinks + "/:id/read", id: "setRead", tag: "reading", summary: "Mark a link as read or unread",
+		input: readInput{}, status: http.StatusOK, output: readingStateOutput{}},
+
+	{method: http.MethodPost, path: GroupLists + "/", id: "saveList", tag: "lists", summary: "Create a list",
+		input: listInput{}, status: http.StatusCreated, output: domain.List{}},
+	{method: http.MethodGet, path: GroupLists + "/", id: "getLists", tag: "lists", summary: "Get the lists",
+		page: &service.ListsPageSpec, status: http.StatusOK, output: pagination.Page[domain.List]{}},
+	{method: http.MethodGet, path: GroupLists + "/:id", id: "getList", tag: "lists", summary: "Get a list",
+		status: http.StatusOK, output: domain.List{}},
+	{method: http.MethodPut, path: GroupLists + "/:id", id: "updateList", tag: "lists", summary: "Update a list",
+		input: listInput{}, status: http.StatusOK, output: domain.List{}},
+	{method: http.MethodDelete, path: GroupLists + "/:id", id: "deleteList", tag: "lists", summary: "Delete a list",
+		status: http.StatusNoContent},
+	{method: http.MethodGet, path: GroupLists + "/:id/links", id: "getListLinks", tag: "lists", summary: "Get the links of a list",
+		page: &service.LinksPageSpec, status: http.StatusOK, output: pagination.Page[domain.Link]{}},
+	{method: http.MethodPost, path: GroupLists + "/:id/links", id: "addListLinks", tag: "lists", summary: "Add links to a list",
+		input: listLinksInput{}, status: http.StatusNoContent},
+	{method: http.MethodDelete, path: GroupLists + "/:id/links/:link_id", id: "removeListLink", tag: "lists", summary: "Remove a link from a list",
+		status: http.StatusNoContent},
+	{method: http.MethodGet, path: GroupLists + "/:id/activity", id: "getListActivity", tag: "members", summary: "Get the activity of a list",
+		page: &service.ListActivityPageSpec, status: http.StatusOK, output: pagination.Page[domain.ListActivity]{}},
+
+	{method: http.MethodGet, path: GroupLists + "/:id/members", id: "getListMembers", tag: "members", summary: "Get the members of a list",
+		page: &service.ListMembersPageSpec, status: http.StatusOK, output: pagination.Page[domain.ListMember]{}},
+	{method: http.MethodPut, path: GroupLists + "/:id/members/:user_id", id: "updateListMember", tag: "members", summary: "Change the role of a member",
+		input: memberRoleInput{}, status: http.StatusNoContent},
+	{method: http.MethodDelete, path: GroupLists + "/:id/members/:user_id", id: "removeListMember", tag: "members", summary: "Remove a member, or leave the list",
+		status: http.StatusNoContent},
+	{method: http.MethodGet, path: GroupLists + "/:id/invitations", id: "getListInvitations", tag: "members", summary: "Get the pending invitations of a list",
+		page: &service.ListInvitationsPageSpec, status: http.StatusOK, output: pagination.Page[domain.ListInvitation]{}},
+	{method: http.MethodPost, path: GroupLists + "/:id/invitations", id: "inviteToList", tag: "members", summary: "Invite a user to a list",
+		input: invitationInput{}, status: http.StatusCreated, output: domain.ListInvitation{}},
+	{method: http.MethodDelete, path: GroupLists + "/:id/invitations/:invitation_id", id: "cancelListInvitation", tag: "members", summary: "Cancel an invitation",
+		status: http.StatusNoContent},
+	{method: http.MethodPost, path: GroupInvitations + "/:token/accept", id: "acceptListInvitation", tag: "members", summary: "Accept an invitation",
+		status: http.StatusOK, output: domain.ListInvitation{}},
+
+	{method: http.MethodGet, path: GroupLists + "/:id/share", id: "getShare", tag: "shares", summary: "Get the share of a list",
+		status: http.StatusOK, output: shareOutput{}},
+	{method: http.MethodPut, path: GroupLists + "/:id/share", id: "publishList", tag: "shares", summary: "Publish a list",
+		input: shareInput{}, status: http.StatusOK, output: shareOutput{}},
+	{method: http.MethodPost, path: GroupLists + "/:id/share/regenerate", id: "regenerateShare", tag: "shares", summary: "Move a published list to a new link",
+		status: http.StatusOK, output: shareOutput{}},
+	{method: http.MethodDelete, path: GroupLists + "/:id/share", id: "revokeShare", tag: "shares", summary: "Unpublish a list",
+		status: http.StatusNoContent},
+
+	{method: http.MethodGet, path: GroupLists + "/:id/feed", id: "getListFeed", tag: "feeds", summary: "Get the feed of a list",
+		status: http.StatusOK, output: domain.Feed{}},
+	{method: http.MethodPut, path: GroupLists + "/:id/feed", id: "enableListFeed", tag: "feeds", summary: "Enable the feed of a list",
+		status: http.StatusOK, output: domain.Feed{}},
+	{method: http.MethodDelete, path: GroupLists + "/:id/feed", id: "disableListFeed", tag: "feeds", summary: "Disable the feed of a list",
+		status: http.StatusNoContent},
+
+	{method: http.MethodPost, path: GroupWebhooks + "/", id: "createWebhook", tag: "webhooks", summary: "Create a webhook",
+		input: webhookInput{}, status: http.StatusCreated, output: domain.Webhook{}},
+	{method: http.MethodGet, path: GroupWebhooks + "/", id: "getWebhooks", tag: "webhooks", summary: "Get the webhooks",
+		page: &service.WebhooksPageSpec, status: http.StatusOK, output: pagination.Page[domain.Webhook]{}},
+	{method: http.MethodGet, path: GroupWebhooks + "/:id", id: "getWebhook", tag: "webhooks", summary: "Get a webhook",
+		status: http.StatusOK, output: domain.Webhook{}},
+	{method: http.MethodPut, path: GroupWebhooks + "/:id", id: "updateWebhook", tag: "webhooks", summary: "Update a webhook",
+		input: webhookInput{}, status: http.StatusOK, output: domain.Webhook{}},
+	{method: http.MethodDelete, path: GroupWebhooks + "/:id", id: "deleteWebhook", tag: "webhooks", summary: "Delete a webhook",
+		status: http.StatusNoContent},
+	{method: http.MethodPost, path: GroupWebhooks + "/:id/secret", id: "rotateWebhookSecret", tag: "webhooks", summary: "Rotate the signing secret of a webhook",
+		status: http.StatusOK, output: domain.Webhook{}},
+	{method: http.MethodPost, path: GroupWebhooks + "/:id/test", id: "sendTestWebhook", tag: "webhooks", summary: "Send a test event to a webhook",
+		status: http.StatusOK, output: domain.WebhookDelivery{}},
+	{method: http.MethodGet, path: GroupWebhooks + "/:id/deliveries", id: "getWebhookDeliveries", tag: "webhooks", summary: "Get the deliveries of a webhook",
+		page: &service.WebhookDeliveriesPageSpec, status: http.StatusOK, output: pagination.Page[domain.WebhookDelivery]{}},
+	{method: http.MethodGet, path: GroupWebhooks + "/:id/deliveries/:delivery_id", id: "getWebhookDelivery", tag: "webhooks", summary: "Get a delivery with its attempts",
+		status: http.StatusOK, output: webhookDeliveryOutput{}},
+	{method: http.MethodPost, path: GroupWebhooks + "/:id/deliveries/:delivery_id/retry", id: "redeliverWebhook", tag: "webhooks", summary: "Deliver an event again",
+		status: http.StatusAccepted, output: domain.WebhookDelivery{}},
+
+	{method: http.MethodPost, path: GroupSubscriptions + "/", id: "createSubscription", tag: "subscriptions", summary: "Subscribe to a feed",
+		input: createSubscriptionInput{}, status: http.StatusCreated, output: domain.FeedSubscription{}},
+	{method: http.MethodGet, path: GroupSubscriptions + "/", id: "getSubscriptions", tag: "subscriptions", summary: "Get the subscriptions",
+		page: &service.SubscriptionsPageSpec, status: http.StatusOK, output: pagination.Page[domain.FeedSubscription]{}},
+	{method: http.MethodGet, path: GroupSubscriptions + "/:id", id: "getSubscription", tag: "subscriptions", summary: "Get a subscription",
+		status: http.StatusOK, output: domain.FeedSubscription{}},
+	{method: http.MethodPut, path: GroupSubscriptions + "/:id", id: "updateSubscription", tag: "subscriptions", summary: "Update a subscription",
+		input: subscriptionInput{}, status: http.StatusOK, output: domain.FeedSubscription{}},
+	{method: http.MethodDelete, path: GroupSubscriptions + "/:id", id: "deleteSubscription", tag: "subscriptions", summary: "Unsubscribe from a feed",
+		status: http.StatusNoContent},
+	{method: http.MethodPost, path: GroupSubscriptions + "/:id/refresh", id: "refreshSubscription", tag: "subscriptions", summary: "Fetch a feed now",
+		status: http.StatusOK, output: domain.FeedSubscriptionFetch{}},
+
+	{method: http.MethodGet, path: GroupSync + "/", id: "getSyncChanges", tag: "sync", summary: "Get the changes since a cursor",
+		params: []*openapi.Parameter{
+			queryParam("since", openapi.String(), "Cursor of the last sync, all the data is returned without it"),
+			queryParam("limit", minimum(openapi.Integer(), 1), "Maximum number of the changes"),
+		},
+		status: http.StatusOK, output: domain.SyncDelta{}},
+	{method: http.MethodPost, path: GroupSync + "/", id: "pushSyncMutations", tag: "sync", summary: "Apply the changes made offline",
+		input: syncMutationsInput{}, status: http.StatusOK, output: syncResultsOutput{}},
+
+	{method: http.MethodPost, path: GroupImport + "/", id: "import", tag: "transfer", summary: "Import the bookmarks exported by a browser or a service",
+		params: []*openapi.Parameter{queryParam("format", importFormatSchema(), "Format of the file, detected by its contents if omitted")},
+		body:   importBody(), status: http.StatusAccepted, output: domain.Import{}},
+	{method: http.MethodGet, path: GroupImport + "/:id", id: "getImport", tag: "transfer", summary: "Get the progress of an import",
+		status: http.StatusOK, output: domain.Import{}},
+	{method: http.MethodGet, path: GroupExport + "/", id: "export", tag: "transfer", summary: "Export all the data as a zip archive",
+		status: http.StatusOK, content: binaryContent(mimeZip)},
+	{method: http.MethodPost, path: GroupExport + "/", id: "startExport", tag: "transfer", summary: "Start exporting all the data in the background",
+		status: http.StatusAccepted, output: exportResponse{}},
+	{method: http.MethodGet, path: GroupExport + "/:id", id: "getExport", tag: "transfer", summary: "Get the progress of an export",
+		status: http.StatusOK, output: exportResponse{}},
+	{method: http.MethodGet, path: GroupExport + "/:id/download", id: "downloadExport", tag: "transfer", summary: "Download a finished export, or get redirected to it",
+		status: http.StatusOK, content: binaryContent(mimeZip), others: []int{http.StatusFound}},
+
+	{method: http.MethodGet, path: GroupTags + "/", id: "getTags", tag: "tags", summary: "Get the tags with their usage",
+		page: &service.TagsPageSpec, status: http.StatusOK, output: pagination.Page[domain.TagUsage]{}},
+	{method: http.MethodPost, path: GroupTags + "/attach", id: "attachTags", tag: "tags", summary: "Tag links",
+		input: tagsOnLinksInput{}, status: http.StatusNoContent},
+	{method: http.MethodPost, path: GroupTags + "/detach", id: "detachTags", tag: "tags", summary: "Untag links",
+		input: tagsOnLinksInput{}, status: http.StatusNoContent},
+	{method: http.MethodPut, path: GroupTags + "/:name", id: "renameTag", tag: "tags", summary: "Rename a tag",
+		input: renameTagInput{}, status: http.StatusNoContent},
+	{method: http.MethodPost, path: GroupTags + "/:name/merge", id: "mergeTag", tag: "tags", summary: "Merge a tag into another one",
+		input: mergeTagInput{}, status: http.StatusNoContent},
+	{method: http.MethodDelete, path: GroupTags + "/:name", id: "deleteTag", tag: "tags", summary: "Remove a tag from all the links",
+		status: http.StatusNoContent},
+	{method: http.MethodGet, path: GroupTags + "/:name/feed", id: "getTagFeed", tag: "feeds", summary: "Get the feed of a tag",
+		status: http.StatusOK, output: domain.Feed{}},
+	{method: http.MethodPut, path: GroupTags + "/:name/feed", id: "enableTagFeed", tag: "feeds", summary: "Enable the feed of a tag",
+		status: http.StatusOK, output: domain.Feed{}},
+	{method: http.MethodDelete, path: GroupTags + "/:name/feed", id: "disableTagFeed", tag: "feeds", summary: "Disable the feed of a tag",
+		status: http.StatusNoContent},
+
+	{method: http.MethodGet, path: GroupAdmin + "/tasks", id: "getTasks", tag: "admin", summary: "Get the scheduled tasks",
+		status: http.StatusOK, output: pagination.Page[service.TaskInfo]{}},
+	{method: http.MethodGet, path: GroupAdmin + "/tasks/:name/runs", id: "getTaskRuns", tag: "admin", summary: "Get the runs of a task",
+		page: &service.TaskRunsPageSpec, status: http.StatusOK, output: pagination.Page[scheduler.Run]{}},
+	{method: http.MethodPost, path: GroupAdmin + "/tasks/:name/run", id: "triggerTask", tag: "admin", summary: "Run a task now",
+		status: http.StatusAccepted, output: taskTriggerOutput{}},
+}
+
+// newSpec builds the spec of specRoutes served under the base path. It returns the operations by the method
+// and the full path of their routes as well, e.g. GET /api/v1/links/:id
+func newSpec(basePath string) (*openapi.Document, map[string]*openapi.Operation) {
+	doc := openapi.New(openapi.Info{
+		Title:       "Pocket Link API",
+		Description: "Save links to read later, organize them in lists and tags, and share them",
+		Version:     specVersion,
+	})
+	doc.Servers = []openapi.Server{{URL: basePath}}
+	doc.Tags = specTags
+	doc.Components.SecuritySchemes[securityBearer] = &openapi.SecurityScheme{
+		Type:         "http",
+		Scheme:       "bearer",
+		BearerFormat: "JWT",
+		Description:  "Access token got by signing up or in",
+	}
+
+	gen := newSpecGenerator(doc)
+	problemContent := map[string]openapi.MediaType{mimeProblem: {Schema: gen.Schema(problem{})}}
+	operations := make(map[string]*openapi.Operation, len(specRoutes))
+	for _, route := range specRoutes {
+		op := newSpecOperation(gen, route, problemContent)
+		doc.AddOperation(route.method, openapi.PathOf(route.path), op)
+		operations[route.method+" "+basePath+route.path] = op
+	}
+	return doc, operations
+}
+
+func newSpecGenerator(doc *openapi.Document) *openapi.Generator {
+	gen := openapi.NewGenerator(doc)
+	gen.Enum(domain.ListRoleOwner, domain.ListRoleEditor, domain.ListRoleViewer)
+	gen.Enum(toAny(append(slices.Clone(domain.EventTypes), domain.EventWebhookTest))...)
+	gen.Enum(domain.ExportStatusPending, domain.ExportStatusCompleted, domain.ExportStatusFailed)
+	gen.Enum(domain.ImportStatusPending, domain.ImportStatusRunning, domain.ImportStatusCompleted, domain.ImportStatusFailed)
+	gen.Enum(domain.SnapshotFormatHTML, domain.SnapshotFormatWARC)
+	gen.Enum(domain.SnapshotStatusPending, domain.SnapshotStatusReady, domain.SnapshotStatusFailed)
+	gen.Enum(domain.ListActivityLinkAdded, domain.ListActivityLinkRemoved, domain.ListActivityMemberJoined,
+		domain.ListActivityMemberRoleChanged, domain.ListActivityMemberRemoved)
+	gen.Enum(domain.SyncEntityLink, domain.SyncEntityList)
+	gen.Enum(domain.SyncOperationCreate, domain.SyncOperationUpdate, domain.SyncOperationDelete)
+	gen.Enum(domain.SyncMutationApplied, domain.SyncMutationConflict, domain.SyncMutationRejected)
+	gen.Enum(domain.WebhookDeliveryPending, domain.WebhookDeliverySucceeded, domain.WebhookDeliveryDead)
+	return gen
+}
+
+func newSpecOperation(gen *openapi.Generator, route specRoute,
+	problemContent map[string]openapi.MediaType) *openapi.Operation {
+	op := &openapi.Operation{
+		OperationID: route.id,
+		Summary:     route.summary,
+		Tags:        []string{route.tag},
+		Responses:   make(map[string]*openapi.Response),
+	}
+
+	for _, name := range openapi.ParamNames(route.path) {
+		if !slices.ContainsFunc(route.params, func(p *openapi.Parameter) bool { return p.In == openapi.InPath && p.Name == name }) {
+			op.Parameters = append(op.Parameters, pathParam(name, pathParamSchema(name)))
+		}
+	}
+	op.Parameters = append(op.Parameters, route.params...)
+	if route.page != nil {
+		op.Parameters = append(op.Parameters, pageParams(*route.page)...)
+	}
+
+	switch {
+	case route.body != nil:
+		op.RequestBody = route.body
+	case route.input != nil:
+		op.RequestBody = &openapi.RequestBody{
+			Required: !route.optionalInput,
+			Content:  jsonContent(gen.Input(route.input)),
+		}
+	}
+
+	content := route.content
+	if route.output != nil {
+		content = jsonContent(gen.Schema(route.output))
+	}
+	op.Responses[strconv.Itoa(route.status)] = &openapi.Response{Description: http.StatusText(route.status), Content: content}
+	for _, status := range route.others {
+		response := &openapi.Response{Description: http.StatusText(status)}
+		if status < http.StatusMultipleChoices {
+			response.Content = content
+		}
+		op.Responses[strconv.Itoa(status)] = response
+	}
+
+	if !route.public {
+		op.Security = []openapi.SecurityRequirement{{securityBearer: {}}}
+		op.Responses[strconv.Itoa(http.StatusUnauthorized)] = &openapi.Response{
+			Description: http.StatusText(http.StatusUnauthorized),
+			Content:     problemContent,
+		}
+	}
+	op.Responses["default"] = &openapi.Response{Description: "Problem", Content: problemContent}
+	return op
+}
+
+// pageParams describes the parameters parsed by the spec, the filters being field=value for the equality
+// and field[operator]=value for the other operators
+func pageParams(spec pagination.Spec) []*openapi.Parameter {
+	limit := fmt.Sprintf("Maximum number of the items, %d by default and %d at most", spec.DefaultLimit, spec.MaxLimit)
+	params := []*openapi.Parameter{
+		queryParam(pagination.ParamLimit, minimum(openapi.Integer(), 1), limit),
+	}
+
+	names := make([]string, 0, len(spec.Fields))
+	for name := range spec.Fields {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var sorts []any
+	for _, name := range names {
+		if spec.Fields[name].Sortable {
+			sorts = append(sorts, name, "-"+name)
+		}
+	}
+	if len(sorts) > 0 {
+		defaultSort := spec.DefaultSort
+		if spec.DefaultDesc {
+			defaultSort = "-" + defaultSort
+		}
+		description := fmt.Sprintf("Field to sort by, prefixed with - for the descending order, %s by default", defaultSort)
+		params = append(params, queryParam(pagination.ParamSort, openapi.String().WithEnum(sorts...), description))
+	}
+	params = append(params,
+		queryParam(pagination.ParamAfter, openapi.String(), "Cursor of the next page"),
+		queryParam(pagination.ParamBefore, openapi.String(), "Cursor of the previous page"),
+	)
+
+	for _, name := range names {
+		field := spec.Fields[name]
+		for _, op := range field.Operators {
+			paramName := name
+			if op != pagination.OpEq {
+				paramName = fmt.Sprintf("%s[%s]", name, op)
+			}
+			params = append(params, queryParam(paramName, filterSchema(field), fmt.Sprintf("Filter by %s (%s)", name, op)))
+		}
+	}
+	return params
+}
+
+func filterSchema(field pagination.Field) *openapi.Schema {
+	var schema *openapi.Schema
+	switch field.Type {
+	case pagination.TypeTime:
+		schema = openapi.DateTime()
+	case pagination.TypeInt:
+		schema = openapi.Integer()
+	case pagination.TypeFloat:
+		schema = openapi.Number()
+	case pagination.TypeBool:
+		schema = openapi.Boolean()
+	case pagination.TypeUUID:
+		schema = openapi.UUID()
+	default:
+		schema = openapi.String()
+	}
+	if len(field.Values) > 0 {
+		schema.Enum = toAny(field.Values)
+	}
+	return schema
+}
+
+func pathParamSchema(name string) *openapi.Schema {
+	if name == "id" || strings.HasSuffix(name, "_id") {
+		return openapi.UUID()
+	}
+	return openapi.String()
+}
+
+func pathParam(name string, schema *openapi.Schema) *openapi.Parameter {
+	return &openapi.Parameter{Name: name, In: openapi.InPath, Required: true, Schema: schema}
+}
+
+func queryParam(name string, schema *openapi.Schema, description string) *openapi.Parameter {
+	return &openapi.Parameter{Name: name, In: openapi.InQuery, Description: description, Schema: schema}
+}
+
+func headerParam(name string, schema *openapi.Schema, description string) *openapi.Parameter {
+	return &openapi.Parameter{Name: name, In: openapi.InHeader, Description: description, Schema: schema}
+}
+
+func minimum(schema *openapi.Schema, limit float64) *openapi.Schema {
+	schema.Minimum = &limit
+	return schema
+}
+
+func jsonContent(schema *openapi.Schema) map[string]openapi.MediaType {
+	return map[string]openapi.MediaType{openapi.MediaTypeJSON: {Schema: schema}}
+}
+
+func binaryContent(mediaTypes ...string) map[string]openapi.MediaType {
+	content := make(map[string]openapi.MediaType, len(mediaTypes))
+	for _, mediaType := range mediaTypes {
+		content[mediaType] = openapi.MediaType{Schema: openapi.Binary()}
+	}
+	return content
+}
+
+func feedFormatSchema() *openapi.Schema {
+	return openapi.String().WithEnum(toAny(feed.Formats)...)
+}
+
+func feedContent() map[string]openapi.MediaType {
+	mediaTypes := make([]string, 0, len(feed.Formats))
+	for _, format := range feed.Formats {
+		mediaType, _, _ := mime.ParseMediaType(format.ContentType())
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	return binaryContent(mediaTypes...)
+}
+
+func snapshotFormatSchema() *openapi.Schema {
+	return openapi.String().WithEnum(domain.SnapshotFormatHTML, domain.SnapshotFormatWARC)
+}
+
+func snapshotContent() map[string]openapi.MediaType {
+	return binaryContent("text/html", "application/warc")
+}
+
+func importFormatSchema() *openapi.Schema {
+	return openapi.String().WithEnum(bookmarks.FormatNetscape, bookmarks.FormatPocketHTML, bookmarks.FormatPocketCSV,
+		bookmarks.FormatPinboard, bookmarks.FormatCSV)
+}
+
+func importBody() *openapi.RequestBody {
+	content := binaryContent("application/octet-stream")
+	content["multipart/form-data"] = openapi.MediaType{Schema: &openapi.Schema{
+		Type:       openapi.Types{openapi.TypeObject},
+		Properties: map[string]*openapi.Schema{formImportFile: openapi.Binary()},
+		Required:   []string{formImportFile},
+	}}
+	return &openapi.RequestBody{
+		Description: fmt.Sprintf("The exported file, either as the body or as the %s field of a form", formImportFile),
+		Required:    true,
+		Content:     content,
+	}
+}
+
+func toAny[T any](values []T) []any {
+	s := make([]any, 0, len(values))
+	for _, v := range values {
+		s = append(s, v)
+	}
+	return s
+}
+
+func (h *Handler) handleGetSpec(c *gin.Context) {
+	c.JSON(http.StatusOK, h.spec)
+}
+
+func (h *Handler) handleGetDocs(c *gin.Context) {
+	c.Header("Content-Security-Policy", docsPolicy)
+	c.Data(http.StatusOK, "text/html; charset=utf-8", docsPage)
+}
+
+// useRequestValidation rejects the requests which do not match the operations of their routes in the spec
+// before they reach the handlers, so the clients get all the errors of the request at once
+func (h *Handler) useRequestValidation(c *gin.Context) {
+	op, ok := h.operations[c.Request.Method+" "+c.FullPath()]
+	if !ok {
+		return
+	}
+
+	pathParams := make(map[string]string, len(c.Params))
+	for _, param := range c.Params {
+		pathParams[param.Key] = param.Value
+	}
+
+	if err := h.spec.ValidateRequest(op, c.Request, pathParams); err != nil {
+		var validationErrs openapi.ValidationErrors
+		if errors.As(err, &validationErrs) {
+			writeAbort(c, http.StatusBadRequest, "invalid input", err)
+			return
+		}
+		writeAbort(c, http.StatusBadRequest, "failed to read request", err)
+	}
+}
@@ -0,0 +1,42 @@
+package v1
+
+import (
+	"github.com/adanyl0v/go-pocket-link/internal/service"
+	"github.com/adanyl0v/go-pocket-link/pkg/openapi"
+	"github.com/gin-gonic/gin"
+	"strings"
+	"testing"
+)
+
+func TestSpecMatchesRouter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := NewHandler(&service.Services{})
+	h.InitEndpoints(router.Group("/api/v1"))
+
+	routes := make([]openapi.Route, 0, len(router.Routes()))
+	for _, route := range router.Routes() {
+		if path, ok := strings.CutPrefix(route.Path, h.basePath); ok {
+			routes = append(routes, openapi.Route{Method: route.Method, Path: path})
+		}
+	}
+	if len(routes) == 0 {
+		t.Fatal("no routes under the base path")
+	}
+
+	if err := h.spec.CheckRoutes(routes); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSpecReportsUndocumentedRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := NewHandler(&service.Services{})
+	h.InitEndpoints(router.Group("/api/v1"))
+
+	routes := []openapi.Route{{Method: "GET", Path: "/undocumented/:id"}}
+	if err := h.spec.CheckRoutes(routes); err == nil {
+		t.Fatal("expected the undocumented route and the unrouted operations to be reported")
+	}
+}
@@ -0,0 +1,154 @@
+package v1
+
+import (
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/internal/service"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+func (h *Handler) handleGetReadingState(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	linkID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	state, err := h.services.Reading.Get(c, userID, linkID)
+	if err != nil {
+		writeServiceError(c, "failed to get reading state", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, state)
+	slog.DebugContext(c, "got reading state", "link_id", linkID)
+}
+
+// readingStateOutput tells whether the change has been applied, the state being the current one either way
+type readingStateOutput struct {
+	Applied      bool                `json:"applied"`
+	ReadingState domain.ReadingState `json:"reading_state"`
+}
+
+type progressInput struct {
+	Percentage      float64   `json:"percentage" binding:"min=0,max=100"`
+	CharOffset      int       `json:"char_offset" binding:"min=0"`
+	ClientTimestamp time.Time `json:"client_timestamp"`
+}
+
+func (h *Handler) handleUpdateProgress(c *gin.Context) {
+	var input progressInput
+	if err := bindInput(c, &input); err != nil {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	linkID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	state, applied, err := h.services.Reading.UpdateProgress(c, userID, linkID, input.Percentage, input.CharOffset,
+		input.ClientTimestamp)
+	if err != nil {
+		writeServiceError(c, "failed to update reading progress", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, readingStateOutput{Applied: applied, ReadingState: state})
+	slog.DebugContext(c, "updated reading progress", "link_id", linkID, "applied", applied)
+}
+
+type openLinkInput struct {
+	ClientTimestamp time.Time `json:"client_timestamp"`
+}
+
+func (h *Handler) handleOpenLink(c *gin.Context) {
+	var input openLinkInput
+	if c.Request.ContentLength > 0 {
+		if err := bindInput(c, &input); err != nil {
+			return
+		}
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	linkID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	state, err := h.services.Reading.MarkOpened(c, userID, linkID, input.ClientTimestamp)
+	if err != nil {
+		writeServiceError(c, "failed to mark link opened", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, state)
+	slog.DebugContext(c, "opened link", "link_id", linkID)
+}
+
+type readInput struct {
+	Read            *bool     `json:"read" binding:"required"`
+	ClientTimestamp time.Time `json:"client_timestamp"`
+}
+
+func (h *Handler) handleSetRead(c *gin.Context) {
+	var input readInput
+	if err := bindInput(c, &input); err != nil {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	linkID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	state, applied, err := h.services.Reading.SetRead(c, userID, linkID, *input.Read, input.ClientTimestamp)
+	if err != nil {
+		writeServiceError(c, "failed to set read state", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, readingStateOutput{Applied: applied, ReadingState: state})
+	slog.DebugContext(c, "set read state", "link_id", linkID, "read", *input.Read, "applied", applied)
+}
+
+func (h *Handler) handleContinueReading(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	params, ok := parsePageParams(c, service.ContinueReadingPageSpec)
+	if !ok {
+		return
+	}
+
+	page, err := h.services.Reading.GetContinueReading(c, userID, params)
+	if err != nil {
+		writeServiceError(c, "failed to get links to continue reading", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+	slog.DebugContext(c, "got links to continue reading", "count", len(page.Data))
+}
@@ -0,0 +1,166 @@
+package v1
+
+import (
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/internal/service"
+	"github.com/adanyl0v/go-pocket-link/pkg/pagination"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const headerSharePassword = "X-Share-Password"
+
+type shareOutput struct {
+	domain.ListShare
+	HasPassword bool `json:"has_password"`
+}
+
+func newShareOutput(share domain.ListShare) shareOutput {
+	return shareOutput{ListShare: share, HasPassword: share.HasPassword()}
+}
+
+// sharedLinkOutput hides the owner of the shared links
+type sharedLinkOutput struct {
+	URL       string    `json:"url"`
+	Title     string    `json:"title"`
+	Tags      []string  `json:"tags"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type sharedListOutput struct {
+	Title string             `json:"title"`
+	Links []sharedLinkOutput `json:"links"`
+	Page  pagination.Info    `json:"page"`
+}
+
+type shareInput struct {
+	Password  string     `json:"password" form:"password"`
+	ExpiresAt *time.Time `json:"expires_at" form:"expires_at"`
+}
+
+func (h *Handler) handlePublishList(c *gin.Context) {
+	var input shareInput
+	if err := bindInput(c, &input); err != nil {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	share := domain.ListShare{
+		ListID:    id,
+		UserID:    userID,
+		ExpiresAt: input.ExpiresAt,
+	}
+	if err := h.services.Shares.Publish(c, &share, input.Password); err != nil {
+		writeServiceError(c, "failed to publish list", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newShareOutput(share))
+	slog.DebugContext(c, "published list", "id", id)
+}
+
+func (h *Handler) handleGetShare(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	share, err := h.services.Shares.Get(c, userID, id)
+	if err != nil {
+		writeServiceError(c, "failed to get share", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newShareOutput(share))
+	slog.DebugContext(c, "got share", "list_id", id)
+}
+
+func (h *Handler) handleRegenerateShare(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	share, err := h.services.Shares.Regenerate(c, userID, id)
+	if err != nil {
+		writeServiceError(c, "failed to regenerate share", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newShareOutput(share))
+	slog.DebugContext(c, "regenerated share", "list_id", id)
+}
+
+func (h *Handler) handleRevokeShare(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Shares.Revoke(c, userID, id); err != nil {
+		writeServiceError(c, "failed to revoke share", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	slog.DebugContext(c, "revoked share", "list_id", id)
+}
+
+// handleViewShare is public, the password of a protected share is sent in the X-Share-Password header.
+// It is never read from the query, which is logged and kept in the browser history
+func (h *Handler) handleViewShare(c *gin.Context) {
+	params, ok := parsePageParams(c, service.LinksPageSpec)
+	if !ok {
+		return
+	}
+
+	shared, err := h.services.Shares.View(c, c.Param("slug"), c.GetHeader(headerSharePassword), params)
+	if err != nil {
+		writeServiceError(c, "failed to view share", err)
+		return
+	}
+
+	links := make([]sharedLinkOutput, 0, len(shared.Links.Data))
+	for _, link := range shared.Links.Data {
+		links = append(links, sharedLinkOutput{
+			URL:       link.URL,
+			Title:     link.Title,
+			Tags:      link.Tags,
+			CreatedAt: link.CreatedAt,
+		})
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, sharedListOutput{
+		Title: shared.List.Title,
+		Links: links,
+		Page:  shared.Links.Page,
+	})
+	slog.DebugContext(c, "viewed share", "list_id", shared.List.ID)
+}
@@ -0,0 +1,169 @@
+package v1
+
+import (
+	"fmt"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/internal/service"
+	"github.com/gin-gonic/gin"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+// snapshotPolicy keeps the snapshots from running anything or loading anything but their inlined assets,
+// since they are served from the origin of the api
+const snapshotPolicy = "sandbox; default-src 'none'; img-src data:; style-src 'unsafe-inline' data:; font-src data:"
+
+type snapshotFormatInput struct {
+	Format domain.SnapshotFormat `json:"format" form:"format"`
+}
+
+func (i snapshotFormatInput) format() domain.SnapshotFormat {
+	if i.Format == "" {
+		return domain.SnapshotFormatHTML
+	}
+	return i.Format
+}
+
+func (h *Handler) handleCaptureSnapshot(c *gin.Context) {
+	var input snapshotFormatInput
+	if err := bindInput(c, &input); err != nil {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	snapshot, err := h.services.Snapshots.Capture(c, userID, id, input.format())
+	if err != nil {
+		writeServiceError(c, "failed to capture snapshot", err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, snapshot)
+	slog.DebugContext(c, "started snapshot", "id", snapshot.ID, "link_id", id, "format", snapshot.Format)
+}
+
+func (h *Handler) handleGetSnapshots(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	params, ok := parsePageParams(c, service.SnapshotsPageSpec)
+	if !ok {
+		return
+	}
+
+	page, err := h.services.Snapshots.GetPageByLinkID(c, userID, id, params)
+	if err != nil {
+		writeServiceError(c, "failed to get snapshots", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+	slog.DebugContext(c, "got snapshots", "link_id", id, "count", len(page.Data))
+}
+
+// handleGetSnapshot serves the file of the snapshot, the HTML ones to be viewed in the browser
+// and the WARC ones to be downloaded
+func (h *Handler) handleGetSnapshot(c *gin.Context) {
+	var input snapshotFormatInput
+	if err := c.ShouldBindQuery(&input); err != nil {
+		writeError(c, http.StatusBadRequest, "invalid input", err)
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	object, snapshot, err := h.services.Snapshots.Open(c, userID, id, input.format())
+	if err != nil {
+		writeServiceError(c, "failed to open snapshot", err)
+		return
+	}
+	defer func() { _ = object.Close() }()
+
+	// the files are stored by their content, so the hash of the content is a strong validator
+	etag := fmt.Sprintf(`"%s-%s"`, snapshot.ContentHash, snapshot.Format)
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "private, no-cache")
+	if snapshot.CapturedAt != nil && isNotModified(c.Request, etag, *snapshot.CapturedAt) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Header("X-Content-Type-Options", "nosniff")
+	if snapshot.Format == domain.SnapshotFormatWARC {
+		c.Header("Content-Type", "application/warc")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="snapshot-%s.warc"`, snapshot.LinkID))
+	} else {
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		c.Header("Content-Security-Policy", snapshotPolicy)
+	}
+	c.Header("Content-Length", fmt.Sprint(snapshot.Size))
+	c.Status(http.StatusOK)
+
+	if _, err = io.Copy(c.Writer, object.Body); err != nil {
+		// the headers are already sent, so the client only gets a truncated file
+		_ = c.Error(err)
+		slog.ErrorContext(c, "failed to write snapshot", "id", snapshot.ID, logError, err)
+		return
+	}
+	slog.DebugContext(c, "got snapshot", "id", snapshot.ID, "format", snapshot.Format)
+}
+
+func (h *Handler) handleDeleteSnapshots(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Snapshots.Delete(c, userID, id); err != nil {
+		writeServiceError(c, "failed to delete snapshots", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	slog.DebugContext(c, "deleted snapshots", "link_id", id)
+}
+
+func (h *Handler) handleGetSnapshotUsage(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	usage, err := h.services.Snapshots.GetUsage(c, userID)
+	if err != nil {
+		writeServiceError(c, "failed to get snapshot usage", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, usage)
+	slog.DebugContext(c, "got snapshot usage", "used", usage.Used, "quota", usage.Quota)
+}
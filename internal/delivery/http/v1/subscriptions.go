@@ -0,0 +1,167 @@
+package v1
+
+import (
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"log/slog"
+	"net/http"
+)
+
+type subscriptionInput struct {
+	ListID *uuid.UUID `json:"list_id" form:"list_id"`
+	Tags   []string   `json:"tags" form:"tags"`
+	Active *bool      `json:"active" form:"active"`
+}
+
+func (i subscriptionInput) active() bool {
+	return i.Active == nil || *i.Active
+}
+
+type createSubscriptionInput struct {
+	URL string `json:"url" form:"url" binding:"required"`
+	subscriptionInput
+}
+
+func (h *Handler) handleCreateSubscription(c *gin.Context) {
+	var input createSubscriptionInput
+	if err := bindInput(c, &input); err != nil {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	subscription := domain.FeedSubscription{
+		UserID: userID,
+		URL:    input.URL,
+		ListID: input.ListID,
+		Tags:   input.Tags,
+		Active: input.active(),
+	}
+	if err := h.services.Subscriptions.Create(c, &subscription); err != nil {
+		writeServiceError(c, "failed to create feed subscription", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, subscription)
+	slog.DebugContext(c, "created feed subscription", "id", subscription.ID)
+}
+
+func (h *Handler) handleGetSubscriptions(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	params, ok := parsePageParams(c, service.SubscriptionsPageSpec)
+	if !ok {
+		return
+	}
+
+	page, err := h.services.Subscriptions.GetPage(c, userID, params)
+	if err != nil {
+		writeServiceError(c, "failed to get feed subscriptions", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+	slog.DebugContext(c, "got feed subscriptions", "count", len(page.Data))
+}
+
+func (h *Handler) handleGetSubscription(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	subscription, err := h.services.Subscriptions.Get(c, userID, id)
+	if err != nil {
+		writeServiceError(c, "failed to get feed subscription", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+	slog.DebugContext(c, "got feed subscription", "id", subscription.ID)
+}
+
+func (h *Handler) handleUpdateSubscription(c *gin.Context) {
+	var input subscriptionInput
+	if err := bindInput(c, &input); err != nil {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	subscription := domain.FeedSubscription{
+		ID:     id,
+		UserID: userID,
+		ListID: input.ListID,
+		Tags:   input.Tags,
+		Active: input.active(),
+	}
+	if err := h.services.Subscriptions.Update(c, &subscription); err != nil {
+		writeServiceError(c, "failed to update feed subscription", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+	slog.DebugContext(c, "updated feed subscription", "id", subscription.ID)
+}
+
+func (h *Handler) handleDeleteSubscription(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Subscriptions.Delete(c, userID, id); err != nil {
+		writeServiceError(c, "failed to delete feed subscription", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	slog.DebugContext(c, "deleted feed subscription", "id", id)
+}
+
+func (h *Handler) handleRefreshSubscription(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	fetch, err := h.services.Subscriptions.Refresh(c, userID, id)
+	if err != nil {
+		writeServiceError(c, "failed to refresh feed subscription", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, fetch)
+	slog.DebugContext(c, "refreshed feed subscription", "id", id, "saved", fetch.Saved, "not_modified", fetch.NotModified)
+}
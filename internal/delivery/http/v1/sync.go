@@ -0,0 +1,84 @@
+package v1
+
+import (
+	"encoding/json"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type syncMutationInput struct {
+	Type        domain.SyncEntityType `json:"type" binding:"required,oneof=link list"`
+	Op          domain.SyncOperation  `json:"op" binding:"required,oneof=create update delete"`
+	ID          uuid.UUID             `json:"id"`
+	BaseVersion int64                 `json:"base_version" binding:"min=0"`
+	Data        json.RawMessage       `json:"data"`
+}
+
+type syncResultsOutput struct {
+	Results []domain.SyncMutationResult `json:"results"`
+}
+
+func (h *Handler) handleGetSyncChanges(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	limit := 0
+	if rawLimit := c.Query("limit"); rawLimit != "" {
+		var err error
+		if limit, err = strconv.Atoi(rawLimit); err != nil || limit < 1 {
+			writeError(c, http.StatusBadRequest, "invalid limit", err)
+			return
+		}
+	}
+
+	delta, err := h.services.Sync.GetChanges(c, userID, c.Query("since"), limit)
+	if err != nil {
+		writeServiceError(c, "failed to get changes", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, delta)
+	slog.DebugContext(c, "got changes", "count", len(delta.Changes), "has_more", delta.HasMore)
+}
+
+type syncMutationsInput struct {
+	Mutations []syncMutationInput `json:"mutations" binding:"required,min=1,max=500,dive"`
+}
+
+func (h *Handler) handlePushSyncMutations(c *gin.Context) {
+	var input syncMutationsInput
+	if err := bindInput(c, &input); err != nil {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	mutations := make([]domain.SyncMutation, 0, len(input.Mutations))
+	for _, mutation := range input.Mutations {
+		mutations = append(mutations, domain.SyncMutation{
+			Type:        mutation.Type,
+			Op:          mutation.Op,
+			ID:          mutation.ID,
+			BaseVersion: mutation.BaseVersion,
+			Data:        mutation.Data,
+		})
+	}
+
+	results, err := h.services.Sync.Push(c, userID, mutations)
+	if err != nil {
+		writeServiceError(c, "failed to apply mutations", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, syncResultsOutput{Results: results})
+	slog.DebugContext(c, "applied mutations", "count", len(results))
+}
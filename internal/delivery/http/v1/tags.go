@@ -0,0 +1,138 @@
+package v1
+
+import (
+	"github.com/adanyl0v/go-pocket-link/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"log/slog"
+	"net/http"
+)
+
+type tagsOnLinksInput struct {
+	LinkIDs []uuid.UUID `json:"link_ids" binding:"required,min=1"`
+	Tags    []string    `json:"tags" binding:"required,min=1"`
+}
+
+func (h *Handler) handleGetTags(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	params, ok := parsePageParams(c, service.TagsPageSpec)
+	if !ok {
+		return
+	}
+
+	page, err := h.services.Tags.GetPage(c, userID, params)
+	if err != nil {
+		writeServiceError(c, "failed to get tags", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+	slog.DebugContext(c, "got tags", "count", len(page.Data))
+}
+
+func (h *Handler) handleAttachTags(c *gin.Context) {
+	var input tagsOnLinksInput
+	if err := bindInput(c, &input); err != nil {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.services.Tags.AddToLinks(c, userID, input.LinkIDs, input.Tags); err != nil {
+		writeServiceError(c, "failed to attach tags", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	slog.DebugContext(c, "attached tags", "links", len(input.LinkIDs), "tags", input.Tags)
+}
+
+func (h *Handler) handleDetachTags(c *gin.Context) {
+	var input tagsOnLinksInput
+	if err := bindInput(c, &input); err != nil {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.services.Tags.RemoveFromLinks(c, userID, input.LinkIDs, input.Tags); err != nil {
+		writeServiceError(c, "failed to detach tags", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	slog.DebugContext(c, "detached tags", "links", len(input.LinkIDs), "tags", input.Tags)
+}
+
+type renameTagInput struct {
+	Name string `json:"name" form:"name" binding:"required"`
+}
+
+func (h *Handler) handleRenameTag(c *gin.Context) {
+	var input renameTagInput
+	if err := bindInput(c, &input); err != nil {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.services.Tags.Rename(c, userID, c.Param("name"), input.Name); err != nil {
+		writeServiceError(c, "failed to rename tag", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	slog.DebugContext(c, "renamed tag", "from", c.Param("name"), "to", input.Name)
+}
+
+type mergeTagInput struct {
+	Into string `json:"into" form:"into" binding:"required"`
+}
+
+func (h *Handler) handleMergeTag(c *gin.Context) {
+	var input mergeTagInput
+	if err := bindInput(c, &input); err != nil {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.services.Tags.Merge(c, userID, c.Param("name"), input.Into); err != nil {
+		writeServiceError(c, "failed to merge tags", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	slog.DebugContext(c, "merged tags", "source", c.Param("name"), "target", input.Into)
+}
+
+func (h *Handler) handleDeleteTag(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.services.Tags.Delete(c, userID, c.Param("name")); err != nil {
+		writeServiceError(c, "failed to delete tag", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	slog.DebugContext(c, "deleted tag", "name", c.Param("name"))
+}
@@ -2,6 +2,7 @@ package v1
 
 import (
 	"github.com/adanyl0v/go-pocket-link/internal/service"
+	"github.com/adanyl0v/go-pocket-link/pkg/validator"
 	"github.com/gin-gonic/gin"
 	"log/slog"
 	"net/http"
@@ -25,16 +26,18 @@ func (h *Handler) handleGetUser(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, user)
-	slog.Debug("got user", "user", user)
+	slog.DebugContext(c, "got user", "user", user)
+}
+
+type updateUserInput struct {
+	Name            string `json:"name" form:"name"`
+	Email           string `json:"email" form:"email"`
+	Password        string `json:"password" form:"password"`
+	CurrentPassword string `json:"current_password" form:"current_password"`
 }
 
 func (h *Handler) handleUpdateUser(c *gin.Context) {
-	var input struct {
-		Name            string `json:"name" form:"name"`
-		Email           string `json:"email" form:"email"`
-		Password        string `json:"password" form:"password"`
-		CurrentPassword string `json:"current_password" form:"current_password"`
-	}
+	var input updateUserInput
 	if err := bindInput(c, &input); err != nil {
 		return
 	}
@@ -75,23 +78,56 @@ func (h *Handler) handleUpdateUser(c *gin.Context) {
 		writeError(c, http.StatusInternalServerError, "failed to update user", err)
 		return
 	}
-	slog.Debug("updated user", "id", user.ID)
+	slog.DebugContext(c, "updated user", "id", user.ID)
 }
 
-func validateCredentials(s *service.UsersService, name, email, password string) error {
-	if err := s.ValidateName(name); err != nil {
-		return err
-	} else if err = validateEmailAndPassword(s, email, password); err != nil {
-		return err
+type deleteUserInput struct {
+	Password string `json:"password" form:"password" binding:"required"`
+}
+
+// handleDeleteUser deletes the account once the password is confirmed, logging it out everywhere.
+// The account is purged along with its data after a grace period
+func (h *Handler) handleDeleteUser(c *gin.Context) {
+	var input deleteUserInput
+	if err := bindInput(c, &input); err != nil {
+		return
 	}
-	return nil
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.services.Users.Get(c, userID)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, "failed to get user", err)
+		return
+	}
+
+	if !h.services.Users.ComparePasswordAndHash(input.Password, user.Password) {
+		writeError(c, http.StatusBadRequest, "incorrect password", nil)
+		return
+	}
+
+	if err = h.services.Users.Delete(c, userID); err != nil {
+		writeError(c, http.StatusInternalServerError, "failed to delete user", err)
+		return
+	}
+
+	if err = h.services.Tokens.InvalidateUser(c, userID); err != nil {
+		writeError(c, http.StatusInternalServerError, "failed to invalidate user", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	slog.DebugContext(c, "deleted user", "id", userID)
+}
+
+// validateCredentials validates all the credentials at once, so the clients get the errors of every field
+func validateCredentials(s *service.UsersService, name, email, password string) error {
+	return validator.Join(s.ValidateName(name), s.ValidateEmail(email), s.ValidatePassword(password))
 }
 
 func validateEmailAndPassword(s *service.UsersService, email, password string) error {
-	if err := s.ValidateEmail(email); err != nil {
-		return err
-	} else if err = s.ValidatePassword(password); err != nil {
-		return err
-	}
-	return nil
+	return validator.Join(s.ValidateEmail(email), s.ValidatePassword(password))
 }
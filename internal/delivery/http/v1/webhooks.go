@@ -0,0 +1,269 @@
+package v1
+
+import (
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/internal/service"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net/http"
+)
+
+type webhookInput struct {
+	URL    string               `json:"url" form:"url" binding:"required"`
+	Events domain.WebhookEvents `json:"events" form:"events" binding:"required,min=1"`
+	Active *bool                `json:"active" form:"active"`
+}
+
+func (i webhookInput) active() bool {
+	return i.Active == nil || *i.Active
+}
+
+func (h *Handler) handleCreateWebhook(c *gin.Context) {
+	var input webhookInput
+	if err := bindInput(c, &input); err != nil {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	webhook := domain.Webhook{
+		UserID: userID,
+		URL:    input.URL,
+		Events: input.Events,
+		Active: input.active(),
+	}
+	if err := h.services.Webhooks.Create(c, &webhook); err != nil {
+		writeServiceError(c, "failed to create webhook", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhook)
+	slog.DebugContext(c, "created webhook", "id", webhook.ID)
+}
+
+func (h *Handler) handleGetWebhooks(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	params, ok := parsePageParams(c, service.WebhooksPageSpec)
+	if !ok {
+		return
+	}
+
+	page, err := h.services.Webhooks.GetPage(c, userID, params)
+	if err != nil {
+		writeServiceError(c, "failed to get webhooks", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+	slog.DebugContext(c, "got webhooks", "count", len(page.Data))
+}
+
+func (h *Handler) handleGetWebhook(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	webhook, err := h.services.Webhooks.Get(c, userID, id)
+	if err != nil {
+		writeServiceError(c, "failed to get webhook", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+	slog.DebugContext(c, "got webhook", "id", webhook.ID)
+}
+
+func (h *Handler) handleUpdateWebhook(c *gin.Context) {
+	var input webhookInput
+	if err := bindInput(c, &input); err != nil {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	webhook, err := h.services.Webhooks.Get(c, userID, id)
+	if err != nil {
+		writeServiceError(c, "failed to get webhook", err)
+		return
+	}
+
+	webhook.URL = input.URL
+	webhook.Events = input.Events
+	webhook.Active = input.active()
+	if err = h.services.Webhooks.Update(c, &webhook); err != nil {
+		writeServiceError(c, "failed to update webhook", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+	slog.DebugContext(c, "updated webhook", "id", webhook.ID)
+}
+
+func (h *Handler) handleDeleteWebhook(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Webhooks.Delete(c, userID, id); err != nil {
+		writeServiceError(c, "failed to delete webhook", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	slog.DebugContext(c, "deleted webhook", "id", id)
+}
+
+func (h *Handler) handleRotateWebhookSecret(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	webhook, err := h.services.Webhooks.RotateSecret(c, userID, id)
+	if err != nil {
+		writeServiceError(c, "failed to rotate webhook secret", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+	slog.DebugContext(c, "rotated webhook secret", "id", webhook.ID)
+}
+
+func (h *Handler) handleSendTestWebhook(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	delivery, err := h.services.Webhooks.SendTest(c, userID, id)
+	if err != nil {
+		writeServiceError(c, "failed to send test webhook", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+	slog.DebugContext(c, "sent test webhook", "id", id, "delivery_id", delivery.ID, "status", delivery.Status)
+}
+
+func (h *Handler) handleGetWebhookDeliveries(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	params, ok := parsePageParams(c, service.WebhookDeliveriesPageSpec)
+	if !ok {
+		return
+	}
+
+	page, err := h.services.Webhooks.GetDeliveriesPage(c, userID, id, params)
+	if err != nil {
+		writeServiceError(c, "failed to get webhook deliveries", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+	slog.DebugContext(c, "got webhook deliveries", "id", id, "count", len(page.Data))
+}
+
+type webhookDeliveryOutput struct {
+	Delivery domain.WebhookDelivery  `json:"delivery"`
+	Attempts []domain.WebhookAttempt `json:"attempts"`
+}
+
+func (h *Handler) handleGetWebhookDelivery(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	deliveryID, ok := parseIDParam(c, "delivery_id")
+	if !ok {
+		return
+	}
+
+	delivery, attempts, err := h.services.Webhooks.GetDelivery(c, userID, id, deliveryID)
+	if err != nil {
+		writeServiceError(c, "failed to get webhook delivery", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhookDeliveryOutput{
+		Delivery: delivery,
+		Attempts: attempts,
+	})
+	slog.DebugContext(c, "got webhook delivery", "id", id, "delivery_id", deliveryID)
+}
+
+func (h *Handler) handleRedeliverWebhook(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	deliveryID, ok := parseIDParam(c, "delivery_id")
+	if !ok {
+		return
+	}
+
+	delivery, err := h.services.Webhooks.Redeliver(c, userID, id, deliveryID)
+	if err != nil {
+		writeServiceError(c, "failed to retry webhook delivery", err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+	slog.DebugContext(c, "queued webhook delivery", "id", id, "delivery_id", deliveryID)
+}
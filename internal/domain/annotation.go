@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Highlight is anchored to the article text by both the quote (Exact with its Prefix and Suffix)
+// and its character offsets (Start and End), so it can be found again if the text changes
+type Highlight struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	LinkID    uuid.UUID `json:"link_id" db:"link_id"`
+	Exact     string    `json:"exact" db:"exact"`
+	Prefix    string    `json:"prefix" db:"prefix"`
+	Suffix    string    `json:"suffix" db:"suffix"`
+	Start     int       `json:"start" db:"start_pos"`
+	End       int       `json:"end" db:"end_pos"`
+	Color     string    `json:"color" db:"color"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Note is attached to a link, and optionally to one of its highlights
+type Note struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	UserID      uuid.UUID  `json:"user_id" db:"user_id"`
+	LinkID      uuid.UUID  `json:"link_id" db:"link_id"`
+	HighlightID *uuid.UUID `json:"highlight_id" db:"highlight_id"`
+	Body        string     `json:"body" db:"body"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNotFound      = errors.New("not found")
+	ErrAlreadyExists = errors.New("already exists")
+	ErrInvalidInput  = errors.New("invalid input")
+	ErrForbidden     = errors.New("forbidden")
+	ErrUnavailable   = errors.New("unavailable")
+	ErrConflict      = errors.New("conflict")
+)
+
+var (
+	ErrInvalidCredentials = fmt.Errorf("user %w: email or password is wrong", ErrNotFound)
+
+	ErrLinkNotFound      = fmt.Errorf("link %w", ErrNotFound)
+	ErrLinkAlreadyExists = fmt.Errorf("link %w", ErrAlreadyExists)
+	ErrInvalidURL        = fmt.Errorf("%w: url must be an absolute http(s) url", ErrInvalidInput)
+	ErrLinkConflict      = fmt.Errorf("link %w: version does not match", ErrConflict)
+
+	ErrListNotFound      = fmt.Errorf("list %w", ErrNotFound)
+	ErrListAlreadyExists = fmt.Errorf("list %w", ErrAlreadyExists)
+	ErrInvalidListTitle  = fmt.Errorf("%w: list title must be 1-255 characters long", ErrInvalidInput)
+	ErrListConflict      = fmt.Errorf("list %w: version does not match", ErrConflict)
+
+	ErrListPermissionDenied    = fmt.Errorf("%w: not enough permissions for the list", ErrForbidden)
+	ErrInvalidListRole         = fmt.Errorf("%w: role must be editor or viewer", ErrInvalidInput)
+	ErrListMemberNotFound      = fmt.Errorf("list member %w", ErrNotFound)
+	ErrListMemberAlreadyExists = fmt.Errorf("list member %w", ErrAlreadyExists)
+	ErrOwnerNotRemovable       = fmt.Errorf("%w: the owner cannot leave the list", ErrInvalidInput)
+	ErrInvalidEmail            = fmt.Errorf("%w: email", ErrInvalidInput)
+	ErrInvitationNotFound      = fmt.Errorf("invitation %w", ErrNotFound)
+	ErrInvitationExpired       = fmt.Errorf("invitation %w (expired)", ErrNotFound)
+	ErrInvitationEmailMismatch = fmt.Errorf("%w: invitation was sent to another email", ErrForbidden)
+
+	ErrShareNotFound         = fmt.Errorf("share %w", ErrNotFound)
+	ErrShareExpired          = fmt.Errorf("share %w (expired)", ErrNotFound)
+	ErrSharePasswordRequired = fmt.Errorf("%w: share password is missing or wrong", ErrForbidden)
+	ErrInvalidShareExpiry    = fmt.Errorf("%w: share expiry must be in the future", ErrInvalidInput)
+	ErrInvalidSharePassword  = fmt.Errorf("%w: share password must be at most 72 bytes long", ErrInvalidInput)
+
+	ErrWebhookNotFound         = fmt.Errorf("webhook %w", ErrNotFound)
+	ErrInvalidWebhookURL       = fmt.Errorf("%w: webhook url must be an absolute http(s) url", ErrInvalidInput)
+	ErrInvalidWebhookEvents    = fmt.Errorf("%w: webhook events must be known event types", ErrInvalidInput)
+	ErrWebhookDeliveryNotFound = fmt.Errorf("webhook delivery %w", ErrNotFound)
+	ErrWebhookDeliveryNotDead  = fmt.Errorf("%w: only dead deliveries can be retried", ErrInvalidInput)
+
+	ErrImportNotFound    = fmt.Errorf("import %w", ErrNotFound)
+	ErrUnknownFormat     = fmt.Errorf("%w: unknown format", ErrInvalidInput)
+	ErrEmptyImportSource = fmt.Errorf("%w: nothing to import", ErrInvalidInput)
+
+	ErrExportNotFound = fmt.Errorf("export %w", ErrNotFound)
+	ErrExportNotReady = fmt.Errorf("%w: export is not completed", ErrInvalidInput)
+	ErrExportExpired  = fmt.Errorf("export %w (expired)", ErrNotFound)
+
+	ErrBlobNotFound   = fmt.Errorf("blob %w", ErrNotFound)
+	ErrInvalidBlobURL = fmt.Errorf("%w: blob url signature is invalid or expired", ErrForbidden)
+
+	ErrSnapshotNotFound      = fmt.Errorf("snapshot %w", ErrNotFound)
+	ErrSnapshotNotReady      = fmt.Errorf("%w: snapshot is not captured yet", ErrInvalidInput)
+	ErrInvalidSnapshotFormat = fmt.Errorf("%w: snapshot format must be html or warc", ErrInvalidInput)
+	ErrSnapshotQuotaExceeded = fmt.Errorf("%w: snapshot storage quota exceeded", ErrForbidden)
+
+	ErrHighlightNotFound = fmt.Errorf("highlight %w", ErrNotFound)
+	ErrInvalidHighlight  = fmt.Errorf("%w: highlight", ErrInvalidInput)
+	ErrNoteNotFound      = fmt.Errorf("note %w", ErrNotFound)
+	ErrInvalidNote       = fmt.Errorf("%w: note body must not be empty", ErrInvalidInput)
+
+	ErrFeedNotFound = fmt.Errorf("feed %w", ErrNotFound)
+
+	ErrSubscriptionNotFound      = fmt.Errorf("feed subscription %w", ErrNotFound)
+	ErrSubscriptionAlreadyExists = fmt.Errorf("feed subscription %w", ErrAlreadyExists)
+	ErrInvalidSubscriptionURL    = fmt.Errorf("%w: feed url must be an absolute http(s) url", ErrInvalidInput)
+
+	ErrLinkHealthNotFound = fmt.Errorf("link health %w", ErrNotFound)
+
+	ErrInvalidSyncToken    = fmt.Errorf("%w: sync token", ErrInvalidInput)
+	ErrInvalidSyncMutation = fmt.Errorf("%w: sync mutation", ErrInvalidInput)
+
+	ErrStreamUnavailable = fmt.Errorf("event stream %w", ErrUnavailable)
+
+	ErrTaskNotFound         = fmt.Errorf("task %w", ErrNotFound)
+	ErrTaskAlreadyTriggered = fmt.Errorf("task %w: it has been triggered already", ErrConflict)
+	ErrAdminRequired        = fmt.Errorf("%w: admin access required", ErrForbidden)
+
+	ErrInvalidProgress = fmt.Errorf("%w: percentage must be within [0, 100] and offset must not be negative", ErrInvalidInput)
+
+	ErrTagNotFound      = fmt.Errorf("tag %w", ErrNotFound)
+	ErrTagAlreadyExists = fmt.Errorf("tag %w", ErrAlreadyExists)
+	ErrInvalidTagName   = fmt.Errorf("%w: tag name must be 1-64 characters long", ErrInvalidInput)
+	ErrSameTag          = fmt.Errorf("%w: source and target tags must differ", ErrInvalidInput)
+)
@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventLinkSaved       EventType = "link.saved"
+	EventLinkUpdated     EventType = "link.updated"
+	EventLinkDeleted     EventType = "link.deleted"
+	EventLinkTagged      EventType = "link.tagged"
+	EventLinkUntagged    EventType = "link.untagged"
+	EventLinkArchived    EventType = "link.archived"
+	EventLinkUnarchived  EventType = "link.unarchived"
+	EventListCreated     EventType = "list.created"
+	EventListUpdated     EventType = "list.updated"
+	EventListDeleted     EventType = "list.deleted"
+	EventListLinkAdded   EventType = "list.link_added"
+	EventListLinkRemoved EventType = "list.link_removed"
+)
+
+// EventTypes are the types of the events published by the services
+var EventTypes = []EventType{
+	EventLinkSaved,
+	EventLinkUpdated,
+	EventLinkDeleted,
+	EventLinkTagged,
+	EventLinkUntagged,
+	EventLinkArchived,
+	EventLinkUnarchived,
+	EventListCreated,
+	EventListUpdated,
+	EventListDeleted,
+	EventListLinkAdded,
+	EventListLinkRemoved,
+}
+
+// Event is a change of the user's data, Data is its JSON-serializable subject
+type Event struct {
+	ID        uuid.UUID `json:"id"`
+	Type      EventType `json:"type"`
+	UserID    uuid.UUID `json:"user_id"`
+	Data      any       `json:"data"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewEvent(eventType EventType, userID uuid.UUID, data any) Event {
+	return Event{
+		ID:        uuid.New(),
+		Type:      eventType,
+		UserID:    userID,
+		Data:      data,
+		CreatedAt: time.Now(),
+	}
+}
+
+// LinkIDsEventData is the data of the events about several links
+type LinkIDsEventData struct {
+	LinkIDs []uuid.UUID `json:"link_ids"`
+	Tags    []string    `json:"tags,omitempty"`
+	ListID  *uuid.UUID  `json:"list_id,omitempty"`
+}
+
+type ListEventData struct {
+	ListID uuid.UUID `json:"list_id"`
+}
+
+// LoggedEvent is an event kept in the user's event log. Its ID orders the events of the user
+// and lets the clients resume receiving them
+type LoggedEvent struct {
+	ID    string
+	Event Event
+}
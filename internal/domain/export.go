@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type ExportStatus string
+
+const (
+	ExportStatusPending   ExportStatus = "pending"
+	ExportStatusCompleted ExportStatus = "completed"
+	ExportStatusFailed    ExportStatus = "failed"
+)
+
+type Export struct {
+	ID     uuid.UUID    `json:"id" db:"id"`
+	UserID uuid.UUID    `json:"user_id" db:"user_id"`
+	Status ExportStatus `json:"status" db:"status"`
+	// Size is the size of the archive in bytes
+	Size       int64      `json:"size" db:"size"`
+	Error      string     `json:"error,omitempty" db:"error"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	FinishedAt *time.Time `json:"finished_at" db:"finished_at"`
+	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`
+}
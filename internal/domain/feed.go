@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Feed publishes the links of either a list or a tag to the feed readers knowing its secret token
+type Feed struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	UserID     uuid.UUID  `json:"user_id" db:"user_id"`
+	ListID     *uuid.UUID `json:"list_id,omitempty" db:"list_id"`
+	TagID      *uuid.UUID `json:"tag_id,omitempty" db:"tag_id"`
+	TokenHash  string     `json:"-" db:"token_hash"`
+	AccessedAt *time.Time `json:"accessed_at" db:"accessed_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
+	// URLs are the addresses of the feed by format. They are only known when its token is generated,
+	// since the token is stored hashed
+	URLs map[string]string `json:"urls,omitempty" db:"-"`
+}
+
+// FeedLink is a link along with the time it was added to the list or tagged with the tag of a feed
+type FeedLink struct {
+	Link
+	AddedAt time.Time `db:"added_at"`
+}
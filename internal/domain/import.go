@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"time"
+)
+
+type ImportStatus string
+
+const (
+	ImportStatusPending   ImportStatus = "pending"
+	ImportStatusRunning   ImportStatus = "running"
+	ImportStatusCompleted ImportStatus = "completed"
+	ImportStatusFailed    ImportStatus = "failed"
+)
+
+type Import struct {
+	ID         uuid.UUID    `json:"id" db:"id"`
+	UserID     uuid.UUID    `json:"user_id" db:"user_id"`
+	Format     string       `json:"format" db:"format"`
+	Status     ImportStatus `json:"status" db:"status"`
+	Total      int          `json:"total" db:"total"`
+	Processed  int          `json:"processed" db:"processed"`
+	Imported   int          `json:"imported" db:"imported"`
+	Duplicates int          `json:"duplicates" db:"duplicates"`
+	Failed     int          `json:"failed" db:"failed"`
+	Errors     ImportErrors `json:"errors" db:"errors"`
+	// Error is set if the whole import has failed
+	Error      string     `json:"error,omitempty" db:"error"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
+	FinishedAt *time.Time `json:"finished_at" db:"finished_at"`
+}
+
+type ImportItemError struct {
+	// Index is the position of the item in the imported file, starting from 0
+	Index int    `json:"index"`
+	URL   string `json:"url"`
+	Error string `json:"error"`
+}
+
+type ImportErrors []ImportItemError
+
+func (e ImportErrors) Value() (driver.Value, error) {
+	if e == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(e)
+}
+
+func (e *ImportErrors) Scan(src any) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, e)
+	case string:
+		return json.Unmarshal([]byte(v), e)
+	case nil:
+		*e = ImportErrors{}
+		return nil
+	default:
+		return fmt.Errorf("unsupported import errors type %T", src)
+	}
+}
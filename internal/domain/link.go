@@ -0,0 +1,18 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type Link struct {
+	ID            uuid.UUID `json:"id" db:"id"`
+	UserID        uuid.UUID `json:"user_id" db:"user_id"`
+	URL           string    `json:"url" db:"url"`
+	NormalizedURL string    `json:"normalized_url" db:"normalized_url"`
+	Title         string    `json:"title" db:"title"`
+	Tags          []string  `json:"tags" db:"-"`
+	Version       int64     `json:"version" db:"version"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// LinkHealth is the outcome of the latest check of a link. The link is Broken once its checks
+// have failed BrokenAfter times in a row, and works again as soon as one of them succeeds
+type LinkHealth struct {
+	LinkID uuid.UUID `json:"link_id" db:"link_id"`
+	// StatusCode is 0 if the request has failed without a response
+	StatusCode     int    `json:"status_code" db:"status_code"`
+	FinalURL       string `json:"final_url" db:"final_url"`
+	ResponseTimeMs int64  `json:"response_time_ms" db:"response_time_ms"`
+	Error          string `json:"error,omitempty" db:"error"`
+	// Failures is how many checks in a row have failed
+	Failures    int        `json:"failures" db:"failures"`
+	Broken      bool       `json:"broken" db:"broken"`
+	CheckedAt   *time.Time `json:"checked_at" db:"checked_at"`
+	NextCheckAt time.Time  `json:"next_check_at" db:"next_check_at"`
+}
+
+// LinkCheck is a check in the history of a link
+type LinkCheck struct {
+	ID             uuid.UUID `json:"id" db:"id"`
+	LinkID         uuid.UUID `json:"link_id" db:"link_id"`
+	StatusCode     int       `json:"status_code" db:"status_code"`
+	FinalURL       string    `json:"final_url" db:"final_url"`
+	ResponseTimeMs int64     `json:"response_time_ms" db:"response_time_ms"`
+	Error          string    `json:"error,omitempty" db:"error"`
+	OK             bool      `json:"ok" db:"ok"`
+	CheckedAt      time.Time `json:"checked_at" db:"checked_at"`
+}
+
+// LinkCheckTarget is a link due to be checked along with its current health
+type LinkCheckTarget struct {
+	LinkHealth
+	UserID uuid.UUID `db:"user_id"`
+	URL    string    `db:"url"`
+}
+
+// LinkHealthReport is the health of a link with its latest checks. Health is nil until the link is first checked
+type LinkHealthReport struct {
+	Health *LinkHealth `json:"health"`
+	Checks []LinkCheck `json:"checks"`
+}
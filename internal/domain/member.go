@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type ListRole string
+
+const (
+	ListRoleOwner  ListRole = "owner"
+	ListRoleEditor ListRole = "editor"
+	ListRoleViewer ListRole = "viewer"
+)
+
+var listRoleRanks = map[ListRole]int{
+	ListRoleViewer: 1,
+	ListRoleEditor: 2,
+	ListRoleOwner:  3,
+}
+
+// IsAssignable reports whether the role can be given to a member, since a list has a single owner
+func (r ListRole) IsAssignable() bool {
+	return r == ListRoleEditor || r == ListRoleViewer
+}
+
+// Allows reports whether the role grants all permissions of the required one
+func (r ListRole) Allows(required ListRole) bool {
+	return listRoleRanks[r] >= listRoleRanks[required]
+}
+
+// ListMember is a user with access to a list. The owner of the list is its member too
+type ListMember struct {
+	ListID    uuid.UUID `json:"list_id" db:"list_id"`
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	Name      string    `json:"name" db:"name"`
+	Email     string    `json:"email" db:"email"`
+	Role      ListRole  `json:"role" db:"role"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// ListInvitation is accepted with the token sent to the Email, of which only the hash is stored
+type ListInvitation struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	ListID     uuid.UUID  `json:"list_id" db:"list_id"`
+	InviterID  uuid.UUID  `json:"inviter_id" db:"inviter_id"`
+	Email      string     `json:"email" db:"email"`
+	Role       ListRole   `json:"role" db:"role"`
+	TokenHash  string     `json:"-" db:"token_hash"`
+	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at" db:"accepted_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+type ListActivityAction string
+
+const (
+	ListActivityLinkAdded         ListActivityAction = "link_added"
+	ListActivityLinkRemoved       ListActivityAction = "link_removed"
+	ListActivityMemberJoined      ListActivityAction = "member_joined"
+	ListActivityMemberRoleChanged ListActivityAction = "member_role_changed"
+	ListActivityMemberRemoved     ListActivityAction = "member_removed"
+)
+
+// ListActivity is an entry of the activity stream of a list. The link fields are copied,
+// so the entry outlives the link
+type ListActivity struct {
+	ID        uuid.UUID          `json:"id" db:"id"`
+	ListID    uuid.UUID          `json:"list_id" db:"list_id"`
+	ActorID   *uuid.UUID         `json:"actor_id" db:"actor_id"`
+	ActorName string             `json:"actor_name" db:"actor_name"`
+	Action    ListActivityAction `json:"action" db:"action"`
+	LinkID    *uuid.UUID         `json:"link_id,omitempty" db:"link_id"`
+	LinkURL   string             `json:"link_url,omitempty" db:"link_url"`
+	LinkTitle string             `json:"link_title,omitempty" db:"link_title"`
+	MemberID  *uuid.UUID         `json:"member_id,omitempty" db:"member_id"`
+	CreatedAt time.Time          `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// ReadingState is how far the user got in a link. The progress and the read state are updated
+// independently, each keeping the change with the latest client timestamp
+type ReadingState struct {
+	LinkID     uuid.UUID `json:"link_id" db:"link_id"`
+	UserID     uuid.UUID `json:"user_id" db:"user_id"`
+	Percentage float64   `json:"percentage" db:"percentage"`
+	CharOffset int       `json:"char_offset" db:"char_offset"`
+	// ProgressUpdatedAt is the client timestamp of the last applied progress update
+	ProgressUpdatedAt *time.Time `json:"progress_updated_at" db:"progress_updated_at"`
+	LastOpenedAt      *time.Time `json:"last_opened_at" db:"last_opened_at"`
+	ReadAt            *time.Time `json:"read_at" db:"read_at"`
+	// ReadUpdatedAt is the client timestamp of the last applied read state change
+	ReadUpdatedAt *time.Time `json:"read_updated_at" db:"read_updated_at"`
+}
+
+func (s *ReadingState) IsRead() bool {
+	return s.ReadAt != nil
+}
+
+type LinkWithReadingState struct {
+	Link
+	ReadingState ReadingState `json:"reading_state"`
+}
@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// ListShare publishes a list read-only to anyone knowing its unguessable Slug
+type ListShare struct {
+	ListID       uuid.UUID  `json:"list_id" db:"list_id"`
+	UserID       uuid.UUID  `json:"user_id" db:"user_id"`
+	Slug         string     `json:"slug" db:"slug"`
+	PasswordHash string     `json:"-" db:"password_hash"`
+	ExpiresAt    *time.Time `json:"expires_at" db:"expires_at"`
+	Views        int64      `json:"views" db:"views"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+func (s *ListShare) HasPassword() bool {
+	return s.PasswordHash != ""
+}
+
+func (s *ListShare) IsExpired(now time.Time) bool {
+	return s.ExpiresAt != nil && !now.Before(*s.ExpiresAt)
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type SnapshotFormat string
+
+const (
+	// SnapshotFormatHTML is a single HTML file with the images and the styles of the page inlined
+	SnapshotFormatHTML SnapshotFormat = "html"
+	// SnapshotFormatWARC is a WARC file with the responses of the page and of its assets
+	SnapshotFormatWARC SnapshotFormat = "warc"
+)
+
+type SnapshotStatus string
+
+const (
+	SnapshotStatusPending SnapshotStatus = "pending"
+	SnapshotStatusReady   SnapshotStatus = "ready"
+	SnapshotStatusFailed  SnapshotStatus = "failed"
+)
+
+// Snapshot is an archived copy of the page of a link, a link having at most one in each format
+type Snapshot struct {
+	ID     uuid.UUID      `json:"id" db:"id"`
+	UserID uuid.UUID      `json:"user_id" db:"user_id"`
+	LinkID uuid.UUID      `json:"link_id" db:"link_id"`
+	Format SnapshotFormat `json:"format" db:"format"`
+	Status SnapshotStatus `json:"status" db:"status"`
+	// ContentHash is the hash of the captured content, the snapshots of the same content sharing the blob at BlobKey
+	ContentHash string  `json:"content_hash,omitempty" db:"content_hash"`
+	BlobKey     *string `json:"-" db:"blob_key"`
+	Title       string  `json:"title" db:"title"`
+	// Size is the size of the file in bytes, and Resources how many responses, the page and its assets, it holds
+	Size       int64      `json:"size" db:"size"`
+	Resources  int        `json:"resources" db:"resources"`
+	Error      string     `json:"error,omitempty" db:"error"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	CapturedAt *time.Time `json:"captured_at" db:"captured_at"`
+}
+
+// SnapshotUsage is how much of the storage quota the snapshots of the user take, the quota being 0 if there is none
+type SnapshotUsage struct {
+	Used  int64 `json:"used"`
+	Quota int64 `json:"quota"`
+}
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"time"
+)
+
+// FeedSubscription polls an external feed, saving its new items as links of the user
+// tagged with Tags and added to the list, if it is set
+type FeedSubscription struct {
+	ID     uuid.UUID `json:"id" db:"id"`
+	UserID uuid.UUID `json:"user_id" db:"user_id"`
+	URL    string    `json:"url" db:"url"`
+	// Title is the title of the feed, known once it has been fetched
+	Title  string           `json:"title" db:"title"`
+	ListID *uuid.UUID       `json:"list_id" db:"list_id"`
+	Tags   SubscriptionTags `json:"tags" db:"tags"`
+	Active bool             `json:"active" db:"active"`
+	// ETag and LastModified are the validators of the last response, sent with the conditional requests
+	ETag         string `json:"-" db:"etag"`
+	LastModified string `json:"-" db:"last_modified"`
+	// Failures is how many fetches in a row have failed
+	Failures    int        `json:"failures" db:"failures"`
+	LastError   string     `json:"last_error,omitempty" db:"last_error"`
+	NextFetchAt time.Time  `json:"next_fetch_at" db:"next_fetch_at"`
+	FetchedAt   *time.Time `json:"fetched_at" db:"fetched_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// SubscriptionTags are the names of the tags the links saved from a feed are tagged with
+type SubscriptionTags []string
+
+func (t SubscriptionTags) Value() (driver.Value, error) {
+	if t == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(t)
+}
+
+func (t *SubscriptionTags) Scan(src any) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, t)
+	case string:
+		return json.Unmarshal([]byte(v), t)
+	case nil:
+		*t = SubscriptionTags{}
+		return nil
+	default:
+		return fmt.Errorf("unsupported subscription tags type %T", src)
+	}
+}
+
+// FeedSubscriptionFetch is the outcome of fetching a feed. Error is the reason the fetch has failed, if it has
+type FeedSubscriptionFetch struct {
+	Subscription FeedSubscription `json:"subscription"`
+	NotModified  bool             `json:"not_modified"`
+	// Saved is how many new items have been saved
+	Saved int    `json:"saved"`
+	Error string `json:"error,omitempty"`
+}
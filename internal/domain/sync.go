@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"time"
+)
+
+type SyncEntityType string
+
+const (
+	SyncEntityLink SyncEntityType = "link"
+	SyncEntityList SyncEntityType = "list"
+)
+
+// SyncChange is the last change of an entity. Data is the entity, or nil if it has been deleted
+type SyncChange struct {
+	Type      SyncEntityType `json:"type" db:"entity_type"`
+	ID        uuid.UUID      `json:"id" db:"entity_id"`
+	Seq       int64          `json:"-" db:"seq"`
+	Version   int64          `json:"version" db:"version"`
+	Deleted   bool           `json:"deleted" db:"deleted"`
+	ChangedAt time.Time      `json:"changed_at" db:"changed_at"`
+	Data      any            `json:"data,omitempty" db:"-"`
+}
+
+// SyncDelta holds the changes made since a sync token, ordered by the time they were made.
+// Token is where the next sync continues from
+type SyncDelta struct {
+	Changes []SyncChange `json:"changes"`
+	Token   string       `json:"token"`
+	HasMore bool         `json:"has_more"`
+}
+
+// ListWithLinks is a list along with the ids of its links, as it is synced
+type ListWithLinks struct {
+	List
+	LinkIDs []uuid.UUID `json:"link_ids"`
+}
+
+type SyncOperation string
+
+const (
+	SyncOperationCreate SyncOperation = "create"
+	SyncOperationUpdate SyncOperation = "update"
+	SyncOperationDelete SyncOperation = "delete"
+)
+
+// SyncMutation is a change made by an offline client. The updates and deletions are applied
+// only if the entity is still at BaseVersion, so the changes made since are not overwritten
+type SyncMutation struct {
+	Type        SyncEntityType  `json:"type"`
+	Op          SyncOperation   `json:"op"`
+	ID          uuid.UUID       `json:"id"`
+	BaseVersion int64           `json:"base_version"`
+	Data        json.RawMessage `json:"data"`
+}
+
+// SyncLinkData is the data of the link mutations. Nil Tags are left as they are
+type SyncLinkData struct {
+	URL   string   `json:"url"`
+	Title string   `json:"title"`
+	Tags  []string `json:"tags"`
+}
+
+// SyncListData is the data of the list mutations. Nil LinkIDs are left as they are
+type SyncListData struct {
+	Title   string      `json:"title"`
+	LinkIDs []uuid.UUID `json:"link_ids"`
+}
+
+type SyncMutationStatus string
+
+const (
+	SyncMutationApplied  SyncMutationStatus = "applied"
+	SyncMutationConflict SyncMutationStatus = "conflict"
+	SyncMutationRejected SyncMutationStatus = "rejected"
+)
+
+// SyncMutationResult is the outcome of a mutation. Current is the entity as it is on the server
+// when the mutation conflicts with another change
+type SyncMutationResult struct {
+	Status  SyncMutationStatus `json:"status"`
+	ID      uuid.UUID          `json:"id"`
+	Version int64              `json:"version,omitempty"`
+	Current any                `json:"current,omitempty"`
+	Error   string             `json:"error,omitempty"`
+}
@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type Tag struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	Name      string    `json:"name" db:"name"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// TagUsage is a Tag with the number of links it is attached to
+type TagUsage struct {
+	Tag
+	LinksCount int `json:"links_count" db:"links_count"`
+}
@@ -12,4 +12,6 @@ type User struct {
 	Password  string    `json:"password" db:"password"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	// DeletedAt is set once the account is deleted, it is purged after a grace period
+	DeletedAt *time.Time `json:"-" db:"deleted_at"`
 }
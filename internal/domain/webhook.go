@@ -0,0 +1,125 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"time"
+)
+
+// EventWebhookTest is only sent to the webhook it is requested for
+const EventWebhookTest EventType = "webhook.test"
+
+type Webhook struct {
+	ID     uuid.UUID `json:"id" db:"id"`
+	UserID uuid.UUID `json:"user_id" db:"user_id"`
+	URL    string    `json:"url" db:"url"`
+	// Secret signs the payloads, it is only returned when the webhook is created or the secret is rotated
+	Secret    string        `json:"secret,omitempty" db:"secret"`
+	Events    WebhookEvents `json:"events" db:"events"`
+	Active    bool          `json:"active" db:"active"`
+	CreatedAt time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at" db:"updated_at"`
+}
+
+// WebhookEvents are the types of the events the webhook is subscribed to
+type WebhookEvents []EventType
+
+func (e WebhookEvents) Has(eventType EventType) bool {
+	for _, t := range e {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func (e WebhookEvents) Value() (driver.Value, error) {
+	if e == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(e)
+}
+
+func (e *WebhookEvents) Scan(src any) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, e)
+	case string:
+		return json.Unmarshal([]byte(v), e)
+	case nil:
+		*e = WebhookEvents{}
+		return nil
+	default:
+		return fmt.Errorf("unsupported webhook events type %T", src)
+	}
+}
+
+// JSONPayload is a JSON document stored and returned as is
+type JSONPayload json.RawMessage
+
+func (p JSONPayload) MarshalJSON() ([]byte, error) {
+	if p == nil {
+		return []byte("null"), nil
+	}
+	return p, nil
+}
+
+func (p JSONPayload) Value() (driver.Value, error) {
+	return []byte(p), nil
+}
+
+func (p *JSONPayload) Scan(src any) error {
+	switch v := src.(type) {
+	case []byte:
+		*p = append((*p)[:0], v...)
+		return nil
+	case string:
+		*p = JSONPayload(v)
+		return nil
+	case nil:
+		*p = nil
+		return nil
+	default:
+		return fmt.Errorf("unsupported json payload type %T", src)
+	}
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	// WebhookDeliveryPending deliveries are waiting for their first attempt or a retry
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	// WebhookDeliveryDead deliveries have run out of attempts and are only retried manually
+	WebhookDeliveryDead WebhookDeliveryStatus = "dead"
+)
+
+type WebhookDelivery struct {
+	ID             uuid.UUID             `json:"id" db:"id"`
+	WebhookID      uuid.UUID             `json:"webhook_id" db:"webhook_id"`
+	EventID        uuid.UUID             `json:"event_id" db:"event_id"`
+	Event          EventType             `json:"event" db:"event"`
+	Payload        JSONPayload           `json:"payload" db:"payload"`
+	Status         WebhookDeliveryStatus `json:"status" db:"status"`
+	Attempts       int                   `json:"attempts" db:"attempts"`
+	NextAttemptAt  time.Time             `json:"next_attempt_at" db:"next_attempt_at"`
+	LastStatusCode *int                  `json:"last_status_code" db:"last_status_code"`
+	LastError      string                `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt      time.Time             `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at" db:"updated_at"`
+	DeliveredAt    *time.Time            `json:"delivered_at" db:"delivered_at"`
+}
+
+// WebhookAttempt is the log of a single attempt of a delivery
+type WebhookAttempt struct {
+	ID           uuid.UUID `json:"id" db:"id"`
+	DeliveryID   uuid.UUID `json:"delivery_id" db:"delivery_id"`
+	Attempt      int       `json:"attempt" db:"attempt"`
+	StatusCode   *int      `json:"status_code" db:"status_code"`
+	Error        string    `json:"error,omitempty" db:"error"`
+	ResponseBody string    `json:"response_body" db:"response_body"`
+	DurationMs   int64     `json:"duration_ms" db:"duration_ms"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"context"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/pkg/database/postgres"
+	"github.com/adanyl0v/go-pocket-link/pkg/pagination"
+	"github.com/google/uuid"
+	"time"
+)
+
+type ListActivitiesRepository struct {
+	db *postgres.DB
+}
+
+func NewListActivitiesRepository(db *postgres.DB) *ListActivitiesRepository {
+	return &ListActivitiesRepository{db: db}
+}
+
+func (r *ListActivitiesRepository) Save(ctx context.Context, activity *domain.ListActivity) error {
+	err := r.db.Save(ctx, &activity.ID, `INSERT INTO list_activities(list_id, actor_id, action, link_id, link_url, link_title, member_id)
+		VALUES (:list_id, :actor_id, :action, :link_id, :link_url, :link_title, :member_id) RETURNING id`, activity)
+	if err != nil {
+		return err
+	}
+	activity.CreatedAt = time.Now()
+	return nil
+}
+
+func (r *ListActivitiesRepository) SaveForLinks(ctx context.Context, listID, actorID uuid.UUID,
+	action domain.ListActivityAction, linkIDs []uuid.UUID) error {
+	return r.db.Update(ctx, `INSERT INTO list_activities(list_id, actor_id, action, link_id, link_url, link_title)
+		SELECT $1, $2, $3, l.id, l.url, l.title FROM links l WHERE l.id = ANY($4::uuid[])`,
+		listID.String(), actorID.String(), action, uuidsToStrings(linkIDs))
+}
+
+var activitiesColumns = pagination.Columns{
+	"created_at": "a.created_at",
+	"action":     "a.action",
+	"actor_id":   "a.actor_id",
+}
+
+func (r *ListActivitiesRepository) GetPage(ctx context.Context, listID uuid.UUID, params pagination.Params) (pagination.Page[domain.ListActivity], error) {
+	return selectPage(ctx, r.db, `SELECT a.*, COALESCE(u.name, '') AS actor_name FROM list_activities a
+		LEFT JOIN users u ON u.id = a.actor_id
+		WHERE a.list_id = $1`, []any{listID.String()}, params, activitiesColumns, "a.id",
+		func(activity domain.ListActivity, _ string) (any, string) {
+			return activity.CreatedAt, activity.ID.String()
+		})
+}
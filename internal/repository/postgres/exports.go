@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/pkg/database/postgres"
+	"github.com/google/uuid"
+	"time"
+)
+
+type ExportsRepository struct {
+	db *postgres.DB
+}
+
+func NewExportsRepository(db *postgres.DB) *ExportsRepository {
+	return &ExportsRepository{db: db}
+}
+
+func (r *ExportsRepository) Save(ctx context.Context, export *domain.Export) error {
+	err := r.db.Save(ctx, &export.ID, `INSERT INTO exports(user_id, status, expires_at)
+		VALUES (:user_id, :status, :expires_at) RETURNING id`, export)
+	if err != nil {
+		return err
+	}
+	export.CreatedAt = time.Now()
+	return nil
+}
+
+func (r *ExportsRepository) Get(ctx context.Context, userID, id uuid.UUID) (domain.Export, error) {
+	var export domain.Export
+	err := r.db.GetPrepared(ctx, &export, `SELECT * FROM exports WHERE id = $1 AND user_id = $2`, id.String(), userID.String())
+	if errors.Is(err, postgres.ErrNoRowsInResultSet) {
+		return domain.Export{}, domain.ErrExportNotFound
+	} else if err != nil {
+		return domain.Export{}, err
+	}
+	return export, nil
+}
+
+func (r *ExportsRepository) Update(ctx context.Context, export *domain.Export) error {
+	return r.db.UpdateNamed(ctx, `UPDATE exports SET status = :status, size = :size, error = :error,
+		finished_at = :finished_at WHERE id = :id`, export)
+}
@@ -0,0 +1,135 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/pkg/database/postgres"
+	"github.com/google/uuid"
+	"time"
+)
+
+type FeedsRepository struct {
+	db *postgres.DB
+}
+
+func NewFeedsRepository(db *postgres.DB) *FeedsRepository {
+	return &FeedsRepository{db: db}
+}
+
+func (r *FeedsRepository) Save(ctx context.Context, feed *domain.Feed) error {
+	conflictTarget := "(user_id, tag_id)"
+	if feed.ListID != nil {
+		conflictTarget = "(user_id, list_id)"
+	}
+
+	err := r.db.GetNamed(ctx, feed, `INSERT INTO feeds(user_id, list_id, tag_id, token_hash)
+		VALUES (:user_id, :list_id, :tag_id, :token_hash)
+		ON CONFLICT `+conflictTarget+` DO UPDATE SET token_hash = EXCLUDED.token_hash, updated_at = now()
+		RETURNING *`, feed)
+	if err != nil {
+		return feedError(err)
+	}
+	return nil
+}
+
+func (r *FeedsRepository) GetByListID(ctx context.Context, userID, listID uuid.UUID) (domain.Feed, error) {
+	var feed domain.Feed
+	err := r.db.GetPrepared(ctx, &feed, `SELECT * FROM feeds WHERE user_id = $1 AND list_id = $2`,
+		userID.String(), listID.String())
+	if err != nil {
+		return domain.Feed{}, feedError(err)
+	}
+	return feed, nil
+}
+
+func (r *FeedsRepository) GetByTagID(ctx context.Context, userID, tagID uuid.UUID) (domain.Feed, error) {
+	var feed domain.Feed
+	err := r.db.GetPrepared(ctx, &feed, `SELECT * FROM feeds WHERE user_id = $1 AND tag_id = $2`,
+		userID.String(), tagID.String())
+	if err != nil {
+		return domain.Feed{}, feedError(err)
+	}
+	return feed, nil
+}
+
+func (r *FeedsRepository) GetByTokenHash(ctx context.Context, tokenHash string) (domain.Feed, error) {
+	var feed domain.Feed
+	err := r.db.GetPrepared(ctx, &feed, `SELECT f.* FROM feeds f
+		JOIN users u ON u.id = f.user_id AND u.deleted_at IS NULL
+		WHERE f.token_hash = $1`, tokenHash)
+	if err != nil {
+		return domain.Feed{}, feedError(err)
+	}
+	return feed, nil
+}
+
+func (r *FeedsRepository) UpdateAccessedAt(ctx context.Context, id uuid.UUID, accessedAt time.Time) error {
+	return r.db.Update(ctx, `UPDATE feeds SET accessed_at = $1 WHERE id = $2`, accessedAt, id.String())
+}
+
+func (r *FeedsRepository) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	var deletedID uuid.UUID
+	err := r.db.Get(ctx, &deletedID, `DELETE FROM feeds WHERE id = $1 AND user_id = $2 RETURNING id`,
+		id.String(), userID.String())
+	if err != nil {
+		return feedError(err)
+	}
+	return nil
+}
+
+func (r *FeedsRepository) GetListLinks(ctx context.Context, listID uuid.UUID, limit int) ([]domain.FeedLink, error) {
+	links := make([]domain.FeedLink, 0, limit)
+	err := r.db.SelectPrepared(ctx, &links, `SELECT l.*, ll.created_at AS added_at FROM links l
+		JOIN list_links ll ON ll.link_id = l.id
+		WHERE ll.list_id = $1
+		ORDER BY ll.created_at DESC, l.id
+		LIMIT $2`, listID.String(), limit)
+	if err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+func (r *FeedsRepository) GetTagLinks(ctx context.Context, tagID uuid.UUID, limit int) ([]domain.FeedLink, error) {
+	links := make([]domain.FeedLink, 0, limit)
+	err := r.db.SelectPrepared(ctx, &links, `SELECT l.*, lt.created_at AS added_at FROM links l
+		JOIN link_tags lt ON lt.link_id = l.id
+		WHERE lt.tag_id = $1
+		ORDER BY lt.created_at DESC, l.id
+		LIMIT $2`, tagID.String(), limit)
+	if err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// GetListModifiedAt relies on the sync changes, which also record the links removed from the list
+func (r *FeedsRepository) GetListModifiedAt(ctx context.Context, listID uuid.UUID) (time.Time, error) {
+	var modifiedAt time.Time
+	err := r.db.GetPrepared(ctx, &modifiedAt, `SELECT COALESCE(max(changed_at), 'epoch') FROM sync_changes
+		WHERE (entity_type = 'list' AND entity_id = $1)
+			OR (entity_type = 'link' AND entity_id IN (SELECT link_id FROM list_links WHERE list_id = $1))`,
+		listID.String())
+	if err != nil {
+		return time.Time{}, err
+	}
+	return modifiedAt, nil
+}
+
+func (r *FeedsRepository) GetLinksModifiedAt(ctx context.Context, userID uuid.UUID) (time.Time, error) {
+	var modifiedAt time.Time
+	err := r.db.GetPrepared(ctx, &modifiedAt, `SELECT COALESCE(max(changed_at), 'epoch') FROM sync_changes
+		WHERE user_id = $1 AND entity_type = 'link'`, userID.String())
+	if err != nil {
+		return time.Time{}, err
+	}
+	return modifiedAt, nil
+}
+
+func feedError(err error) error {
+	if errors.Is(err, postgres.ErrNoRowsInResultSet) {
+		return domain.ErrFeedNotFound
+	}
+	return err
+}
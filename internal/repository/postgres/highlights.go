@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/pkg/database/postgres"
+	"github.com/adanyl0v/go-pocket-link/pkg/pagination"
+	"github.com/google/uuid"
+	"time"
+)
+
+type HighlightsRepository struct {
+	db *postgres.DB
+}
+
+func NewHighlightsRepository(db *postgres.DB) *HighlightsRepository {
+	return &HighlightsRepository{db: db}
+}
+
+func (r *HighlightsRepository) Save(ctx context.Context, highlight *domain.Highlight) error {
+	err := r.db.Save(ctx, &highlight.ID, `INSERT INTO highlights(user_id, link_id, exact, prefix, suffix, start_pos, end_pos, color)
+		VALUES (:user_id, :link_id, :exact, :prefix, :suffix, :start_pos, :end_pos, :color) RETURNING id`, highlight)
+	if err != nil {
+		return err
+	}
+	highlight.CreatedAt = time.Now()
+	highlight.UpdatedAt = highlight.CreatedAt
+	return nil
+}
+
+func (r *HighlightsRepository) Get(ctx context.Context, userID, id uuid.UUID) (domain.Highlight, error) {
+	var highlight domain.Highlight
+	err := r.db.GetPrepared(ctx, &highlight, `SELECT * FROM highlights WHERE id = $1 AND user_id = $2`,
+		id.String(), userID.String())
+	if err != nil {
+		return domain.Highlight{}, highlightError(err)
+	}
+	return highlight, nil
+}
+
+func (r *HighlightsRepository) GetByLinkID(ctx context.Context, userID, linkID uuid.UUID) ([]domain.Highlight, error) {
+	highlights := make([]domain.Highlight, 0)
+	err := r.db.SelectPrepared(ctx, &highlights, `SELECT * FROM highlights WHERE link_id = $1 AND user_id = $2
+		ORDER BY start_pos`, linkID.String(), userID.String())
+	if err != nil {
+		return nil, err
+	}
+	return highlights, nil
+}
+
+var highlightsColumns = pagination.Columns{
+	"start":      "start_pos",
+	"created_at": "created_at",
+	"color":      "color",
+}
+
+func (r *HighlightsRepository) GetPageByLinkID(ctx context.Context, userID, linkID uuid.UUID, params pagination.Params) (pagination.Page[domain.Highlight], error) {
+	return selectPage(ctx, r.db, `SELECT * FROM highlights WHERE link_id = $1 AND user_id = $2`,
+		[]any{linkID.String(), userID.String()}, params, highlightsColumns, "id", highlightKey)
+}
+
+func (r *HighlightsRepository) Update(ctx context.Context, highlight *domain.Highlight) error {
+	previousUpdatedTime := highlight.UpdatedAt
+	highlight.UpdatedAt = time.Now()
+
+	var id uuid.UUID
+	err := r.db.GetNamed(ctx, &id, `UPDATE highlights SET exact = :exact, prefix = :prefix, suffix = :suffix,
+		start_pos = :start_pos, end_pos = :end_pos, color = :color, updated_at = :updated_at
+		WHERE id = :id AND user_id = :user_id RETURNING id`, highlight)
+	if err != nil {
+		highlight.UpdatedAt = previousUpdatedTime
+		return highlightError(err)
+	}
+	return nil
+}
+
+func (r *HighlightsRepository) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	var deletedID uuid.UUID
+	err := r.db.Get(ctx, &deletedID, `DELETE FROM highlights WHERE id = $1 AND user_id = $2 RETURNING id`,
+		id.String(), userID.String())
+	if err != nil {
+		return highlightError(err)
+	}
+	return nil
+}
+
+func (r *HighlightsRepository) Each(ctx context.Context, userID uuid.UUID, fn func(highlight domain.Highlight) error) error {
+	rows, err := r.db.Query(ctx, `SELECT * FROM highlights WHERE user_id = $1 ORDER BY link_id, start_pos`, userID.String())
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var highlight domain.Highlight
+		if err = rows.Scan(&highlight); err != nil {
+			return err
+		}
+		if err = fn(highlight); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func highlightKey(highlight domain.Highlight, sort string) (any, string) {
+	if sort == "created_at" {
+		return highlight.CreatedAt, highlight.ID.String()
+	}
+	return highlight.Start, highlight.ID.String()
+}
+
+func highlightError(err error) error {
+	if errors.Is(err, postgres.ErrNoRowsInResultSet) {
+		return domain.ErrHighlightNotFound
+	}
+	return err
+}
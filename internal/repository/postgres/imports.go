@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/pkg/database/postgres"
+	"github.com/google/uuid"
+	"time"
+)
+
+type ImportsRepository struct {
+	db *postgres.DB
+}
+
+func NewImportsRepository(db *postgres.DB) *ImportsRepository {
+	return &ImportsRepository{db: db}
+}
+
+func (r *ImportsRepository) Save(ctx context.Context, imp *domain.Import) error {
+	err := r.db.Save(ctx, &imp.ID, `INSERT INTO imports(user_id, format, status)
+		VALUES (:user_id, :format, :status) RETURNING id`, imp)
+	if err != nil {
+		return err
+	}
+	imp.CreatedAt = time.Now()
+	imp.UpdatedAt = imp.CreatedAt
+	return nil
+}
+
+func (r *ImportsRepository) Get(ctx context.Context, userID, id uuid.UUID) (domain.Import, error) {
+	var imp domain.Import
+	err := r.db.GetPrepared(ctx, &imp, `SELECT * FROM imports WHERE id = $1 AND user_id = $2`, id.String(), userID.String())
+	if errors.Is(err, postgres.ErrNoRowsInResultSet) {
+		return domain.Import{}, domain.ErrImportNotFound
+	} else if err != nil {
+		return domain.Import{}, err
+	}
+	return imp, nil
+}
+
+func (r *ImportsRepository) Update(ctx context.Context, imp *domain.Import) error {
+	imp.UpdatedAt = time.Now()
+	return r.db.UpdateNamed(ctx, `UPDATE imports SET status = :status, total = :total, processed = :processed,
+		imported = :imported, duplicates = :duplicates, failed = :failed, errors = :errors, error = :error,
+		updated_at = :updated_at, finished_at = :finished_at WHERE id = :id`, imp)
+}
@@ -0,0 +1,23 @@
+package postgres
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// jsonArray scans a json array, e.g. one built with json_agg, into a slice
+type jsonArray[T any] []T
+
+func (a *jsonArray[T]) Scan(src any) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, a)
+	case string:
+		return json.Unmarshal([]byte(v), a)
+	case nil:
+		*a = jsonArray[T]{}
+		return nil
+	default:
+		return fmt.Errorf("unsupported json array type %T", src)
+	}
+}
@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/pkg/database/postgres"
+	"github.com/google/uuid"
+)
+
+type LinkChecksRepository struct {
+	db *postgres.DB
+}
+
+func NewLinkChecksRepository(db *postgres.DB) *LinkChecksRepository {
+	return &LinkChecksRepository{db: db}
+}
+
+// GetDue selects the links without health as never checked, so the new links are checked by the next run
+func (r *LinkChecksRepository) GetDue(ctx context.Context, limit int) ([]domain.LinkCheckTarget, error) {
+	targets := make([]domain.LinkCheckTarget, 0, limit)
+	err := r.db.Select(ctx, &targets, `SELECT l.id AS link_id, l.user_id, l.url,
+			COALESCE(h.status_code, 0) AS status_code, COALESCE(h.final_url, '') AS final_url,
+			COALESCE(h.response_time_ms, 0) AS response_time_ms, COALESCE(h.error, '') AS error,
+			COALESCE(h.failures, 0) AS failures, COALESCE(h.broken, false) AS broken,
+			h.checked_at, COALESCE(h.next_check_at, l.created_at) AS next_check_at
+		FROM links l
+		LEFT JOIN link_health h ON h.link_id = l.id
+		WHERE (h.link_id IS NULL OR h.next_check_at <= now())
+			AND NOT EXISTS (SELECT 1 FROM users u WHERE u.id = l.user_id AND u.deleted_at IS NOT NULL)
+		ORDER BY h.next_check_at NULLS FIRST, l.created_at
+		LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+	return targets, nil
+}
+
+func (r *LinkChecksRepository) GetHealth(ctx context.Context, linkID uuid.UUID) (domain.LinkHealth, error) {
+	var health domain.LinkHealth
+	err := r.db.GetPrepared(ctx, &health, `SELECT * FROM link_health WHERE link_id = $1`, linkID.String())
+	if err != nil {
+		return domain.LinkHealth{}, linkHealthError(err)
+	}
+	return health, nil
+}
+
+func (r *LinkChecksRepository) GetChecks(ctx context.Context, linkID uuid.UUID, limit int) ([]domain.LinkCheck, error) {
+	checks := make([]domain.LinkCheck, 0, limit)
+	err := r.db.SelectPrepared(ctx, &checks, `SELECT * FROM link_checks WHERE link_id = $1
+		ORDER BY checked_at DESC LIMIT $2`, linkID.String(), limit)
+	if err != nil {
+		return nil, err
+	}
+	return checks, nil
+}
+
+func (r *LinkChecksRepository) Save(ctx context.Context, health *domain.LinkHealth, check *domain.LinkCheck, historySize int) error {
+	return linkHealthError(r.db.WithTx(ctx, func(tx *postgres.Tx) error {
+		err := tx.Exec(ctx, `INSERT INTO link_health(link_id, status_code, final_url, response_time_ms, error,
+				failures, broken, checked_at, next_check_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			ON CONFLICT (link_id) DO UPDATE SET status_code = EXCLUDED.status_code, final_url = EXCLUDED.final_url,
+				response_time_ms = EXCLUDED.response_time_ms, error = EXCLUDED.error, failures = EXCLUDED.failures,
+				broken = EXCLUDED.broken, checked_at = EXCLUDED.checked_at, next_check_at = EXCLUDED.next_check_at`,
+			health.LinkID.String(), health.StatusCode, health.FinalURL, health.ResponseTimeMs, health.Error,
+			health.Failures, health.Broken, health.CheckedAt, health.NextCheckAt)
+		if err != nil || check == nil {
+			return err
+		}
+
+		err = tx.Get(ctx, &check.ID, `INSERT INTO link_checks(link_id, status_code, final_url, response_time_ms, error, ok, checked_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`,
+			check.LinkID.String(), check.StatusCode, check.FinalURL, check.ResponseTimeMs, check.Error, check.OK, check.CheckedAt)
+		if err != nil {
+			return err
+		}
+		return tx.Exec(ctx, `DELETE FROM link_checks WHERE link_id = $1 AND id NOT IN (
+			SELECT id FROM link_checks WHERE link_id = $1 ORDER BY checked_at DESC LIMIT $2)`,
+			check.LinkID.String(), historySize)
+	}))
+}
+
+// linkHealthError reports the links deleted while being checked as not found, their health being of no use anymore
+func linkHealthError(err error) error {
+	if errors.Is(err, postgres.ErrNoRowsInResultSet) {
+		return domain.ErrLinkHealthNotFound
+	} else if postgres.ErrorCode(err) == postgres.ErrCodeForeignKeyViolation {
+		return domain.ErrLinkNotFound
+	}
+	return err
+}
@@ -0,0 +1,324 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/pkg/database/postgres"
+	"github.com/adanyl0v/go-pocket-link/pkg/pagination"
+	"github.com/google/uuid"
+	"time"
+)
+
+type LinksRepository struct {
+	db *postgres.DB
+}
+
+func NewLinksRepository(db *postgres.DB) *LinksRepository {
+	return &LinksRepository{db: db}
+}
+
+// SaveWithTags saves the link, keeping its CreatedAt if it is set, e.g. when the link is imported
+func (r *LinksRepository) SaveWithTags(ctx context.Context, link *domain.Link, tags []string) (created bool, err error) {
+	if link.CreatedAt.IsZero() {
+		link.CreatedAt = time.Now()
+	}
+	link.UpdatedAt = link.CreatedAt
+
+	saved := *link
+	err = r.db.WithTx(ctx, func(tx *postgres.Tx) error {
+		// the url saved already is skipped rather than violating the unique index, which would abort the transaction
+		var ids []uuid.UUID
+		err := tx.Select(ctx, &ids, `INSERT INTO links(user_id, url, normalized_url, title, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT (user_id, normalized_url) DO NOTHING RETURNING id`,
+			saved.UserID.String(), saved.URL, saved.NormalizedURL, saved.Title, saved.CreatedAt, saved.UpdatedAt)
+		if err != nil {
+			return err
+		}
+
+		if created = len(ids) == 1; created {
+			saved.ID, saved.Version = ids[0], 1
+		} else {
+			err = tx.Get(ctx, &saved, `SELECT * FROM links WHERE user_id = $1 AND normalized_url = $2`,
+				saved.UserID.String(), saved.NormalizedURL)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(tags) == 0 {
+			return nil
+		}
+		return addTagsToLinks(ctx, tx, saved.UserID, []uuid.UUID{saved.ID}, tags)
+	})
+	if err != nil {
+		return false, linkError(err)
+	}
+	*link = saved
+	return created, nil
+}
+
+func (r *LinksRepository) Get(ctx context.Context, userID, id uuid.UUID) (domain.Link, error) {
+	var link domain.Link
+	err := r.db.GetPrepared(ctx, &link, `SELECT * FROM links WHERE id = $1 AND user_id = $2`, id.String(), userID.String())
+	if err != nil {
+		return domain.Link{}, linkError(err)
+	}
+	return link, nil
+}
+
+func (r *LinksRepository) GetByIDs(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) ([]domain.Link, error) {
+	var rows []linkWithTags
+	err := r.db.Select(ctx, &rows, `SELECT l.*, COALESCE(json_agg(t.name ORDER BY t.name)
+			FILTER (WHERE t.name IS NOT NULL), '[]') AS tags
+		FROM links l
+		LEFT JOIN link_tags lt ON lt.link_id = l.id
+		LEFT JOIN tags t ON t.id = lt.tag_id
+		WHERE l.user_id = $1 AND l.id = ANY($2::uuid[])
+		GROUP BY l.id`, userID.String(), uuidsToStrings(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	links := make([]domain.Link, 0, len(rows))
+	for _, row := range rows {
+		links = append(links, row.link())
+	}
+	return links, nil
+}
+
+var linksColumns = pagination.Columns{
+	"created_at": "l.created_at",
+	"updated_at": "l.updated_at",
+	"title":      "l.title",
+	"url":        "l.url",
+	"tag": `EXISTS (SELECT 1 FROM link_tags lt JOIN tags t ON t.id = lt.tag_id
+		WHERE lt.link_id = l.id AND t.name = lower(%s))`,
+	"is": `CASE %s WHEN 'broken' THEN EXISTS (SELECT 1 FROM link_health h WHERE h.link_id = l.id AND h.broken) END`,
+}
+
+func (r *LinksRepository) GetPage(ctx context.Context, userID uuid.UUID, params pagination.Params) (pagination.Page[domain.Link], error) {
+	return selectPage(ctx, r.db, `SELECT l.* FROM links l WHERE l.user_id = $1`, []any{userID.String()},
+		params, linksColumns, "l.id", linkKey)
+}
+
+func (r *LinksRepository) Each(ctx context.Context, userID uuid.UUID, fn func(link domain.Link) error) error {
+	rows, err := r.db.Query(ctx, `SELECT l.*, COALESCE(json_agg(t.name ORDER BY t.name)
+			FILTER (WHERE t.name IS NOT NULL), '[]') AS tags
+		FROM links l
+		LEFT JOIN link_tags lt ON lt.link_id = l.id
+		LEFT JOIN tags t ON t.id = lt.tag_id
+		WHERE l.user_id = $1
+		GROUP BY l.id
+		ORDER BY l.created_at`, userID.String())
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var row linkWithTags
+		if err = rows.Scan(&row); err != nil {
+			return err
+		}
+		if err = fn(row.link()); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (r *LinksRepository) EachByList(ctx context.Context, userID uuid.UUID, fn func(list string, link domain.Link) error) error {
+	rows, err := r.db.Query(ctx, `SELECT COALESCE(ls.title, '') AS list_title, l.*, COALESCE(json_agg(t.name ORDER BY t.name)
+			FILTER (WHERE t.name IS NOT NULL), '[]') AS tags
+		FROM links l
+		LEFT JOIN list_links ll ON ll.link_id = l.id
+		LEFT JOIN lists ls ON ls.id = ll.list_id
+		LEFT JOIN link_tags lt ON lt.link_id = l.id
+		LEFT JOIN tags t ON t.id = lt.tag_id
+		WHERE l.user_id = $1
+		GROUP BY ls.title, l.id
+		ORDER BY ls.title NULLS FIRST, l.created_at`, userID.String())
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var row struct {
+			ListTitle string `db:"list_title"`
+			linkWithTags
+		}
+		if err = rows.Scan(&row); err != nil {
+			return err
+		}
+		if err = fn(row.ListTitle, row.link()); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (r *LinksRepository) Update(ctx context.Context, link *domain.Link) error {
+	previousUpdatedTime := link.UpdatedAt
+	link.UpdatedAt = time.Now()
+
+	var version int64
+	err := r.db.GetNamed(ctx, &version, `UPDATE links SET url = :url, normalized_url = :normalized_url, title = :title, updated_at = :updated_at
+		WHERE id = :id AND user_id = :user_id AND version = :version RETURNING version`, link)
+	if err != nil {
+		link.UpdatedAt = previousUpdatedTime
+		return r.versionError(ctx, link.UserID, link.ID, err)
+	}
+	link.Version = version
+	return nil
+}
+
+func (r *LinksRepository) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	var deletedID uuid.UUID
+	err := r.db.Get(ctx, &deletedID, `DELETE FROM links WHERE id = $1 AND user_id = $2 RETURNING id`, id.String(), userID.String())
+	if err != nil {
+		return linkError(err)
+	}
+	return nil
+}
+
+func (r *LinksRepository) DeleteVersion(ctx context.Context, userID, id uuid.UUID, version int64) error {
+	var deletedID uuid.UUID
+	err := r.db.Get(ctx, &deletedID, `DELETE FROM links WHERE id = $1 AND user_id = $2 AND version = $3 RETURNING id`,
+		id.String(), userID.String(), version)
+	if err != nil {
+		return r.versionError(ctx, userID, id, err)
+	}
+	return nil
+}
+
+// mergeLinkStatements move what the user has added to the link $1 to the link $2 it is merged into.
+// The link checks and the snapshots are not moved, they are made again for the kept link
+var mergeLinkStatements = []string{
+	`INSERT INTO link_tags(link_id, tag_id, created_at) SELECT $2::uuid, tag_id, created_at FROM link_tags WHERE link_id = $1
+		ON CONFLICT DO NOTHING`,
+	`INSERT INTO list_links(list_id, link_id, created_at) SELECT list_id, $2::uuid, created_at FROM list_links WHERE link_id = $1
+		ON CONFLICT DO NOTHING`,
+	`UPDATE highlights SET link_id = $2 WHERE link_id = $1`,
+	`UPDATE notes SET link_id = $2 WHERE link_id = $1`,
+	`UPDATE reading_states SET link_id = $2 WHERE link_id = $1 AND NOT EXISTS (SELECT 1 FROM reading_states WHERE link_id = $2)`,
+	`UPDATE list_activities SET link_id = $2 WHERE link_id = $1`,
+	`DELETE FROM links WHERE id = $1`,
+}
+
+type normalizedLink struct {
+	ID            uuid.UUID `db:"id"`
+	URL           string    `db:"url"`
+	NormalizedURL string    `db:"normalized_url"`
+}
+
+// NormalizeURLs normalizes the links of each user in a transaction of its own, so it can be run again if it fails
+func (r *LinksRepository) NormalizeURLs(ctx context.Context, normalize func(rawURL string) string) (updated, merged int64, err error) {
+	var userIDs []uuid.UUID
+	if err = r.db.Select(ctx, &userIDs, `SELECT DISTINCT user_id FROM links`); err != nil {
+		return 0, 0, err
+	}
+
+	for _, userID := range userIDs {
+		var userUpdated, userMerged int64
+		err = r.db.WithTx(ctx, func(tx *postgres.Tx) error {
+			var err error
+			userUpdated, userMerged, err = normalizeUserURLs(ctx, tx, userID, normalize)
+			return err
+		})
+		if err != nil {
+			return updated, merged, err
+		}
+		updated += userUpdated
+		merged += userMerged
+	}
+	return updated, merged, nil
+}
+
+// normalizeUserURLs merges the duplicates before setting the normalized urls. The unique index is checked
+// row by row, so a link may not take the url another link still holds before that one is updated.
+// The changed links are given their ids as placeholders first, which no url can collide with
+func normalizeUserURLs(ctx context.Context, tx *postgres.Tx, userID uuid.UUID, normalize func(rawURL string) string) (updated, merged int64, err error) {
+	var links []normalizedLink
+	err = tx.Select(ctx, &links, `SELECT id, url, normalized_url FROM links
+		WHERE user_id = $1 ORDER BY created_at, id FOR UPDATE`, userID.String())
+	if err != nil {
+		return 0, 0, err
+	}
+
+	kept := make(map[string]uuid.UUID, len(links))
+	changed := make([]normalizedLink, 0)
+	for _, link := range links {
+		normalized := normalize(link.URL)
+		if keptID, ok := kept[normalized]; ok {
+			for _, statement := range mergeLinkStatements {
+				if err = tx.Exec(ctx, statement, link.ID.String(), keptID.String()); err != nil {
+					return 0, 0, err
+				}
+			}
+			merged++
+			continue
+		}
+
+		kept[normalized] = link.ID
+		if link.NormalizedURL != normalized {
+			link.NormalizedURL = normalized
+			changed = append(changed, link)
+		}
+	}
+
+	for _, link := range changed {
+		if err = tx.Exec(ctx, `UPDATE links SET normalized_url = id::text WHERE id = $1`, link.ID.String()); err != nil {
+			return 0, 0, err
+		}
+	}
+	for _, link := range changed {
+		err = tx.Exec(ctx, `UPDATE links SET normalized_url = $1 WHERE id = $2`, link.NormalizedURL, link.ID.String())
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	return int64(len(changed)), merged, nil
+}
+
+// versionError tells whether the link a versioned statement did not affect is missing or has another version
+func (r *LinksRepository) versionError(ctx context.Context, userID, id uuid.UUID, err error) error {
+	if !errors.Is(err, postgres.ErrNoRowsInResultSet) {
+		return linkError(err)
+	} else if _, err = r.Get(ctx, userID, id); err != nil {
+		return err
+	}
+	return domain.ErrLinkConflict
+}
+
+func linkError(err error) error {
+	if errors.Is(err, postgres.ErrNoRowsInResultSet) {
+		return domain.ErrLinkNotFound
+	} else if postgres.ErrorCode(err) == postgres.ErrCodeUniqueViolation {
+		return domain.ErrLinkAlreadyExists
+	}
+	return err
+}
+
+func linkKey(link domain.Link, sort string) (any, string) {
+	switch sort {
+	case "updated_at":
+		return link.UpdatedAt, link.ID.String()
+	case "title":
+		return link.Title, link.ID.String()
+	default:
+		return link.CreatedAt, link.ID.String()
+	}
+}
+
+type linkWithTags struct {
+	domain.Link
+	TagNames jsonArray[string] `db:"tags"`
+}
+
+func (l linkWithTags) link() domain.Link {
+	link := l.Link
+	link.Tags = l.TagNames
+	return link
+}
@@ -0,0 +1,212 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/pkg/database/postgres"
+	"github.com/adanyl0v/go-pocket-link/pkg/pagination"
+	"github.com/google/uuid"
+	"time"
+)
+
+type ListsRepository struct {
+	db *postgres.DB
+}
+
+func NewListsRepository(db *postgres.DB) *ListsRepository {
+	return &ListsRepository{db: db}
+}
+
+func (r *ListsRepository) Save(ctx context.Context, list *domain.List) error {
+	err := r.db.Save(ctx, &list.ID, `INSERT INTO lists(user_id, title) VALUES (:user_id, :title) RETURNING id`, list)
+	if err != nil {
+		return listError(err)
+	}
+	list.CreatedAt = time.Now()
+	list.UpdatedAt = list.CreatedAt
+	list.Version = 1
+	return nil
+}
+
+func (r *ListsRepository) Get(ctx context.Context, userID, id uuid.UUID) (domain.List, error) {
+	var list domain.List
+	err := r.db.GetPrepared(ctx, &list, `SELECT * FROM lists WHERE id = $1 AND user_id = $2`, id.String(), userID.String())
+	if err != nil {
+		return domain.List{}, listError(err)
+	}
+	return list, nil
+}
+
+func (r *ListsRepository) GetByID(ctx context.Context, id uuid.UUID) (domain.List, error) {
+	var list domain.List
+	err := r.db.GetPrepared(ctx, &list, `SELECT * FROM lists WHERE id = $1`, id.String())
+	if err != nil {
+		return domain.List{}, listError(err)
+	}
+	return list, nil
+}
+
+func (r *ListsRepository) GetByTitle(ctx context.Context, userID uuid.UUID, title string) (domain.List, error) {
+	var list domain.List
+	err := r.db.GetPrepared(ctx, &list, `SELECT * FROM lists WHERE user_id = $1 AND title = $2`, userID.String(), title)
+	if err != nil {
+		return domain.List{}, listError(err)
+	}
+	return list, nil
+}
+
+var listsColumns = pagination.Columns{
+	"title":      "title",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+func (r *ListsRepository) GetPage(ctx context.Context, userID uuid.UUID, params pagination.Params) (pagination.Page[domain.List], error) {
+	return selectPage(ctx, r.db, `SELECT * FROM lists
+		WHERE (user_id = $1 OR id IN (SELECT list_id FROM list_members WHERE user_id = $1))`, []any{userID.String()},
+		params, listsColumns, "id", listKey)
+}
+
+func (r *ListsRepository) Update(ctx context.Context, list *domain.List) error {
+	previousUpdatedTime := list.UpdatedAt
+	list.UpdatedAt = time.Now()
+
+	var version int64
+	err := r.db.GetNamed(ctx, &version, `UPDATE lists SET title = :title, updated_at = :updated_at
+		WHERE id = :id AND user_id = :user_id AND version = :version RETURNING version`, list)
+	if err != nil {
+		list.UpdatedAt = previousUpdatedTime
+		return r.versionError(ctx, list.UserID, list.ID, err)
+	}
+	list.Version = version
+	return nil
+}
+
+func (r *ListsRepository) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	var deletedID uuid.UUID
+	err := r.db.Get(ctx, &deletedID, `DELETE FROM lists WHERE id = $1 AND user_id = $2 RETURNING id`, id.String(), userID.String())
+	if err != nil {
+		return listError(err)
+	}
+	return nil
+}
+
+func (r *ListsRepository) DeleteVersion(ctx context.Context, userID, id uuid.UUID, version int64) error {
+	var deletedID uuid.UUID
+	err := r.db.Get(ctx, &deletedID, `DELETE FROM lists WHERE id = $1 AND user_id = $2 AND version = $3 RETURNING id`,
+		id.String(), userID.String(), version)
+	if err != nil {
+		return r.versionError(ctx, userID, id, err)
+	}
+	return nil
+}
+
+// versionError tells whether the list a versioned statement did not affect is missing or has another version
+func (r *ListsRepository) versionError(ctx context.Context, userID, id uuid.UUID, err error) error {
+	if !errors.Is(err, postgres.ErrNoRowsInResultSet) {
+		return listError(err)
+	} else if _, err = r.Get(ctx, userID, id); err != nil {
+		return err
+	}
+	return domain.ErrListConflict
+}
+
+func (r *ListsRepository) GetLinksPage(ctx context.Context, userID, id uuid.UUID, params pagination.Params) (pagination.Page[domain.Link], error) {
+	return selectPage(ctx, r.db, `SELECT l.* FROM links l
+		JOIN list_links ll ON ll.link_id = l.id
+		JOIN lists ls ON ls.id = ll.list_id
+		WHERE ls.id = $1 AND ls.user_id = $2`, []any{id.String(), userID.String()},
+		params, linksColumns, "l.id", linkKey)
+}
+
+func (r *ListsRepository) AddLinks(ctx context.Context, id, userID uuid.UUID, linkIDs []uuid.UUID) ([]uuid.UUID, error) {
+	added := make([]uuid.UUID, 0, len(linkIDs))
+	err := r.db.Select(ctx, &added, `INSERT INTO list_links(list_id, link_id)
+		SELECT $1, l.id FROM links l WHERE l.user_id = $2 AND l.id = ANY($3::uuid[])
+		ON CONFLICT DO NOTHING RETURNING link_id`, id.String(), userID.String(), uuidsToStrings(linkIDs))
+	if err != nil {
+		return nil, err
+	}
+	return added, nil
+}
+
+func (r *ListsRepository) RemoveLinks(ctx context.Context, id uuid.UUID, linkIDs []uuid.UUID) ([]uuid.UUID, error) {
+	removed := make([]uuid.UUID, 0, len(linkIDs))
+	err := r.db.Select(ctx, &removed, `DELETE FROM list_links WHERE list_id = $1 AND link_id = ANY($2::uuid[])
+		RETURNING link_id`, id.String(), uuidsToStrings(linkIDs))
+	if err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
+
+func (r *ListsRepository) EachWithLinkIDs(ctx context.Context, userID uuid.UUID, fn func(list domain.List, linkIDs []uuid.UUID) error) error {
+	rows, err := r.db.Query(ctx, `SELECT ls.*, COALESCE(json_agg(ll.link_id ORDER BY ll.created_at)
+			FILTER (WHERE ll.link_id IS NOT NULL), '[]') AS link_ids
+		FROM lists ls
+		LEFT JOIN list_links ll ON ll.list_id = ls.id
+		WHERE ls.user_id = $1
+		GROUP BY ls.id
+		ORDER BY ls.title`, userID.String())
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var row struct {
+			domain.List
+			LinkIDs jsonArray[uuid.UUID] `db:"link_ids"`
+		}
+		if err = rows.Scan(&row); err != nil {
+			return err
+		}
+		if err = fn(row.List, row.LinkIDs); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (r *ListsRepository) GetByIDsWithLinkIDs(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) ([]domain.ListWithLinks, error) {
+	var rows []struct {
+		domain.List
+		LinkIDs jsonArray[uuid.UUID] `db:"link_ids"`
+	}
+	err := r.db.Select(ctx, &rows, `SELECT ls.*, COALESCE(json_agg(ll.link_id ORDER BY ll.created_at)
+			FILTER (WHERE ll.link_id IS NOT NULL), '[]') AS link_ids
+		FROM lists ls
+		LEFT JOIN list_links ll ON ll.list_id = ls.id
+		WHERE ls.user_id = $1 AND ls.id = ANY($2::uuid[])
+		GROUP BY ls.id`, userID.String(), uuidsToStrings(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	lists := make([]domain.ListWithLinks, 0, len(rows))
+	for _, row := range rows {
+		lists = append(lists, domain.ListWithLinks{List: row.List, LinkIDs: row.LinkIDs})
+	}
+	return lists, nil
+}
+
+func listKey(list domain.List, sort string) (any, string) {
+	switch sort {
+	case "created_at":
+		return list.CreatedAt, list.ID.String()
+	case "updated_at":
+		return list.UpdatedAt, list.ID.String()
+	default:
+		return list.Title, list.ID.String()
+	}
+}
+
+func listError(err error) error {
+	if errors.Is(err, postgres.ErrNoRowsInResultSet) {
+		return domain.ErrListNotFound
+	} else if postgres.ErrorCode(err) == postgres.ErrCodeUniqueViolation {
+		return domain.ErrListAlreadyExists
+	}
+	return err
+}
@@ -0,0 +1,166 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/pkg/database/postgres"
+	"github.com/adanyl0v/go-pocket-link/pkg/pagination"
+	"github.com/google/uuid"
+	"time"
+)
+
+type ListMembersRepository struct {
+	db *postgres.DB
+}
+
+func NewListMembersRepository(db *postgres.DB) *ListMembersRepository {
+	return &ListMembersRepository{db: db}
+}
+
+func (r *ListMembersRepository) GetRole(ctx context.Context, userID, listID uuid.UUID) (domain.ListRole, error) {
+	var role domain.ListRole
+	err := r.db.GetPrepared(ctx, &role, `SELECT CASE WHEN ls.user_id = $1 THEN 'owner' ELSE lm.role END
+		FROM lists ls
+		LEFT JOIN list_members lm ON lm.list_id = ls.id AND lm.user_id = $1
+		WHERE ls.id = $2 AND (ls.user_id = $1 OR lm.user_id IS NOT NULL)`, userID.String(), listID.String())
+	if errors.Is(err, postgres.ErrNoRowsInResultSet) {
+		return "", domain.ErrListNotFound
+	} else if err != nil {
+		return "", err
+	}
+	return role, nil
+}
+
+func (r *ListMembersRepository) GetAll(ctx context.Context, listID uuid.UUID) ([]domain.ListMember, error) {
+	members := make([]domain.ListMember, 0)
+	err := r.db.SelectPrepared(ctx, &members, `SELECT ls.id AS list_id, u.id AS user_id, u.name, u.email,
+			'owner' AS role, ls.created_at
+		FROM lists ls JOIN users u ON u.id = ls.user_id
+		WHERE ls.id = $1
+		UNION ALL
+		SELECT lm.list_id, u.id, u.name, u.email, lm.role, lm.created_at
+		FROM list_members lm JOIN users u ON u.id = lm.user_id
+		WHERE lm.list_id = $1
+		ORDER BY created_at`, listID.String())
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+var membersColumns = pagination.Columns{
+	"created_at": "m.created_at",
+	"name":       "m.name",
+	"role":       "m.role",
+}
+
+func (r *ListMembersRepository) GetPage(ctx context.Context, listID uuid.UUID, params pagination.Params) (pagination.Page[domain.ListMember], error) {
+	return selectPage(ctx, r.db, `SELECT * FROM (
+			SELECT ls.id AS list_id, u.id AS user_id, u.name, u.email, 'owner' AS role, ls.created_at
+			FROM lists ls JOIN users u ON u.id = ls.user_id
+			UNION ALL
+			SELECT lm.list_id, u.id, u.name, u.email, lm.role, lm.created_at
+			FROM list_members lm JOIN users u ON u.id = lm.user_id
+		) m WHERE m.list_id = $1`, []any{listID.String()},
+		params, membersColumns, "m.user_id", func(member domain.ListMember, sort string) (any, string) {
+			if sort == "name" {
+				return member.Name, member.UserID.String()
+			}
+			return member.CreatedAt, member.UserID.String()
+		})
+}
+
+func (r *ListMembersRepository) UpdateRole(ctx context.Context, member *domain.ListMember) error {
+	var userID uuid.UUID
+	err := r.db.GetNamed(ctx, &userID, `UPDATE list_members SET role = :role
+		WHERE list_id = :list_id AND user_id = :user_id RETURNING user_id`, member)
+	if errors.Is(err, postgres.ErrNoRowsInResultSet) {
+		return domain.ErrListMemberNotFound
+	}
+	return err
+}
+
+func (r *ListMembersRepository) Delete(ctx context.Context, listID, userID uuid.UUID) error {
+	var deletedID uuid.UUID
+	err := r.db.Get(ctx, &deletedID, `DELETE FROM list_members WHERE list_id = $1 AND user_id = $2 RETURNING user_id`,
+		listID.String(), userID.String())
+	if errors.Is(err, postgres.ErrNoRowsInResultSet) {
+		return domain.ErrListMemberNotFound
+	}
+	return err
+}
+
+type ListInvitationsRepository struct {
+	db *postgres.DB
+}
+
+func NewListInvitationsRepository(db *postgres.DB) *ListInvitationsRepository {
+	return &ListInvitationsRepository{db: db}
+}
+
+func (r *ListInvitationsRepository) Save(ctx context.Context, invitation *domain.ListInvitation) error {
+	err := r.db.Save(ctx, &invitation.ID, `INSERT INTO list_invitations(list_id, inviter_id, email, role, token_hash, expires_at)
+		VALUES (:list_id, :inviter_id, :email, :role, :token_hash, :expires_at) RETURNING id`, invitation)
+	if err != nil {
+		return err
+	}
+	invitation.CreatedAt = time.Now()
+	return nil
+}
+
+func (r *ListInvitationsRepository) GetByTokenHash(ctx context.Context, tokenHash string) (domain.ListInvitation, error) {
+	var invitation domain.ListInvitation
+	err := r.db.GetPrepared(ctx, &invitation, `SELECT * FROM list_invitations WHERE token_hash = $1`, tokenHash)
+	if errors.Is(err, postgres.ErrNoRowsInResultSet) {
+		return domain.ListInvitation{}, domain.ErrInvitationNotFound
+	} else if err != nil {
+		return domain.ListInvitation{}, err
+	}
+	return invitation, nil
+}
+
+var invitationsColumns = pagination.Columns{
+	"created_at": "created_at",
+	"expires_at": "expires_at",
+	"email":      "email",
+	"role":       "role",
+}
+
+func (r *ListInvitationsRepository) GetPendingPage(ctx context.Context, listID uuid.UUID, params pagination.Params) (pagination.Page[domain.ListInvitation], error) {
+	return selectPage(ctx, r.db, `SELECT * FROM list_invitations
+		WHERE list_id = $1 AND accepted_at IS NULL AND expires_at > now()`, []any{listID.String()},
+		params, invitationsColumns, "id", func(invitation domain.ListInvitation, sort string) (any, string) {
+			if sort == "expires_at" {
+				return invitation.ExpiresAt, invitation.ID.String()
+			}
+			return invitation.CreatedAt, invitation.ID.String()
+		})
+}
+
+// Accept marks the invitation accepted and adds the user to the list with its role,
+// keeping the role of the user if they already are a member
+func (r *ListInvitationsRepository) Accept(ctx context.Context, invitation *domain.ListInvitation, userID uuid.UUID) error {
+	return r.db.WithTx(ctx, func(tx *postgres.Tx) error {
+		err := tx.Get(ctx, &invitation.AcceptedAt, `UPDATE list_invitations SET accepted_at = now()
+			WHERE id = $1 AND accepted_at IS NULL RETURNING accepted_at`, invitation.ID.String())
+		if errors.Is(err, postgres.ErrNoRowsInResultSet) {
+			return domain.ErrInvitationNotFound
+		} else if err != nil {
+			return err
+		}
+
+		return tx.Exec(ctx, `INSERT INTO list_members(list_id, user_id, role) VALUES ($1, $2, $3)
+			ON CONFLICT DO NOTHING`, invitation.ListID.String(), userID.String(), invitation.Role)
+	})
+}
+
+func (r *ListInvitationsRepository) Delete(ctx context.Context, listID, id uuid.UUID) error {
+	var deletedID uuid.UUID
+	err := r.db.Get(ctx, &deletedID, `DELETE FROM list_invitations WHERE id = $1 AND list_id = $2 RETURNING id`,
+		id.String(), listID.String())
+	if errors.Is(err, postgres.ErrNoRowsInResultSet) {
+		return domain.ErrInvitationNotFound
+	}
+	return err
+}
@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/pkg/database/postgres"
+	"github.com/adanyl0v/go-pocket-link/pkg/pagination"
+	"github.com/google/uuid"
+	"time"
+)
+
+type NotesRepository struct {
+	db *postgres.DB
+}
+
+func NewNotesRepository(db *postgres.DB) *NotesRepository {
+	return &NotesRepository{db: db}
+}
+
+func (r *NotesRepository) Save(ctx context.Context, note *domain.Note) error {
+	err := r.db.Save(ctx, &note.ID, `INSERT INTO notes(user_id, link_id, highlight_id, body)
+		VALUES (:user_id, :link_id, :highlight_id, :body) RETURNING id`, note)
+	if err != nil {
+		return err
+	}
+	note.CreatedAt = time.Now()
+	note.UpdatedAt = note.CreatedAt
+	return nil
+}
+
+func (r *NotesRepository) Get(ctx context.Context, userID, id uuid.UUID) (domain.Note, error) {
+	var note domain.Note
+	err := r.db.GetPrepared(ctx, &note, `SELECT * FROM notes WHERE id = $1 AND user_id = $2`,
+		id.String(), userID.String())
+	if err != nil {
+		return domain.Note{}, noteError(err)
+	}
+	return note, nil
+}
+
+func (r *NotesRepository) GetByLinkID(ctx context.Context, userID, linkID uuid.UUID) ([]domain.Note, error) {
+	notes := make([]domain.Note, 0)
+	err := r.db.SelectPrepared(ctx, &notes, `SELECT * FROM notes WHERE link_id = $1 AND user_id = $2
+		ORDER BY created_at`, linkID.String(), userID.String())
+	if err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+var notesColumns = pagination.Columns{
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"body":       "body",
+}
+
+func (r *NotesRepository) GetPageByLinkID(ctx context.Context, userID, linkID uuid.UUID, params pagination.Params) (pagination.Page[domain.Note], error) {
+	return selectPage(ctx, r.db, `SELECT * FROM notes WHERE link_id = $1 AND user_id = $2`,
+		[]any{linkID.String(), userID.String()}, params, notesColumns, "id", noteKey)
+}
+
+func (r *NotesRepository) Update(ctx context.Context, note *domain.Note) error {
+	previousUpdatedTime := note.UpdatedAt
+	note.UpdatedAt = time.Now()
+
+	var id uuid.UUID
+	err := r.db.GetNamed(ctx, &id, `UPDATE notes SET body = :body, updated_at = :updated_at
+		WHERE id = :id AND user_id = :user_id RETURNING id`, note)
+	if err != nil {
+		note.UpdatedAt = previousUpdatedTime
+		return noteError(err)
+	}
+	return nil
+}
+
+func (r *NotesRepository) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	var deletedID uuid.UUID
+	err := r.db.Get(ctx, &deletedID, `DELETE FROM notes WHERE id = $1 AND user_id = $2 RETURNING id`,
+		id.String(), userID.String())
+	if err != nil {
+		return noteError(err)
+	}
+	return nil
+}
+
+func (r *NotesRepository) Each(ctx context.Context, userID uuid.UUID, fn func(note domain.Note) error) error {
+	rows, err := r.db.Query(ctx, `SELECT * FROM notes WHERE user_id = $1 ORDER BY link_id, created_at`, userID.String())
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var note domain.Note
+		if err = rows.Scan(&note); err != nil {
+			return err
+		}
+		if err = fn(note); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func noteKey(note domain.Note, sort string) (any, string) {
+	if sort == "updated_at" {
+		return note.UpdatedAt, note.ID.String()
+	}
+	return note.CreatedAt, note.ID.String()
+}
+
+func noteError(err error) error {
+	if errors.Is(err, postgres.ErrNoRowsInResultSet) {
+		return domain.ErrNoteNotFound
+	}
+	return err
+}
@@ -0,0 +1,22 @@
+package postgres
+
+import (
+	"context"
+	"github.com/adanyl0v/go-pocket-link/pkg/database/postgres"
+	"github.com/adanyl0v/go-pocket-link/pkg/pagination"
+)
+
+// selectPage selects a page of the rows of the query, see pagination.Params.Apply
+func selectPage[T any](ctx context.Context, db *postgres.DB, query string, args []any, params pagination.Params,
+	columns pagination.Columns, idColumn string, keyOf pagination.KeyFunc[T]) (pagination.Page[T], error) {
+	query, args, err := params.Apply(query, args, columns, idColumn)
+	if err != nil {
+		return pagination.Page[T]{}, err
+	}
+
+	items := make([]T, 0, params.Limit+1)
+	if err = db.Select(ctx, &items, query, args...); err != nil {
+		return pagination.Page[T]{}, err
+	}
+	return pagination.NewPage(items, params, keyOf), nil
+}
@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/pkg/database/postgres"
+	"github.com/adanyl0v/go-pocket-link/pkg/pagination"
+	"github.com/google/uuid"
+	"time"
+)
+
+type ReadingStatesRepository struct {
+	db *postgres.DB
+}
+
+func NewReadingStatesRepository(db *postgres.DB) *ReadingStatesRepository {
+	return &ReadingStatesRepository{db: db}
+}
+
+func (r *ReadingStatesRepository) Get(ctx context.Context, userID, linkID uuid.UUID) (domain.ReadingState, error) {
+	var state domain.ReadingState
+	err := r.db.GetPrepared(ctx, &state, `SELECT l.id AS link_id, l.user_id,
+			COALESCE(rs.percentage, 0) AS percentage, COALESCE(rs.char_offset, 0) AS char_offset,
+			rs.progress_updated_at, rs.last_opened_at, rs.read_at, rs.read_updated_at
+		FROM links l LEFT JOIN reading_states rs ON rs.link_id = l.id
+		WHERE l.id = $1 AND l.user_id = $2`, linkID.String(), userID.String())
+	if err != nil {
+		return domain.ReadingState{}, linkError(err)
+	}
+	return state, nil
+}
+
+func (r *ReadingStatesRepository) UpdateProgress(ctx context.Context, state *domain.ReadingState) (bool, error) {
+	err := r.db.Get(ctx, state, `INSERT INTO reading_states(link_id, user_id, percentage, char_offset, progress_updated_at, last_opened_at)
+		SELECT id, user_id, $3, $4, $5, $5 FROM links WHERE id = $1 AND user_id = $2
+		ON CONFLICT (link_id) DO UPDATE SET percentage = EXCLUDED.percentage, char_offset = EXCLUDED.char_offset,
+			progress_updated_at = EXCLUDED.progress_updated_at,
+			last_opened_at = GREATEST(reading_states.last_opened_at, EXCLUDED.last_opened_at)
+		WHERE reading_states.progress_updated_at IS NULL OR reading_states.progress_updated_at < EXCLUDED.progress_updated_at
+		RETURNING *`, state.LinkID.String(), state.UserID.String(), state.Percentage, state.CharOffset, state.ProgressUpdatedAt)
+	if errors.Is(err, postgres.ErrNoRowsInResultSet) {
+		// either there is no such link or a newer progress has already been applied
+		current, err := r.Get(ctx, state.UserID, state.LinkID)
+		if err != nil {
+			return false, err
+		}
+		*state = current
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *ReadingStatesRepository) MarkOpened(ctx context.Context, userID, linkID uuid.UUID, at time.Time) (domain.ReadingState, error) {
+	var state domain.ReadingState
+	err := r.db.Get(ctx, &state, `INSERT INTO reading_states(link_id, user_id, last_opened_at)
+		SELECT id, user_id, $3 FROM links WHERE id = $1 AND user_id = $2
+		ON CONFLICT (link_id) DO UPDATE
+			SET last_opened_at = GREATEST(reading_states.last_opened_at, EXCLUDED.last_opened_at)
+		RETURNING *`, linkID.String(), userID.String(), at)
+	if err != nil {
+		return domain.ReadingState{}, linkError(err)
+	}
+	return state, nil
+}
+
+func (r *ReadingStatesRepository) SetRead(ctx context.Context, userID, linkID uuid.UUID, readAt *time.Time, changedAt time.Time) (domain.ReadingState, bool, error) {
+	var state domain.ReadingState
+	err := r.db.Get(ctx, &state, `INSERT INTO reading_states(link_id, user_id, read_at, read_updated_at)
+		SELECT id, user_id, $3, $4 FROM links WHERE id = $1 AND user_id = $2
+		ON CONFLICT (link_id) DO UPDATE SET read_at = EXCLUDED.read_at, read_updated_at = EXCLUDED.read_updated_at
+		WHERE reading_states.read_updated_at IS NULL OR reading_states.read_updated_at < EXCLUDED.read_updated_at
+		RETURNING *`, linkID.String(), userID.String(), readAt, changedAt)
+	if errors.Is(err, postgres.ErrNoRowsInResultSet) {
+		state, err = r.Get(ctx, userID, linkID)
+		return state, false, err
+	} else if err != nil {
+		return domain.ReadingState{}, false, err
+	}
+	return state, true, nil
+}
+
+func (r *ReadingStatesRepository) GetContinueReading(ctx context.Context, userID uuid.UUID, params pagination.Params) (pagination.Page[domain.LinkWithReadingState], error) {
+	page, err := selectPage(ctx, r.db, `SELECT l.*, rs.percentage, rs.char_offset, rs.progress_updated_at, rs.last_opened_at
+		FROM reading_states rs JOIN links l ON l.id = rs.link_id
+		WHERE rs.user_id = $1 AND rs.read_at IS NULL AND rs.last_opened_at IS NOT NULL
+			AND (rs.percentage > 0 OR rs.char_offset > 0)`, []any{userID.String()},
+		params, pagination.Columns{"last_opened_at": "rs.last_opened_at"}, "l.id",
+		func(row continueReadingRow, _ string) (any, string) {
+			return row.LastOpenedAt, row.ID.String()
+		})
+	if err != nil {
+		return pagination.Page[domain.LinkWithReadingState]{}, err
+	}
+
+	return pagination.MapPage(page, func(row continueReadingRow) domain.LinkWithReadingState {
+		return domain.LinkWithReadingState{
+			Link: row.Link,
+			ReadingState: domain.ReadingState{
+				LinkID:            row.ID,
+				UserID:            row.UserID,
+				Percentage:        row.Percentage,
+				CharOffset:        row.CharOffset,
+				ProgressUpdatedAt: row.ProgressUpdatedAt,
+				LastOpenedAt:      row.LastOpenedAt,
+			},
+		}
+	}), nil
+}
+
+type continueReadingRow struct {
+	domain.Link
+	Percentage        float64    `db:"percentage"`
+	CharOffset        int        `db:"char_offset"`
+	ProgressUpdatedAt *time.Time `db:"progress_updated_at"`
+	LastOpenedAt      *time.Time `db:"last_opened_at"`
+}
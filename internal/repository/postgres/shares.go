@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/pkg/database/postgres"
+	"github.com/google/uuid"
+	"time"
+)
+
+type ListSharesRepository struct {
+	db *postgres.DB
+}
+
+func NewListSharesRepository(db *postgres.DB) *ListSharesRepository {
+	return &ListSharesRepository{db: db}
+}
+
+func (r *ListSharesRepository) Save(ctx context.Context, share *domain.ListShare) error {
+	err := r.db.GetNamed(ctx, share, `INSERT INTO list_shares(list_id, user_id, slug, password_hash, expires_at)
+		VALUES (:list_id, :user_id, :slug, :password_hash, :expires_at)
+		ON CONFLICT (list_id) DO UPDATE SET password_hash = EXCLUDED.password_hash,
+			expires_at = EXCLUDED.expires_at, updated_at = now()
+		RETURNING *`, share)
+	if err != nil {
+		return shareError(err)
+	}
+	return nil
+}
+
+func (r *ListSharesRepository) Get(ctx context.Context, userID, listID uuid.UUID) (domain.ListShare, error) {
+	var share domain.ListShare
+	err := r.db.GetPrepared(ctx, &share, `SELECT * FROM list_shares WHERE list_id = $1 AND user_id = $2`,
+		listID.String(), userID.String())
+	if err != nil {
+		return domain.ListShare{}, shareError(err)
+	}
+	return share, nil
+}
+
+func (r *ListSharesRepository) GetBySlug(ctx context.Context, slug string) (domain.ListShare, error) {
+	var share domain.ListShare
+	err := r.db.GetPrepared(ctx, &share, `SELECT s.* FROM list_shares s
+		JOIN users u ON u.id = s.user_id AND u.deleted_at IS NULL
+		WHERE s.slug = $1`, slug)
+	if err != nil {
+		return domain.ListShare{}, shareError(err)
+	}
+	return share, nil
+}
+
+func (r *ListSharesRepository) UpdateSlug(ctx context.Context, share *domain.ListShare) error {
+	err := r.db.GetNamed(ctx, share, `UPDATE list_shares SET slug = :slug, views = 0, updated_at = now()
+		WHERE list_id = :list_id AND user_id = :user_id RETURNING *`, share)
+	if err != nil {
+		return shareError(err)
+	}
+	return nil
+}
+
+func (r *ListSharesRepository) IncrementViews(ctx context.Context, listID uuid.UUID) error {
+	return r.db.Update(ctx, `UPDATE list_shares SET views = views + 1 WHERE list_id = $1`, listID.String())
+}
+
+func (r *ListSharesRepository) Delete(ctx context.Context, userID, listID uuid.UUID) error {
+	var deletedID uuid.UUID
+	err := r.db.Get(ctx, &deletedID, `DELETE FROM list_shares WHERE list_id = $1 AND user_id = $2 RETURNING list_id`,
+		listID.String(), userID.String())
+	if err != nil {
+		return shareError(err)
+	}
+	return nil
+}
+
+func (r *ListSharesRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	listIDs := make([]uuid.UUID, 0)
+	err := r.db.Select(ctx, &listIDs, `DELETE FROM list_shares WHERE expires_at < $1 RETURNING list_id`, before)
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(listIDs)), nil
+}
+
+func shareError(err error) error {
+	if errors.Is(err, postgres.ErrNoRowsInResultSet) {
+		return domain.ErrShareNotFound
+	}
+	return err
+}
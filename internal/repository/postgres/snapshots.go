@@ -0,0 +1,173 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/pkg/database/postgres"
+	"github.com/adanyl0v/go-pocket-link/pkg/pagination"
+	"github.com/google/uuid"
+	"time"
+)
+
+type SnapshotsRepository struct {
+	db *postgres.DB
+}
+
+func NewSnapshotsRepository(db *postgres.DB) *SnapshotsRepository {
+	return &SnapshotsRepository{db: db}
+}
+
+// Save keeps the content of the existing snapshot until the new one is captured, so its blob stays referenced
+func (r *SnapshotsRepository) Save(ctx context.Context, snapshot *domain.Snapshot) error {
+	err := r.db.GetNamed(ctx, snapshot, `INSERT INTO snapshots(user_id, link_id, format)
+		VALUES (:user_id, :link_id, :format)
+		ON CONFLICT (link_id, format) DO UPDATE SET status = 'pending', error = '', created_at = now()
+		RETURNING *`, snapshot)
+	if err != nil {
+		return snapshotError(err)
+	}
+	return nil
+}
+
+func (r *SnapshotsRepository) Get(ctx context.Context, userID, linkID uuid.UUID, format domain.SnapshotFormat) (domain.Snapshot, error) {
+	var snapshot domain.Snapshot
+	err := r.db.GetPrepared(ctx, &snapshot, `SELECT * FROM snapshots WHERE link_id = $1 AND user_id = $2 AND format = $3`,
+		linkID.String(), userID.String(), format)
+	if err != nil {
+		return domain.Snapshot{}, snapshotError(err)
+	}
+	return snapshot, nil
+}
+
+func (r *SnapshotsRepository) GetByID(ctx context.Context, id uuid.UUID) (domain.Snapshot, error) {
+	var snapshot domain.Snapshot
+	err := r.db.GetPrepared(ctx, &snapshot, `SELECT * FROM snapshots WHERE id = $1`, id.String())
+	if err != nil {
+		return domain.Snapshot{}, snapshotError(err)
+	}
+	return snapshot, nil
+}
+
+var snapshotsColumns = pagination.Columns{
+	"created_at": "created_at",
+	"size":       "size",
+	"format":     "format",
+	"status":     "status",
+}
+
+func (r *SnapshotsRepository) GetPageByLinkID(ctx context.Context, userID, linkID uuid.UUID,
+	params pagination.Params) (pagination.Page[domain.Snapshot], error) {
+	return selectPage(ctx, r.db, `SELECT * FROM snapshots WHERE link_id = $1 AND user_id = $2`,
+		[]any{linkID.String(), userID.String()}, params, snapshotsColumns, "id", func(snapshot domain.Snapshot, sort string) (any, string) {
+			if sort == "size" {
+				return snapshot.Size, snapshot.ID.String()
+			}
+			return snapshot.CreatedAt, snapshot.ID.String()
+		})
+}
+
+func (r *SnapshotsRepository) Complete(ctx context.Context, snapshot *domain.Snapshot, quota int64,
+	put func(ctx context.Context) error) error {
+	return snapshotError(r.db.WithTx(ctx, func(tx *postgres.Tx) error {
+		// the snapshots of a user are completed one at a time, so together they cannot exceed the quota
+		err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtextextended($1, 0))`, "snapshots:"+snapshot.UserID.String())
+		if err != nil {
+			return err
+		}
+		if quota > 0 {
+			var used int64
+			err = tx.Get(ctx, &used, `SELECT COALESCE(SUM(size), 0) FROM snapshots
+				WHERE user_id = $1 AND status = 'ready' AND id <> $2`, snapshot.UserID.String(), snapshot.ID.String())
+			if err != nil {
+				return err
+			} else if used+snapshot.Size > quota {
+				return domain.ErrSnapshotQuotaExceeded
+			}
+		}
+
+		// the blob row stays locked until the snapshot refers to it, DeleteOrphanBlobs skipping the locked rows
+		var inserted bool
+		err = tx.Get(ctx, &inserted, `INSERT INTO snapshot_blobs(key, size) VALUES ($1, $2)
+			ON CONFLICT (key) DO UPDATE SET touched_at = now()
+			RETURNING xmax = 0`, snapshot.BlobKey, snapshot.Size)
+		if err != nil {
+			return err
+		}
+		if inserted {
+			if err = put(ctx); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		var id uuid.UUID
+		err = tx.Get(ctx, &id, `UPDATE snapshots SET status = $2, content_hash = $3, blob_key = $4, title = $5, size = $6,
+				resources = $7, error = '', captured_at = $8
+			WHERE id = $1 RETURNING id`, snapshot.ID.String(), domain.SnapshotStatusReady, snapshot.ContentHash,
+			snapshot.BlobKey, snapshot.Title, snapshot.Size, snapshot.Resources, now)
+		if err != nil {
+			return err
+		}
+		snapshot.Status, snapshot.Error, snapshot.CapturedAt = domain.SnapshotStatusReady, "", &now
+		return nil
+	}))
+}
+
+func (r *SnapshotsRepository) Fail(ctx context.Context, snapshot *domain.Snapshot) error {
+	return r.db.Update(ctx, `UPDATE snapshots SET status = $2, error = $3 WHERE id = $1`,
+		snapshot.ID.String(), snapshot.Status, snapshot.Error)
+}
+
+func (r *SnapshotsRepository) Delete(ctx context.Context, userID, linkID uuid.UUID) error {
+	return r.db.Delete(ctx, `DELETE FROM snapshots WHERE link_id = $1 AND user_id = $2`, linkID.String(), userID.String())
+}
+
+func (r *SnapshotsRepository) GetUsage(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var used int64
+	err := r.db.GetPrepared(ctx, &used, `SELECT COALESCE(SUM(size), 0) FROM snapshots WHERE user_id = $1 AND status = 'ready'`,
+		userID.String())
+	if err != nil {
+		return 0, err
+	}
+	return used, nil
+}
+
+// DeleteOrphanBlobs keeps the rows locked while the blobs are removed, so they cannot be referred to again meanwhile.
+// If removing any of them fails, none of the rows are deleted, and the removed blobs are retried by the next call
+func (r *SnapshotsRepository) DeleteOrphanBlobs(ctx context.Context, before time.Time, limit int,
+	remove func(ctx context.Context, key string) error) (int64, error) {
+	var deleted int64
+	err := r.db.WithTx(ctx, func(tx *postgres.Tx) error {
+		keys := make([]string, 0, limit)
+		err := tx.Select(ctx, &keys, `SELECT key FROM snapshot_blobs b
+			WHERE touched_at < $1 AND NOT EXISTS (SELECT 1 FROM snapshots s WHERE s.blob_key = b.key)
+			ORDER BY touched_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED`, before, limit)
+		if err != nil || len(keys) == 0 {
+			return err
+		}
+
+		for _, key := range keys {
+			if err = remove(ctx, key); err != nil {
+				return err
+			}
+		}
+		if err = tx.Exec(ctx, `DELETE FROM snapshot_blobs WHERE key = ANY($1::text[])`, keys); err != nil {
+			return err
+		}
+		deleted = int64(len(keys))
+		return nil
+	})
+	return deleted, err
+}
+
+func snapshotError(err error) error {
+	if errors.Is(err, postgres.ErrNoRowsInResultSet) {
+		return domain.ErrSnapshotNotFound
+	} else if postgres.ErrorCode(err) == postgres.ErrCodeForeignKeyViolation {
+		return domain.ErrLinkNotFound
+	}
+	return err
+}
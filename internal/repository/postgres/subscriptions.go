@@ -0,0 +1,145 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/pkg/database/postgres"
+	"github.com/adanyl0v/go-pocket-link/pkg/pagination"
+	"github.com/google/uuid"
+	"time"
+)
+
+type SubscriptionsRepository struct {
+	db *postgres.DB
+}
+
+func NewSubscriptionsRepository(db *postgres.DB) *SubscriptionsRepository {
+	return &SubscriptionsRepository{db: db}
+}
+
+func (r *SubscriptionsRepository) Save(ctx context.Context, subscription *domain.FeedSubscription) error {
+	if subscription.NextFetchAt.IsZero() {
+		subscription.NextFetchAt = time.Now()
+	}
+
+	err := r.db.Save(ctx, &subscription.ID, `INSERT INTO feed_subscriptions(user_id, url, list_id, tags, active, next_fetch_at)
+		VALUES (:user_id, :url, :list_id, :tags, :active, :next_fetch_at) RETURNING id`, subscription)
+	if err != nil {
+		return subscriptionError(err)
+	}
+	subscription.CreatedAt = time.Now()
+	subscription.UpdatedAt = subscription.CreatedAt
+	return nil
+}
+
+func (r *SubscriptionsRepository) Get(ctx context.Context, userID, id uuid.UUID) (domain.FeedSubscription, error) {
+	var subscription domain.FeedSubscription
+	err := r.db.GetPrepared(ctx, &subscription, `SELECT * FROM feed_subscriptions WHERE id = $1 AND user_id = $2`,
+		id.String(), userID.String())
+	if err != nil {
+		return domain.FeedSubscription{}, subscriptionError(err)
+	}
+	return subscription, nil
+}
+
+var subscriptionsColumns = pagination.Columns{
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"title":      "title",
+	"url":        "url",
+	"active":     "active",
+	"list_id":    "list_id",
+}
+
+func (r *SubscriptionsRepository) GetPage(ctx context.Context, userID uuid.UUID, params pagination.Params) (pagination.Page[domain.FeedSubscription], error) {
+	return selectPage(ctx, r.db, `SELECT * FROM feed_subscriptions WHERE user_id = $1`, []any{userID.String()},
+		params, subscriptionsColumns, "id", func(subscription domain.FeedSubscription, sort string) (any, string) {
+			switch sort {
+			case "updated_at":
+				return subscription.UpdatedAt, subscription.ID.String()
+			case "title":
+				return subscription.Title, subscription.ID.String()
+			default:
+				return subscription.CreatedAt, subscription.ID.String()
+			}
+		})
+}
+
+func (r *SubscriptionsRepository) Update(ctx context.Context, subscription *domain.FeedSubscription) error {
+	previousUpdatedTime := subscription.UpdatedAt
+	subscription.UpdatedAt = time.Now()
+
+	var id uuid.UUID
+	err := r.db.GetNamed(ctx, &id, `UPDATE feed_subscriptions SET list_id = :list_id, tags = :tags, active = :active,
+		next_fetch_at = :next_fetch_at, updated_at = :updated_at
+		WHERE id = :id AND user_id = :user_id RETURNING id`, subscription)
+	if err != nil {
+		subscription.UpdatedAt = previousUpdatedTime
+		return subscriptionError(err)
+	}
+	return nil
+}
+
+func (r *SubscriptionsRepository) UpdateFetch(ctx context.Context, subscription *domain.FeedSubscription) error {
+	subscription.UpdatedAt = time.Now()
+	return r.db.UpdateNamed(ctx, `UPDATE feed_subscriptions SET title = :title, active = :active, etag = :etag,
+		last_modified = :last_modified, failures = :failures, last_error = :last_error, next_fetch_at = :next_fetch_at,
+		fetched_at = :fetched_at, updated_at = :updated_at
+		WHERE id = :id`, subscription)
+}
+
+func (r *SubscriptionsRepository) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	var deletedID uuid.UUID
+	err := r.db.Get(ctx, &deletedID, `DELETE FROM feed_subscriptions WHERE id = $1 AND user_id = $2 RETURNING id`,
+		id.String(), userID.String())
+	if err != nil {
+		return subscriptionError(err)
+	}
+	return nil
+}
+
+// Claim leases the due active subscriptions, postponing their next fetch by lease, so they are
+// not claimed again while being fetched, even by another instance
+func (r *SubscriptionsRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]domain.FeedSubscription, error) {
+	subscriptions := make([]domain.FeedSubscription, 0, limit)
+	err := r.db.Select(ctx, &subscriptions, `UPDATE feed_subscriptions s
+		SET next_fetch_at = now() + make_interval(secs => $1)
+		FROM (SELECT id FROM feed_subscriptions
+			WHERE active AND next_fetch_at <= now()
+				AND NOT EXISTS (SELECT 1 FROM users u WHERE u.id = user_id AND u.deleted_at IS NOT NULL)
+			ORDER BY next_fetch_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED) due
+		WHERE s.id = due.id
+		RETURNING s.*`, lease.Seconds(), limit)
+	if err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (r *SubscriptionsRepository) GetUnseenItems(ctx context.Context, id uuid.UUID, hashes []string) ([]string, error) {
+	unseen := make([]string, 0, len(hashes))
+	err := r.db.Select(ctx, &unseen, `SELECT h FROM unnest($2::text[]) WITH ORDINALITY AS u(h, n)
+		WHERE NOT EXISTS (SELECT 1 FROM feed_subscription_items WHERE subscription_id = $1 AND item_hash = h)
+		ORDER BY n`, id.String(), hashes)
+	if err != nil {
+		return nil, err
+	}
+	return unseen, nil
+}
+
+func (r *SubscriptionsRepository) SaveSeenItems(ctx context.Context, id uuid.UUID, hashes []string) error {
+	return r.db.Update(ctx, `INSERT INTO feed_subscription_items(subscription_id, item_hash)
+		SELECT $1, unnest($2::text[]) ON CONFLICT DO NOTHING`, id.String(), hashes)
+}
+
+func subscriptionError(err error) error {
+	if errors.Is(err, postgres.ErrNoRowsInResultSet) {
+		return domain.ErrSubscriptionNotFound
+	} else if postgres.ErrorCode(err) == postgres.ErrCodeUniqueViolation {
+		return domain.ErrSubscriptionAlreadyExists
+	}
+	return err
+}
@@ -0,0 +1,26 @@
+package postgres
+
+import (
+	"context"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/pkg/database/postgres"
+	"github.com/google/uuid"
+)
+
+type SyncRepository struct {
+	db *postgres.DB
+}
+
+func NewSyncRepository(db *postgres.DB) *SyncRepository {
+	return &SyncRepository{db: db}
+}
+
+func (r *SyncRepository) GetChanges(ctx context.Context, userID uuid.UUID, since int64, limit int) ([]domain.SyncChange, error) {
+	changes := make([]domain.SyncChange, 0, limit)
+	err := r.db.SelectPrepared(ctx, &changes, `SELECT entity_type, entity_id, seq, version, deleted, changed_at
+		FROM sync_changes WHERE user_id = $1 AND seq > $2 ORDER BY seq LIMIT $3`, userID.String(), since, limit)
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
@@ -0,0 +1,179 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/pkg/database/postgres"
+	"github.com/adanyl0v/go-pocket-link/pkg/pagination"
+	"github.com/google/uuid"
+)
+
+type TagsRepository struct {
+	db *postgres.DB
+}
+
+func NewTagsRepository(db *postgres.DB) *TagsRepository {
+	return &TagsRepository{db: db}
+}
+
+func (r *TagsRepository) GetAll(ctx context.Context, userID uuid.UUID) ([]domain.TagUsage, error) {
+	tags := make([]domain.TagUsage, 0)
+	err := r.db.SelectPrepared(ctx, &tags, `SELECT t.*, count(lt.link_id) AS links_count FROM tags t
+		LEFT JOIN link_tags lt ON lt.tag_id = t.id
+		WHERE t.user_id = $1
+		GROUP BY t.id
+		ORDER BY links_count DESC, t.name`, userID.String())
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (r *TagsRepository) GetByID(ctx context.Context, userID, id uuid.UUID) (domain.Tag, error) {
+	var tag domain.Tag
+	err := r.db.GetPrepared(ctx, &tag, `SELECT * FROM tags WHERE id = $1 AND user_id = $2`, id.String(), userID.String())
+	if err != nil {
+		return domain.Tag{}, tagError(err)
+	}
+	return tag, nil
+}
+
+func (r *TagsRepository) GetByName(ctx context.Context, userID uuid.UUID, name string) (domain.Tag, error) {
+	var tag domain.Tag
+	err := r.db.GetPrepared(ctx, &tag, `SELECT * FROM tags WHERE user_id = $1 AND name = $2`, userID.String(), name)
+	if err != nil {
+		return domain.Tag{}, tagError(err)
+	}
+	return tag, nil
+}
+
+var tagsColumns = pagination.Columns{
+	"name":        "name",
+	"links_count": "links_count",
+	"created_at":  "created_at",
+}
+
+func (r *TagsRepository) GetPage(ctx context.Context, userID uuid.UUID, params pagination.Params) (pagination.Page[domain.TagUsage], error) {
+	return selectPage(ctx, r.db, `SELECT * FROM (SELECT t.*, count(lt.link_id) AS links_count FROM tags t
+			LEFT JOIN link_tags lt ON lt.tag_id = t.id
+			WHERE t.user_id = $1
+			GROUP BY t.id) t
+		WHERE t.user_id = $1`, []any{userID.String()}, params, tagsColumns, "id", tagKey)
+}
+
+func (r *TagsRepository) GetNamesByLinkIDs(ctx context.Context, userID uuid.UUID, linkIDs []uuid.UUID) (map[uuid.UUID][]string, error) {
+	var rows []struct {
+		LinkID uuid.UUID `db:"link_id"`
+		Name   string    `db:"name"`
+	}
+	err := r.db.Select(ctx, &rows, `SELECT lt.link_id, t.name FROM link_tags lt
+		JOIN tags t ON t.id = lt.tag_id
+		WHERE t.user_id = $1 AND lt.link_id = ANY($2::uuid[])
+		ORDER BY t.name`, userID.String(), uuidsToStrings(linkIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[uuid.UUID][]string, len(linkIDs))
+	for _, row := range rows {
+		names[row.LinkID] = append(names[row.LinkID], row.Name)
+	}
+	return names, nil
+}
+
+func (r *TagsRepository) AddToLinks(ctx context.Context, userID uuid.UUID, linkIDs []uuid.UUID, names []string) error {
+	return r.db.WithTx(ctx, func(tx *postgres.Tx) error {
+		return addTagsToLinks(ctx, tx, userID, linkIDs, names)
+	})
+}
+
+// addTagsToLinks is AddToLinks inside the transaction, e.g. the one saving the links
+func addTagsToLinks(ctx context.Context, tx *postgres.Tx, userID uuid.UUID, linkIDs []uuid.UUID, names []string) error {
+	err := tx.Exec(ctx, `INSERT INTO tags(user_id, name) SELECT $1, unnest($2::text[])
+		ON CONFLICT (user_id, name) DO NOTHING`, userID.String(), names)
+	if err != nil {
+		return err
+	}
+
+	return tx.Exec(ctx, `INSERT INTO link_tags(link_id, tag_id)
+		SELECT l.id, t.id FROM links l CROSS JOIN tags t
+		WHERE l.user_id = $1 AND l.id = ANY($2::uuid[]) AND t.user_id = $1 AND t.name = ANY($3::text[])
+		ON CONFLICT DO NOTHING`, userID.String(), uuidsToStrings(linkIDs), names)
+}
+
+func (r *TagsRepository) RemoveFromLinks(ctx context.Context, userID uuid.UUID, linkIDs []uuid.UUID, names []string) error {
+	return r.db.Delete(ctx, `DELETE FROM link_tags lt USING tags t
+		WHERE lt.tag_id = t.id AND t.user_id = $1 AND lt.link_id = ANY($2::uuid[]) AND t.name = ANY($3::text[])`,
+		userID.String(), uuidsToStrings(linkIDs), names)
+}
+
+func (r *TagsRepository) Rename(ctx context.Context, userID uuid.UUID, oldName, newName string) error {
+	var id uuid.UUID
+	err := r.db.Get(ctx, &id, `UPDATE tags SET name = $1 WHERE user_id = $2 AND name = $3 RETURNING id`,
+		newName, userID.String(), oldName)
+	if err != nil {
+		return tagError(err)
+	}
+	return nil
+}
+
+func (r *TagsRepository) Merge(ctx context.Context, userID uuid.UUID, source, target string) error {
+	return r.db.WithTx(ctx, func(tx *postgres.Tx) error {
+		var sourceID, targetID uuid.UUID
+		err := tx.Get(ctx, &sourceID, `SELECT id FROM tags WHERE user_id = $1 AND name = $2 FOR UPDATE`, userID.String(), source)
+		if err != nil {
+			return tagError(err)
+		}
+
+		err = tx.Get(ctx, &targetID, `SELECT id FROM tags WHERE user_id = $1 AND name = $2 FOR UPDATE`, userID.String(), target)
+		if err != nil {
+			return tagError(err)
+		}
+
+		err = tx.Exec(ctx, `INSERT INTO link_tags(link_id, tag_id) SELECT link_id, $1 FROM link_tags WHERE tag_id = $2
+			ON CONFLICT DO NOTHING`, targetID.String(), sourceID.String())
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec(ctx, `DELETE FROM tags WHERE id = $1`, sourceID.String())
+	})
+}
+
+func (r *TagsRepository) Delete(ctx context.Context, userID uuid.UUID, name string) error {
+	var id uuid.UUID
+	err := r.db.Get(ctx, &id, `DELETE FROM tags WHERE user_id = $1 AND name = $2 RETURNING id`, userID.String(), name)
+	if err != nil {
+		return tagError(err)
+	}
+	return nil
+}
+
+func tagKey(tag domain.TagUsage, sort string) (any, string) {
+	switch sort {
+	case "name":
+		return tag.Name, tag.ID.String()
+	case "created_at":
+		return tag.CreatedAt, tag.ID.String()
+	default:
+		return tag.LinksCount, tag.ID.String()
+	}
+}
+
+func tagError(err error) error {
+	if errors.Is(err, postgres.ErrNoRowsInResultSet) {
+		return domain.ErrTagNotFound
+	} else if postgres.ErrorCode(err) == postgres.ErrCodeUniqueViolation {
+		return domain.ErrTagAlreadyExists
+	}
+	return err
+}
+
+func uuidsToStrings(ids []uuid.UUID) []string {
+	s := make([]string, 0, len(ids))
+	for _, id := range ids {
+		s = append(s, id.String())
+	}
+	return s
+}
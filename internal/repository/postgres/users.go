@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"github.com/adanyl0v/go-pocket-link/internal/domain"
 	"github.com/adanyl0v/go-pocket-link/pkg/database/postgres"
 	"github.com/google/uuid"
@@ -28,7 +29,7 @@ func (r *UsersRepository) Save(ctx context.Context, user *domain.User) error {
 
 func (r *UsersRepository) Get(ctx context.Context, id uuid.UUID) (domain.User, error) {
 	var user domain.User
-	if err := r.db.GetPrepared(ctx, &user, `SELECT * FROM users WHERE id = $1`, id.String()); err != nil {
+	if err := r.db.GetPrepared(ctx, &user, `SELECT * FROM users WHERE id = $1 AND deleted_at IS NULL`, id.String()); err != nil {
 		return domain.User{}, err
 	}
 	return user, nil
@@ -36,8 +37,10 @@ func (r *UsersRepository) Get(ctx context.Context, id uuid.UUID) (domain.User, e
 
 func (r *UsersRepository) GetByCredentials(ctx context.Context, email, password string) (domain.User, error) {
 	var user domain.User
-	err := r.db.GetPrepared(ctx, &user, `SELECT * FROM users WHERE email = $1 AND password = $2`, email, password)
-	if err != nil {
+	err := r.db.GetPrepared(ctx, &user, `SELECT * FROM users WHERE email = $1 AND password = $2 AND deleted_at IS NULL`, email, password)
+	if errors.Is(err, postgres.ErrNoRowsInResultSet) {
+		return domain.User{}, domain.ErrInvalidCredentials
+	} else if err != nil {
 		return domain.User{}, err
 	}
 	return user, nil
@@ -46,7 +49,7 @@ func (r *UsersRepository) GetByCredentials(ctx context.Context, email, password
 func (r *UsersRepository) Update(ctx context.Context, user *domain.User) error {
 	previousUpdatedTime := user.UpdatedAt
 	user.UpdatedAt = time.Now()
-	err := r.db.UpdateNamed(ctx, `UPDATE users SET name = :name, email = :email, password = :password, updated_at = :updated_at WHERE id = :id`, user)
+	err := r.db.UpdateNamed(ctx, `UPDATE users SET name = :name, email = :email, password = :password, updated_at = :updated_at WHERE id = :id AND deleted_at IS NULL`, user)
 	if err != nil {
 		user.UpdatedAt = previousUpdatedTime
 		return err
@@ -55,5 +58,14 @@ func (r *UsersRepository) Update(ctx context.Context, user *domain.User) error {
 }
 
 func (r *UsersRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.Delete(ctx, `DELETE FROM users WHERE id = $1`, id.String())
+	return r.db.Update(ctx, `UPDATE users SET deleted_at = now(), updated_at = now() WHERE id = $1 AND deleted_at IS NULL`,
+		id.String())
+}
+
+func (r *UsersRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	ids := make([]uuid.UUID, 0)
+	if err := r.db.Select(ctx, &ids, `DELETE FROM users WHERE deleted_at < $1 RETURNING id`, before); err != nil {
+		return 0, err
+	}
+	return int64(len(ids)), nil
 }
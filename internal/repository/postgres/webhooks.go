@@ -0,0 +1,215 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/pkg/database/postgres"
+	"github.com/adanyl0v/go-pocket-link/pkg/pagination"
+	"github.com/google/uuid"
+	"time"
+)
+
+type WebhooksRepository struct {
+	db *postgres.DB
+}
+
+func NewWebhooksRepository(db *postgres.DB) *WebhooksRepository {
+	return &WebhooksRepository{db: db}
+}
+
+func (r *WebhooksRepository) Save(ctx context.Context, webhook *domain.Webhook) error {
+	err := r.db.Save(ctx, &webhook.ID, `INSERT INTO webhooks(user_id, url, secret, events, active)
+		VALUES (:user_id, :url, :secret, :events, :active) RETURNING id`, webhook)
+	if err != nil {
+		return err
+	}
+	webhook.CreatedAt = time.Now()
+	webhook.UpdatedAt = webhook.CreatedAt
+	return nil
+}
+
+func (r *WebhooksRepository) Get(ctx context.Context, userID, id uuid.UUID) (domain.Webhook, error) {
+	var webhook domain.Webhook
+	err := r.db.GetPrepared(ctx, &webhook, `SELECT * FROM webhooks WHERE id = $1 AND user_id = $2`, id.String(), userID.String())
+	if err != nil {
+		return domain.Webhook{}, webhookError(err)
+	}
+	return webhook, nil
+}
+
+func (r *WebhooksRepository) GetByID(ctx context.Context, id uuid.UUID) (domain.Webhook, error) {
+	var webhook domain.Webhook
+	err := r.db.GetPrepared(ctx, &webhook, `SELECT * FROM webhooks WHERE id = $1`, id.String())
+	if err != nil {
+		return domain.Webhook{}, webhookError(err)
+	}
+	return webhook, nil
+}
+
+var webhooksColumns = pagination.Columns{
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"url":        "url",
+	"active":     "active",
+}
+
+func (r *WebhooksRepository) GetPage(ctx context.Context, userID uuid.UUID, params pagination.Params) (pagination.Page[domain.Webhook], error) {
+	return selectPage(ctx, r.db, `SELECT * FROM webhooks WHERE user_id = $1`, []any{userID.String()},
+		params, webhooksColumns, "id", func(webhook domain.Webhook, sort string) (any, string) {
+			if sort == "updated_at" {
+				return webhook.UpdatedAt, webhook.ID.String()
+			}
+			return webhook.CreatedAt, webhook.ID.String()
+		})
+}
+
+func (r *WebhooksRepository) GetSubscribed(ctx context.Context, userID uuid.UUID, eventType domain.EventType) ([]domain.Webhook, error) {
+	webhooks := make([]domain.Webhook, 0)
+	err := r.db.SelectPrepared(ctx, &webhooks, `SELECT * FROM webhooks
+		WHERE user_id = $1 AND active AND events @> jsonb_build_array($2::text)`, userID.String(), eventType)
+	if err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func (r *WebhooksRepository) Update(ctx context.Context, webhook *domain.Webhook) error {
+	previousUpdatedTime := webhook.UpdatedAt
+	webhook.UpdatedAt = time.Now()
+
+	var id uuid.UUID
+	err := r.db.GetNamed(ctx, &id, `UPDATE webhooks SET url = :url, events = :events, active = :active, updated_at = :updated_at
+		WHERE id = :id AND user_id = :user_id RETURNING id`, webhook)
+	if err != nil {
+		webhook.UpdatedAt = previousUpdatedTime
+		return webhookError(err)
+	}
+	return nil
+}
+
+func (r *WebhooksRepository) UpdateSecret(ctx context.Context, webhook *domain.Webhook) error {
+	previousUpdatedTime := webhook.UpdatedAt
+	webhook.UpdatedAt = time.Now()
+
+	var id uuid.UUID
+	err := r.db.GetNamed(ctx, &id, `UPDATE webhooks SET secret = :secret, updated_at = :updated_at
+		WHERE id = :id AND user_id = :user_id RETURNING id`, webhook)
+	if err != nil {
+		webhook.UpdatedAt = previousUpdatedTime
+		return webhookError(err)
+	}
+	return nil
+}
+
+func (r *WebhooksRepository) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	var deletedID uuid.UUID
+	err := r.db.Get(ctx, &deletedID, `DELETE FROM webhooks WHERE id = $1 AND user_id = $2 RETURNING id`, id.String(), userID.String())
+	if err != nil {
+		return webhookError(err)
+	}
+	return nil
+}
+
+func webhookError(err error) error {
+	if errors.Is(err, postgres.ErrNoRowsInResultSet) {
+		return domain.ErrWebhookNotFound
+	}
+	return err
+}
+
+type WebhookDeliveriesRepository struct {
+	db *postgres.DB
+}
+
+func NewWebhookDeliveriesRepository(db *postgres.DB) *WebhookDeliveriesRepository {
+	return &WebhookDeliveriesRepository{db: db}
+}
+
+func (r *WebhookDeliveriesRepository) Save(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	if delivery.NextAttemptAt.IsZero() {
+		delivery.NextAttemptAt = time.Now()
+	}
+
+	err := r.db.Save(ctx, &delivery.ID, `INSERT INTO webhook_deliveries(webhook_id, event_id, event, payload, status, next_attempt_at)
+		VALUES (:webhook_id, :event_id, :event, :payload, :status, :next_attempt_at) RETURNING id`, delivery)
+	if err != nil {
+		return err
+	}
+	delivery.CreatedAt = time.Now()
+	delivery.UpdatedAt = delivery.CreatedAt
+	return nil
+}
+
+func (r *WebhookDeliveriesRepository) Get(ctx context.Context, webhookID, id uuid.UUID) (domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+	err := r.db.GetPrepared(ctx, &delivery, `SELECT * FROM webhook_deliveries WHERE id = $1 AND webhook_id = $2`,
+		id.String(), webhookID.String())
+	if errors.Is(err, postgres.ErrNoRowsInResultSet) {
+		return domain.WebhookDelivery{}, domain.ErrWebhookDeliveryNotFound
+	} else if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	return delivery, nil
+}
+
+var deliveriesColumns = pagination.Columns{
+	"created_at": "created_at",
+	"status":     "status",
+	"event":      "event",
+}
+
+func (r *WebhookDeliveriesRepository) GetPage(ctx context.Context, webhookID uuid.UUID, params pagination.Params) (pagination.Page[domain.WebhookDelivery], error) {
+	return selectPage(ctx, r.db, `SELECT * FROM webhook_deliveries WHERE webhook_id = $1`, []any{webhookID.String()},
+		params, deliveriesColumns, "id", func(delivery domain.WebhookDelivery, _ string) (any, string) {
+			return delivery.CreatedAt, delivery.ID.String()
+		})
+}
+
+// Claim leases the due pending deliveries, postponing their next attempt by lease, so they are
+// not claimed again while being delivered, even by another instance. A delivery whose claimer
+// has died is retried once the lease is over
+func (r *WebhookDeliveriesRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]domain.WebhookDelivery, error) {
+	deliveries := make([]domain.WebhookDelivery, 0, limit)
+	err := r.db.Select(ctx, &deliveries, `UPDATE webhook_deliveries d
+		SET next_attempt_at = now() + make_interval(secs => $1), updated_at = now()
+		FROM (SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= now()
+			ORDER BY next_attempt_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED) due
+		WHERE d.id = due.id
+		RETURNING d.*`, lease.Seconds(), limit)
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (r *WebhookDeliveriesRepository) Update(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	delivery.UpdatedAt = time.Now()
+	return r.db.UpdateNamed(ctx, `UPDATE webhook_deliveries SET status = :status, attempts = :attempts,
+		next_attempt_at = :next_attempt_at, last_status_code = :last_status_code, last_error = :last_error,
+		updated_at = :updated_at, delivered_at = :delivered_at
+		WHERE id = :id`, delivery)
+}
+
+func (r *WebhookDeliveriesRepository) SaveAttempt(ctx context.Context, attempt *domain.WebhookAttempt) error {
+	err := r.db.Save(ctx, &attempt.ID, `INSERT INTO webhook_attempts(delivery_id, attempt, status_code, error, response_body, duration_ms)
+		VALUES (:delivery_id, :attempt, :status_code, :error, :response_body, :duration_ms) RETURNING id`, attempt)
+	if err != nil {
+		return err
+	}
+	attempt.CreatedAt = time.Now()
+	return nil
+}
+
+func (r *WebhookDeliveriesRepository) GetAttempts(ctx context.Context, deliveryID uuid.UUID) ([]domain.WebhookAttempt, error) {
+	attempts := make([]domain.WebhookAttempt, 0)
+	err := r.db.SelectPrepared(ctx, &attempts, `SELECT * FROM webhook_attempts WHERE delivery_id = $1 ORDER BY attempt`,
+		deliveryID.String())
+	if err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
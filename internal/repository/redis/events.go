@@ -0,0 +1,103 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/pkg/cache/redis"
+	"github.com/google/uuid"
+	"regexp"
+	"time"
+)
+
+const (
+	eventsKeyPrefix = "events:"
+	eventsField     = "event"
+)
+
+var streamIDRegexp = regexp.MustCompile(`^\d+-\d+$`)
+
+// loggedEventMessage is published to the replicas when an event is logged
+type loggedEventMessage struct {
+	ID    string       `json:"id"`
+	Event domain.Event `json:"event"`
+}
+
+// EventLogRepository keeps the latest events of each user in a redis stream
+// and publishes them to the channel of the user
+type EventLogRepository struct {
+	cache  *redis.DB
+	maxLen int64
+	ttl    time.Duration
+}
+
+func NewEventLogRepository(cache *redis.DB, maxLen int64, ttl time.Duration) *EventLogRepository {
+	return &EventLogRepository{
+		cache:  cache,
+		maxLen: maxLen,
+		ttl:    ttl,
+	}
+}
+
+func (r *EventLogRepository) Append(ctx context.Context, event domain.Event) (domain.LoggedEvent, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return domain.LoggedEvent{}, err
+	}
+
+	key := eventsKey(event.UserID)
+	id, err := r.cache.Append(ctx, key, map[string]any{eventsField: payload}, r.maxLen, r.ttl)
+	if err != nil {
+		return domain.LoggedEvent{}, err
+	}
+
+	message, err := json.Marshal(loggedEventMessage{ID: id, Event: event})
+	if err != nil {
+		return domain.LoggedEvent{}, err
+	}
+	if err = r.cache.Publish(ctx, key, message); err != nil {
+		return domain.LoggedEvent{}, err
+	}
+	return domain.LoggedEvent{ID: id, Event: event}, nil
+}
+
+func (r *EventLogRepository) GetAfter(ctx context.Context, userID uuid.UUID, afterID string) ([]domain.LoggedEvent, bool, error) {
+	key := eventsKey(userID)
+	if !streamIDRegexp.MatchString(afterID) {
+		return nil, false, nil
+	} else if ok, err := r.cache.HasEntry(ctx, key, afterID); err != nil || !ok {
+		return nil, false, err
+	}
+
+	entries, err := r.cache.Range(ctx, key, afterID, 0)
+	if err != nil {
+		return nil, false, err
+	}
+
+	events := make([]domain.LoggedEvent, 0, len(entries))
+	for _, entry := range entries {
+		payload, _ := entry.Values[eventsField].(string)
+
+		var event domain.Event
+		if err = json.Unmarshal([]byte(payload), &event); err != nil {
+			return nil, false, fmt.Errorf("%w (decoding event %s)", err, entry.ID)
+		}
+		events = append(events, domain.LoggedEvent{ID: entry.ID, Event: event})
+	}
+	return events, true, nil
+}
+
+func (r *EventLogRepository) Listen(ctx context.Context, fn func(event domain.LoggedEvent)) error {
+	return r.cache.Listen(ctx, eventsKeyPrefix+"*", func(channel, payload string) {
+		var message loggedEventMessage
+		if err := json.Unmarshal([]byte(payload), &message); err != nil {
+			return
+		}
+		fn(domain.LoggedEvent{ID: message.ID, Event: message.Event})
+	})
+}
+
+func eventsKey(userID uuid.UUID) string {
+	return eventsKeyPrefix + userID.String()
+}
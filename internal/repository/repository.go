@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/pkg/pagination"
 	"github.com/google/uuid"
 	"time"
 )
@@ -13,7 +14,10 @@ type UsersRepository interface {
 	GetByCredentials(ctx context.Context, email, password string) (domain.User, error)
 	// Update domain.User Name, Email and Password by ID
 	Update(ctx context.Context, user *domain.User) error
+	// Delete marks the user as deleted, the deleted users not being found anymore
 	Delete(ctx context.Context, id uuid.UUID) error
+	// Purge removes the users deleted before the time along with their data, returning how many have been removed
+	Purge(ctx context.Context, before time.Time) (int64, error)
 }
 
 type TokensRepository interface {
@@ -27,7 +31,291 @@ type TokensRepository interface {
 	DeleteByTokenID(ctx context.Context, tokenID uuid.UUID) error
 }
 
+type LinksRepository interface {
+	// SaveWithTags saves the link and attaches the tags to it in one transaction, creating the missing tags.
+	// If the user has saved the normalized url already, the link is replaced with the existing one,
+	// the tags are attached to it and created is false
+	SaveWithTags(ctx context.Context, link *domain.Link, tags []string) (created bool, err error)
+	Get(ctx context.Context, userID, id uuid.UUID) (domain.Link, error)
+	// GetByIDs returns the user's links having the ids, with their tags
+	GetByIDs(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) ([]domain.Link, error)
+	GetPage(ctx context.Context, userID uuid.UUID, params pagination.Params) (pagination.Page[domain.Link], error)
+	// Each streams all links of the user with their tags to fn, stopping on the first error
+	Each(ctx context.Context, userID uuid.UUID, fn func(link domain.Link) error) error
+	// EachByList streams all links of the user ordered by the title of the list containing them.
+	// A link is streamed once per list containing it, and once with an empty title if it is in no list
+	EachByList(ctx context.Context, userID uuid.UUID, fn func(list string, link domain.Link) error) error
+	// Update domain.Link URL, NormalizedURL and Title by ID and UserID if its Version has not changed,
+	// setting the new one
+	Update(ctx context.Context, link *domain.Link) error
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+	// DeleteVersion deletes the link if it is still at the version
+	DeleteVersion(ctx context.Context, userID, id uuid.UUID, version int64) error
+	// NormalizeURLs sets domain.Link NormalizedURL of all links to the normalized URL, merging the links of a user
+	// normalized to the same url into the oldest one. It returns how many links have been updated and merged
+	NormalizeURLs(ctx context.Context, normalize func(rawURL string) string) (updated, merged int64, err error)
+}
+
+type ListsRepository interface {
+	Save(ctx context.Context, list *domain.List) error
+	Get(ctx context.Context, userID, id uuid.UUID) (domain.List, error)
+	// GetByID returns the list regardless of its owner, so access to it must be checked beforehand
+	GetByID(ctx context.Context, id uuid.UUID) (domain.List, error)
+	GetByTitle(ctx context.Context, userID uuid.UUID, title string) (domain.List, error)
+	// GetPage returns the lists the user owns or is a member of
+	GetPage(ctx context.Context, userID uuid.UUID, params pagination.Params) (pagination.Page[domain.List], error)
+	// Update domain.List Title by ID and UserID if its Version has not changed, setting the new one
+	Update(ctx context.Context, list *domain.List) error
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+	// DeleteVersion deletes the list if it is still at the version
+	DeleteVersion(ctx context.Context, userID, id uuid.UUID, version int64) error
+	GetByIDsWithLinkIDs(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) ([]domain.ListWithLinks, error)
+	GetLinksPage(ctx context.Context, userID, id uuid.UUID, params pagination.Params) (pagination.Page[domain.Link], error)
+	// AddLinks adds the user's links to the list, skipping the ones that are already there,
+	// and returns the ids of the added ones
+	AddLinks(ctx context.Context, id, userID uuid.UUID, linkIDs []uuid.UUID) ([]uuid.UUID, error)
+	// RemoveLinks returns the ids of the links that have been in the list
+	RemoveLinks(ctx context.Context, id uuid.UUID, linkIDs []uuid.UUID) ([]uuid.UUID, error)
+	// EachWithLinkIDs streams all lists of the user with the ids of their links to fn
+	EachWithLinkIDs(ctx context.Context, userID uuid.UUID, fn func(list domain.List, linkIDs []uuid.UUID) error) error
+}
+
+type ListMembersRepository interface {
+	// GetRole returns the role of the user in the list, or domain.ErrListNotFound if they have no access to it
+	GetRole(ctx context.Context, userID, listID uuid.UUID) (domain.ListRole, error)
+	// GetAll returns the members of the list, starting with its owner
+	GetAll(ctx context.Context, listID uuid.UUID) ([]domain.ListMember, error)
+	GetPage(ctx context.Context, listID uuid.UUID, params pagination.Params) (pagination.Page[domain.ListMember], error)
+	// UpdateRole updates domain.ListMember Role by ListID and UserID
+	UpdateRole(ctx context.Context, member *domain.ListMember) error
+	Delete(ctx context.Context, listID, userID uuid.UUID) error
+}
+
+type ListInvitationsRepository interface {
+	Save(ctx context.Context, invitation *domain.ListInvitation) error
+	GetByTokenHash(ctx context.Context, tokenHash string) (domain.ListInvitation, error)
+	// GetPendingPage returns the invitations of the list that are neither accepted nor expired
+	GetPendingPage(ctx context.Context, listID uuid.UUID, params pagination.Params) (pagination.Page[domain.ListInvitation], error)
+	// Accept marks the invitation accepted and makes the user a member of the list
+	Accept(ctx context.Context, invitation *domain.ListInvitation, userID uuid.UUID) error
+	Delete(ctx context.Context, listID, id uuid.UUID) error
+}
+
+type ListActivitiesRepository interface {
+	Save(ctx context.Context, activity *domain.ListActivity) error
+	// SaveForLinks saves an activity with the action for each of the links
+	SaveForLinks(ctx context.Context, listID, actorID uuid.UUID, action domain.ListActivityAction, linkIDs []uuid.UUID) error
+	GetPage(ctx context.Context, listID uuid.UUID, params pagination.Params) (pagination.Page[domain.ListActivity], error)
+}
+
+type ListSharesRepository interface {
+	// Save publishes the list, or updates the password and the expiry of its share keeping the slug
+	Save(ctx context.Context, share *domain.ListShare) error
+	Get(ctx context.Context, userID, listID uuid.UUID) (domain.ListShare, error)
+	GetBySlug(ctx context.Context, slug string) (domain.ListShare, error)
+	// UpdateSlug replaces the slug of the share by ListID and UserID, resetting its views
+	UpdateSlug(ctx context.Context, share *domain.ListShare) error
+	IncrementViews(ctx context.Context, listID uuid.UUID) error
+	Delete(ctx context.Context, userID, listID uuid.UUID) error
+	// DeleteExpired deletes the shares expired before the time, returning how many have been deleted
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+type TagsRepository interface {
+	GetAll(ctx context.Context, userID uuid.UUID) ([]domain.TagUsage, error)
+	GetPage(ctx context.Context, userID uuid.UUID, params pagination.Params) (pagination.Page[domain.TagUsage], error)
+	GetByID(ctx context.Context, userID, id uuid.UUID) (domain.Tag, error)
+	GetByName(ctx context.Context, userID uuid.UUID, name string) (domain.Tag, error)
+	// GetNamesByLinkIDs returns the names of the tags attached to each of the links
+	GetNamesByLinkIDs(ctx context.Context, userID uuid.UUID, linkIDs []uuid.UUID) (map[uuid.UUID][]string, error)
+	// AddToLinks creates the missing tags and attaches all of them to the links
+	AddToLinks(ctx context.Context, userID uuid.UUID, linkIDs []uuid.UUID, names []string) error
+	RemoveFromLinks(ctx context.Context, userID uuid.UUID, linkIDs []uuid.UUID, names []string) error
+	Rename(ctx context.Context, userID uuid.UUID, oldName, newName string) error
+	// Merge moves all links of the source tag to the target tag and deletes the source tag
+	Merge(ctx context.Context, userID uuid.UUID, source, target string) error
+	Delete(ctx context.Context, userID uuid.UUID, name string) error
+}
+
+type HighlightsRepository interface {
+	Save(ctx context.Context, highlight *domain.Highlight) error
+	Get(ctx context.Context, userID, id uuid.UUID) (domain.Highlight, error)
+	GetByLinkID(ctx context.Context, userID, linkID uuid.UUID) ([]domain.Highlight, error)
+	GetPageByLinkID(ctx context.Context, userID, linkID uuid.UUID, params pagination.Params) (pagination.Page[domain.Highlight], error)
+	// Update domain.Highlight selectors and Color by ID and UserID
+	Update(ctx context.Context, highlight *domain.Highlight) error
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+	Each(ctx context.Context, userID uuid.UUID, fn func(highlight domain.Highlight) error) error
+}
+
+type NotesRepository interface {
+	Save(ctx context.Context, note *domain.Note) error
+	Get(ctx context.Context, userID, id uuid.UUID) (domain.Note, error)
+	GetByLinkID(ctx context.Context, userID, linkID uuid.UUID) ([]domain.Note, error)
+	GetPageByLinkID(ctx context.Context, userID, linkID uuid.UUID, params pagination.Params) (pagination.Page[domain.Note], error)
+	// Update domain.Note Body by ID and UserID
+	Update(ctx context.Context, note *domain.Note) error
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+	Each(ctx context.Context, userID uuid.UUID, fn func(note domain.Note) error) error
+}
+
+type ReadingStatesRepository interface {
+	// Get returns the reading state of the user's link, which is empty if the link has never been opened
+	Get(ctx context.Context, userID, linkID uuid.UUID) (domain.ReadingState, error)
+	// UpdateProgress applies the progress unless a newer one has already been applied,
+	// and returns the current state with whether the update has been applied
+	UpdateProgress(ctx context.Context, state *domain.ReadingState) (bool, error)
+	MarkOpened(ctx context.Context, userID, linkID uuid.UUID, at time.Time) (domain.ReadingState, error)
+	// SetRead marks the link read at the time or unread if it is nil, unless a newer change has already been applied
+	SetRead(ctx context.Context, userID, linkID uuid.UUID, readAt *time.Time, changedAt time.Time) (domain.ReadingState, bool, error)
+	// GetContinueReading returns the started but unread links ordered by the time they were last opened
+	GetContinueReading(ctx context.Context, userID uuid.UUID, params pagination.Params) (pagination.Page[domain.LinkWithReadingState], error)
+}
+
+type WebhooksRepository interface {
+	Save(ctx context.Context, webhook *domain.Webhook) error
+	Get(ctx context.Context, userID, id uuid.UUID) (domain.Webhook, error)
+	// GetByID returns the webhook regardless of its owner, for delivering to it
+	GetByID(ctx context.Context, id uuid.UUID) (domain.Webhook, error)
+	GetPage(ctx context.Context, userID uuid.UUID, params pagination.Params) (pagination.Page[domain.Webhook], error)
+	// GetSubscribed returns the active webhooks of the user subscribed to the event type
+	GetSubscribed(ctx context.Context, userID uuid.UUID, eventType domain.EventType) ([]domain.Webhook, error)
+	// Update domain.Webhook URL, Events and Active by ID and UserID
+	Update(ctx context.Context, webhook *domain.Webhook) error
+	// UpdateSecret updates domain.Webhook Secret by ID and UserID
+	UpdateSecret(ctx context.Context, webhook *domain.Webhook) error
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+}
+
+type WebhookDeliveriesRepository interface {
+	Save(ctx context.Context, delivery *domain.WebhookDelivery) error
+	Get(ctx context.Context, webhookID, id uuid.UUID) (domain.WebhookDelivery, error)
+	GetPage(ctx context.Context, webhookID uuid.UUID, params pagination.Params) (pagination.Page[domain.WebhookDelivery], error)
+	// Claim leases up to limit due pending deliveries for the time of lease
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]domain.WebhookDelivery, error)
+	// Update domain.WebhookDelivery Status, Attempts, NextAttemptAt, the last attempt results and DeliveredAt by ID
+	Update(ctx context.Context, delivery *domain.WebhookDelivery) error
+	SaveAttempt(ctx context.Context, attempt *domain.WebhookAttempt) error
+	GetAttempts(ctx context.Context, deliveryID uuid.UUID) ([]domain.WebhookAttempt, error)
+}
+
+type FeedsRepository interface {
+	// Save saves the feed of the list or the tag, replacing the token of the existing one
+	Save(ctx context.Context, feed *domain.Feed) error
+	GetByListID(ctx context.Context, userID, listID uuid.UUID) (domain.Feed, error)
+	GetByTagID(ctx context.Context, userID, tagID uuid.UUID) (domain.Feed, error)
+	GetByTokenHash(ctx context.Context, tokenHash string) (domain.Feed, error)
+	UpdateAccessedAt(ctx context.Context, id uuid.UUID, accessedAt time.Time) error
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+	// GetListLinks returns the links last added to the list
+	GetListLinks(ctx context.Context, listID uuid.UUID, limit int) ([]domain.FeedLink, error)
+	// GetTagLinks returns the links last tagged with the tag
+	GetTagLinks(ctx context.Context, tagID uuid.UUID, limit int) ([]domain.FeedLink, error)
+	// GetListModifiedAt returns the time the list or any of its links last changed
+	GetListModifiedAt(ctx context.Context, listID uuid.UUID) (time.Time, error)
+	// GetLinksModifiedAt returns the time any of the user's links last changed
+	GetLinksModifiedAt(ctx context.Context, userID uuid.UUID) (time.Time, error)
+}
+
+type SubscriptionsRepository interface {
+	Save(ctx context.Context, subscription *domain.FeedSubscription) error
+	Get(ctx context.Context, userID, id uuid.UUID) (domain.FeedSubscription, error)
+	GetPage(ctx context.Context, userID uuid.UUID, params pagination.Params) (pagination.Page[domain.FeedSubscription], error)
+	// Update domain.FeedSubscription ListID, Tags, Active and NextFetchAt by ID and UserID
+	Update(ctx context.Context, subscription *domain.FeedSubscription) error
+	// UpdateFetch updates domain.FeedSubscription Title, Active, the validators and the fetch results by ID
+	UpdateFetch(ctx context.Context, subscription *domain.FeedSubscription) error
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+	// Claim leases up to limit due active subscriptions for the time of lease
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]domain.FeedSubscription, error)
+	// GetUnseenItems returns the item hashes the subscription has not seen yet, in the order they are given
+	GetUnseenItems(ctx context.Context, id uuid.UUID, hashes []string) ([]string, error)
+	SaveSeenItems(ctx context.Context, id uuid.UUID, hashes []string) error
+}
+
+type LinkChecksRepository interface {
+	// GetDue returns up to limit links due to be checked, the ones never checked first
+	GetDue(ctx context.Context, limit int) ([]domain.LinkCheckTarget, error)
+	GetHealth(ctx context.Context, linkID uuid.UUID) (domain.LinkHealth, error)
+	// GetChecks returns up to limit latest checks of the link
+	GetChecks(ctx context.Context, linkID uuid.UUID, limit int) ([]domain.LinkCheck, error)
+	// Save saves the health of the link along with the check, if it is not nil, keeping only the latest
+	// historySize checks of the link
+	Save(ctx context.Context, health *domain.LinkHealth, check *domain.LinkCheck, historySize int) error
+}
+
+type SnapshotsRepository interface {
+	// Save saves the pending snapshot, resetting the existing one of the link in the format, whose ID it takes
+	Save(ctx context.Context, snapshot *domain.Snapshot) error
+	Get(ctx context.Context, userID, linkID uuid.UUID, format domain.SnapshotFormat) (domain.Snapshot, error)
+	GetByID(ctx context.Context, id uuid.UUID) (domain.Snapshot, error)
+	GetPageByLinkID(ctx context.Context, userID, linkID uuid.UUID, params pagination.Params) (pagination.Page[domain.Snapshot], error)
+	// Complete marks the snapshot ready with its blob, failing with domain.ErrSnapshotQuotaExceeded if the ready
+	// snapshots of the user would take more than quota, unless it is 0. put stores the blob unless another snapshot
+	// has stored it already, and is called while the blob is locked, so the blob is not deleted meanwhile
+	Complete(ctx context.Context, snapshot *domain.Snapshot, quota int64, put func(ctx context.Context) error) error
+	// Fail updates domain.Snapshot Status and Error by ID
+	Fail(ctx context.Context, snapshot *domain.Snapshot) error
+	// Delete deletes all snapshots of the link, their blobs being left to DeleteOrphanBlobs
+	Delete(ctx context.Context, userID, linkID uuid.UUID) error
+	// GetUsage returns the total size of the ready snapshots of the user
+	GetUsage(ctx context.Context, userID uuid.UUID) (int64, error)
+	// DeleteOrphanBlobs deletes up to limit blobs no snapshot refers to, untouched since before, calling remove
+	// to delete each of them from the store. It returns how many have been deleted
+	DeleteOrphanBlobs(ctx context.Context, before time.Time, limit int,
+		remove func(ctx context.Context, key string) error) (int64, error)
+}
+
+type SyncRepository interface {
+	// GetChanges returns up to limit last changes of the user's entities made after the one numbered since
+	GetChanges(ctx context.Context, userID uuid.UUID, since int64, limit int) ([]domain.SyncChange, error)
+}
+
+type EventLogRepository interface {
+	// Append logs the event and publishes it to the listeners on all replicas
+	Append(ctx context.Context, event domain.Event) (domain.LoggedEvent, error)
+	// GetAfter returns the logged events of the user following the one with afterID.
+	// ok is false if that event is no longer in the log, so the events missed since it cannot be told
+	GetAfter(ctx context.Context, userID uuid.UUID, afterID string) (events []domain.LoggedEvent, ok bool, err error)
+	// Listen passes the events appended by any replica to fn until ctx is done
+	Listen(ctx context.Context, fn func(event domain.LoggedEvent)) error
+}
+
+type ImportsRepository interface {
+	Save(ctx context.Context, imp *domain.Import) error
+	Get(ctx context.Context, userID, id uuid.UUID) (domain.Import, error)
+	// Update domain.Import Status, progress counters, Errors, Error and FinishedAt by ID
+	Update(ctx context.Context, imp *domain.Import) error
+}
+
+type ExportsRepository interface {
+	Save(ctx context.Context, export *domain.Export) error
+	Get(ctx context.Context, userID, id uuid.UUID) (domain.Export, error)
+	// Update domain.Export Status, Size, Error and FinishedAt by ID
+	Update(ctx context.Context, export *domain.Export) error
+}
+
 type Repositories struct {
-	Users  UsersRepository
-	Tokens TokensRepository
+	Users         UsersRepository
+	Tokens        TokensRepository
+	Links         LinksRepository
+	Lists         ListsRepository
+	Members       ListMembersRepository
+	Invitations   ListInvitationsRepository
+	Activities    ListActivitiesRepository
+	Shares        ListSharesRepository
+	Tags          TagsRepository
+	Highlights    HighlightsRepository
+	Notes         NotesRepository
+	Reading       ReadingStatesRepository
+	Webhooks      WebhooksRepository
+	Deliveries    WebhookDeliveriesRepository
+	EventLog      EventLogRepository
+	Sync          SyncRepository
+	Feeds         FeedsRepository
+	Subscriptions SubscriptionsRepository
+	LinkChecks    LinkChecksRepository
+	Snapshots     SnapshotsRepository
+	Imports       ImportsRepository
+	Exports       ExportsRepository
 }
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/internal/repository"
+	"github.com/adanyl0v/go-pocket-link/pkg/annotation"
+	"github.com/adanyl0v/go-pocket-link/pkg/pagination"
+	"github.com/google/uuid"
+	"io"
+	"strings"
+)
+
+const defaultHighlightColor = "yellow"
+
+type AnnotationsService struct {
+	highlights repository.HighlightsRepository
+	notes      repository.NotesRepository
+	links      repository.LinksRepository
+}
+
+func NewAnnotationsService(highlights repository.HighlightsRepository, notes repository.NotesRepository,
+	links repository.LinksRepository) *AnnotationsService {
+	return &AnnotationsService{
+		highlights: highlights,
+		notes:      notes,
+		links:      links,
+	}
+}
+
+func (s *AnnotationsService) SaveHighlight(ctx context.Context, highlight *domain.Highlight) error {
+	if err := validateHighlight(highlight); err != nil {
+		return err
+	}
+
+	if _, err := s.links.Get(ctx, highlight.UserID, highlight.LinkID); err != nil {
+		return err
+	}
+	return s.highlights.Save(ctx, highlight)
+}
+
+// GetHighlight returns the highlight if it belongs to the user's link
+func (s *AnnotationsService) GetHighlight(ctx context.Context, userID, linkID, id uuid.UUID) (domain.Highlight, error) {
+	highlight, err := s.highlights.Get(ctx, userID, id)
+	if err != nil {
+		return domain.Highlight{}, err
+	} else if highlight.LinkID != linkID {
+		return domain.Highlight{}, domain.ErrHighlightNotFound
+	}
+	return highlight, nil
+}
+
+func (s *AnnotationsService) GetHighlights(ctx context.Context, userID, linkID uuid.UUID) ([]domain.Highlight, error) {
+	if _, err := s.links.Get(ctx, userID, linkID); err != nil {
+		return nil, err
+	}
+	return s.highlights.GetByLinkID(ctx, userID, linkID)
+}
+
+func (s *AnnotationsService) GetHighlightsPage(ctx context.Context, userID, linkID uuid.UUID, params pagination.Params) (pagination.Page[domain.Highlight], error) {
+	if _, err := s.links.Get(ctx, userID, linkID); err != nil {
+		return pagination.Page[domain.Highlight]{}, err
+	}
+	return s.highlights.GetPageByLinkID(ctx, userID, linkID, params)
+}
+
+func (s *AnnotationsService) UpdateHighlight(ctx context.Context, highlight *domain.Highlight) error {
+	if err := validateHighlight(highlight); err != nil {
+		return err
+	}
+	return s.highlights.Update(ctx, highlight)
+}
+
+func (s *AnnotationsService) DeleteHighlight(ctx context.Context, userID, linkID, id uuid.UUID) error {
+	if _, err := s.GetHighlight(ctx, userID, linkID, id); err != nil {
+		return err
+	}
+	return s.highlights.Delete(ctx, userID, id)
+}
+
+// ReanchorHighlights finds the highlights of the link in its new text, updating the positions
+// of the ones that have moved. It returns the highlights that could not be found anymore
+func (s *AnnotationsService) ReanchorHighlights(ctx context.Context, userID, linkID uuid.UUID, text string) ([]domain.Highlight, error) {
+	highlights, err := s.GetHighlights(ctx, userID, linkID)
+	if err != nil {
+		return nil, err
+	}
+
+	orphans := make([]domain.Highlight, 0)
+	for i := range highlights {
+		h := &highlights[i]
+		position, ok := annotation.Anchor(text,
+			annotation.TextQuoteSelector{Exact: h.Exact, Prefix: h.Prefix, Suffix: h.Suffix},
+			annotation.TextPositionSelector{Start: h.Start, End: h.End})
+		if !ok {
+			orphans = append(orphans, *h)
+			continue
+		} else if position.Start == h.Start {
+			continue
+		}
+
+		quote, position, err := annotation.Describe(text, position.Start, position.End, annotation.DefaultContextLength)
+		if err != nil {
+			return nil, err
+		}
+		h.Prefix, h.Suffix = quote.Prefix, quote.Suffix
+		h.Start, h.End = position.Start, position.End
+		if err = s.highlights.Update(ctx, h); err != nil {
+			return nil, err
+		}
+	}
+	return orphans, nil
+}
+
+func (s *AnnotationsService) SaveNote(ctx context.Context, note *domain.Note) error {
+	if err := validateNote(note); err != nil {
+		return err
+	}
+
+	if _, err := s.links.Get(ctx, note.UserID, note.LinkID); err != nil {
+		return err
+	}
+
+	if note.HighlightID != nil {
+		if _, err := s.GetHighlight(ctx, note.UserID, note.LinkID, *note.HighlightID); err != nil {
+			return err
+		}
+	}
+	return s.notes.Save(ctx, note)
+}
+
+// GetNote returns the note if it belongs to the user's link
+func (s *AnnotationsService) GetNote(ctx context.Context, userID, linkID, id uuid.UUID) (domain.Note, error) {
+	note, err := s.notes.Get(ctx, userID, id)
+	if err != nil {
+		return domain.Note{}, err
+	} else if note.LinkID != linkID {
+		return domain.Note{}, domain.ErrNoteNotFound
+	}
+	return note, nil
+}
+
+func (s *AnnotationsService) GetNotesPage(ctx context.Context, userID, linkID uuid.UUID, params pagination.Params) (pagination.Page[domain.Note], error) {
+	if _, err := s.links.Get(ctx, userID, linkID); err != nil {
+		return pagination.Page[domain.Note]{}, err
+	}
+	return s.notes.GetPageByLinkID(ctx, userID, linkID, params)
+}
+
+func (s *AnnotationsService) UpdateNote(ctx context.Context, note *domain.Note) error {
+	if err := validateNote(note); err != nil {
+		return err
+	}
+	return s.notes.Update(ctx, note)
+}
+
+func (s *AnnotationsService) DeleteNote(ctx context.Context, userID, linkID, id uuid.UUID) error {
+	if _, err := s.GetNote(ctx, userID, linkID, id); err != nil {
+		return err
+	}
+	return s.notes.Delete(ctx, userID, id)
+}
+
+// WriteMarkdown writes the link's notes and highlights, each followed by its notes, as Markdown
+func (s *AnnotationsService) WriteMarkdown(ctx context.Context, userID, linkID uuid.UUID, w io.Writer) error {
+	link, err := s.links.Get(ctx, userID, linkID)
+	if err != nil {
+		return err
+	}
+
+	highlights, err := s.highlights.GetByLinkID(ctx, userID, linkID)
+	if err != nil {
+		return err
+	}
+
+	notes, err := s.notes.GetByLinkID(ctx, userID, linkID)
+	if err != nil {
+		return err
+	}
+
+	var (
+		linkNotes      = make([]domain.Note, 0)
+		highlightNotes = make(map[uuid.UUID][]domain.Note)
+	)
+	for _, note := range notes {
+		if note.HighlightID == nil {
+			linkNotes = append(linkNotes, note)
+		} else {
+			highlightNotes[*note.HighlightID] = append(highlightNotes[*note.HighlightID], note)
+		}
+	}
+
+	title := link.Title
+	if title == "" {
+		title = link.URL
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n\n<%s>\n", title, link.URL)
+
+	if len(linkNotes) > 0 {
+		sb.WriteString("\n## Notes\n")
+		for _, note := range linkNotes {
+			fmt.Fprintf(&sb, "\n%s\n", strings.TrimSpace(note.Body))
+		}
+	}
+
+	if len(highlights) > 0 {
+		sb.WriteString("\n## Highlights\n")
+		for _, highlight := range highlights {
+			fmt.Fprintf(&sb, "\n%s\n", markdownQuote(highlight.Exact))
+			for _, note := range highlightNotes[highlight.ID] {
+				fmt.Fprintf(&sb, "\n%s\n", strings.TrimSpace(note.Body))
+			}
+		}
+	}
+
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+func markdownQuote(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("> "+line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func validateHighlight(highlight *domain.Highlight) error {
+	if highlight.Color == "" {
+		highlight.Color = defaultHighlightColor
+	}
+
+	err := annotation.Validate(
+		annotation.TextQuoteSelector{Exact: highlight.Exact, Prefix: highlight.Prefix, Suffix: highlight.Suffix},
+		annotation.TextPositionSelector{Start: highlight.Start, End: highlight.End})
+	if err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrInvalidHighlight, err)
+	}
+	return nil
+}
+
+func validateNote(note *domain.Note) error {
+	if strings.TrimSpace(note.Body) == "" {
+		return domain.ErrInvalidNote
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/pkg/blob"
+	"net/url"
+)
+
+// BlobsService serves the blobs of the signed urls for the stores which cannot serve them themselves,
+// e.g. the filesystem one
+type BlobsService struct {
+	store blob.Store
+	// signer is nil if the urls are not signed by the app, in which case no blob is served
+	signer *blob.URLSigner
+}
+
+func NewBlobsService(store blob.Store, signer *blob.URLSigner) *BlobsService {
+	return &BlobsService{
+		store:  store,
+		signer: signer,
+	}
+}
+
+// Open opens the blob of the signed url, given the key and the query of the url. The caller must close it
+func (s *BlobsService) Open(ctx context.Context, key string, query url.Values) (*blob.Object, error) {
+	if s.signer == nil || blob.ValidateKey(key) != nil {
+		return nil, domain.ErrBlobNotFound
+	}
+	if err := s.signer.Verify(key, query); err != nil {
+		return nil, domain.ErrInvalidBlobURL
+	}
+
+	object, err := s.store.Get(ctx, key)
+	if errors.Is(err, blob.ErrNotFound) {
+		return nil, domain.ErrBlobNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return object, nil
+}
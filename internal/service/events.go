@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"log/slog"
+)
+
+// EventHandler reacts to the events published by the services
+type EventHandler interface {
+	HandleEvent(ctx context.Context, event domain.Event) error
+}
+
+// EventBus passes the published events to the subscribed handlers synchronously
+type EventBus struct {
+	handlers []EventHandler
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+// Subscribe must be called before the events are published, since the handlers are not guarded
+func (b *EventBus) Subscribe(handler EventHandler) {
+	b.handlers = append(b.handlers, handler)
+}
+
+// Publish logs the errors of the handlers instead of returning them, so the change
+// the event is about does not fail after it has been made
+func (b *EventBus) Publish(ctx context.Context, event domain.Event) {
+	for _, handler := range b.handlers {
+		if err := handler.HandleEvent(ctx, event); err != nil {
+			slog.ErrorContext(ctx, "failed to handle event", "event_id", event.ID, "type", event.Type, logError, err)
+		}
+	}
+}
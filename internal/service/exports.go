@@ -0,0 +1,429 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/internal/repository"
+	"github.com/adanyl0v/go-pocket-link/pkg/blob"
+	"github.com/adanyl0v/go-pocket-link/pkg/bookmarks"
+	"github.com/adanyl0v/go-pocket-link/pkg/jobs"
+	"github.com/google/uuid"
+	"io"
+	"log/slog"
+	"time"
+)
+
+const (
+	exportProfileFile    = "profile.json"
+	exportLinksFile      = "links.json"
+	exportListsFile      = "lists.json"
+	exportTagsFile       = "tags.json"
+	exportHighlightsFile = "highlights.json"
+	exportNotesFile      = "notes.json"
+	exportBookmarksFile  = "bookmarks.html"
+	exportCSVFile        = "links.csv"
+)
+
+const (
+	// exportsBlobPrefix is where the archives of the async exports are kept in the blob store
+	exportsBlobPrefix  = "exports/"
+	exportsContentType = "application/zip"
+	// exportURLTTL is how long the signed download urls of the archives are valid for
+	exportURLTTL = 15 * time.Minute
+)
+
+type ExportsService struct {
+	repo  repository.ExportsRepository
+	users repository.UsersRepository
+	links repository.LinksRepository
+	lists repository.ListsRepository
+	tags  repository.TagsRepository
+	// highlights and notes are exported along with the links they belong to
+	highlights repository.HighlightsRepository
+	notes      repository.NotesRepository
+	// store keeps the archives of the async exports for ttl
+	store blob.Store
+	ttl   time.Duration
+	queue jobs.Queue
+}
+
+func NewExportsService(repo repository.ExportsRepository, users repository.UsersRepository, links repository.LinksRepository,
+	lists repository.ListsRepository, tags repository.TagsRepository, highlights repository.HighlightsRepository,
+	notes repository.NotesRepository, store blob.Store, ttl time.Duration, queue jobs.Queue) *ExportsService {
+	return &ExportsService{
+		repo:       repo,
+		users:      users,
+		links:      links,
+		lists:      lists,
+		tags:       tags,
+		highlights: highlights,
+		notes:      notes,
+		store:      store,
+		ttl:        ttl,
+		queue:      queue,
+	}
+}
+
+// Write streams a zip archive with all the user's data to w
+func (s *ExportsService) Write(ctx context.Context, userID uuid.UUID, w io.Writer) error {
+	zw := zip.NewWriter(w)
+
+	sections := []struct {
+		name  string
+		write func(ctx context.Context, userID uuid.UUID, w io.Writer) error
+	}{
+		{exportProfileFile, s.writeProfile},
+		{exportLinksFile, s.writeLinks},
+		{exportListsFile, s.writeLists},
+		{exportTagsFile, s.writeTags},
+		{exportHighlightsFile, s.writeHighlights},
+		{exportNotesFile, s.writeNotes},
+		{exportBookmarksFile, s.writeBookmarks},
+		{exportCSVFile, s.writeCSV},
+	}
+	for _, section := range sections {
+		fw, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     section.name,
+			Method:   zip.Deflate,
+			Modified: time.Now(),
+		})
+		if err != nil {
+			return errWritingExport(section.name, err)
+		}
+
+		if err = section.write(ctx, userID, fw); err != nil {
+			return errWritingExport(section.name, err)
+		}
+	}
+
+	return zw.Close()
+}
+
+// exportJob is the payload of the export jobs
+type exportJob struct {
+	ExportID uuid.UUID `json:"export_id"`
+	UserID   uuid.UUID `json:"user_id"`
+}
+
+// Start saves a pending export and queues a job writing its archive
+func (s *ExportsService) Start(ctx context.Context, userID uuid.UUID) (domain.Export, error) {
+	export := domain.Export{
+		UserID:    userID,
+		Status:    domain.ExportStatusPending,
+		ExpiresAt: time.Now().Add(s.ttl),
+	}
+	if err := s.repo.Save(ctx, &export); err != nil {
+		return domain.Export{}, err
+	}
+
+	_, err := jobs.EnqueueJSON(ctx, s.queue, JobRunExport, exportJob{ExportID: export.ID, UserID: userID},
+		jobs.Unique("export:"+export.ID.String()))
+	if err != nil {
+		return domain.Export{}, err
+	}
+	return export, nil
+}
+
+func (s *ExportsService) Get(ctx context.Context, userID, id uuid.UUID) (domain.Export, error) {
+	return s.repo.Get(ctx, userID, id)
+}
+
+// Open opens the archive of a completed export. The caller must close it
+func (s *ExportsService) Open(ctx context.Context, userID, id uuid.UUID) (*blob.Object, domain.Export, error) {
+	export, err := s.getDownloadable(ctx, userID, id)
+	if err != nil {
+		return nil, domain.Export{}, err
+	}
+
+	object, err := s.store.Get(ctx, exportKey(export.ID))
+	if errors.Is(err, blob.ErrNotFound) {
+		return nil, domain.Export{}, domain.ErrExportExpired
+	} else if err != nil {
+		return nil, domain.Export{}, err
+	}
+	return object, export, nil
+}
+
+// SignedURL returns a short-lived url the archive of a completed export can be downloaded from directly,
+// empty if the blob store cannot sign them
+func (s *ExportsService) SignedURL(ctx context.Context, userID, id uuid.UUID) (string, error) {
+	export, err := s.getDownloadable(ctx, userID, id)
+	if err != nil {
+		return "", err
+	}
+
+	signedURL, err := s.store.SignedURL(ctx, exportKey(export.ID), min(exportURLTTL, time.Until(export.ExpiresAt)))
+	if errors.Is(err, blob.ErrNotSupported) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	return signedURL, nil
+}
+
+// DeleteExpired deletes the archives older than the ttl of the exports. It is run by the scheduler,
+// returning how many archives have been deleted
+func (s *ExportsService) DeleteExpired(ctx context.Context) (int64, error) {
+	var deleted int64
+	before := time.Now().Add(-s.ttl)
+	err := s.store.List(ctx, exportsBlobPrefix, func(info blob.Info) error {
+		if info.ModifiedAt.After(before) {
+			return nil
+		}
+		if err := s.store.Delete(ctx, info.Key); err != nil {
+			return err
+		}
+		deleted++
+		return nil
+	})
+	return deleted, err
+}
+
+func (s *ExportsService) getDownloadable(ctx context.Context, userID, id uuid.UUID) (domain.Export, error) {
+	export, err := s.repo.Get(ctx, userID, id)
+	if err != nil {
+		return domain.Export{}, err
+	} else if export.Status != domain.ExportStatusCompleted {
+		return domain.Export{}, domain.ErrExportNotReady
+	} else if time.Now().After(export.ExpiresAt) {
+		return domain.Export{}, domain.ErrExportExpired
+	}
+	return export, nil
+}
+
+// HandleJob writes the archive of the export of the job. The export is only failed by its last attempt
+func (s *ExportsService) HandleJob(ctx context.Context, job jobs.Job) error {
+	var payload exportJob
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+
+	export, err := s.repo.Get(ctx, payload.UserID, payload.ExportID)
+	if errors.Is(err, domain.ErrExportNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	} else if export.FinishedAt != nil {
+		return nil
+	}
+
+	size, err := s.writeFile(ctx, export)
+	if err != nil && willRetry(job, err) {
+		return err
+	}
+
+	now := time.Now()
+	export.FinishedAt = &now
+	if err != nil {
+		export.Status = domain.ExportStatusFailed
+		export.Error = err.Error()
+		slog.ErrorContext(ctx, "failed to export", "export_id", export.ID, logError, err)
+	} else {
+		export.Status = domain.ExportStatusCompleted
+		export.Size = size
+	}
+	return s.repo.Update(context.WithoutCancel(ctx), &export)
+}
+
+// writeFile streams the archive to the blob store, which never serves it partially written
+func (s *ExportsService) writeFile(ctx context.Context, export domain.Export) (int64, error) {
+	pr, pw := io.Pipe()
+	counter := &countingWriter{w: pw}
+	go func() {
+		_ = pw.CloseWithError(s.Write(ctx, export.UserID, counter))
+	}()
+
+	if err := s.store.Put(ctx, exportKey(export.ID), pr, exportsContentType); err != nil {
+		// unblocks the writing of the archive if the store has given up reading it
+		_ = pr.CloseWithError(err)
+		return 0, err
+	}
+	return counter.n, nil
+}
+
+func exportKey(id uuid.UUID) string {
+	return exportsBlobPrefix + id.String() + ".zip"
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+func (s *ExportsService) writeProfile(ctx context.Context, userID uuid.UUID, w io.Writer) error {
+	user, err := s.users.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	// the password hash is deliberately left out
+	profile := struct {
+		ID        uuid.UUID `json:"id"`
+		Name      string    `json:"name"`
+		Email     string    `json:"email"`
+		CreatedAt time.Time `json:"created_at"`
+		UpdatedAt time.Time `json:"updated_at"`
+	}{user.ID, user.Name, user.Email, user.CreatedAt, user.UpdatedAt}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(profile)
+}
+
+func (s *ExportsService) writeLinks(ctx context.Context, userID uuid.UUID, w io.Writer) error {
+	aw := newJSONArrayWriter(w)
+	if err := s.links.Each(ctx, userID, func(link domain.Link) error {
+		return aw.Write(link)
+	}); err != nil {
+		return err
+	}
+	return aw.Close()
+}
+
+func (s *ExportsService) writeLists(ctx context.Context, userID uuid.UUID, w io.Writer) error {
+	aw := newJSONArrayWriter(w)
+	if err := s.lists.EachWithLinkIDs(ctx, userID, func(list domain.List, linkIDs []uuid.UUID) error {
+		return aw.Write(struct {
+			domain.List
+			LinkIDs []uuid.UUID `json:"link_ids"`
+		}{list, linkIDs})
+	}); err != nil {
+		return err
+	}
+	return aw.Close()
+}
+
+func (s *ExportsService) writeTags(ctx context.Context, userID uuid.UUID, w io.Writer) error {
+	tags, err := s.tags.GetAll(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	aw := newJSONArrayWriter(w)
+	for _, tag := range tags {
+		if err = aw.Write(tag); err != nil {
+			return err
+		}
+	}
+	return aw.Close()
+}
+
+func (s *ExportsService) writeHighlights(ctx context.Context, userID uuid.UUID, w io.Writer) error {
+	aw := newJSONArrayWriter(w)
+	if err := s.highlights.Each(ctx, userID, func(highlight domain.Highlight) error {
+		return aw.Write(highlight)
+	}); err != nil {
+		return err
+	}
+	return aw.Close()
+}
+
+func (s *ExportsService) writeNotes(ctx context.Context, userID uuid.UUID, w io.Writer) error {
+	aw := newJSONArrayWriter(w)
+	if err := s.notes.Each(ctx, userID, func(note domain.Note) error {
+		return aw.Write(note)
+	}); err != nil {
+		return err
+	}
+	return aw.Close()
+}
+
+func (s *ExportsService) writeBookmarks(ctx context.Context, userID uuid.UUID, w io.Writer) error {
+	nw := bookmarks.NewNetscapeWriter(w)
+
+	currentList := ""
+	if err := s.links.EachByList(ctx, userID, func(list string, link domain.Link) error {
+		if list != currentList {
+			if currentList != "" {
+				if err := nw.EndFolder(); err != nil {
+					return err
+				}
+			}
+			if err := nw.StartFolder(list); err != nil {
+				return err
+			}
+			currentList = list
+		}
+		return nw.Write(linkToBookmark(link, nil))
+	}); err != nil {
+		return err
+	}
+	return nw.Close()
+}
+
+func (s *ExportsService) writeCSV(ctx context.Context, userID uuid.UUID, w io.Writer) error {
+	cw := bookmarks.NewCSVWriter(w)
+	if err := s.links.EachByList(ctx, userID, func(list string, link domain.Link) error {
+		var folders []string
+		if list != "" {
+			folders = []string{list}
+		}
+		return cw.Write(linkToBookmark(link, folders))
+	}); err != nil {
+		return err
+	}
+	return cw.Close()
+}
+
+func linkToBookmark(link domain.Link, folders []string) bookmarks.Bookmark {
+	return bookmarks.Bookmark{
+		URL:     link.URL,
+		Title:   link.Title,
+		Tags:    link.Tags,
+		Folders: folders,
+		AddedAt: link.CreatedAt,
+	}
+}
+
+// jsonArrayWriter streams the values as a json array, one element per line
+type jsonArrayWriter struct {
+	w     io.Writer
+	count int
+}
+
+func newJSONArrayWriter(w io.Writer) *jsonArrayWriter {
+	return &jsonArrayWriter{w: w}
+}
+
+func (aw *jsonArrayWriter) Write(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	prefix := ",\n"
+	if aw.count == 0 {
+		prefix = "[\n"
+	}
+	aw.count++
+
+	if _, err = io.WriteString(aw.w, prefix); err != nil {
+		return err
+	}
+	_, err = aw.w.Write(data)
+	return err
+}
+
+func (aw *jsonArrayWriter) Close() error {
+	closing := "\n]\n"
+	if aw.count == 0 {
+		closing = "[]\n"
+	}
+	_, err := io.WriteString(aw.w, closing)
+	return err
+}
+
+func errWritingExport(section string, err error) error {
+	return fmt.Errorf("%w (writing %s)", err, section)
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/internal/repository"
+	"github.com/adanyl0v/go-pocket-link/pkg/feed"
+	"github.com/google/uuid"
+	"time"
+)
+
+type FeedsOptions struct {
+	// BaseURL is where the feeds are served, with the token and the format appended
+	BaseURL string
+	// HomeURL is the page of the service the feeds link to
+	HomeURL    string
+	ItemsLimit int
+}
+
+type FeedsService struct {
+	repo     repository.FeedsRepository
+	tagsRepo repository.TagsRepository
+	lists    *ListsService
+	opts     FeedsOptions
+}
+
+func NewFeedsService(repo repository.FeedsRepository, tagsRepo repository.TagsRepository, lists *ListsService,
+	opts FeedsOptions) *FeedsService {
+	return &FeedsService{
+		repo:     repo,
+		tagsRepo: tagsRepo,
+		lists:    lists,
+		opts:     opts,
+	}
+}
+
+// EnableListFeed creates the feed of the list the user can view, or replaces the token of the existing one
+func (s *FeedsService) EnableListFeed(ctx context.Context, userID, listID uuid.UUID) (domain.Feed, error) {
+	if _, err := s.lists.Get(ctx, userID, listID); err != nil {
+		return domain.Feed{}, err
+	}
+	return s.save(ctx, domain.Feed{UserID: userID, ListID: &listID})
+}
+
+func (s *FeedsService) GetListFeed(ctx context.Context, userID, listID uuid.UUID) (domain.Feed, error) {
+	return s.repo.GetByListID(ctx, userID, listID)
+}
+
+func (s *FeedsService) DisableListFeed(ctx context.Context, userID, listID uuid.UUID) error {
+	f, err := s.repo.GetByListID(ctx, userID, listID)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, userID, f.ID)
+}
+
+// EnableTagFeed creates the feed of the tag, or replaces the token of the existing one
+func (s *FeedsService) EnableTagFeed(ctx context.Context, userID uuid.UUID, name string) (domain.Feed, error) {
+	tag, err := s.getTag(ctx, userID, name)
+	if err != nil {
+		return domain.Feed{}, err
+	}
+	return s.save(ctx, domain.Feed{UserID: userID, TagID: &tag.ID})
+}
+
+func (s *FeedsService) GetTagFeed(ctx context.Context, userID uuid.UUID, name string) (domain.Feed, error) {
+	tag, err := s.getTag(ctx, userID, name)
+	if err != nil {
+		return domain.Feed{}, err
+	}
+	return s.repo.GetByTagID(ctx, userID, tag.ID)
+}
+
+func (s *FeedsService) DisableTagFeed(ctx context.Context, userID uuid.UUID, name string) error {
+	f, err := s.GetTagFeed(ctx, userID, name)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, userID, f.ID)
+}
+
+// Resolve returns the feed with the token and the time its content last changed, so the unchanged feeds
+// are not built again. The feeds of the lists their users can no longer view are not found
+func (s *FeedsService) Resolve(ctx context.Context, token string) (domain.Feed, time.Time, error) {
+	f, err := s.repo.GetByTokenHash(ctx, hashSecretToken(token))
+	if err != nil {
+		return domain.Feed{}, time.Time{}, err
+	}
+
+	var modifiedAt time.Time
+	if f.ListID != nil {
+		if _, err = s.lists.Get(ctx, f.UserID, *f.ListID); errors.Is(err, domain.ErrListPermissionDenied) {
+			return domain.Feed{}, time.Time{}, domain.ErrFeedNotFound
+		} else if err != nil {
+			return domain.Feed{}, time.Time{}, err
+		}
+		modifiedAt, err = s.repo.GetListModifiedAt(ctx, *f.ListID)
+	} else {
+		modifiedAt, err = s.repo.GetLinksModifiedAt(ctx, f.UserID)
+	}
+	if err != nil {
+		return domain.Feed{}, time.Time{}, err
+	}
+
+	if f.UpdatedAt.After(modifiedAt) {
+		modifiedAt = f.UpdatedAt
+	}
+	return f, modifiedAt, nil
+}
+
+// Build returns the latest links of the feed resolved by the token
+func (s *FeedsService) Build(ctx context.Context, token string, f domain.Feed, format feed.Format, modifiedAt time.Time) (feed.Feed, error) {
+	var title, description string
+	var links []domain.FeedLink
+	var err error
+	if f.ListID != nil {
+		var list domain.List
+		if list, err = s.lists.Get(ctx, f.UserID, *f.ListID); err != nil {
+			return feed.Feed{}, err
+		}
+		title, description = list.Title, fmt.Sprintf("Links of the %s list", list.Title)
+		links, err = s.repo.GetListLinks(ctx, *f.ListID, s.opts.ItemsLimit)
+	} else {
+		var tag domain.Tag
+		if tag, err = s.tagsRepo.GetByID(ctx, f.UserID, *f.TagID); err != nil {
+			return feed.Feed{}, err
+		}
+		title, description = "#"+tag.Name, fmt.Sprintf("Links tagged with %s", tag.Name)
+		links, err = s.repo.GetTagLinks(ctx, *f.TagID, s.opts.ItemsLimit)
+	}
+	if err != nil {
+		return feed.Feed{}, err
+	}
+
+	tagged := make([]domain.Link, 0, len(links))
+	for _, link := range links {
+		tagged = append(tagged, link.Link)
+	}
+	if err = attachTags(ctx, s.tagsRepo, tagged); err != nil {
+		return feed.Feed{}, err
+	}
+
+	out := feed.Feed{
+		ID:          "urn:uuid:" + f.ID.String(),
+		Title:       title,
+		Description: description,
+		HomeURL:     s.opts.HomeURL,
+		FeedURL:     s.feedURL(token, format),
+		Updated:     modifiedAt,
+		Items:       make([]feed.Item, 0, len(links)),
+	}
+	for i, link := range links {
+		updated := link.UpdatedAt
+		if link.AddedAt.After(updated) {
+			updated = link.AddedAt
+		}
+
+		out.Items = append(out.Items, feed.Item{
+			ID:        "urn:uuid:" + link.ID.String(),
+			URL:       link.URL,
+			Title:     link.Title,
+			Tags:      tagged[i].Tags,
+			Published: link.AddedAt,
+			Updated:   updated,
+		})
+	}
+
+	if err = s.repo.UpdateAccessedAt(ctx, f.ID, time.Now()); err != nil {
+		return feed.Feed{}, err
+	}
+	return out, nil
+}
+
+func (s *FeedsService) save(ctx context.Context, f domain.Feed) (domain.Feed, error) {
+	token, err := newSecretToken()
+	if err != nil {
+		return domain.Feed{}, err
+	}
+
+	f.TokenHash = hashSecretToken(token)
+	if err = s.repo.Save(ctx, &f); err != nil {
+		return domain.Feed{}, err
+	}
+
+	f.URLs = make(map[string]string, len(feed.Formats))
+	for _, format := range feed.Formats {
+		f.URLs[string(format)] = s.feedURL(token, format)
+	}
+	return f, nil
+}
+
+func (s *FeedsService) getTag(ctx context.Context, userID uuid.UUID, name string) (domain.Tag, error) {
+	name, err := normalizeTagName(name)
+	if err != nil {
+		return domain.Tag{}, err
+	}
+	return s.tagsRepo.GetByName(ctx, userID, name)
+}
+
+func (s *FeedsService) feedURL(token string, format feed.Format) string {
+	return s.opts.BaseURL + token + "/" + string(format)
+}
@@ -0,0 +1,286 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/internal/repository"
+	"github.com/adanyl0v/go-pocket-link/pkg/blob"
+	"github.com/adanyl0v/go-pocket-link/pkg/bookmarks"
+	"github.com/adanyl0v/go-pocket-link/pkg/jobs"
+	"github.com/google/uuid"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const (
+	// importProgressInterval is how often the progress of a running import is persisted
+	importProgressInterval = time.Second
+	// maxImportErrors limits the size of the per-item error report
+	maxImportErrors = 1000
+
+	importFolderSeparator = " / "
+
+	// importsBlobPrefix is where the uploaded sources are kept in the blob store until they are imported
+	importsBlobPrefix = "imports/"
+)
+
+// importContentTypes are the media types the sources are stored with
+var importContentTypes = map[bookmarks.Format]string{
+	bookmarks.FormatNetscape:   "text/html; charset=utf-8",
+	bookmarks.FormatPocketHTML: "text/html; charset=utf-8",
+	bookmarks.FormatPocketCSV:  "text/csv; charset=utf-8",
+	bookmarks.FormatCSV:        "text/csv; charset=utf-8",
+	bookmarks.FormatPinboard:   "application/json",
+}
+
+type ImportsService struct {
+	repo    repository.ImportsRepository
+	links   *LinksService
+	lists   *ListsService
+	reading *ReadingService
+	store   blob.Store
+	queue   jobs.Queue
+}
+
+func NewImportsService(repo repository.ImportsRepository, links *LinksService, lists *ListsService, reading *ReadingService,
+	store blob.Store, queue jobs.Queue) *ImportsService {
+	return &ImportsService{
+		repo:    repo,
+		links:   links,
+		lists:   lists,
+		reading: reading,
+		store:   store,
+		queue:   queue,
+	}
+}
+
+// importJob is the payload of the import jobs. The source is kept in the blob store under the key
+type importJob struct {
+	ImportID uuid.UUID        `json:"import_id"`
+	UserID   uuid.UUID        `json:"user_id"`
+	Format   bookmarks.Format `json:"format"`
+	Key      string           `json:"key"`
+}
+
+// Start saves a pending import and queues a job running it. If format is empty,
+// it is detected by the contents of data
+func (s *ImportsService) Start(ctx context.Context, userID uuid.UUID, format string, data []byte) (domain.Import, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return domain.Import{}, domain.ErrEmptyImportSource
+	}
+
+	f, err := parseImportFormat(format, data)
+	if err != nil {
+		return domain.Import{}, err
+	}
+
+	imp := domain.Import{
+		UserID: userID,
+		Format: string(f),
+		Status: domain.ImportStatusPending,
+		Errors: domain.ImportErrors{},
+	}
+	if err = s.repo.Save(ctx, &imp); err != nil {
+		return domain.Import{}, err
+	}
+
+	key := importKey(imp.ID)
+	if err = s.store.Put(ctx, key, bytes.NewReader(data), importContentTypes[f]); err != nil {
+		s.finish(context.WithoutCancel(ctx), &imp, err)
+		return domain.Import{}, err
+	}
+
+	_, err = jobs.EnqueueJSON(ctx, s.queue, JobRunImport, importJob{ImportID: imp.ID, UserID: userID, Format: f, Key: key},
+		jobs.Unique("import:"+imp.ID.String()))
+	if err != nil {
+		s.finish(context.WithoutCancel(ctx), &imp, err)
+		if deleteErr := s.store.Delete(context.WithoutCancel(ctx), key); deleteErr != nil {
+			slog.ErrorContext(ctx, "failed to delete import source", "import_id", imp.ID, logError, deleteErr)
+		}
+		return domain.Import{}, err
+	}
+	return imp, nil
+}
+
+func (s *ImportsService) Get(ctx context.Context, userID, id uuid.UUID) (domain.Import, error) {
+	return s.repo.Get(ctx, userID, id)
+}
+
+// HandleJob runs the import of the job. The import is only failed by its last attempt, the interrupted
+// ones being started over, since saving the already imported links again is harmless
+func (s *ImportsService) HandleJob(ctx context.Context, job jobs.Job) error {
+	var payload importJob
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+
+	imp, err := s.repo.Get(ctx, payload.UserID, payload.ImportID)
+	if errors.Is(err, domain.ErrImportNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	} else if imp.FinishedAt != nil {
+		return s.store.Delete(ctx, payload.Key)
+	}
+
+	data, err := s.readSource(ctx, payload.Key)
+	if errors.Is(err, blob.ErrNotFound) {
+		s.finish(ctx, &imp, err)
+		return nil
+	} else if err == nil {
+		err = s.run(ctx, imp, payload.Format, data)
+	}
+	if err != nil && willRetry(job, err) {
+		return err
+	} else if err != nil {
+		s.finish(context.WithoutCancel(ctx), &imp, err)
+	}
+	// the source is only needed by the retries
+	if deleteErr := s.store.Delete(context.WithoutCancel(ctx), payload.Key); deleteErr != nil {
+		slog.ErrorContext(ctx, "failed to delete import source", "import_id", imp.ID, logError, deleteErr)
+	}
+	return err
+}
+
+func (s *ImportsService) readSource(ctx context.Context, key string) ([]byte, error) {
+	object, err := s.store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = object.Close() }()
+	return io.ReadAll(object.Body)
+}
+
+// run imports the items, recording the failures of the single items in the import. Only the errors
+// interrupting the whole import are returned
+func (s *ImportsService) run(ctx context.Context, imp domain.Import, format bookmarks.Format, data []byte) error {
+	logger := slog.With("import_id", imp.ID, "user_id", imp.UserID)
+
+	items, err := bookmarks.Parse(format, bytes.NewReader(data))
+	if err != nil {
+		s.finish(ctx, &imp, err)
+		logger.Error("failed to parse import", logError, err)
+		return nil
+	}
+
+	imp.Status = domain.ImportStatusRunning
+	imp.Total = len(items)
+	imp.Processed, imp.Imported, imp.Duplicates, imp.Failed = 0, 0, 0, 0
+	imp.Errors = domain.ImportErrors{}
+	s.update(ctx, &imp)
+
+	lists := make(map[string]uuid.UUID)
+	lastUpdate := time.Now()
+	for i, item := range items {
+		// the items which could not be parsed entirely are reported rather than imported without their fields
+		created, err := false, item.Err
+		if err == nil {
+			created, err = s.importItem(ctx, imp.UserID, item, lists)
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		switch {
+		case err != nil:
+			imp.Failed++
+			if len(imp.Errors) < maxImportErrors {
+				imp.Errors = append(imp.Errors, domain.ImportItemError{Index: i, URL: item.URL, Error: err.Error()})
+			}
+		case created:
+			imp.Imported++
+		default:
+			imp.Duplicates++
+		}
+		imp.Processed++
+
+		if time.Since(lastUpdate) >= importProgressInterval {
+			s.update(ctx, &imp)
+			lastUpdate = time.Now()
+		}
+	}
+
+	s.finish(ctx, &imp, nil)
+	logger.Info("finished import", "imported", imp.Imported, "duplicates", imp.Duplicates, "failed", imp.Failed)
+	return nil
+}
+
+func (s *ImportsService) importItem(ctx context.Context, userID uuid.UUID, item bookmarks.Bookmark, lists map[string]uuid.UUID) (bool, error) {
+	link := domain.Link{
+		UserID:    userID,
+		URL:       item.URL,
+		Title:     item.Title,
+		Tags:      item.Tags,
+		CreatedAt: item.AddedAt,
+	}
+	created, err := s.links.Save(ctx, &link)
+	if err != nil {
+		return false, err
+	}
+
+	// the time the link was added at is older than any change the user has made, which is kept then
+	if item.Read {
+		if _, _, err = s.reading.SetRead(ctx, userID, link.ID, true, item.AddedAt); err != nil {
+			return created, err
+		}
+	}
+
+	if len(item.Folders) == 0 {
+		return created, nil
+	}
+
+	title := strings.Join(item.Folders, importFolderSeparator)
+	listID, ok := lists[title]
+	if !ok {
+		list, err := s.lists.GetOrCreate(ctx, userID, title)
+		if err != nil {
+			return created, err
+		}
+		listID = list.ID
+		lists[title] = listID
+	}
+
+	return created, s.lists.AddLinks(ctx, userID, listID, []uuid.UUID{link.ID})
+}
+
+func (s *ImportsService) update(ctx context.Context, imp *domain.Import) {
+	if err := s.repo.Update(ctx, imp); err != nil {
+		slog.ErrorContext(ctx, "failed to update import", "import_id", imp.ID, logError, err)
+	}
+}
+
+func (s *ImportsService) finish(ctx context.Context, imp *domain.Import, err error) {
+	now := time.Now()
+	imp.FinishedAt = &now
+	if err != nil {
+		imp.Status = domain.ImportStatusFailed
+		imp.Error = err.Error()
+	} else {
+		imp.Status = domain.ImportStatusCompleted
+	}
+	s.update(ctx, imp)
+}
+
+func importKey(id uuid.UUID) string {
+	return importsBlobPrefix + id.String()
+}
+
+func parseImportFormat(format string, data []byte) (bookmarks.Format, error) {
+	var (
+		f   bookmarks.Format
+		err error
+	)
+	if format == "" {
+		f, err = bookmarks.DetectFormat(data)
+	} else {
+		f, err = bookmarks.ParseFormat(format)
+	}
+	if err != nil {
+		return "", domain.ErrUnknownFormat
+	}
+	return f, nil
+}
@@ -0,0 +1,19 @@
+package service
+
+import "github.com/adanyl0v/go-pocket-link/pkg/jobs"
+
+// The kinds of the background jobs, each run by the HandleJob of its service
+const (
+	JobRunImport = "imports.run"
+	JobRunExport = "exports.run"
+	JobSendMail  = "mail.send"
+	JobRunTask   = "tasks.run"
+
+	JobCaptureSnapshot = "snapshots.capture"
+)
+
+// willRetry tells whether the failed attempt of the job is going to be retried,
+// so its failure is not recorded yet
+func willRetry(job jobs.Job, err error) bool {
+	return !jobs.IsPermanent(err) && job.Attempt < job.MaxAttempts
+}
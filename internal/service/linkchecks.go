@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/internal/repository"
+	"github.com/adanyl0v/go-pocket-link/pkg/backoff"
+	"github.com/adanyl0v/go-pocket-link/pkg/linkcheck"
+	"github.com/google/uuid"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type LinkChecksOptions struct {
+	// CheckInterval is how often each link is checked. The failed checks are retried after RetryInterval,
+	// doubled for each failure in a row up to CheckInterval
+	CheckInterval time.Duration
+	RetryInterval time.Duration
+	// BrokenAfter is how many checks in a row must fail for the link to be flagged as broken
+	BrokenAfter int
+	// BatchSize is how many due links are selected at once, Concurrency of them being checked in parallel
+	BatchSize   int
+	Concurrency int
+	// HistorySize is how many latest checks of each link are kept
+	HistorySize int
+}
+
+// LinkChecksService checks the saved links for rot, recording the history of their checks
+type LinkChecksService struct {
+	repo    repository.LinkChecksRepository
+	links   repository.LinksRepository
+	checker *linkcheck.Checker
+	opts    LinkChecksOptions
+}
+
+func NewLinkChecksService(repo repository.LinkChecksRepository, links repository.LinksRepository,
+	checker *linkcheck.Checker, opts LinkChecksOptions) *LinkChecksService {
+	return &LinkChecksService{
+		repo:    repo,
+		links:   links,
+		checker: checker,
+		opts:    opts,
+	}
+}
+
+// Get returns the health of the user's link with its check history
+func (s *LinkChecksService) Get(ctx context.Context, userID, linkID uuid.UUID) (domain.LinkHealthReport, error) {
+	if _, err := s.links.Get(ctx, userID, linkID); err != nil {
+		return domain.LinkHealthReport{}, err
+	}
+
+	report := domain.LinkHealthReport{Checks: []domain.LinkCheck{}}
+	health, err := s.repo.GetHealth(ctx, linkID)
+	if errors.Is(err, domain.ErrLinkHealthNotFound) {
+		return report, nil
+	} else if err != nil {
+		return domain.LinkHealthReport{}, err
+	}
+	report.Health = &health
+
+	if report.Checks, err = s.repo.GetChecks(ctx, linkID, s.opts.HistorySize); err != nil {
+		return domain.LinkHealthReport{}, err
+	}
+	return report, nil
+}
+
+// CheckDue checks the due links in batches until fewer than a batch are due. It is run by the scheduler,
+// returning how many links have been checked
+func (s *LinkChecksService) CheckDue(ctx context.Context) (int64, error) {
+	var checked int64
+	for ctx.Err() == nil {
+		targets, err := s.repo.GetDue(ctx, s.opts.BatchSize)
+		if err != nil {
+			return checked, err
+		}
+
+		n := s.checkBatch(ctx, targets)
+		checked += n
+		// a batch none of which could be saved would be selected again, so the run stops instead
+		if len(targets) < s.opts.BatchSize || n == 0 {
+			break
+		}
+	}
+	return checked, ctx.Err()
+}
+
+// checkBatch checks the links in parallel, returning how many checks have been saved. The checks of the same host
+// are spaced by the checker, so the links of a host wait for their turns while the ones of other hosts are checked
+func (s *LinkChecksService) checkBatch(ctx context.Context, targets []domain.LinkCheckTarget) int64 {
+	queue := make(chan domain.LinkCheckTarget)
+	var saved atomic.Int64
+
+	var wg sync.WaitGroup
+	for range min(s.opts.Concurrency, len(targets)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for target := range queue {
+				if err := s.check(ctx, target); err == nil {
+					saved.Add(1)
+				} else if ctx.Err() == nil && !errors.Is(err, domain.ErrLinkNotFound) {
+					slog.ErrorContext(ctx, "failed to check link", "link_id", target.LinkID, logError, err)
+				}
+			}
+		}()
+	}
+
+send:
+	for _, target := range targets {
+		select {
+		case queue <- target:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(queue)
+	wg.Wait()
+	return saved.Load()
+}
+
+// check checks the link once and schedules its next check. The failures of the link are recorded in its health,
+// only the ones of checking it at all, like the cancellation of ctx, and of recording them are returned
+func (s *LinkChecksService) check(ctx context.Context, target domain.LinkCheckTarget) error {
+	result, err := s.checker.Check(ctx, target.URL)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	now := time.Now()
+	health := target.LinkHealth
+	health.CheckedAt = &now
+
+	switch {
+	case errors.Is(err, linkcheck.ErrDisallowed):
+		// the link is left as it is, it is looked at again once robots.txt may have changed
+		health.Error = err.Error()
+		health.NextCheckAt = now.Add(s.opts.CheckInterval)
+		return s.repo.Save(ctx, &health, nil, s.opts.HistorySize)
+	case err == nil && result.RateLimited():
+		// the host refusing to answer for now tells nothing about the link
+		health.Error = "rate limited"
+		health.NextCheckAt = now.Add(max(s.opts.RetryInterval, result.RetryAfter))
+		return s.repo.Save(ctx, &health, nil, s.opts.HistorySize)
+	}
+
+	check := domain.LinkCheck{
+		LinkID:         target.LinkID,
+		StatusCode:     result.StatusCode,
+		FinalURL:       result.FinalURL,
+		ResponseTimeMs: result.ResponseTime.Milliseconds(),
+		OK:             err == nil && result.OK(),
+		CheckedAt:      now,
+	}
+	if err != nil {
+		check.Error = err.Error()
+	}
+	health.StatusCode, health.FinalURL, health.ResponseTimeMs = check.StatusCode, check.FinalURL, check.ResponseTimeMs
+	health.Error = check.Error
+
+	wasBroken := health.Broken
+	if check.OK {
+		health.Failures, health.Broken = 0, false
+		health.NextCheckAt = now.Add(s.opts.CheckInterval)
+	} else {
+		health.Failures++
+		health.Broken = health.Failures >= s.opts.BrokenAfter
+		delay := backoff.Exponential(health.Failures, s.opts.RetryInterval, s.opts.CheckInterval)
+		health.NextCheckAt = now.Add(max(delay, result.RetryAfter))
+	}
+
+	if err = s.repo.Save(ctx, &health, &check, s.opts.HistorySize); err != nil {
+		return err
+	}
+	if health.Broken != wasBroken {
+		slog.DebugContext(ctx, "link health changed", "link_id", target.LinkID, "broken", health.Broken,
+			"status_code", health.StatusCode, "failures", health.Failures)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/internal/repository"
+	"github.com/adanyl0v/go-pocket-link/pkg/pagination"
+	"github.com/adanyl0v/go-pocket-link/pkg/urlnorm"
+	"github.com/google/uuid"
+	"net/url"
+)
+
+type LinksService struct {
+	repo       repository.LinksRepository
+	tagsRepo   repository.TagsRepository
+	normalizer *urlnorm.Normalizer
+	events     *EventBus
+}
+
+func NewLinksService(repo repository.LinksRepository, tagsRepo repository.TagsRepository, normalizer *urlnorm.Normalizer,
+	events *EventBus) *LinksService {
+	return &LinksService{
+		repo:       repo,
+		tagsRepo:   tagsRepo,
+		normalizer: normalizer,
+		events:     events,
+	}
+}
+
+// Save saves the link unless the user has already saved the same normalized url.
+// In that case the link is replaced with the existing one and created is false
+func (s *LinksService) Save(ctx context.Context, link *domain.Link) (created bool, err error) {
+	if err = s.normalize(link); err != nil {
+		return false, err
+	}
+
+	tags, err := normalizeTagNames(link.Tags)
+	if err != nil {
+		return false, err
+	}
+
+	// the link is saved along with its tags, so it is not left saved without them if tagging fails
+	if created, err = s.repo.SaveWithTags(ctx, link, tags); err != nil {
+		return false, err
+	} else if created {
+		linksSaved.Inc()
+	}
+
+	links := []domain.Link{*link}
+	if err = attachTags(ctx, s.tagsRepo, links); err != nil {
+		return false, err
+	}
+	*link = links[0]
+
+	if created {
+		s.events.Publish(ctx, domain.NewEvent(domain.EventLinkSaved, link.UserID, *link))
+	}
+	return created, nil
+}
+
+func (s *LinksService) Get(ctx context.Context, userID, id uuid.UUID) (domain.Link, error) {
+	link, err := s.repo.Get(ctx, userID, id)
+	if err != nil {
+		return domain.Link{}, err
+	}
+
+	links := []domain.Link{link}
+	if err = attachTags(ctx, s.tagsRepo, links); err != nil {
+		return domain.Link{}, err
+	}
+	return links[0], nil
+}
+
+func (s *LinksService) GetPage(ctx context.Context, userID uuid.UUID, params pagination.Params) (pagination.Page[domain.Link], error) {
+	page, err := s.repo.GetPage(ctx, userID, params)
+	if err != nil {
+		return pagination.Page[domain.Link]{}, err
+	}
+
+	if err = attachTags(ctx, s.tagsRepo, page.Data); err != nil {
+		return pagination.Page[domain.Link]{}, err
+	}
+	return page, nil
+}
+
+func (s *LinksService) Update(ctx context.Context, link *domain.Link) error {
+	if err := s.normalize(link); err != nil {
+		return err
+	} else if err := s.repo.Update(ctx, link); err != nil {
+		return err
+	}
+
+	s.events.Publish(ctx, domain.NewEvent(domain.EventLinkUpdated, link.UserID, *link))
+	return nil
+}
+
+func (s *LinksService) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	if err := s.repo.Delete(ctx, userID, id); err != nil {
+		return err
+	}
+
+	s.events.Publish(ctx, domain.NewEvent(domain.EventLinkDeleted, userID, domain.LinkIDsEventData{
+		LinkIDs: []uuid.UUID{id},
+	}))
+	return nil
+}
+
+// DeleteVersion deletes the link if it has not changed since the version
+func (s *LinksService) DeleteVersion(ctx context.Context, userID, id uuid.UUID, version int64) error {
+	if err := s.repo.DeleteVersion(ctx, userID, id, version); err != nil {
+		return err
+	}
+
+	s.events.Publish(ctx, domain.NewEvent(domain.EventLinkDeleted, userID, domain.LinkIDsEventData{
+		LinkIDs: []uuid.UUID{id},
+	}))
+	return nil
+}
+
+func (s *LinksService) ValidateURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || !u.IsAbs() || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return domain.ErrInvalidURL
+	}
+	return nil
+}
+
+// NormalizeURLs normalizes the urls of all links again, e.g. after the normalization options have changed.
+// The links of a user which turn out
+// to have the same url are merged into the oldest one. The urls which cannot be normalized are kept as they are
+func (s *LinksService) NormalizeURLs(ctx context.Context) (updated, merged int64, err error) {
+	return s.repo.NormalizeURLs(ctx, func(rawURL string) string {
+		normalized, err := s.normalizer.Normalize(rawURL)
+		if err != nil {
+			return rawURL
+		}
+		return normalized
+	})
+}
+
+func (s *LinksService) normalize(link *domain.Link) error {
+	if err := s.ValidateURL(link.URL); err != nil {
+		return err
+	}
+
+	normalized, err := s.normalizer.Normalize(link.URL)
+	if err != nil {
+		return domain.ErrInvalidURL
+	}
+	link.NormalizedURL = normalized
+	return nil
+}
+
+// attachTags sets the tags of the links. The links of a list may belong to several of its members,
+// each having their own tags, so the tags are looked up by the owner of each link
+func attachTags(ctx context.Context, tagsRepo repository.TagsRepository, links []domain.Link) error {
+	idsByOwner := make(map[uuid.UUID][]uuid.UUID)
+	for _, link := range links {
+		idsByOwner[link.UserID] = append(idsByOwner[link.UserID], link.ID)
+	}
+
+	names := make(map[uuid.UUID][]string, len(links))
+	for userID, ids := range idsByOwner {
+		ownerNames, err := tagsRepo.GetNamesByLinkIDs(ctx, userID, ids)
+		if err != nil {
+			return err
+		}
+		for id, linkNames := range ownerNames {
+			names[id] = linkNames
+		}
+	}
+
+	for i := range links {
+		links[i].Tags = names[links[i].ID]
+		if links[i].Tags == nil {
+			links[i].Tags = []string{}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/internal/repository"
+	"github.com/adanyl0v/go-pocket-link/pkg/urlnorm"
+	"github.com/google/uuid"
+	"strings"
+	"testing"
+)
+
+// fakeLinksRepository saves the links with their tags by their normalized urls, failing if err is set,
+// and normalizes the urls it holds
+type fakeLinksRepository struct {
+	repository.LinksRepository
+	saved map[string]domain.Link
+	tags  map[uuid.UUID][]string
+	err   error
+	urls  []string
+}
+
+func (r *fakeLinksRepository) SaveWithTags(_ context.Context, link *domain.Link, tags []string) (bool, error) {
+	if r.err != nil {
+		return false, r.err
+	}
+
+	existing, ok := r.saved[link.NormalizedURL]
+	if !ok {
+		link.ID = uuid.New()
+		r.saved[link.NormalizedURL] = *link
+	} else {
+		*link = existing
+	}
+	r.tags[link.ID] = append(r.tags[link.ID], tags...)
+	return !ok, nil
+}
+
+// fakeTagsRepository returns the tags attached by fakeLinksRepository
+type fakeTagsRepository struct {
+	repository.TagsRepository
+	links *fakeLinksRepository
+}
+
+func (r *fakeTagsRepository) GetNamesByLinkIDs(_ context.Context, _ uuid.UUID, linkIDs []uuid.UUID) (map[uuid.UUID][]string, error) {
+	names := make(map[uuid.UUID][]string, len(linkIDs))
+	for _, id := range linkIDs {
+		names[id] = r.links.tags[id]
+	}
+	return names, nil
+}
+
+type recordedEvents []domain.Event
+
+func (e *recordedEvents) HandleEvent(_ context.Context, event domain.Event) error {
+	*e = append(*e, event)
+	return nil
+}
+
+func newTestLinksService(repo *fakeLinksRepository) (*LinksService, *recordedEvents) {
+	repo.saved = make(map[string]domain.Link)
+	repo.tags = make(map[uuid.UUID][]string)
+
+	var events recordedEvents
+	bus := NewEventBus()
+	bus.Subscribe(&events)
+	return NewLinksService(repo, &fakeTagsRepository{links: repo}, urlnorm.New(urlnorm.DefaultOptions()), bus), &events
+}
+
+func TestLinksSave(t *testing.T) {
+	repo := &fakeLinksRepository{}
+	s, events := newTestLinksService(repo)
+	userID := uuid.New()
+
+	first := domain.Link{UserID: userID, URL: "http://Example.com/a?utm_source=x", Tags: []string{" Go ", "go", "Feeds"}}
+	created, err := s.Save(context.Background(), &first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !created || first.NormalizedURL != "https://example.com/a" {
+		t.Errorf("created %v, normalized url %q", created, first.NormalizedURL)
+	}
+	if strings.Join(first.Tags, ",") != "go,feeds" {
+		t.Errorf("tags %v, want the normalized ones saved with the link", first.Tags)
+	}
+
+	// the same url is not saved again, its tags are added to the existing link
+	second := domain.Link{UserID: userID, URL: "https://example.com/a/", Tags: []string{"later"}}
+	created, err = s.Save(context.Background(), &second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created || second.ID != first.ID || second.URL != first.URL {
+		t.Errorf("created %v, link %+v, want the existing one", created, second)
+	}
+	if strings.Join(second.Tags, ",") != "go,feeds,later" {
+		t.Errorf("tags %v", second.Tags)
+	}
+
+	if len(*events) != 1 || (*events)[0].Type != domain.EventLinkSaved {
+		t.Errorf("events %v, want a single link.saved", *events)
+	}
+}
+
+func TestLinksSaveFails(t *testing.T) {
+	repo := &fakeLinksRepository{}
+	s, events := newTestLinksService(repo)
+
+	link := domain.Link{UserID: uuid.New(), URL: "https://example.com", Tags: []string{"x"}}
+	if _, err := s.Save(context.Background(), &domain.Link{URL: "ftp://example.com"}); !errors.Is(err, domain.ErrInvalidURL) {
+		t.Errorf("saving an ftp url = %v, want ErrInvalidURL", err)
+	}
+	if _, err := s.Save(context.Background(), &domain.Link{URL: link.URL, Tags: []string{" "}}); !errors.Is(err, domain.ErrInvalidTagName) {
+		t.Errorf("saving a blank tag = %v, want ErrInvalidTagName", err)
+	}
+
+	repo.err = errors.New("tagging failed")
+	if created, err := s.Save(context.Background(), &link); !errors.Is(err, repo.err) || created {
+		t.Errorf("Save = %v, %v, want the error of the repository", created, err)
+	}
+	if len(*events) != 0 {
+		t.Errorf("events %v, want none for the links not saved", *events)
+	}
+}
+
+func (r *fakeLinksRepository) NormalizeURLs(_ context.Context, normalize func(rawURL string) string) (updated, merged int64, err error) {
+	seen := make(map[string]bool)
+	for i, rawURL := range r.urls {
+		normalized := normalize(rawURL)
+		if seen[normalized] {
+			merged++
+		} else if normalized != rawURL {
+			updated++
+		}
+		seen[normalized] = true
+		r.urls[i] = normalized
+	}
+	return updated, merged, nil
+}
+
+func TestLinksNormalizeURLs(t *testing.T) {
+	repo := &fakeLinksRepository{urls: []string{
+		"https://example.com/a",
+		"http://Example.com/a/?utm_source=x",
+		"https://example.com/b/",
+		"not a url",
+	}}
+	s := NewLinksService(repo, nil, urlnorm.New(urlnorm.DefaultOptions()), nil)
+
+	updated, merged, err := s.NormalizeURLs(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated != 1 || merged != 1 {
+		t.Errorf("updated %d, merged %d, want 1 and 1", updated, merged)
+	}
+	// the urls which cannot be normalized are kept as they are
+	want := []string{"https://example.com/a", "https://example.com/a", "https://example.com/b", "not a url"}
+	for i := range want {
+		if repo.urls[i] != want[i] {
+			t.Errorf("url %d is %q, want %q", i, repo.urls[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/internal/repository"
+	"github.com/adanyl0v/go-pocket-link/pkg/pagination"
+	"github.com/google/uuid"
+	"strings"
+	"unicode/utf8"
+)
+
+const maxListTitleLength = 255
+
+type ListsService struct {
+	repo           repository.ListsRepository
+	tagsRepo       repository.TagsRepository
+	membersRepo    repository.ListMembersRepository
+	activitiesRepo repository.ListActivitiesRepository
+	events         *EventBus
+}
+
+func NewListsService(repo repository.ListsRepository, tagsRepo repository.TagsRepository, membersRepo repository.ListMembersRepository,
+	activitiesRepo repository.ListActivitiesRepository, events *EventBus) *ListsService {
+	return &ListsService{
+		repo:           repo,
+		tagsRepo:       tagsRepo,
+		membersRepo:    membersRepo,
+		activitiesRepo: activitiesRepo,
+		events:         events,
+	}
+}
+
+func (s *ListsService) Save(ctx context.Context, list *domain.List) error {
+	title, err := normalizeListTitle(list.Title)
+	if err != nil {
+		return err
+	}
+	list.Title = title
+	if err = s.repo.Save(ctx, list); err != nil {
+		return err
+	}
+
+	s.events.Publish(ctx, domain.NewEvent(domain.EventListCreated, list.UserID, *list))
+	return nil
+}
+
+// Authorize returns the list if the user's role in it allows the required one
+func (s *ListsService) Authorize(ctx context.Context, userID, id uuid.UUID, required domain.ListRole) (domain.List, error) {
+	role, err := s.membersRepo.GetRole(ctx, userID, id)
+	if err != nil {
+		return domain.List{}, err
+	} else if !role.Allows(required) {
+		return domain.List{}, domain.ErrListPermissionDenied
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *ListsService) Get(ctx context.Context, userID, id uuid.UUID) (domain.List, error) {
+	return s.Authorize(ctx, userID, id, domain.ListRoleViewer)
+}
+
+// GetOrCreate returns the user's list with the title, creating it if it does not exist
+func (s *ListsService) GetOrCreate(ctx context.Context, userID uuid.UUID, title string) (domain.List, error) {
+	title, err := normalizeListTitle(title)
+	if err != nil {
+		return domain.List{}, err
+	}
+
+	list, err := s.repo.GetByTitle(ctx, userID, title)
+	if err == nil || !errors.Is(err, domain.ErrListNotFound) {
+		return list, err
+	}
+
+	list = domain.List{UserID: userID, Title: title}
+	err = s.repo.Save(ctx, &list)
+	if errors.Is(err, domain.ErrListAlreadyExists) {
+		return s.repo.GetByTitle(ctx, userID, title)
+	} else if err != nil {
+		return domain.List{}, err
+	}
+
+	s.events.Publish(ctx, domain.NewEvent(domain.EventListCreated, userID, list))
+	return list, nil
+}
+
+func (s *ListsService) GetPage(ctx context.Context, userID uuid.UUID, params pagination.Params) (pagination.Page[domain.List], error) {
+	return s.repo.GetPage(ctx, userID, params)
+}
+
+// Update renames the list on behalf of the user, who must be at least its editor
+func (s *ListsService) Update(ctx context.Context, userID uuid.UUID, list *domain.List) error {
+	title, err := normalizeListTitle(list.Title)
+	if err != nil {
+		return err
+	}
+
+	current, err := s.Authorize(ctx, userID, list.ID, domain.ListRoleEditor)
+	if err != nil {
+		return err
+	}
+
+	list.UserID = current.UserID
+	list.Title = title
+	if err = s.repo.Update(ctx, list); err != nil {
+		return err
+	}
+
+	s.events.Publish(ctx, domain.NewEvent(domain.EventListUpdated, userID, *list))
+	return nil
+}
+
+func (s *ListsService) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	if _, err := s.Authorize(ctx, userID, id, domain.ListRoleOwner); err != nil {
+		return err
+	} else if err := s.repo.Delete(ctx, userID, id); err != nil {
+		return err
+	}
+
+	s.events.Publish(ctx, domain.NewEvent(domain.EventListDeleted, userID, domain.ListEventData{ListID: id}))
+	return nil
+}
+
+// DeleteVersion deletes the list if it has not changed since the version
+func (s *ListsService) DeleteVersion(ctx context.Context, userID, id uuid.UUID, version int64) error {
+	if _, err := s.Authorize(ctx, userID, id, domain.ListRoleOwner); err != nil {
+		return err
+	} else if err := s.repo.DeleteVersion(ctx, userID, id, version); err != nil {
+		return err
+	}
+
+	s.events.Publish(ctx, domain.NewEvent(domain.EventListDeleted, userID, domain.ListEventData{ListID: id}))
+	return nil
+}
+
+func (s *ListsService) GetLinksPage(ctx context.Context, userID, id uuid.UUID, params pagination.Params) (pagination.Page[domain.Link], error) {
+	list, err := s.Authorize(ctx, userID, id, domain.ListRoleViewer)
+	if err != nil {
+		return pagination.Page[domain.Link]{}, err
+	}
+
+	page, err := s.repo.GetLinksPage(ctx, list.UserID, id, params)
+	if err != nil {
+		return pagination.Page[domain.Link]{}, err
+	}
+
+	if err = attachTags(ctx, s.tagsRepo, page.Data); err != nil {
+		return pagination.Page[domain.Link]{}, err
+	}
+	return page, nil
+}
+
+// AddLinks adds the user's links to the list, recording the added ones in its activity stream
+func (s *ListsService) AddLinks(ctx context.Context, userID, id uuid.UUID, linkIDs []uuid.UUID) error {
+	if _, err := s.Authorize(ctx, userID, id, domain.ListRoleEditor); err != nil {
+		return err
+	}
+
+	added, err := s.repo.AddLinks(ctx, id, userID, linkIDs)
+	if err != nil || len(added) == 0 {
+		return err
+	}
+	if err = s.activitiesRepo.SaveForLinks(ctx, id, userID, domain.ListActivityLinkAdded, added); err != nil {
+		return err
+	}
+
+	s.events.Publish(ctx, domain.NewEvent(domain.EventListLinkAdded, userID, domain.LinkIDsEventData{
+		LinkIDs: added,
+		ListID:  &id,
+	}))
+	return nil
+}
+
+// RemoveLinks removes the links of any member from the list, recording it in its activity stream
+func (s *ListsService) RemoveLinks(ctx context.Context, userID, id uuid.UUID, linkIDs []uuid.UUID) error {
+	if _, err := s.Authorize(ctx, userID, id, domain.ListRoleEditor); err != nil {
+		return err
+	}
+
+	removed, err := s.repo.RemoveLinks(ctx, id, linkIDs)
+	if err != nil || len(removed) == 0 {
+		return err
+	}
+	if err = s.activitiesRepo.SaveForLinks(ctx, id, userID, domain.ListActivityLinkRemoved, removed); err != nil {
+		return err
+	}
+
+	s.events.Publish(ctx, domain.NewEvent(domain.EventListLinkRemoved, userID, domain.LinkIDsEventData{
+		LinkIDs: removed,
+		ListID:  &id,
+	}))
+	return nil
+}
+
+func (s *ListsService) GetActivityPage(ctx context.Context, userID, id uuid.UUID, params pagination.Params) (pagination.Page[domain.ListActivity], error) {
+	if _, err := s.Authorize(ctx, userID, id, domain.ListRoleViewer); err != nil {
+		return pagination.Page[domain.ListActivity]{}, err
+	}
+	return s.activitiesRepo.GetPage(ctx, id, params)
+}
+
+func normalizeListTitle(title string) (string, error) {
+	title = strings.TrimSpace(title)
+	if title == "" || utf8.RuneCountInString(title) > maxListTitleLength {
+		return "", domain.ErrInvalidListTitle
+	}
+	return title, nil
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+	"github.com/adanyl0v/go-pocket-link/pkg/jobs"
+	"github.com/adanyl0v/go-pocket-link/pkg/mail"
+)
+
+// MailQueue is a mail.Sender queueing the mails, so they are sent in the background and retried if sending fails
+type MailQueue struct {
+	queue  jobs.Queue
+	sender mail.Sender
+}
+
+func NewMailQueue(queue jobs.Queue, sender mail.Sender) *MailQueue {
+	return &MailQueue{
+		queue:  queue,
+		sender: sender,
+	}
+}
+
+func (q *MailQueue) Send(ctx context.Context, msg mail.Message) error {
+	_, err := jobs.EnqueueJSON(ctx, q.queue, JobSendMail, msg)
+	return err
+}
+
+func (q *MailQueue) HandleJob(ctx context.Context, job jobs.Job) error {
+	var msg mail.Message
+	if err := job.Decode(&msg); err != nil {
+		return err
+	}
+	return q.sender.Send(ctx, msg)
+}
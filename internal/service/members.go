@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/internal/repository"
+	"github.com/adanyl0v/go-pocket-link/pkg/mail"
+	"github.com/adanyl0v/go-pocket-link/pkg/pagination"
+	"github.com/google/uuid"
+	netmail "net/mail"
+	"strings"
+	"time"
+)
+
+const secretTokenBytes = 32
+
+type MembersService struct {
+	repo            repository.ListMembersRepository
+	invitationsRepo repository.ListInvitationsRepository
+	activitiesRepo  repository.ListActivitiesRepository
+	usersRepo       repository.UsersRepository
+	lists           *ListsService
+	mailer          mail.Sender
+	invitationTTL   time.Duration
+	// acceptURL is the page the invitation emails link to, with the token appended
+	acceptURL string
+}
+
+func NewMembersService(repo repository.ListMembersRepository, invitationsRepo repository.ListInvitationsRepository,
+	activitiesRepo repository.ListActivitiesRepository, usersRepo repository.UsersRepository, lists *ListsService,
+	mailer mail.Sender, invitationTTL time.Duration, acceptURL string) *MembersService {
+	return &MembersService{
+		repo:            repo,
+		invitationsRepo: invitationsRepo,
+		activitiesRepo:  activitiesRepo,
+		usersRepo:       usersRepo,
+		lists:           lists,
+		mailer:          mailer,
+		invitationTTL:   invitationTTL,
+		acceptURL:       acceptURL,
+	}
+}
+
+func (s *MembersService) GetPage(ctx context.Context, userID, listID uuid.UUID, params pagination.Params) (pagination.Page[domain.ListMember], error) {
+	if _, err := s.lists.Authorize(ctx, userID, listID, domain.ListRoleViewer); err != nil {
+		return pagination.Page[domain.ListMember]{}, err
+	}
+	return s.repo.GetPage(ctx, listID, params)
+}
+
+// UpdateRole changes the role of the member on behalf of the owner of the list
+func (s *MembersService) UpdateRole(ctx context.Context, userID uuid.UUID, member *domain.ListMember) error {
+	if !member.Role.IsAssignable() {
+		return domain.ErrInvalidListRole
+	} else if _, err := s.lists.Authorize(ctx, userID, member.ListID, domain.ListRoleOwner); err != nil {
+		return err
+	}
+
+	if err := s.repo.UpdateRole(ctx, member); err != nil {
+		return err
+	}
+	return s.saveActivity(ctx, member.ListID, userID, domain.ListActivityMemberRoleChanged, member.UserID)
+}
+
+// Remove removes the member from the list. The owner can remove anyone but themselves,
+// and the other members can only leave
+func (s *MembersService) Remove(ctx context.Context, userID, listID, memberID uuid.UUID) error {
+	required := domain.ListRoleOwner
+	if userID == memberID {
+		required = domain.ListRoleViewer
+	}
+
+	list, err := s.lists.Authorize(ctx, userID, listID, required)
+	if err != nil {
+		return err
+	} else if list.UserID == memberID {
+		return domain.ErrOwnerNotRemovable
+	}
+
+	if err = s.repo.Delete(ctx, listID, memberID); err != nil {
+		return err
+	}
+	return s.saveActivity(ctx, listID, userID, domain.ListActivityMemberRemoved, memberID)
+}
+
+// Invite emails an invitation to join the list with the role. The token is only sent
+// in the email, so the invitation can only be accepted by someone reading it
+func (s *MembersService) Invite(ctx context.Context, userID uuid.UUID, invitation *domain.ListInvitation) error {
+	if !invitation.Role.IsAssignable() {
+		return domain.ErrInvalidListRole
+	}
+
+	address, err := netmail.ParseAddress(invitation.Email)
+	if err != nil {
+		return fmt.Errorf("%w (%w)", domain.ErrInvalidEmail, err)
+	}
+	invitation.Email = strings.ToLower(address.Address)
+
+	list, err := s.lists.Authorize(ctx, userID, invitation.ListID, domain.ListRoleOwner)
+	if err != nil {
+		return err
+	}
+
+	members, err := s.repo.GetAll(ctx, invitation.ListID)
+	if err != nil {
+		return err
+	}
+	for _, member := range members {
+		if strings.EqualFold(member.Email, invitation.Email) {
+			return domain.ErrListMemberAlreadyExists
+		}
+	}
+
+	inviter, err := s.usersRepo.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	token, err := newSecretToken()
+	if err != nil {
+		return err
+	}
+
+	invitation.InviterID = userID
+	invitation.TokenHash = hashSecretToken(token)
+	invitation.ExpiresAt = time.Now().Add(s.invitationTTL)
+	if err = s.invitationsRepo.Save(ctx, invitation); err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mail.Message{
+		To:      []string{invitation.Email},
+		Subject: fmt.Sprintf("%s invited you to the list %q", inviter.Name, list.Title),
+		Body: fmt.Sprintf("%s invited you to the list %q as %s.\n\nAccept the invitation: %s%s\n\nIt expires on %s.\n",
+			inviter.Name, list.Title, invitation.Role, s.acceptURL, token, invitation.ExpiresAt.Format(time.RFC1123)),
+	})
+}
+
+func (s *MembersService) GetPendingInvitationsPage(ctx context.Context, userID, listID uuid.UUID, params pagination.Params) (pagination.Page[domain.ListInvitation], error) {
+	if _, err := s.lists.Authorize(ctx, userID, listID, domain.ListRoleOwner); err != nil {
+		return pagination.Page[domain.ListInvitation]{}, err
+	}
+	return s.invitationsRepo.GetPendingPage(ctx, listID, params)
+}
+
+func (s *MembersService) CancelInvitation(ctx context.Context, userID, listID, id uuid.UUID) error {
+	if _, err := s.lists.Authorize(ctx, userID, listID, domain.ListRoleOwner); err != nil {
+		return err
+	}
+	return s.invitationsRepo.Delete(ctx, listID, id)
+}
+
+// AcceptInvitation makes the user a member of the list if the invitation has been sent to their email
+func (s *MembersService) AcceptInvitation(ctx context.Context, userID uuid.UUID, token string) (domain.ListInvitation, error) {
+	invitation, err := s.invitationsRepo.GetByTokenHash(ctx, hashSecretToken(token))
+	if err != nil {
+		return domain.ListInvitation{}, err
+	} else if invitation.AcceptedAt != nil {
+		return domain.ListInvitation{}, domain.ErrInvitationNotFound
+	} else if !time.Now().Before(invitation.ExpiresAt) {
+		return domain.ListInvitation{}, domain.ErrInvitationExpired
+	}
+
+	user, err := s.usersRepo.Get(ctx, userID)
+	if err != nil {
+		return domain.ListInvitation{}, err
+	} else if !strings.EqualFold(user.Email, invitation.Email) {
+		return domain.ListInvitation{}, domain.ErrInvitationEmailMismatch
+	}
+
+	if _, err = s.repo.GetRole(ctx, userID, invitation.ListID); err == nil {
+		return domain.ListInvitation{}, domain.ErrListMemberAlreadyExists
+	} else if !errors.Is(err, domain.ErrListNotFound) {
+		return domain.ListInvitation{}, err
+	}
+
+	if err = s.invitationsRepo.Accept(ctx, &invitation, userID); err != nil {
+		return domain.ListInvitation{}, err
+	}
+	return invitation, s.saveActivity(ctx, invitation.ListID, userID, domain.ListActivityMemberJoined, userID)
+}
+
+func (s *MembersService) saveActivity(ctx context.Context, listID, actorID uuid.UUID,
+	action domain.ListActivityAction, memberID uuid.UUID) error {
+	return s.activitiesRepo.Save(ctx, &domain.ListActivity{
+		ListID:   listID,
+		ActorID:  &actorID,
+		Action:   action,
+		MemberID: &memberID,
+	})
+}
+
+// newSecretToken returns a random url-safe token, which is stored only as its hash
+func newSecretToken() (string, error) {
+	b := make([]byte, secretTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashSecretToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	signUps = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "pocketlink_sign_ups_total",
+		Help: "Number of the accounts signed up",
+	})
+	signIns = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "pocketlink_sign_ins_total",
+		Help: "Number of the successful sign-ins",
+	})
+	failedLogins = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "pocketlink_failed_logins_total",
+		Help: "Number of the sign-ins with the credentials of no account",
+	})
+	linksSaved = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "pocketlink_links_saved_total",
+		Help: "Number of the links saved, not counting the ones already saved by their users",
+	})
+)
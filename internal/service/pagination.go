@@ -0,0 +1,196 @@
+package service
+
+import (
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/pkg/pagination"
+	"github.com/adanyl0v/go-pocket-link/pkg/scheduler"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+var (
+	timeOperators   = []pagination.Operator{pagination.OpGt, pagination.OpGte, pagination.OpLt, pagination.OpLte}
+	numberOperators = append([]pagination.Operator{pagination.OpEq}, timeOperators...)
+	stringOperators = []pagination.Operator{pagination.OpEq, pagination.OpNe, pagination.OpContains}
+)
+
+// The specs whitelist the fields each collection endpoint can be sorted and filtered by
+var (
+	LinksPageSpec = pagination.Spec{
+		Fields: map[string]pagination.Field{
+			"created_at": {Type: pagination.TypeTime, Sortable: true, Operators: timeOperators},
+			"updated_at": {Type: pagination.TypeTime, Sortable: true, Operators: timeOperators},
+			"title":      {Type: pagination.TypeString, Sortable: true, Operators: stringOperators},
+			"url":        {Type: pagination.TypeString, Operators: stringOperators},
+			"tag":        {Type: pagination.TypeString, Operators: []pagination.Operator{pagination.OpEq}},
+			// is filters the links by their state, e.g. is=broken
+			"is": {Type: pagination.TypeString, Operators: []pagination.Operator{pagination.OpEq}, Values: []string{"broken"}},
+		},
+		DefaultSort:  "created_at",
+		DefaultDesc:  true,
+		DefaultLimit: defaultPageLimit,
+		MaxLimit:     maxPageLimit,
+	}
+
+	ListsPageSpec = pagination.Spec{
+		Fields: map[string]pagination.Field{
+			"title":      {Type: pagination.TypeString, Sortable: true, Operators: stringOperators},
+			"created_at": {Type: pagination.TypeTime, Sortable: true, Operators: timeOperators},
+			"updated_at": {Type: pagination.TypeTime, Sortable: true, Operators: timeOperators},
+		},
+		DefaultSort:  "title",
+		DefaultLimit: defaultPageLimit,
+		MaxLimit:     maxPageLimit,
+	}
+
+	TagsPageSpec = pagination.Spec{
+		Fields: map[string]pagination.Field{
+			"name":        {Type: pagination.TypeString, Sortable: true, Operators: stringOperators},
+			"links_count": {Type: pagination.TypeInt, Sortable: true, Operators: numberOperators},
+			"created_at":  {Type: pagination.TypeTime, Sortable: true, Operators: timeOperators},
+		},
+		DefaultSort:  "links_count",
+		DefaultDesc:  true,
+		DefaultLimit: defaultPageLimit,
+		MaxLimit:     maxPageLimit,
+	}
+
+	HighlightsPageSpec = pagination.Spec{
+		Fields: map[string]pagination.Field{
+			"start":      {Type: pagination.TypeInt, Sortable: true},
+			"created_at": {Type: pagination.TypeTime, Sortable: true, Operators: timeOperators},
+			"color":      {Type: pagination.TypeString, Operators: []pagination.Operator{pagination.OpEq}},
+		},
+		DefaultSort:  "start",
+		DefaultLimit: defaultPageLimit,
+		MaxLimit:     maxPageLimit,
+	}
+
+	NotesPageSpec = pagination.Spec{
+		Fields: map[string]pagination.Field{
+			"created_at": {Type: pagination.TypeTime, Sortable: true, Operators: timeOperators},
+			"updated_at": {Type: pagination.TypeTime, Sortable: true, Operators: timeOperators},
+			"body":       {Type: pagination.TypeString, Operators: []pagination.Operator{pagination.OpContains}},
+		},
+		DefaultSort:  "created_at",
+		DefaultLimit: defaultPageLimit,
+		MaxLimit:     maxPageLimit,
+	}
+
+	SnapshotsPageSpec = pagination.Spec{
+		Fields: map[string]pagination.Field{
+			"created_at": {Type: pagination.TypeTime, Sortable: true, Operators: timeOperators},
+			"size":       {Type: pagination.TypeInt, Sortable: true, Operators: numberOperators},
+			"format": {Type: pagination.TypeString, Operators: []pagination.Operator{pagination.OpEq},
+				Values: []string{string(domain.SnapshotFormatHTML), string(domain.SnapshotFormatWARC)}},
+			"status": {Type: pagination.TypeString, Operators: []pagination.Operator{pagination.OpEq, pagination.OpNe},
+				Values: []string{string(domain.SnapshotStatusPending), string(domain.SnapshotStatusReady),
+					string(domain.SnapshotStatusFailed)}},
+		},
+		DefaultSort:  "created_at",
+		DefaultLimit: defaultPageLimit,
+		MaxLimit:     maxPageLimit,
+	}
+
+	ListActivityPageSpec = pagination.Spec{
+		Fields: map[string]pagination.Field{
+			"created_at": {Type: pagination.TypeTime, Sortable: true, Operators: timeOperators},
+			"action":     {Type: pagination.TypeString, Operators: []pagination.Operator{pagination.OpEq, pagination.OpNe}},
+			"actor_id":   {Type: pagination.TypeUUID, Operators: []pagination.Operator{pagination.OpEq}},
+		},
+		DefaultSort:  "created_at",
+		DefaultDesc:  true,
+		DefaultLimit: defaultPageLimit,
+		MaxLimit:     maxPageLimit,
+	}
+
+	ListMembersPageSpec = pagination.Spec{
+		Fields: map[string]pagination.Field{
+			"created_at": {Type: pagination.TypeTime, Sortable: true, Operators: timeOperators},
+			"name":       {Type: pagination.TypeString, Sortable: true, Operators: stringOperators},
+			"role": {Type: pagination.TypeString, Operators: []pagination.Operator{pagination.OpEq, pagination.OpNe},
+				Values: []string{string(domain.ListRoleOwner), string(domain.ListRoleEditor), string(domain.ListRoleViewer)}},
+		},
+		DefaultSort:  "created_at",
+		DefaultLimit: defaultPageLimit,
+		MaxLimit:     maxPageLimit,
+	}
+
+	ListInvitationsPageSpec = pagination.Spec{
+		Fields: map[string]pagination.Field{
+			"created_at": {Type: pagination.TypeTime, Sortable: true, Operators: timeOperators},
+			"expires_at": {Type: pagination.TypeTime, Sortable: true, Operators: timeOperators},
+			"email":      {Type: pagination.TypeString, Operators: stringOperators},
+			"role": {Type: pagination.TypeString, Operators: []pagination.Operator{pagination.OpEq},
+				Values: []string{string(domain.ListRoleEditor), string(domain.ListRoleViewer)}},
+		},
+		DefaultSort:  "created_at",
+		DefaultLimit: defaultPageLimit,
+		MaxLimit:     maxPageLimit,
+	}
+
+	WebhooksPageSpec = pagination.Spec{
+		Fields: map[string]pagination.Field{
+			"created_at": {Type: pagination.TypeTime, Sortable: true, Operators: timeOperators},
+			"updated_at": {Type: pagination.TypeTime, Sortable: true, Operators: timeOperators},
+			"url":        {Type: pagination.TypeString, Operators: stringOperators},
+			"active":     {Type: pagination.TypeBool, Operators: []pagination.Operator{pagination.OpEq}},
+		},
+		DefaultSort:  "created_at",
+		DefaultLimit: defaultPageLimit,
+		MaxLimit:     maxPageLimit,
+	}
+
+	WebhookDeliveriesPageSpec = pagination.Spec{
+		Fields: map[string]pagination.Field{
+			"created_at": {Type: pagination.TypeTime, Sortable: true, Operators: timeOperators},
+			"status":     {Type: pagination.TypeString, Operators: []pagination.Operator{pagination.OpEq, pagination.OpNe}},
+			"event":      {Type: pagination.TypeString, Operators: []pagination.Operator{pagination.OpEq}},
+		},
+		DefaultSort:  "created_at",
+		DefaultDesc:  true,
+		DefaultLimit: defaultPageLimit,
+		MaxLimit:     maxPageLimit,
+	}
+
+	SubscriptionsPageSpec = pagination.Spec{
+		Fields: map[string]pagination.Field{
+			"created_at": {Type: pagination.TypeTime, Sortable: true, Operators: timeOperators},
+			"updated_at": {Type: pagination.TypeTime, Sortable: true, Operators: timeOperators},
+			"title":      {Type: pagination.TypeString, Sortable: true, Operators: stringOperators},
+			"url":        {Type: pagination.TypeString, Operators: stringOperators},
+			"active":     {Type: pagination.TypeBool, Operators: []pagination.Operator{pagination.OpEq}},
+			"list_id":    {Type: pagination.TypeUUID, Operators: []pagination.Operator{pagination.OpEq}},
+		},
+		DefaultSort:  "created_at",
+		DefaultLimit: defaultPageLimit,
+		MaxLimit:     maxPageLimit,
+	}
+
+	TaskRunsPageSpec = pagination.Spec{
+		Fields: map[string]pagination.Field{
+			"started_at": {Type: pagination.TypeTime, Sortable: true, Operators: timeOperators},
+			"status": {Type: pagination.TypeString, Operators: []pagination.Operator{pagination.OpEq, pagination.OpNe},
+				Values: []string{scheduler.StatusRunning, scheduler.StatusSucceeded, scheduler.StatusFailed}},
+			"trigger": {Type: pagination.TypeString, Operators: []pagination.Operator{pagination.OpEq},
+				Values: []string{scheduler.TriggerSchedule, scheduler.TriggerManual}},
+		},
+		DefaultSort:  "started_at",
+		DefaultDesc:  true,
+		DefaultLimit: defaultPageLimit,
+		MaxLimit:     maxPageLimit,
+	}
+
+	ContinueReadingPageSpec = pagination.Spec{
+		Fields: map[string]pagination.Field{
+			"last_opened_at": {Type: pagination.TypeTime, Sortable: true, Operators: timeOperators},
+		},
+		DefaultSort:  "last_opened_at",
+		DefaultDesc:  true,
+		DefaultLimit: defaultPageLimit,
+		MaxLimit:     maxPageLimit,
+	}
+)
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/internal/repository"
+	"github.com/adanyl0v/go-pocket-link/pkg/pagination"
+	"github.com/google/uuid"
+	"time"
+)
+
+type ReadingService struct {
+	repo     repository.ReadingStatesRepository
+	tagsRepo repository.TagsRepository
+	events   *EventBus
+}
+
+func NewReadingService(repo repository.ReadingStatesRepository, tagsRepo repository.TagsRepository, events *EventBus) *ReadingService {
+	return &ReadingService{
+		repo:     repo,
+		tagsRepo: tagsRepo,
+		events:   events,
+	}
+}
+
+func (s *ReadingService) Get(ctx context.Context, userID, linkID uuid.UUID) (domain.ReadingState, error) {
+	return s.repo.Get(ctx, userID, linkID)
+}
+
+// UpdateProgress applies the progress if clientTime is later than the one of the last applied progress,
+// so retries and updates arriving out of order from several devices are harmless
+func (s *ReadingService) UpdateProgress(ctx context.Context, userID, linkID uuid.UUID, percentage float64, charOffset int,
+	clientTime time.Time) (domain.ReadingState, bool, error) {
+	if percentage < 0 || percentage > 100 || charOffset < 0 {
+		return domain.ReadingState{}, false, domain.ErrInvalidProgress
+	}
+
+	clientTime = clampClientTime(clientTime)
+	state := domain.ReadingState{
+		LinkID:            linkID,
+		UserID:            userID,
+		Percentage:        percentage,
+		CharOffset:        charOffset,
+		ProgressUpdatedAt: &clientTime,
+	}
+	applied, err := s.repo.UpdateProgress(ctx, &state)
+	if err != nil {
+		return domain.ReadingState{}, false, err
+	}
+	return state, applied, nil
+}
+
+func (s *ReadingService) MarkOpened(ctx context.Context, userID, linkID uuid.UUID, clientTime time.Time) (domain.ReadingState, error) {
+	return s.repo.MarkOpened(ctx, userID, linkID, clampClientTime(clientTime))
+}
+
+// SetRead marks the link read or unread, keeping the change with the latest clientTime.
+// Reading a link archives it
+func (s *ReadingService) SetRead(ctx context.Context, userID, linkID uuid.UUID, read bool, clientTime time.Time) (domain.ReadingState, bool, error) {
+	clientTime = clampClientTime(clientTime)
+
+	var readAt *time.Time
+	if read {
+		readAt = &clientTime
+	}
+
+	state, applied, err := s.repo.SetRead(ctx, userID, linkID, readAt, clientTime)
+	if err != nil || !applied {
+		return state, applied, err
+	}
+
+	eventType := domain.EventLinkUnarchived
+	if read {
+		eventType = domain.EventLinkArchived
+	}
+	s.events.Publish(ctx, domain.NewEvent(eventType, userID, state))
+	return state, applied, nil
+}
+
+func (s *ReadingService) GetContinueReading(ctx context.Context, userID uuid.UUID, params pagination.Params) (pagination.Page[domain.LinkWithReadingState], error) {
+	page, err := s.repo.GetContinueReading(ctx, userID, params)
+	if err != nil {
+		return pagination.Page[domain.LinkWithReadingState]{}, err
+	}
+
+	links := make([]domain.Link, 0, len(page.Data))
+	for _, link := range page.Data {
+		links = append(links, link.Link)
+	}
+	if err = attachTags(ctx, s.tagsRepo, links); err != nil {
+		return pagination.Page[domain.LinkWithReadingState]{}, err
+	}
+	for i := range page.Data {
+		page.Data[i].Tags = links[i].Tags
+	}
+	return page, nil
+}
+
+// clampClientTime uses the server time if the client did not send its time or its clock is ahead,
+// so a device with a wrong clock cannot block the updates from the other ones
+func clampClientTime(t time.Time) time.Time {
+	now := time.Now()
+	if t.IsZero() || t.After(now) {
+		return now
+	}
+	return t
+}
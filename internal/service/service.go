@@ -1,6 +1,26 @@
 package service
 
+const logError = "error"
+
 type Services struct {
-	Users  *UsersService
-	Tokens *TokensService
+	Users         *UsersService
+	Tokens        *TokensService
+	Links         *LinksService
+	Lists         *ListsService
+	Members       *MembersService
+	Shares        *SharesService
+	Feeds         *FeedsService
+	Tags          *TagsService
+	Annotations   *AnnotationsService
+	Reading       *ReadingService
+	Webhooks      *WebhooksService
+	Subscriptions *SubscriptionsService
+	LinkChecks    *LinkChecksService
+	Snapshots     *SnapshotsService
+	Blobs         *BlobsService
+	Streams       *StreamsService
+	Sync          *SyncService
+	Imports       *ImportsService
+	Exports       *ExportsService
+	Tasks         *TasksService
 }
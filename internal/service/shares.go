@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/internal/repository"
+	"github.com/adanyl0v/go-pocket-link/pkg/crypto/hash"
+	"github.com/adanyl0v/go-pocket-link/pkg/pagination"
+	"github.com/google/uuid"
+	"log/slog"
+	"time"
+)
+
+const shareSlugBytes = 16
+
+// SharedList is what the visitors of a share see
+type SharedList struct {
+	List  domain.List
+	Links pagination.Page[domain.Link]
+}
+
+type SharesService struct {
+	repo      repository.ListSharesRepository
+	listsRepo repository.ListsRepository
+	tagsRepo  repository.TagsRepository
+	passwords hash.PasswordHasher
+}
+
+func NewSharesService(repo repository.ListSharesRepository, listsRepo repository.ListsRepository,
+	tagsRepo repository.TagsRepository, passwords hash.PasswordHasher) *SharesService {
+	return &SharesService{
+		repo:      repo,
+		listsRepo: listsRepo,
+		tagsRepo:  tagsRepo,
+		passwords: passwords,
+	}
+}
+
+// Publish shares the list, or replaces the password and the expiry of its share.
+// An empty password makes the share public
+func (s *SharesService) Publish(ctx context.Context, share *domain.ListShare, password string) error {
+	if _, err := s.listsRepo.Get(ctx, share.UserID, share.ListID); err != nil {
+		return err
+	} else if share.IsExpired(time.Now()) {
+		return domain.ErrInvalidShareExpiry
+	} else if len(password) > hash.MaxPasswordLength {
+		return domain.ErrInvalidSharePassword
+	}
+
+	slug, err := newShareSlug()
+	if err != nil {
+		return err
+	}
+	share.Slug = slug
+
+	share.PasswordHash = ""
+	if password != "" {
+		if share.PasswordHash, err = s.passwords.Hash(password); err != nil {
+			return err
+		}
+	}
+	return s.repo.Save(ctx, share)
+}
+
+func (s *SharesService) Get(ctx context.Context, userID, listID uuid.UUID) (domain.ListShare, error) {
+	return s.repo.Get(ctx, userID, listID)
+}
+
+// Regenerate replaces the slug of the share, so the old link stops working
+func (s *SharesService) Regenerate(ctx context.Context, userID, listID uuid.UUID) (domain.ListShare, error) {
+	slug, err := newShareSlug()
+	if err != nil {
+		return domain.ListShare{}, err
+	}
+
+	share := domain.ListShare{ListID: listID, UserID: userID, Slug: slug}
+	if err = s.repo.UpdateSlug(ctx, &share); err != nil {
+		return domain.ListShare{}, err
+	}
+	return share, nil
+}
+
+func (s *SharesService) Revoke(ctx context.Context, userID, listID uuid.UUID) error {
+	return s.repo.Delete(ctx, userID, listID)
+}
+
+// DeleteExpired deletes the shares expired before the time, returning how many have been deleted
+func (s *SharesService) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	return s.repo.DeleteExpired(ctx, before)
+}
+
+// View returns a page of the shared list. Only the first pages are counted as views,
+// so paging through the list does not inflate them
+func (s *SharesService) View(ctx context.Context, slug, password string, params pagination.Params) (SharedList, error) {
+	share, err := s.repo.GetBySlug(ctx, slug)
+	if err != nil {
+		return SharedList{}, err
+	} else if share.IsExpired(time.Now()) {
+		return SharedList{}, domain.ErrShareExpired
+	} else if share.HasPassword() && !s.passwords.Compare(share.PasswordHash, password) {
+		return SharedList{}, domain.ErrSharePasswordRequired
+	}
+
+	list, err := s.listsRepo.Get(ctx, share.UserID, share.ListID)
+	if err != nil {
+		return SharedList{}, err
+	}
+
+	links, err := s.listsRepo.GetLinksPage(ctx, share.UserID, share.ListID, params)
+	if err != nil {
+		return SharedList{}, err
+	}
+	if err = attachTags(ctx, s.tagsRepo, links.Data); err != nil {
+		return SharedList{}, err
+	}
+
+	if params.After == nil && params.Before == nil {
+		if err = s.repo.IncrementViews(ctx, share.ListID); err != nil {
+			slog.WarnContext(ctx, "failed to count share view", "list_id", share.ListID, logError, err)
+		}
+	}
+	return SharedList{List: list, Links: links}, nil
+}
+
+func newShareSlug() (string, error) {
+	b := make([]byte, shareSlugBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
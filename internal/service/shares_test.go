@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/internal/repository"
+	"github.com/adanyl0v/go-pocket-link/pkg/crypto/hash"
+	"github.com/adanyl0v/go-pocket-link/pkg/pagination"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+	"testing"
+)
+
+// fakeSharesRepository keeps the shares by their slugs
+type fakeSharesRepository struct {
+	repository.ListSharesRepository
+	shares map[string]domain.ListShare
+}
+
+func (r *fakeSharesRepository) Save(_ context.Context, share *domain.ListShare) error {
+	r.shares[share.Slug] = *share
+	return nil
+}
+
+func (r *fakeSharesRepository) GetBySlug(_ context.Context, slug string) (domain.ListShare, error) {
+	share, ok := r.shares[slug]
+	if !ok {
+		return domain.ListShare{}, domain.ErrShareNotFound
+	}
+	return share, nil
+}
+
+func (r *fakeSharesRepository) IncrementViews(context.Context, uuid.UUID) error {
+	return nil
+}
+
+// fakeListsRepository has a single empty list
+type fakeListsRepository struct {
+	repository.ListsRepository
+	list domain.List
+}
+
+func (r *fakeListsRepository) Get(_ context.Context, userID, id uuid.UUID) (domain.List, error) {
+	if userID != r.list.UserID || id != r.list.ID {
+		return domain.List{}, domain.ErrListNotFound
+	}
+	return r.list, nil
+}
+
+func (r *fakeListsRepository) GetLinksPage(context.Context, uuid.UUID, uuid.UUID, pagination.Params) (pagination.Page[domain.Link], error) {
+	return pagination.Page[domain.Link]{}, nil
+}
+
+func TestSharesPassword(t *testing.T) {
+	list := domain.List{ID: uuid.New(), UserID: uuid.New()}
+	repo := &fakeSharesRepository{shares: make(map[string]domain.ListShare)}
+	s := NewSharesService(repo, &fakeListsRepository{list: list}, nil, hash.NewBcryptHasher(bcrypt.MinCost))
+	ctx := context.Background()
+
+	protected := domain.ListShare{ListID: list.ID, UserID: list.UserID}
+	if err := s.Publish(ctx, &protected, "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(protected.PasswordHash, "$2a$") {
+		t.Errorf("password hash %q is not a bcrypt one", protected.PasswordHash)
+	}
+
+	// the same password is salted differently for each share
+	other := domain.ListShare{ListID: list.ID, UserID: list.UserID}
+	if err := s.Publish(ctx, &other, "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if other.PasswordHash == protected.PasswordHash {
+		t.Error("the shares of the same password have the same hash")
+	}
+
+	public := domain.ListShare{ListID: list.ID, UserID: list.UserID}
+	if err := s.Publish(ctx, &public, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		slug     string
+		password string
+		want     error
+	}{
+		{"right password", protected.Slug, "secret", nil},
+		{"wrong password", protected.Slug, "Secret", domain.ErrSharePasswordRequired},
+		{"no password", protected.Slug, "", domain.ErrSharePasswordRequired},
+		{"the hash as the password", protected.Slug, protected.PasswordHash, domain.ErrSharePasswordRequired},
+		{"public share", public.Slug, "", nil},
+		{"public share with a password", public.Slug, "anything", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.View(ctx, tt.slug, tt.password, pagination.Params{}); !errors.Is(err, tt.want) {
+				t.Errorf("View = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSharesPasswordTooLong(t *testing.T) {
+	list := domain.List{ID: uuid.New(), UserID: uuid.New()}
+	repo := &fakeSharesRepository{shares: make(map[string]domain.ListShare)}
+	s := NewSharesService(repo, &fakeListsRepository{list: list}, nil, hash.NewBcryptHasher(bcrypt.MinCost))
+
+	share := domain.ListShare{ListID: list.ID, UserID: list.UserID}
+	err := s.Publish(context.Background(), &share, strings.Repeat("a", hash.MaxPasswordLength+1))
+	if !errors.Is(err, domain.ErrInvalidSharePassword) || !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("Publish = %v, want ErrInvalidSharePassword", err)
+	}
+	if len(repo.shares) != 0 {
+		t.Error("the share must not be saved")
+	}
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/internal/repository"
+	"github.com/adanyl0v/go-pocket-link/pkg/archiver"
+	"github.com/adanyl0v/go-pocket-link/pkg/blob"
+	"github.com/adanyl0v/go-pocket-link/pkg/jobs"
+	"github.com/adanyl0v/go-pocket-link/pkg/pagination"
+	"github.com/google/uuid"
+	"log/slog"
+	"time"
+)
+
+// snapshotsBlobPrefix is where the files of the snapshots are kept in the blob store
+const snapshotsBlobPrefix = "snapshots/"
+
+type SnapshotsOptions struct {
+	// Quota is how much storage the snapshots of each user may take in bytes, 0 if there is no limit
+	Quota int64
+	// OrphanTTL is how long the blobs no snapshot refers to are kept before they are deleted, so they are
+	// not deleted while being referred to again, and OrphanBatch is how many of them are deleted at once
+	OrphanTTL   time.Duration
+	OrphanBatch int
+}
+
+// SnapshotsService captures the pages of the links into self-contained files, so they can be viewed once the pages
+// are gone. The files are stored by the hash of their content, the snapshots of the same content sharing them
+type SnapshotsService struct {
+	repo     repository.SnapshotsRepository
+	links    repository.LinksRepository
+	archiver *archiver.Archiver
+	store    blob.Store
+	queue    jobs.Queue
+	opts     SnapshotsOptions
+}
+
+func NewSnapshotsService(repo repository.SnapshotsRepository, links repository.LinksRepository, archiver *archiver.Archiver,
+	store blob.Store, queue jobs.Queue, opts SnapshotsOptions) *SnapshotsService {
+	return &SnapshotsService{
+		repo:     repo,
+		links:    links,
+		archiver: archiver,
+		store:    store,
+		queue:    queue,
+		opts:     opts,
+	}
+}
+
+// snapshotJob is the payload of the capture jobs
+type snapshotJob struct {
+	SnapshotID uuid.UUID `json:"snapshot_id"`
+}
+
+// Capture saves a pending snapshot of the link and queues a job capturing it. The link keeps its previous
+// snapshot in the format until the new one is captured
+func (s *SnapshotsService) Capture(ctx context.Context, userID, linkID uuid.UUID, format domain.SnapshotFormat) (domain.Snapshot, error) {
+	if format != domain.SnapshotFormatHTML && format != domain.SnapshotFormatWARC {
+		return domain.Snapshot{}, domain.ErrInvalidSnapshotFormat
+	}
+	if _, err := s.links.Get(ctx, userID, linkID); err != nil {
+		return domain.Snapshot{}, err
+	}
+
+	if s.opts.Quota > 0 {
+		used, err := s.repo.GetUsage(ctx, userID)
+		if err != nil {
+			return domain.Snapshot{}, err
+		} else if used >= s.opts.Quota {
+			return domain.Snapshot{}, domain.ErrSnapshotQuotaExceeded
+		}
+	}
+
+	snapshot := domain.Snapshot{UserID: userID, LinkID: linkID, Format: format}
+	if err := s.repo.Save(ctx, &snapshot); err != nil {
+		return domain.Snapshot{}, err
+	}
+
+	_, err := jobs.EnqueueJSON(ctx, s.queue, JobCaptureSnapshot, snapshotJob{SnapshotID: snapshot.ID},
+		jobs.Unique("snapshot:"+snapshot.ID.String()))
+	if err != nil && !errors.Is(err, jobs.ErrDuplicate) {
+		return domain.Snapshot{}, err
+	}
+	return snapshot, nil
+}
+
+func (s *SnapshotsService) GetPageByLinkID(ctx context.Context, userID, linkID uuid.UUID,
+	params pagination.Params) (pagination.Page[domain.Snapshot], error) {
+	return s.repo.GetPageByLinkID(ctx, userID, linkID, params)
+}
+
+// Open opens the file of the captured snapshot. The caller must close it
+func (s *SnapshotsService) Open(ctx context.Context, userID, linkID uuid.UUID,
+	format domain.SnapshotFormat) (*blob.Object, domain.Snapshot, error) {
+	snapshot, err := s.repo.Get(ctx, userID, linkID, format)
+	if err != nil {
+		return nil, domain.Snapshot{}, err
+	} else if snapshot.BlobKey == nil {
+		return nil, domain.Snapshot{}, domain.ErrSnapshotNotReady
+	}
+
+	object, err := s.store.Get(ctx, *snapshot.BlobKey)
+	if errors.Is(err, blob.ErrNotFound) {
+		return nil, domain.Snapshot{}, domain.ErrSnapshotNotFound
+	} else if err != nil {
+		return nil, domain.Snapshot{}, err
+	}
+	return object, snapshot, nil
+}
+
+// Delete deletes the snapshots of the link, freeing their storage
+func (s *SnapshotsService) Delete(ctx context.Context, userID, linkID uuid.UUID) error {
+	if _, err := s.links.Get(ctx, userID, linkID); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, userID, linkID)
+}
+
+func (s *SnapshotsService) GetUsage(ctx context.Context, userID uuid.UUID) (domain.SnapshotUsage, error) {
+	used, err := s.repo.GetUsage(ctx, userID)
+	if err != nil {
+		return domain.SnapshotUsage{}, err
+	}
+	return domain.SnapshotUsage{Used: used, Quota: s.opts.Quota}, nil
+}
+
+// DeleteOrphans deletes the files no snapshot refers to anymore, e.g. the ones of the deleted links.
+// It is run by the scheduler, returning how many files have been deleted
+func (s *SnapshotsService) DeleteOrphans(ctx context.Context) (int64, error) {
+	var deleted int64
+	for ctx.Err() == nil {
+		n, err := s.repo.DeleteOrphanBlobs(ctx, time.Now().Add(-s.opts.OrphanTTL), s.opts.OrphanBatch, s.store.Delete)
+		deleted += n
+		if err != nil {
+			return deleted, err
+		} else if n < int64(s.opts.OrphanBatch) {
+			break
+		}
+	}
+	return deleted, ctx.Err()
+}
+
+// HandleJob captures the snapshot of the job. The snapshot is only failed by the last attempt, unless
+// retrying cannot help, e.g. the link is not an HTML page or the quota is exceeded
+func (s *SnapshotsService) HandleJob(ctx context.Context, job jobs.Job) error {
+	var payload snapshotJob
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+
+	snapshot, err := s.repo.GetByID(ctx, payload.SnapshotID)
+	if errors.Is(err, domain.ErrSnapshotNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	} else if snapshot.Status != domain.SnapshotStatusPending {
+		return nil
+	}
+
+	err = s.capture(ctx, &snapshot)
+	if errors.Is(err, archiver.ErrNotHTML) || errors.Is(err, domain.ErrSnapshotQuotaExceeded) {
+		err = jobs.Permanent(err)
+	} else if errors.Is(err, domain.ErrSnapshotNotFound) || errors.Is(err, domain.ErrLinkNotFound) {
+		return nil
+	}
+	if err == nil || willRetry(job, err) {
+		return err
+	}
+
+	snapshot.Status, snapshot.Error = domain.SnapshotStatusFailed, err.Error()
+	slog.DebugContext(ctx, "failed to capture snapshot", "snapshot_id", snapshot.ID, logError, err)
+	return s.repo.Fail(context.WithoutCancel(ctx), &snapshot)
+}
+
+func (s *SnapshotsService) capture(ctx context.Context, snapshot *domain.Snapshot) error {
+	link, err := s.links.Get(ctx, snapshot.UserID, snapshot.LinkID)
+	if err != nil {
+		return err
+	}
+
+	page, err := s.archiver.Capture(ctx, link.URL)
+	if err != nil {
+		return err
+	}
+
+	format := archiver.Format(snapshot.Format)
+	var buf bytes.Buffer
+	if err = archiver.Write(&buf, page, format); err != nil {
+		return err
+	}
+
+	digest := page.Digest()
+	key := snapshotsBlobPrefix + digest + "." + string(format)
+	snapshot.ContentHash, snapshot.BlobKey = digest, &key
+	snapshot.Title = truncateTitle(page.Title)
+	snapshot.Size, snapshot.Resources = int64(buf.Len()), len(page.Resources)
+
+	return s.repo.Complete(ctx, snapshot, s.opts.Quota, func(ctx context.Context) error {
+		return s.store.Put(ctx, key, bytes.NewReader(buf.Bytes()), format.ContentType())
+	})
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/internal/repository"
+	"github.com/google/uuid"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const streamsRelistenDelay = time.Second
+
+type StreamsOptions struct {
+	// BufferSize is the number of events a subscriber may lag behind before it is dropped
+	BufferSize int
+}
+
+// StreamsService logs the published events and streams them to the subscribed devices of their users.
+// The events reach the subscribers through the event log, so the ones connected to other replicas receive them too
+type StreamsService struct {
+	repo repository.EventLogRepository
+	opts StreamsOptions
+
+	mu          sync.Mutex
+	subscribers map[uuid.UUID]map[*streamSubscriber]struct{}
+	stopped     bool
+}
+
+type streamSubscriber struct {
+	events chan domain.LoggedEvent
+}
+
+func NewStreamsService(repo repository.EventLogRepository, opts StreamsOptions) *StreamsService {
+	return &StreamsService{
+		repo:        repo,
+		opts:        opts,
+		subscribers: make(map[uuid.UUID]map[*streamSubscriber]struct{}),
+	}
+}
+
+func (s *StreamsService) HandleEvent(ctx context.Context, event domain.Event) error {
+	_, err := s.repo.Append(ctx, event)
+	return err
+}
+
+// Subscribe streams the events of the user following the one with lastEventID, or only the new ones if it is empty.
+// reset is true if the events following lastEventID are no longer logged, so the client has to fetch all its data again.
+// The channel is closed when ctx is done, the service stops or the subscriber falls too far behind
+func (s *StreamsService) Subscribe(ctx context.Context, userID uuid.UUID, lastEventID string) (
+	events <-chan domain.LoggedEvent, reset bool, err error) {
+	sub := &streamSubscriber{events: make(chan domain.LoggedEvent, s.opts.BufferSize)}
+	if !s.subscribe(userID, sub) {
+		return nil, false, domain.ErrStreamUnavailable
+	}
+
+	// the subscriber is registered before reading the log, so no event falls in between
+	var missed []domain.LoggedEvent
+	if lastEventID != "" {
+		var ok bool
+		missed, ok, err = s.repo.GetAfter(ctx, userID, lastEventID)
+		if err != nil {
+			s.unsubscribe(userID, sub)
+			return nil, false, err
+		}
+		reset = !ok
+	}
+
+	out := make(chan domain.LoggedEvent)
+	go func() {
+		defer close(out)
+		defer s.unsubscribe(userID, sub)
+
+		sent := make(map[uuid.UUID]struct{}, len(missed))
+		for _, event := range missed {
+			select {
+			case out <- event:
+				sent[event.Event.ID] = struct{}{}
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		for {
+			select {
+			case event, ok := <-sub.events:
+				if !ok {
+					return
+				} else if _, ok = sent[event.Event.ID]; ok {
+					continue
+				}
+
+				select {
+				case out <- event:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, reset, nil
+}
+
+// Run passes the logged events to the subscribers until ctx is done, then closes their streams
+func (s *StreamsService) Run(ctx context.Context) {
+	defer s.stop()
+
+	for {
+		if err := s.repo.Listen(ctx, s.dispatch); err != nil {
+			slog.ErrorContext(ctx, "failed to listen for events", logError, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(streamsRelistenDelay):
+		}
+	}
+}
+
+func (s *StreamsService) dispatch(event domain.LoggedEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for sub := range s.subscribers[event.Event.UserID] {
+		select {
+		case sub.events <- event:
+		default:
+			// the client resumes from the last event it got once it reconnects
+			slog.Warn("dropped slow event stream subscriber", "user_id", event.Event.UserID)
+			s.remove(event.Event.UserID, sub)
+		}
+	}
+}
+
+func (s *StreamsService) subscribe(userID uuid.UUID, sub *streamSubscriber) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return false
+	}
+	if s.subscribers[userID] == nil {
+		s.subscribers[userID] = make(map[*streamSubscriber]struct{})
+	}
+	s.subscribers[userID][sub] = struct{}{}
+	return true
+}
+
+func (s *StreamsService) unsubscribe(userID uuid.UUID, sub *streamSubscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.remove(userID, sub)
+}
+
+// remove must be called with mu locked
+func (s *StreamsService) remove(userID uuid.UUID, sub *streamSubscriber) {
+	if _, ok := s.subscribers[userID][sub]; !ok {
+		return
+	}
+
+	close(sub.events)
+	delete(s.subscribers[userID], sub)
+	if len(s.subscribers[userID]) == 0 {
+		delete(s.subscribers, userID)
+	}
+}
+
+func (s *StreamsService) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stopped = true
+	for userID, subs := range s.subscribers {
+		for sub := range subs {
+			s.remove(userID, sub)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/internal/repository"
+	"github.com/adanyl0v/go-pocket-link/pkg/backoff"
+	"github.com/adanyl0v/go-pocket-link/pkg/feed"
+	"github.com/adanyl0v/go-pocket-link/pkg/pagination"
+	"github.com/google/uuid"
+	"log/slog"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// maxFeedTitleLength is the length of the titles of the links and the subscriptions
+const maxFeedTitleLength = 512
+
+type SubscriptionsOptions struct {
+	// FetchInterval is how often each feed is fetched, the failed fetches are retried
+	// after it is doubled for each failure in a row up to MaxDelay
+	FetchInterval time.Duration
+	MaxDelay      time.Duration
+	// BatchSize is how many subscriptions are claimed and fetched concurrently
+	BatchSize int
+	// ItemsLimit is how many new items of a feed are saved at once, the rest are skipped
+	ItemsLimit int
+	// Lease is how long a claimed subscription is not claimed again, it must exceed the client timeout
+	Lease time.Duration
+}
+
+// SubscriptionsService polls the feeds the users are subscribed to, saving their new items as links
+type SubscriptionsService struct {
+	repo    repository.SubscriptionsRepository
+	links   *LinksService
+	lists   *ListsService
+	fetcher *feed.Fetcher
+	opts    SubscriptionsOptions
+}
+
+func NewSubscriptionsService(repo repository.SubscriptionsRepository, links *LinksService, lists *ListsService,
+	fetcher *feed.Fetcher, opts SubscriptionsOptions) *SubscriptionsService {
+	return &SubscriptionsService{
+		repo:    repo,
+		links:   links,
+		lists:   lists,
+		fetcher: fetcher,
+		opts:    opts,
+	}
+}
+
+// Create saves the subscription, which is fetched by the next poll. Only the items published
+// after the subscription has been created are saved
+func (s *SubscriptionsService) Create(ctx context.Context, sub *domain.FeedSubscription) error {
+	u, err := url.Parse(sub.URL)
+	if err != nil || !u.IsAbs() || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return domain.ErrInvalidSubscriptionURL
+	}
+	if err = s.validate(ctx, sub); err != nil {
+		return err
+	}
+
+	sub.NextFetchAt = time.Now()
+	return s.repo.Save(ctx, sub)
+}
+
+func (s *SubscriptionsService) Get(ctx context.Context, userID, id uuid.UUID) (domain.FeedSubscription, error) {
+	return s.repo.Get(ctx, userID, id)
+}
+
+func (s *SubscriptionsService) GetPage(ctx context.Context, userID uuid.UUID, params pagination.Params) (pagination.Page[domain.FeedSubscription], error) {
+	return s.repo.GetPage(ctx, userID, params)
+}
+
+// Update changes the list, the tags and whether the subscription is active. A reactivated
+// subscription is fetched by the next poll
+func (s *SubscriptionsService) Update(ctx context.Context, sub *domain.FeedSubscription) error {
+	current, err := s.repo.Get(ctx, sub.UserID, sub.ID)
+	if err != nil {
+		return err
+	}
+	if err = s.validate(ctx, sub); err != nil {
+		return err
+	}
+
+	current.ListID, current.Tags = sub.ListID, sub.Tags
+	if sub.Active && !current.Active {
+		current.NextFetchAt = time.Now()
+	}
+	current.Active = sub.Active
+	if err = s.repo.Update(ctx, &current); err != nil {
+		return err
+	}
+	*sub = current
+	return nil
+}
+
+func (s *SubscriptionsService) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	return s.repo.Delete(ctx, userID, id)
+}
+
+// Refresh fetches the feed right away, returning the outcome along with the updated subscription
+func (s *SubscriptionsService) Refresh(ctx context.Context, userID, id uuid.UUID) (domain.FeedSubscriptionFetch, error) {
+	sub, err := s.repo.Get(ctx, userID, id)
+	if err != nil {
+		return domain.FeedSubscriptionFetch{}, err
+	}
+	return s.fetch(ctx, sub)
+}
+
+// validate normalizes the tags and checks the user can add links to the list
+func (s *SubscriptionsService) validate(ctx context.Context, sub *domain.FeedSubscription) error {
+	tags, err := normalizeTagNames(sub.Tags)
+	if err != nil {
+		return err
+	}
+	sub.Tags = tags
+
+	if sub.ListID != nil {
+		if _, err = s.lists.Authorize(ctx, sub.UserID, *sub.ListID, domain.ListRoleEditor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RefreshDue fetches the due feeds, claiming them in batches until fewer than a batch are due.
+// It is run by the scheduler, returning how many feeds have been fetched
+func (s *SubscriptionsService) RefreshDue(ctx context.Context) (int64, error) {
+	var fetched int64
+	for ctx.Err() == nil {
+		n, err := s.poll(ctx)
+		fetched += int64(n)
+		if err != nil {
+			return fetched, err
+		} else if n < s.opts.BatchSize {
+			break
+		}
+	}
+	return fetched, ctx.Err()
+}
+
+// poll claims a batch of subscriptions and fetches them, returning how many have been claimed
+func (s *SubscriptionsService) poll(ctx context.Context) (int, error) {
+	subscriptions, err := s.repo.Claim(ctx, s.opts.BatchSize, s.opts.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	// the fetches in flight are finished on shutdown, the client timeout bounds them
+	fetchCtx := context.WithoutCancel(ctx)
+
+	var wg sync.WaitGroup
+	for _, sub := range subscriptions {
+		wg.Add(1)
+		go func(sub domain.FeedSubscription) {
+			defer wg.Done()
+			if _, err := s.fetch(fetchCtx, sub); err != nil {
+				slog.ErrorContext(ctx, "failed to fetch feed subscription", "subscription_id", sub.ID, logError, err)
+			}
+		}(sub)
+	}
+	wg.Wait()
+	return len(subscriptions), nil
+}
+
+// fetch fetches the feed once, saving its new items and scheduling the next fetch. The failures of the fetch
+// are recorded in the subscription, only the ones of recording them are returned
+func (s *SubscriptionsService) fetch(ctx context.Context, sub domain.FeedSubscription) (domain.FeedSubscriptionFetch, error) {
+	resp, err := s.fetcher.Fetch(ctx, feed.Request{URL: sub.URL, ETag: sub.ETag, LastModified: sub.LastModified})
+
+	result := domain.FeedSubscriptionFetch{NotModified: resp.NotModified}
+	if err == nil && !resp.NotModified {
+		result.Saved, err = s.saveItems(ctx, sub, resp.Feed.Items)
+	}
+
+	now := time.Now()
+	switch {
+	case err == nil:
+		if title := truncateTitle(resp.Feed.Title); title != "" {
+			sub.Title = title
+		}
+		sub.ETag, sub.LastModified = resp.ETag, resp.LastModified
+		sub.Failures, sub.LastError, sub.FetchedAt = 0, "", &now
+		sub.NextFetchAt = now.Add(max(s.opts.FetchInterval, resp.RetryAfter))
+	case errors.Is(err, feed.ErrGone):
+		sub.Active, sub.LastError = false, err.Error()
+		slog.InfoContext(ctx, "feed has been removed, deactivating its subscription", "subscription_id", sub.ID)
+	default:
+		sub.Failures++
+		sub.LastError = err.Error()
+		delay := backoff.Exponential(sub.Failures, s.opts.FetchInterval, s.opts.MaxDelay)
+		sub.NextFetchAt = now.Add(max(delay, resp.RetryAfter))
+		slog.DebugContext(ctx, "failed to fetch feed", "subscription_id", sub.ID, "failures", sub.Failures, logError, err)
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	if err = s.repo.UpdateFetch(ctx, &sub); err != nil {
+		return domain.FeedSubscriptionFetch{}, err
+	}
+	result.Subscription = sub
+	return result, nil
+}
+
+// saveItems saves the items not seen yet, returning how many have been saved. The first fetch only saves
+// the items published after the subscription has been created, the older ones are only marked as seen
+func (s *SubscriptionsService) saveItems(ctx context.Context, sub domain.FeedSubscription, items []feed.Item) (int, error) {
+	hashes := make([]string, 0, len(items))
+	byHash := make(map[string]feed.Item, len(items))
+	for _, item := range items {
+		if item.ID == "" {
+			continue
+		}
+
+		sum := sha256.Sum256([]byte(item.ID))
+		hash := hex.EncodeToString(sum[:])
+		if _, ok := byHash[hash]; !ok {
+			hashes = append(hashes, hash)
+			byHash[hash] = item
+		}
+	}
+	if len(hashes) == 0 {
+		return 0, nil
+	}
+
+	unseen, err := s.repo.GetUnseenItems(ctx, sub.ID, hashes)
+	if err != nil || len(unseen) == 0 {
+		return 0, err
+	}
+
+	// the feeds list their latest items first, the ones over the limit are skipped,
+	// and the rest are saved from the oldest, so the links are ordered as published
+	unseen = slices.DeleteFunc(unseen, func(hash string) bool {
+		item := byHash[hash]
+		return sub.FetchedAt == nil && !item.Published.After(sub.CreatedAt)
+	})
+	if len(unseen) > s.opts.ItemsLimit {
+		unseen = unseen[:s.opts.ItemsLimit]
+	}
+	slices.Reverse(unseen)
+
+	linkIDs := make([]uuid.UUID, 0, len(unseen))
+	for _, hash := range unseen {
+		item := byHash[hash]
+		link := domain.Link{UserID: sub.UserID, URL: item.URL, Title: truncateTitle(item.Title), Tags: sub.Tags}
+		if _, err = s.links.Save(ctx, &link); errors.Is(err, domain.ErrInvalidInput) {
+			slog.DebugContext(ctx, "skipped feed item", "subscription_id", sub.ID, "item_id", item.ID, logError, err)
+			continue
+		} else if err != nil {
+			return 0, err
+		}
+		linkIDs = append(linkIDs, link.ID)
+	}
+
+	if sub.ListID != nil && len(linkIDs) > 0 {
+		if err = s.lists.AddLinks(ctx, sub.UserID, *sub.ListID, linkIDs); err != nil {
+			return 0, err
+		}
+	}
+
+	// the items are only seen once they are saved, so a failed fetch saves them next time,
+	// saving the already saved links again being harmless
+	if err = s.repo.SaveSeenItems(ctx, sub.ID, hashes); err != nil {
+		return 0, err
+	}
+	return len(linkIDs), nil
+}
+
+func truncateTitle(title string) string {
+	if utf8.RuneCountInString(title) <= maxFeedTitleLength {
+		return title
+	}
+	return string([]rune(title)[:maxFeedTitleLength])
+}
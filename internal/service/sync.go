@@ -0,0 +1,349 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/internal/repository"
+	"github.com/google/uuid"
+	"slices"
+	"strconv"
+)
+
+const (
+	defaultSyncLimit = 500
+	maxSyncLimit     = 1000
+)
+
+// SyncService lets the offline clients pull the changes made since their last sync and push their own ones
+type SyncService struct {
+	repo      repository.SyncRepository
+	linksRepo repository.LinksRepository
+	listsRepo repository.ListsRepository
+	links     *LinksService
+	lists     *ListsService
+	tags      *TagsService
+}
+
+func NewSyncService(repo repository.SyncRepository, linksRepo repository.LinksRepository, listsRepo repository.ListsRepository,
+	links *LinksService, lists *ListsService, tags *TagsService) *SyncService {
+	return &SyncService{
+		repo:      repo,
+		linksRepo: linksRepo,
+		listsRepo: listsRepo,
+		links:     links,
+		lists:     lists,
+		tags:      tags,
+	}
+}
+
+// GetChanges returns the changes made after the token, or all entities if it is empty.
+// The number of changes is limited to a maximum, in which case HasMore is set
+func (s *SyncService) GetChanges(ctx context.Context, userID uuid.UUID, token string, limit int) (domain.SyncDelta, error) {
+	since, err := decodeSyncToken(token)
+	if err != nil {
+		return domain.SyncDelta{}, err
+	}
+
+	if limit <= 0 {
+		limit = defaultSyncLimit
+	}
+	limit = min(limit, maxSyncLimit)
+
+	changes, err := s.repo.GetChanges(ctx, userID, since, limit+1)
+	if err != nil {
+		return domain.SyncDelta{}, err
+	}
+
+	delta := domain.SyncDelta{HasMore: len(changes) > limit}
+	if delta.HasMore {
+		changes = changes[:limit]
+	}
+	if len(changes) > 0 {
+		since = changes[len(changes)-1].Seq
+	}
+	delta.Token = encodeSyncToken(since)
+
+	if err = s.attachEntities(ctx, userID, changes); err != nil {
+		return domain.SyncDelta{}, err
+	}
+	delta.Changes = changes
+	return delta, nil
+}
+
+// attachEntities sets the current state of the changed entities. The ones deleted after the changes
+// have been read are returned as deleted, their tombstones come with the next sync
+func (s *SyncService) attachEntities(ctx context.Context, userID uuid.UUID, changes []domain.SyncChange) error {
+	var linkIDs, listIDs []uuid.UUID
+	for _, change := range changes {
+		if change.Deleted {
+			continue
+		}
+
+		switch change.Type {
+		case domain.SyncEntityLink:
+			linkIDs = append(linkIDs, change.ID)
+		case domain.SyncEntityList:
+			listIDs = append(listIDs, change.ID)
+		}
+	}
+
+	entities := make(map[uuid.UUID]any, len(linkIDs)+len(listIDs))
+	versions := make(map[uuid.UUID]int64, len(linkIDs)+len(listIDs))
+	if len(linkIDs) > 0 {
+		links, err := s.linksRepo.GetByIDs(ctx, userID, linkIDs)
+		if err != nil {
+			return err
+		}
+		for _, link := range links {
+			entities[link.ID], versions[link.ID] = link, link.Version
+		}
+	}
+	if len(listIDs) > 0 {
+		lists, err := s.listsRepo.GetByIDsWithLinkIDs(ctx, userID, listIDs)
+		if err != nil {
+			return err
+		}
+		for _, list := range lists {
+			if list.LinkIDs == nil {
+				list.LinkIDs = []uuid.UUID{}
+			}
+			entities[list.ID], versions[list.ID] = list, list.Version
+		}
+	}
+
+	for i := range changes {
+		if changes[i].Deleted {
+			continue
+		}
+
+		entity, ok := entities[changes[i].ID]
+		if !ok {
+			changes[i].Deleted = true
+			continue
+		}
+		changes[i].Data = entity
+		changes[i].Version = versions[changes[i].ID]
+	}
+	return nil
+}
+
+// Push applies the mutations in order. The mutations conflicting with the changes made since their base
+// versions or being invalid are not applied, which does not stop applying the next ones
+func (s *SyncService) Push(ctx context.Context, userID uuid.UUID, mutations []domain.SyncMutation) ([]domain.SyncMutationResult, error) {
+	results := make([]domain.SyncMutationResult, 0, len(mutations))
+	for _, mutation := range mutations {
+		var result domain.SyncMutationResult
+		var err error
+		switch mutation.Type {
+		case domain.SyncEntityLink:
+			result, err = s.pushLink(ctx, userID, mutation)
+		case domain.SyncEntityList:
+			result, err = s.pushList(ctx, userID, mutation)
+		default:
+			err = domain.ErrInvalidSyncMutation
+		}
+
+		if err != nil {
+			result, err = s.failedMutationResult(ctx, userID, mutation, err)
+			if err != nil {
+				return nil, err
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (s *SyncService) pushLink(ctx context.Context, userID uuid.UUID, mutation domain.SyncMutation) (domain.SyncMutationResult, error) {
+	if mutation.Op == domain.SyncOperationDelete {
+		err := s.links.DeleteVersion(ctx, userID, mutation.ID, mutation.BaseVersion)
+		if err != nil && !errors.Is(err, domain.ErrLinkNotFound) {
+			return domain.SyncMutationResult{}, err
+		}
+		return domain.SyncMutationResult{Status: domain.SyncMutationApplied, ID: mutation.ID}, nil
+	}
+
+	var data domain.SyncLinkData
+	if err := json.Unmarshal(mutation.Data, &data); err != nil {
+		return domain.SyncMutationResult{}, domain.ErrInvalidSyncMutation
+	}
+
+	var link domain.Link
+	switch mutation.Op {
+	case domain.SyncOperationCreate:
+		// creating a link which is already saved returns it, so the retried creations are harmless
+		link = domain.Link{UserID: userID, URL: data.URL, Title: data.Title, Tags: data.Tags}
+		if _, err := s.links.Save(ctx, &link); err != nil {
+			return domain.SyncMutationResult{}, err
+		}
+	case domain.SyncOperationUpdate:
+		var err error
+		if link, err = s.links.Get(ctx, userID, mutation.ID); err != nil {
+			return domain.SyncMutationResult{}, err
+		}
+
+		link.URL, link.Title, link.Version = data.URL, data.Title, mutation.BaseVersion
+		if err = s.links.Update(ctx, &link); err != nil {
+			return domain.SyncMutationResult{}, err
+		}
+		if data.Tags != nil {
+			if err = s.setLinkTags(ctx, userID, link, data.Tags); err != nil {
+				return domain.SyncMutationResult{}, err
+			}
+		}
+	default:
+		return domain.SyncMutationResult{}, domain.ErrInvalidSyncMutation
+	}
+
+	// the tags changing the version, it is read again
+	link, err := s.links.Get(ctx, userID, link.ID)
+	if err != nil {
+		return domain.SyncMutationResult{}, err
+	}
+	return domain.SyncMutationResult{Status: domain.SyncMutationApplied, ID: link.ID, Version: link.Version}, nil
+}
+
+func (s *SyncService) setLinkTags(ctx context.Context, userID uuid.UUID, link domain.Link, names []string) error {
+	names, err := normalizeTagNames(names)
+	if err != nil {
+		return err
+	}
+
+	added, removed := diff(link.Tags, names)
+	if len(added) > 0 {
+		if err = s.tags.AddToLinks(ctx, userID, []uuid.UUID{link.ID}, added); err != nil {
+			return err
+		}
+	}
+	if len(removed) > 0 {
+		return s.tags.RemoveFromLinks(ctx, userID, []uuid.UUID{link.ID}, removed)
+	}
+	return nil
+}
+
+func (s *SyncService) pushList(ctx context.Context, userID uuid.UUID, mutation domain.SyncMutation) (domain.SyncMutationResult, error) {
+	if mutation.Op == domain.SyncOperationDelete {
+		err := s.lists.DeleteVersion(ctx, userID, mutation.ID, mutation.BaseVersion)
+		if err != nil && !errors.Is(err, domain.ErrListNotFound) {
+			return domain.SyncMutationResult{}, err
+		}
+		return domain.SyncMutationResult{Status: domain.SyncMutationApplied, ID: mutation.ID}, nil
+	}
+
+	var data domain.SyncListData
+	if err := json.Unmarshal(mutation.Data, &data); err != nil {
+		return domain.SyncMutationResult{}, domain.ErrInvalidSyncMutation
+	}
+
+	var list domain.List
+	var err error
+	switch mutation.Op {
+	case domain.SyncOperationCreate:
+		// the titles of the lists are unique, so the retried creations return the created list
+		if list, err = s.lists.GetOrCreate(ctx, userID, data.Title); err != nil {
+			return domain.SyncMutationResult{}, err
+		}
+	case domain.SyncOperationUpdate:
+		if list, err = s.lists.Get(ctx, userID, mutation.ID); err != nil {
+			return domain.SyncMutationResult{}, err
+		}
+
+		list.Title, list.Version = data.Title, mutation.BaseVersion
+		if err = s.lists.Update(ctx, userID, &list); err != nil {
+			return domain.SyncMutationResult{}, err
+		}
+	default:
+		return domain.SyncMutationResult{}, domain.ErrInvalidSyncMutation
+	}
+
+	if data.LinkIDs != nil {
+		if err = s.setListLinks(ctx, userID, list, data.LinkIDs); err != nil {
+			return domain.SyncMutationResult{}, err
+		}
+	}
+
+	if list, err = s.lists.Get(ctx, userID, list.ID); err != nil {
+		return domain.SyncMutationResult{}, err
+	}
+	return domain.SyncMutationResult{Status: domain.SyncMutationApplied, ID: list.ID, Version: list.Version}, nil
+}
+
+func (s *SyncService) setListLinks(ctx context.Context, userID uuid.UUID, list domain.List, linkIDs []uuid.UUID) error {
+	lists, err := s.listsRepo.GetByIDsWithLinkIDs(ctx, list.UserID, []uuid.UUID{list.ID})
+	if err != nil {
+		return err
+	} else if len(lists) == 0 {
+		return domain.ErrListNotFound
+	}
+
+	added, removed := diff(lists[0].LinkIDs, linkIDs)
+	if len(added) > 0 {
+		if err = s.lists.AddLinks(ctx, userID, list.ID, added); err != nil {
+			return err
+		}
+	}
+	if len(removed) > 0 {
+		return s.lists.RemoveLinks(ctx, userID, list.ID, removed)
+	}
+	return nil
+}
+
+// failedMutationResult turns the error of the mutation into its result, returning the errors
+// which are not caused by the mutation itself
+func (s *SyncService) failedMutationResult(ctx context.Context, userID uuid.UUID, mutation domain.SyncMutation,
+	err error) (domain.SyncMutationResult, error) {
+	switch {
+	case errors.Is(err, domain.ErrConflict):
+		result := domain.SyncMutationResult{Status: domain.SyncMutationConflict, ID: mutation.ID, Error: err.Error()}
+		changes := []domain.SyncChange{{Type: mutation.Type, ID: mutation.ID}}
+		if err = s.attachEntities(ctx, userID, changes); err != nil {
+			return domain.SyncMutationResult{}, err
+		}
+		result.Current, result.Version = changes[0].Data, changes[0].Version
+		return result, nil
+	case errors.Is(err, domain.ErrInvalidInput), errors.Is(err, domain.ErrNotFound),
+		errors.Is(err, domain.ErrAlreadyExists), errors.Is(err, domain.ErrForbidden):
+		return domain.SyncMutationResult{Status: domain.SyncMutationRejected, ID: mutation.ID, Error: err.Error()}, nil
+	default:
+		return domain.SyncMutationResult{}, err
+	}
+}
+
+// diff returns the values of target missing from current and the values of current missing from target
+func diff[T comparable](current, target []T) (added, removed []T) {
+	for _, v := range target {
+		if !slices.Contains(current, v) {
+			added = append(added, v)
+		}
+	}
+	for _, v := range current {
+		if !slices.Contains(target, v) {
+			removed = append(removed, v)
+		}
+	}
+	return added, removed
+}
+
+// the sync tokens are opaque to the clients, so the way the changes are numbered may change
+func encodeSyncToken(seq int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(seq, 10)))
+}
+
+func decodeSyncToken(token string) (int64, error) {
+	if token == "" {
+		return 0, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, domain.ErrInvalidSyncToken
+	}
+	seq, err := strconv.ParseInt(string(raw), 10, 64)
+	if err != nil || seq < 0 {
+		return 0, domain.ErrInvalidSyncToken
+	}
+	return seq, nil
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/internal/repository"
+	"github.com/adanyl0v/go-pocket-link/pkg/pagination"
+	"github.com/google/uuid"
+	"strings"
+	"unicode/utf8"
+)
+
+const maxTagNameLength = 64
+
+type TagsService struct {
+	repo   repository.TagsRepository
+	events *EventBus
+}
+
+func NewTagsService(repo repository.TagsRepository, events *EventBus) *TagsService {
+	return &TagsService{
+		repo:   repo,
+		events: events,
+	}
+}
+
+func (s *TagsService) GetPage(ctx context.Context, userID uuid.UUID, params pagination.Params) (pagination.Page[domain.TagUsage], error) {
+	return s.repo.GetPage(ctx, userID, params)
+}
+
+func (s *TagsService) AddToLinks(ctx context.Context, userID uuid.UUID, linkIDs []uuid.UUID, names []string) error {
+	names, err := normalizeTagNames(names)
+	if err != nil {
+		return err
+	}
+	if err = s.repo.AddToLinks(ctx, userID, linkIDs, names); err != nil {
+		return err
+	}
+
+	s.events.Publish(ctx, domain.NewEvent(domain.EventLinkTagged, userID, domain.LinkIDsEventData{
+		LinkIDs: linkIDs,
+		Tags:    names,
+	}))
+	return nil
+}
+
+func (s *TagsService) RemoveFromLinks(ctx context.Context, userID uuid.UUID, linkIDs []uuid.UUID, names []string) error {
+	names, err := normalizeTagNames(names)
+	if err != nil {
+		return err
+	}
+	if err = s.repo.RemoveFromLinks(ctx, userID, linkIDs, names); err != nil {
+		return err
+	}
+
+	s.events.Publish(ctx, domain.NewEvent(domain.EventLinkUntagged, userID, domain.LinkIDsEventData{
+		LinkIDs: linkIDs,
+		Tags:    names,
+	}))
+	return nil
+}
+
+func (s *TagsService) Rename(ctx context.Context, userID uuid.UUID, oldName, newName string) error {
+	oldName, err := normalizeTagName(oldName)
+	if err != nil {
+		return err
+	}
+
+	newName, err = normalizeTagName(newName)
+	if err != nil {
+		return err
+	} else if oldName == newName {
+		return nil
+	}
+
+	return s.repo.Rename(ctx, userID, oldName, newName)
+}
+
+func (s *TagsService) Merge(ctx context.Context, userID uuid.UUID, source, target string) error {
+	source, err := normalizeTagName(source)
+	if err != nil {
+		return err
+	}
+
+	target, err = normalizeTagName(target)
+	if err != nil {
+		return err
+	} else if source == target {
+		return domain.ErrSameTag
+	}
+
+	return s.repo.Merge(ctx, userID, source, target)
+}
+
+func (s *TagsService) Delete(ctx context.Context, userID uuid.UUID, name string) error {
+	name, err := normalizeTagName(name)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, userID, name)
+}
+
+// normalizeTagNames normalizes each of the names and drops the duplicates
+func normalizeTagNames(names []string) ([]string, error) {
+	normalized := make([]string, 0, len(names))
+	seen := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		name, err := normalizeTagName(name)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		normalized = append(normalized, name)
+	}
+	return normalized, nil
+}
+
+func normalizeTagName(name string) (string, error) {
+	name = strings.ToLower(strings.Join(strings.Fields(name), " "))
+	if name == "" || utf8.RuneCountInString(name) > maxTagNameLength {
+		return "", domain.ErrInvalidTagName
+	}
+	return name, nil
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/pkg/jobs"
+	"github.com/adanyl0v/go-pocket-link/pkg/pagination"
+	"github.com/adanyl0v/go-pocket-link/pkg/scheduler"
+)
+
+// The names of the maintenance tasks run by the scheduler
+const (
+	TaskPurgeAccounts        = "purge_accounts"
+	TaskRefreshSubscriptions = "refresh_subscriptions"
+	TaskCleanShares          = "clean_shares"
+	TaskCheckLinks           = "check_links"
+	TaskCleanSnapshots       = "clean_snapshots"
+	TaskCleanExports         = "clean_exports"
+)
+
+// TaskInfo is a scheduled task along with its last run, nil if it has not been run yet
+type TaskInfo struct {
+	scheduler.Task
+	LastRun *scheduler.Run `json:"last_run"`
+}
+
+// TasksService lets the admins see the scheduled tasks and trigger them
+type TasksService struct {
+	scheduler *scheduler.Scheduler
+	queue     jobs.Queue
+}
+
+func NewTasksService(scheduler *scheduler.Scheduler, queue jobs.Queue) *TasksService {
+	return &TasksService{
+		scheduler: scheduler,
+		queue:     queue,
+	}
+}
+
+// taskJob is the payload of the jobs running the triggered tasks
+type taskJob struct {
+	Task string `json:"task"`
+}
+
+// GetAll returns the tasks in a single page, since there are only a few of them
+func (s *TasksService) GetAll(ctx context.Context) (pagination.Page[TaskInfo], error) {
+	lastRuns, err := s.scheduler.LastRuns(ctx)
+	if err != nil {
+		return pagination.Page[TaskInfo]{}, err
+	}
+
+	tasks := s.scheduler.Tasks()
+	infos := make([]TaskInfo, 0, len(tasks))
+	for _, task := range tasks {
+		info := TaskInfo{Task: task}
+		if run, ok := lastRuns[task.Name]; ok {
+			info.LastRun = &run
+		}
+		infos = append(infos, info)
+	}
+	return pagination.Page[TaskInfo]{Data: infos, Page: pagination.Info{Limit: len(infos)}}, nil
+}
+
+func (s *TasksService) GetRuns(ctx context.Context, name string, params pagination.Params) (pagination.Page[scheduler.Run], error) {
+	page, err := s.scheduler.Runs(ctx, name, params)
+	if errors.Is(err, scheduler.ErrUnknownTask) {
+		return pagination.Page[scheduler.Run]{}, domain.ErrTaskNotFound
+	}
+	return page, err
+}
+
+// Trigger queues a job running the task right away on any instance, returning the id of the job.
+// A task is only triggered once until its job runs
+func (s *TasksService) Trigger(ctx context.Context, name string) (string, error) {
+	if !s.scheduler.HasTask(name) {
+		return "", domain.ErrTaskNotFound
+	}
+
+	jobID, err := jobs.EnqueueJSON(ctx, s.queue, JobRunTask, taskJob{Task: name}, jobs.Unique("task:"+name))
+	if errors.Is(err, jobs.ErrDuplicate) {
+		return "", domain.ErrTaskAlreadyTriggered
+	}
+	return jobID, err
+}
+
+// HandleJob runs the triggered task. The job is retried while the task is being run already, so the task
+// runs once more after that. The failures of the task itself are recorded in its history, so they are not retried
+func (s *TasksService) HandleJob(ctx context.Context, job jobs.Job) error {
+	var payload taskJob
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+
+	run, err := s.scheduler.RunNow(ctx, payload.Task)
+	switch {
+	case errors.Is(err, scheduler.ErrUnknownTask):
+		return jobs.Permanent(err)
+	case run.ID == "" || ctx.Err() != nil:
+		// the task has not been run, e.g. as it is being run already, or has been interrupted by the shutdown
+		return err
+	}
+	return nil
+}
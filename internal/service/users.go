@@ -2,38 +2,62 @@ package service
 
 import (
 	"context"
+	"errors"
 	"github.com/adanyl0v/go-pocket-link/internal/domain"
 	"github.com/adanyl0v/go-pocket-link/internal/repository"
 	"github.com/adanyl0v/go-pocket-link/pkg/crypto/hash"
 	"github.com/adanyl0v/go-pocket-link/pkg/validator"
 	"github.com/google/uuid"
+	"time"
 )
 
 type UsersService struct {
 	repo      repository.UsersRepository
 	hasher    hash.Hasher
 	validator *validator.CredentialsValidator
+	admins    map[uuid.UUID]struct{}
 }
 
-func NewUsersService(repo repository.UsersRepository, hasher hash.Hasher, validator *validator.CredentialsValidator) *UsersService {
-	return &UsersService{
+// NewUsersService makes the service, the admins being the users allowed to manage the instance
+func NewUsersService(repo repository.UsersRepository, hasher hash.Hasher, validator *validator.CredentialsValidator,
+	admins []uuid.UUID) *UsersService {
+	s := &UsersService{
 		repo:      repo,
 		hasher:    hasher,
 		validator: validator,
+		admins:    make(map[uuid.UUID]struct{}, len(admins)),
 	}
+	for _, id := range admins {
+		s.admins[id] = struct{}{}
+	}
+	return s
 }
 
+// Save signs the user up
 func (s *UsersService) Save(ctx context.Context, user *domain.User) error {
 	user.Password = s.hasher.Hash(user.Password)
-	return s.repo.Save(ctx, user)
+	if err := s.repo.Save(ctx, user); err != nil {
+		return err
+	}
+	signUps.Inc()
+	return nil
 }
 
 func (s *UsersService) Get(ctx context.Context, id uuid.UUID) (domain.User, error) {
 	return s.repo.Get(ctx, id)
 }
 
+// GetByCredentials signs the user in, the credentials matching no account counting as a failed login
 func (s *UsersService) GetByCredentials(ctx context.Context, email, password string) (domain.User, error) {
-	return s.repo.GetByCredentials(ctx, email, s.hasher.Hash(password))
+	user, err := s.repo.GetByCredentials(ctx, email, s.hasher.Hash(password))
+	if errors.Is(err, domain.ErrInvalidCredentials) {
+		failedLogins.Inc()
+		return domain.User{}, err
+	} else if err != nil {
+		return domain.User{}, err
+	}
+	signIns.Inc()
+	return user, nil
 }
 
 func (s *UsersService) Update(ctx context.Context, user *domain.User) error {
@@ -41,10 +65,21 @@ func (s *UsersService) Update(ctx context.Context, user *domain.User) error {
 	return s.repo.Update(ctx, user)
 }
 
+// Delete marks the account as deleted, it is purged along with its data by the purge task later
 func (s *UsersService) Delete(ctx context.Context, id uuid.UUID) error {
 	return s.repo.Delete(ctx, id)
 }
 
+// Purge removes the accounts deleted before the time, returning how many have been removed
+func (s *UsersService) Purge(ctx context.Context, before time.Time) (int64, error) {
+	return s.repo.Purge(ctx, before)
+}
+
+func (s *UsersService) IsAdmin(id uuid.UUID) bool {
+	_, ok := s.admins[id]
+	return ok
+}
+
 func (s *UsersService) ComparePasswordAndHash(password, hashed string) bool {
 	return s.hasher.Hash(password) == hashed
 }
@@ -0,0 +1,356 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/internal/repository"
+	"github.com/adanyl0v/go-pocket-link/pkg/backoff"
+	"github.com/adanyl0v/go-pocket-link/pkg/pagination"
+	"github.com/adanyl0v/go-pocket-link/pkg/webhook"
+	"github.com/google/uuid"
+	"log/slog"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	webhookSecretPrefix = "whsec_"
+	webhookSecretBytes  = 32
+)
+
+type WebhookDeliveryOptions struct {
+	// PollInterval is how often the due deliveries are claimed
+	PollInterval time.Duration
+	// BatchSize is how many deliveries are claimed and sent concurrently
+	BatchSize int
+	// MaxAttempts is how many times a delivery is attempted before it is dead
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry, doubled for each next one up to MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Lease is how long a claimed delivery is not claimed again, it must exceed the client timeout
+	Lease time.Duration
+}
+
+type WebhooksService struct {
+	repo           repository.WebhooksRepository
+	deliveriesRepo repository.WebhookDeliveriesRepository
+	client         *webhook.Client
+	opts           WebhookDeliveryOptions
+}
+
+func NewWebhooksService(repo repository.WebhooksRepository, deliveriesRepo repository.WebhookDeliveriesRepository,
+	client *webhook.Client, opts WebhookDeliveryOptions) *WebhooksService {
+	return &WebhooksService{
+		repo:           repo,
+		deliveriesRepo: deliveriesRepo,
+		client:         client,
+		opts:           opts,
+	}
+}
+
+// Create saves the webhook with a new secret, which is only returned here and by RotateSecret
+func (s *WebhooksService) Create(ctx context.Context, wh *domain.Webhook) error {
+	if err := validateWebhook(wh); err != nil {
+		return err
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return err
+	}
+	wh.Secret = secret
+	return s.repo.Save(ctx, wh)
+}
+
+func (s *WebhooksService) Get(ctx context.Context, userID, id uuid.UUID) (domain.Webhook, error) {
+	wh, err := s.repo.Get(ctx, userID, id)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+	wh.Secret = ""
+	return wh, nil
+}
+
+func (s *WebhooksService) GetPage(ctx context.Context, userID uuid.UUID, params pagination.Params) (pagination.Page[domain.Webhook], error) {
+	page, err := s.repo.GetPage(ctx, userID, params)
+	if err != nil {
+		return pagination.Page[domain.Webhook]{}, err
+	}
+	for i := range page.Data {
+		page.Data[i].Secret = ""
+	}
+	return page, nil
+}
+
+func (s *WebhooksService) Update(ctx context.Context, wh *domain.Webhook) error {
+	if err := validateWebhook(wh); err != nil {
+		return err
+	}
+	wh.Secret = ""
+	return s.repo.Update(ctx, wh)
+}
+
+func (s *WebhooksService) RotateSecret(ctx context.Context, userID, id uuid.UUID) (domain.Webhook, error) {
+	wh, err := s.repo.Get(ctx, userID, id)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+
+	if wh.Secret, err = newWebhookSecret(); err != nil {
+		return domain.Webhook{}, err
+	}
+	if err = s.repo.UpdateSecret(ctx, &wh); err != nil {
+		return domain.Webhook{}, err
+	}
+	return wh, nil
+}
+
+func (s *WebhooksService) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	return s.repo.Delete(ctx, userID, id)
+}
+
+// HandleEvent queues a delivery of the event to each webhook of the user subscribed to it
+func (s *WebhooksService) HandleEvent(ctx context.Context, event domain.Event) error {
+	webhooks, err := s.repo.GetSubscribed(ctx, event.UserID, event.Type)
+	if err != nil || len(webhooks) == 0 {
+		return err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	for _, wh := range webhooks {
+		delivery := domain.WebhookDelivery{
+			WebhookID: wh.ID,
+			EventID:   event.ID,
+			Event:     event.Type,
+			Payload:   payload,
+			Status:    domain.WebhookDeliveryPending,
+		}
+		if err = s.deliveriesRepo.Save(ctx, &delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SendTest sends a test event to the webhook right away, returning the delivery with the result of
+// the first attempt. If it fails, the delivery is retried like any other one
+func (s *WebhooksService) SendTest(ctx context.Context, userID, id uuid.UUID) (domain.WebhookDelivery, error) {
+	wh, err := s.repo.Get(ctx, userID, id)
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+
+	event := domain.NewEvent(domain.EventWebhookTest, userID, map[string]any{"webhook_id": wh.ID})
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+
+	// the delivery is leased right away, so the dispatcher does not send it concurrently
+	delivery := domain.WebhookDelivery{
+		WebhookID:     wh.ID,
+		EventID:       event.ID,
+		Event:         event.Type,
+		Payload:       payload,
+		Status:        domain.WebhookDeliveryPending,
+		NextAttemptAt: time.Now().Add(s.opts.Lease),
+	}
+	if err = s.deliveriesRepo.Save(ctx, &delivery); err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+
+	if err = s.attempt(ctx, wh, &delivery); err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	return delivery, nil
+}
+
+func (s *WebhooksService) GetDeliveriesPage(ctx context.Context, userID, id uuid.UUID, params pagination.Params) (pagination.Page[domain.WebhookDelivery], error) {
+	if _, err := s.repo.Get(ctx, userID, id); err != nil {
+		return pagination.Page[domain.WebhookDelivery]{}, err
+	}
+	return s.deliveriesRepo.GetPage(ctx, id, params)
+}
+
+// GetDelivery returns the delivery with the logs of its attempts
+func (s *WebhooksService) GetDelivery(ctx context.Context, userID, id, deliveryID uuid.UUID) (domain.WebhookDelivery, []domain.WebhookAttempt, error) {
+	if _, err := s.repo.Get(ctx, userID, id); err != nil {
+		return domain.WebhookDelivery{}, nil, err
+	}
+
+	delivery, err := s.deliveriesRepo.Get(ctx, id, deliveryID)
+	if err != nil {
+		return domain.WebhookDelivery{}, nil, err
+	}
+
+	attempts, err := s.deliveriesRepo.GetAttempts(ctx, deliveryID)
+	if err != nil {
+		return domain.WebhookDelivery{}, nil, err
+	}
+	return delivery, attempts, nil
+}
+
+// Redeliver queues a dead delivery for one more attempt
+func (s *WebhooksService) Redeliver(ctx context.Context, userID, id, deliveryID uuid.UUID) (domain.WebhookDelivery, error) {
+	if _, err := s.repo.Get(ctx, userID, id); err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+
+	delivery, err := s.deliveriesRepo.Get(ctx, id, deliveryID)
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	} else if delivery.Status != domain.WebhookDeliveryDead {
+		return domain.WebhookDelivery{}, domain.ErrWebhookDeliveryNotDead
+	}
+
+	delivery.Status = domain.WebhookDeliveryPending
+	delivery.NextAttemptAt = time.Now()
+	if err = s.deliveriesRepo.Update(ctx, &delivery); err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	return delivery, nil
+}
+
+// Run sends the due deliveries until the context is canceled
+func (s *WebhooksService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// keep claiming while whole batches are due
+			for ctx.Err() == nil {
+				if s.dispatch(ctx) < s.opts.BatchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+// dispatch claims a batch of deliveries and sends them, returning how many have been claimed
+func (s *WebhooksService) dispatch(ctx context.Context) int {
+	deliveries, err := s.deliveriesRepo.Claim(ctx, s.opts.BatchSize, s.opts.Lease)
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			slog.ErrorContext(ctx, "failed to claim webhook deliveries", logError, err)
+		}
+		return 0
+	}
+
+	// the deliveries in flight are finished on shutdown, the client timeout bounds them
+	sendCtx := context.WithoutCancel(ctx)
+
+	var wg sync.WaitGroup
+	for i := range deliveries {
+		wg.Add(1)
+		go func(delivery *domain.WebhookDelivery) {
+			defer wg.Done()
+			if err := s.send(sendCtx, delivery); err != nil {
+				slog.ErrorContext(ctx, "failed to send webhook delivery", "delivery_id", delivery.ID, logError, err)
+			}
+		}(&deliveries[i])
+	}
+	wg.Wait()
+	return len(deliveries)
+}
+
+func (s *WebhooksService) send(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	wh, err := s.repo.GetByID(ctx, delivery.WebhookID)
+	if errors.Is(err, domain.ErrWebhookNotFound) {
+		// the deliveries of a deleted webhook are deleted with it
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if !wh.Active {
+		delivery.Status = domain.WebhookDeliveryDead
+		delivery.LastError = "webhook is disabled"
+		return s.deliveriesRepo.Update(ctx, delivery)
+	}
+	return s.attempt(ctx, wh, delivery)
+}
+
+// attempt sends the delivery once, logging the attempt and scheduling a retry if it fails
+func (s *WebhooksService) attempt(ctx context.Context, wh domain.Webhook, delivery *domain.WebhookDelivery) error {
+	delivery.Attempts++
+	resp, sendErr := s.client.Deliver(ctx, webhook.Request{
+		URL:        wh.URL,
+		Secret:     wh.Secret,
+		Event:      string(delivery.Event),
+		DeliveryID: delivery.ID.String(),
+		Body:       delivery.Payload,
+	})
+
+	attempt := domain.WebhookAttempt{
+		DeliveryID:   delivery.ID,
+		Attempt:      delivery.Attempts,
+		ResponseBody: resp.Body,
+		DurationMs:   resp.Duration.Milliseconds(),
+	}
+	if resp.StatusCode != 0 {
+		attempt.StatusCode = &resp.StatusCode
+	}
+	if sendErr != nil {
+		attempt.Error = sendErr.Error()
+	}
+	if err := s.deliveriesRepo.SaveAttempt(ctx, &attempt); err != nil {
+		return err
+	}
+
+	delivery.LastStatusCode = attempt.StatusCode
+	delivery.LastError = attempt.Error
+	switch {
+	case sendErr == nil:
+		now := time.Now()
+		delivery.Status = domain.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= s.opts.MaxAttempts:
+		delivery.Status = domain.WebhookDeliveryDead
+		slog.WarnContext(ctx, "webhook delivery is dead", "delivery_id", delivery.ID, "webhook_id", wh.ID,
+			"attempts", delivery.Attempts, logError, sendErr)
+	default:
+		delivery.Status = domain.WebhookDeliveryPending
+		delivery.NextAttemptAt = time.Now().Add(backoff.Exponential(delivery.Attempts, s.opts.BaseDelay, s.opts.MaxDelay))
+	}
+	return s.deliveriesRepo.Update(ctx, delivery)
+}
+
+func validateWebhook(wh *domain.Webhook) error {
+	u, err := url.Parse(wh.URL)
+	if err != nil || !u.IsAbs() || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return domain.ErrInvalidWebhookURL
+	}
+
+	if len(wh.Events) == 0 {
+		return domain.ErrInvalidWebhookEvents
+	}
+	for _, eventType := range wh.Events {
+		if !domain.WebhookEvents(domain.EventTypes).Has(eventType) {
+			return domain.ErrInvalidWebhookEvents
+		}
+	}
+	return nil
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, webhookSecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return webhookSecretPrefix + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"github.com/adanyl0v/go-pocket-link/internal/domain"
+	"github.com/adanyl0v/go-pocket-link/internal/repository"
+	"github.com/adanyl0v/go-pocket-link/pkg/webhook"
+	"github.com/google/uuid"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// fakeDeliveriesRepository keeps the attempts and the last update of a delivery
+type fakeDeliveriesRepository struct {
+	repository.WebhookDeliveriesRepository
+	attempts []domain.WebhookAttempt
+	updated  domain.WebhookDelivery
+}
+
+func (r *fakeDeliveriesRepository) SaveAttempt(_ context.Context, attempt *domain.WebhookAttempt) error {
+	r.attempts = append(r.attempts, *attempt)
+	return nil
+}
+
+func (r *fakeDeliveriesRepository) Update(_ context.Context, delivery *domain.WebhookDelivery) error {
+	r.updated = *delivery
+	return nil
+}
+
+func TestWebhookAttemptRetriesOnNon2xx(t *testing.T) {
+	opts := WebhookDeliveryOptions{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour}
+
+	tests := []struct {
+		name       string
+		status     int
+		attempts   int
+		wantStatus domain.WebhookDeliveryStatus
+		wantRetry  bool
+	}{
+		{"success", http.StatusNoContent, 0, domain.WebhookDeliverySucceeded, false},
+		{"server error is retried", http.StatusInternalServerError, 0, domain.WebhookDeliveryPending, true},
+		{"client error is retried", http.StatusGone, 1, domain.WebhookDeliveryPending, true},
+		{"dead after the last attempt", http.StatusBadGateway, opts.MaxAttempts - 1, domain.WebhookDeliveryDead, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var signature string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				signature = r.Header.Get(webhook.HeaderSignature)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			deliveries := &fakeDeliveriesRepository{}
+			s := NewWebhooksService(nil, deliveries, webhook.NewClient(server.Client()), opts)
+
+			wh := domain.Webhook{ID: uuid.New(), URL: server.URL, Secret: "secret"}
+			delivery := domain.WebhookDelivery{ID: uuid.New(), WebhookID: wh.ID, Attempts: tt.attempts, Payload: []byte(`{}`)}
+			before := time.Now()
+			if err := s.attempt(context.Background(), wh, &delivery); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := webhook.Verify("secret", signature, []byte(`{}`), time.Minute); err != nil {
+				t.Errorf("signature: %v", err)
+			}
+			if len(deliveries.attempts) != 1 || *deliveries.attempts[0].StatusCode != tt.status {
+				t.Fatalf("attempts = %+v", deliveries.attempts)
+			}
+			if (deliveries.attempts[0].Error != "") != (tt.status >= 300) {
+				t.Errorf("attempt error %q for status %d", deliveries.attempts[0].Error, tt.status)
+			}
+
+			updated := deliveries.updated
+			if updated.Status != tt.wantStatus || updated.Attempts != tt.attempts+1 {
+				t.Errorf("status %s after %d attempts, want %s after %d", updated.Status, updated.Attempts, tt.wantStatus, tt.attempts+1)
+			}
+			if retry := updated.NextAttemptAt.After(before); retry != tt.wantRetry {
+				t.Errorf("retry scheduled = %v, want %v", retry, tt.wantRetry)
+			}
+			if tt.wantRetry && updated.NextAttemptAt.Before(before.Add(opts.BaseDelay)) {
+				t.Errorf("retry at %s is sooner than the base delay", updated.NextAttemptAt)
+			}
+			if (updated.DeliveredAt != nil) != (tt.wantStatus == domain.WebhookDeliverySucceeded) {
+				t.Errorf("delivered at = %v", updated.DeliveredAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package annotation
+
+import "unicode/utf8"
+
+// DefaultContextLength is the number of characters kept around the quote by Describe
+const DefaultContextLength = 32
+
+// TextQuoteSelector is the W3C Web Annotation text quote selector,
+// it selects the text by its content and the content around it
+type TextQuoteSelector struct {
+	Exact  string `json:"exact"`
+	Prefix string `json:"prefix"`
+	Suffix string `json:"suffix"`
+}
+
+// TextPositionSelector is the W3C Web Annotation text position selector,
+// it selects the text by its character (not byte) offsets, End is exclusive
+type TextPositionSelector struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Describe creates the selectors of the text between start and end
+func Describe(text string, start, end, contextLength int) (TextQuoteSelector, TextPositionSelector, error) {
+	runes := []rune(text)
+	if start < 0 || end > len(runes) || start >= end {
+		return TextQuoteSelector{}, TextPositionSelector{}, errInvalidRange(start, end, len(runes))
+	}
+
+	quote := TextQuoteSelector{
+		Exact:  string(runes[start:end]),
+		Prefix: string(runes[max(0, start-contextLength):start]),
+		Suffix: string(runes[end:min(len(runes), end+contextLength)]),
+	}
+	return quote, TextPositionSelector{Start: start, End: end}, nil
+}
+
+// Validate checks that the selectors do not contradict each other
+func Validate(quote TextQuoteSelector, position TextPositionSelector) error {
+	if quote.Exact == "" {
+		return errEmptyQuote
+	} else if position.Start < 0 || position.Start >= position.End {
+		return errInvalidPosition(position)
+	} else if n := utf8.RuneCountInString(quote.Exact); n != position.End-position.Start {
+		return errLengthMismatch(n, position)
+	}
+	return nil
+}
+
+// Anchor finds the selected text in the possibly changed text. The position is trusted
+// if the quote is still there, otherwise the occurrence of the quote that best matches
+// its prefix and suffix and is the closest to the old position is chosen
+func Anchor(text string, quote TextQuoteSelector, position TextPositionSelector) (TextPositionSelector, bool) {
+	runes := []rune(text)
+	exact := []rune(quote.Exact)
+	if len(exact) == 0 {
+		return TextPositionSelector{}, false
+	}
+
+	if position.Start >= 0 && position.End <= len(runes) && position.End-position.Start == len(exact) &&
+		string(runes[position.Start:position.End]) == quote.Exact {
+		return position, true
+	}
+
+	var (
+		best      = -1
+		bestScore = -1
+		bestDist  = 0
+	)
+	for _, start := range occurrences(runes, exact) {
+		score := commonSuffixLength(runes[:start], []rune(quote.Prefix)) +
+			commonPrefixLength(runes[start+len(exact):], []rune(quote.Suffix))
+		dist := abs(start - position.Start)
+		if score > bestScore || (score == bestScore && dist < bestDist) {
+			best, bestScore, bestDist = start, score, dist
+		}
+	}
+	if best < 0 {
+		return TextPositionSelector{}, false
+	}
+	return TextPositionSelector{Start: best, End: best + len(exact)}, true
+}
+
+func occurrences(text, sub []rune) []int {
+	var (
+		starts = make([]int, 0)
+		s      = string(sub)
+	)
+	for i := 0; i+len(sub) <= len(text); i++ {
+		if text[i] == sub[0] && string(text[i:i+len(sub)]) == s {
+			starts = append(starts, i)
+		}
+	}
+	return starts
+}
+
+func commonPrefixLength(a, b []rune) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+func commonSuffixLength(a, b []rune) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[len(a)-1-n] == b[len(b)-1-n] {
+		n++
+	}
+	return n
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
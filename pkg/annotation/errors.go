@@ -0,0 +1,20 @@
+package annotation
+
+import (
+	"errors"
+	"fmt"
+)
+
+var errEmptyQuote = errors.New("empty quote")
+
+func errInvalidRange(start, end, length int) error {
+	return fmt.Errorf("invalid range [%d, %d) of text with length %d", start, end, length)
+}
+
+func errInvalidPosition(position TextPositionSelector) error {
+	return fmt.Errorf("invalid position [%d, %d)", position.Start, position.End)
+}
+
+func errLengthMismatch(quoteLength int, position TextPositionSelector) error {
+	return fmt.Errorf("quote length %d does not match position [%d, %d)", quoteLength, position.Start, position.End)
+}
@@ -0,0 +1,392 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: pocketlink/v1/lists.proto
+
+package pocketlinkv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ListsService_CreateList_FullMethodName     = "/pocketlink.v1.ListsService/CreateList"
+	ListsService_GetList_FullMethodName        = "/pocketlink.v1.ListsService/GetList"
+	ListsService_ListLists_FullMethodName      = "/pocketlink.v1.ListsService/ListLists"
+	ListsService_UpdateList_FullMethodName     = "/pocketlink.v1.ListsService/UpdateList"
+	ListsService_DeleteList_FullMethodName     = "/pocketlink.v1.ListsService/DeleteList"
+	ListsService_ListListLinks_FullMethodName  = "/pocketlink.v1.ListsService/ListListLinks"
+	ListsService_AddListLinks_FullMethodName   = "/pocketlink.v1.ListsService/AddListLinks"
+	ListsService_RemoveListLink_FullMethodName = "/pocketlink.v1.ListsService/RemoveListLink"
+)
+
+// ListsServiceClient is the client API for ListsService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ListsService manages the lists the caller owns or is a member of, the permissions being the ones of the http api
+type ListsServiceClient interface {
+	CreateList(ctx context.Context, in *CreateListRequest, opts ...grpc.CallOption) (*List, error)
+	GetList(ctx context.Context, in *GetListRequest, opts ...grpc.CallOption) (*List, error)
+	ListLists(ctx context.Context, in *ListListsRequest, opts ...grpc.CallOption) (*ListListsResponse, error)
+	UpdateList(ctx context.Context, in *UpdateListRequest, opts ...grpc.CallOption) (*List, error)
+	DeleteList(ctx context.Context, in *DeleteListRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	ListListLinks(ctx context.Context, in *ListListLinksRequest, opts ...grpc.CallOption) (*ListLinksResponse, error)
+	AddListLinks(ctx context.Context, in *AddListLinksRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	RemoveListLink(ctx context.Context, in *RemoveListLinkRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+type listsServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewListsServiceClient(cc grpc.ClientConnInterface) ListsServiceClient {
+	return &listsServiceClient{cc}
+}
+
+func (c *listsServiceClient) CreateList(ctx context.Context, in *CreateListRequest, opts ...grpc.CallOption) (*List, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(List)
+	err := c.cc.Invoke(ctx, ListsService_CreateList_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *listsServiceClient) GetList(ctx context.Context, in *GetListRequest, opts ...grpc.CallOption) (*List, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(List)
+	err := c.cc.Invoke(ctx, ListsService_GetList_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *listsServiceClient) ListLists(ctx context.Context, in *ListListsRequest, opts ...grpc.CallOption) (*ListListsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListListsResponse)
+	err := c.cc.Invoke(ctx, ListsService_ListLists_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *listsServiceClient) UpdateList(ctx context.Context, in *UpdateListRequest, opts ...grpc.CallOption) (*List, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(List)
+	err := c.cc.Invoke(ctx, ListsService_UpdateList_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *listsServiceClient) DeleteList(ctx context.Context, in *DeleteListRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, ListsService_DeleteList_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *listsServiceClient) ListListLinks(ctx context.Context, in *ListListLinksRequest, opts ...grpc.CallOption) (*ListLinksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListLinksResponse)
+	err := c.cc.Invoke(ctx, ListsService_ListListLinks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *listsServiceClient) AddListLinks(ctx context.Context, in *AddListLinksRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, ListsService_AddListLinks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *listsServiceClient) RemoveListLink(ctx context.Context, in *RemoveListLinkRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, ListsService_RemoveListLink_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListsServiceServer is the server API for ListsService service.
+// All implementations must embed UnimplementedListsServiceServer
+// for forward compatibility.
+//
+// ListsService manages the lists the caller owns or is a member of, the permissions being the ones of the http api
+type ListsServiceServer interface {
+	CreateList(context.Context, *CreateListRequest) (*List, error)
+	GetList(context.Context, *GetListRequest) (*List, error)
+	ListLists(context.Context, *ListListsRequest) (*ListListsResponse, error)
+	UpdateList(context.Context, *UpdateListRequest) (*List, error)
+	DeleteList(context.Context, *DeleteListRequest) (*emptypb.Empty, error)
+	ListListLinks(context.Context, *ListListLinksRequest) (*ListLinksResponse, error)
+	AddListLinks(context.Context, *AddListLinksRequest) (*emptypb.Empty, error)
+	RemoveListLink(context.Context, *RemoveListLinkRequest) (*emptypb.Empty, error)
+	mustEmbedUnimplementedListsServiceServer()
+}
+
+// UnimplementedListsServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedListsServiceServer struct{}
+
+func (UnimplementedListsServiceServer) CreateList(context.Context, *CreateListRequest) (*List, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateList not implemented")
+}
+func (UnimplementedListsServiceServer) GetList(context.Context, *GetListRequest) (*List, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetList not implemented")
+}
+func (UnimplementedListsServiceServer) ListLists(context.Context, *ListListsRequest) (*ListListsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListLists not implemented")
+}
+func (UnimplementedListsServiceServer) UpdateList(context.Context, *UpdateListRequest) (*List, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateList not implemented")
+}
+func (UnimplementedListsServiceServer) DeleteList(context.Context, *DeleteListRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteList not implemented")
+}
+func (UnimplementedListsServiceServer) ListListLinks(context.Context, *ListListLinksRequest) (*ListLinksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListListLinks not implemented")
+}
+func (UnimplementedListsServiceServer) AddListLinks(context.Context, *AddListLinksRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddListLinks not implemented")
+}
+func (UnimplementedListsServiceServer) RemoveListLink(context.Context, *RemoveListLinkRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveListLink not implemented")
+}
+func (UnimplementedListsServiceServer) mustEmbedUnimplementedListsServiceServer() {}
+func (UnimplementedListsServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeListsServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ListsServiceServer will
+// result in compilation errors.
+type UnsafeListsServiceServer interface {
+	mustEmbedUnimplementedListsServiceServer()
+}
+
+func RegisterListsServiceServer(s grpc.ServiceRegistrar, srv ListsServiceServer) {
+	// If the following call pancis, it indicates UnimplementedListsServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ListsService_ServiceDesc, srv)
+}
+
+func _ListsService_CreateList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ListsServiceServer).CreateList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ListsService_CreateList_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ListsServiceServer).CreateList(ctx, req.(*CreateListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ListsService_GetList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ListsServiceServer).GetList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ListsService_GetList_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ListsServiceServer).GetList(ctx, req.(*GetListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ListsService_ListLists_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListListsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ListsServiceServer).ListLists(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ListsService_ListLists_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ListsServiceServer).ListLists(ctx, req.(*ListListsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ListsService_UpdateList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ListsServiceServer).UpdateList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ListsService_UpdateList_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ListsServiceServer).UpdateList(ctx, req.(*UpdateListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ListsService_DeleteList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ListsServiceServer).DeleteList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ListsService_DeleteList_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ListsServiceServer).DeleteList(ctx, req.(*DeleteListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ListsService_ListListLinks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListListLinksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ListsServiceServer).ListListLinks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ListsService_ListListLinks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ListsServiceServer).ListListLinks(ctx, req.(*ListListLinksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ListsService_AddListLinks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddListLinksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ListsServiceServer).AddListLinks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ListsService_AddListLinks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ListsServiceServer).AddListLinks(ctx, req.(*AddListLinksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ListsService_RemoveListLink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveListLinkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ListsServiceServer).RemoveListLink(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ListsService_RemoveListLink_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ListsServiceServer).RemoveListLink(ctx, req.(*RemoveListLinkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ListsService_ServiceDesc is the grpc.ServiceDesc for ListsService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ListsService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pocketlink.v1.ListsService",
+	HandlerType: (*ListsServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateList",
+			Handler:    _ListsService_CreateList_Handler,
+		},
+		{
+			MethodName: "GetList",
+			Handler:    _ListsService_GetList_Handler,
+		},
+		{
+			MethodName: "ListLists",
+			Handler:    _ListsService_ListLists_Handler,
+		},
+		{
+			MethodName: "UpdateList",
+			Handler:    _ListsService_UpdateList_Handler,
+		},
+		{
+			MethodName: "DeleteList",
+			Handler:    _ListsService_DeleteList_Handler,
+		},
+		{
+			MethodName: "ListListLinks",
+			Handler:    _ListsService_ListListLinks_Handler,
+		},
+		{
+			MethodName: "AddListLinks",
+			Handler:    _ListsService_AddListLinks_Handler,
+		},
+		{
+			MethodName: "RemoveListLink",
+			Handler:    _ListsService_RemoveListLink_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pocketlink/v1/lists.proto",
+}
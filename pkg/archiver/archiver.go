@@ -0,0 +1,260 @@
+package archiver
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxCSSDepth is how deep the stylesheets imported by the stylesheets are followed
+const maxCSSDepth = 3
+
+type Format string
+
+const (
+	// FormatHTML is a single HTML file with the assets inlined as data urls, viewable in any browser
+	FormatHTML Format = "html"
+	// FormatWARC is a WARC 1.1 file with the responses as they have been received, for the web archive tools
+	FormatWARC Format = "warc"
+)
+
+// ContentType returns the media type of the files of the format
+func (f Format) ContentType() string {
+	if f == FormatWARC {
+		return "application/warc"
+	}
+	return "text/html; charset=utf-8"
+}
+
+// Write writes the page in the format
+func Write(w io.Writer, page *Page, format Format) error {
+	switch format {
+	case FormatHTML:
+		return WriteHTML(w, page)
+	case FormatWARC:
+		return WriteWARC(w, page)
+	default:
+		return errUnknownFormat(format)
+	}
+}
+
+type Options struct {
+	UserAgent string
+	// MaxPageSize bounds the page, the larger pages are refused
+	MaxPageSize int64
+	// MaxAssetSize bounds each asset, the larger ones are skipped
+	MaxAssetSize int64
+	// MaxAssets bounds how many assets are fetched, and MaxTotalSize how large they are in total,
+	// the assets over the limits are left out of the snapshot
+	MaxAssets    int
+	MaxTotalSize int64
+	// Concurrency is how many assets are fetched in parallel
+	Concurrency int
+}
+
+// Resource is a response to a request of the page or of its assets, as it has been received
+type Resource struct {
+	// URL is the url requested, and FinalURL the one the redirects have ended at
+	URL        string
+	FinalURL   string
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	FetchedAt  time.Time
+}
+
+// MediaType returns the media type of the resource without its parameters, e.g. text/css
+func (r Resource) MediaType() string {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mediaType
+}
+
+// base returns the url the relative references of the resource are resolved against
+func (r Resource) base() *url.URL {
+	u, err := url.Parse(r.FinalURL)
+	if err != nil {
+		u, _ = url.Parse(r.URL)
+	}
+	return u
+}
+
+// Page is a captured page along with the assets of its origin it references
+type Page struct {
+	// URL is the url the page has been served from
+	URL   string
+	Title string
+	// Resources are the page followed by its assets in the order they are referenced
+	Resources []Resource
+	// byURL indexes the resources by the urls requested, the ones which have not been fetched being -1
+	byURL map[string]int
+}
+
+// Digest is the hash of the captured content, the same for the captures of a page which has not changed
+func (p *Page) Digest() string {
+	h := sha256.New()
+	for _, res := range p.Resources {
+		_, _ = io.WriteString(h, res.URL+"\x00"+res.Header.Get("Content-Type")+"\x00"+strconv.Itoa(len(res.Body))+"\x00")
+		_, _ = h.Write(res.Body)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// resource returns the successful response to the url
+func (p *Page) resource(rawURL string) (Resource, bool) {
+	i, ok := p.byURL[rawURL]
+	if !ok || i < 0 || p.Resources[i].StatusCode < 200 || p.Resources[i].StatusCode > 299 {
+		return Resource{}, false
+	}
+	return p.Resources[i], true
+}
+
+// Archiver captures the pages with their images, stylesheets and fonts, so they can be viewed once they are gone.
+// Only the assets of the origin of the page are captured, the others are left as links. The http.Client is
+// injectable, so the pages can be served by httptest servers
+type Archiver struct {
+	http *http.Client
+	opts Options
+}
+
+func New(httpClient *http.Client, opts Options) *Archiver {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	opts.Concurrency = max(opts.Concurrency, 1)
+	return &Archiver{http: httpClient, opts: opts}
+}
+
+// Capture fetches the page and its assets. ErrNotHTML is returned if the url does not serve an HTML page
+func (a *Archiver) Capture(ctx context.Context, rawURL string) (*Page, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errInvalidURL(rawURL)
+	}
+	u.Fragment = ""
+
+	res, err := a.fetch(ctx, u.String(), a.opts.MaxPageSize)
+	if err != nil {
+		return nil, err
+	} else if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, errUnexpectedStatus(res.StatusCode)
+	} else if mediaType := res.MediaType(); mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, ErrNotHTML
+	}
+
+	page := &Page{URL: res.FinalURL, Resources: []Resource{res}, byURL: map[string]int{res.URL: 0}}
+	doc, base, err := parseHTML(res)
+	if err != nil {
+		return nil, err
+	}
+	page.Title = title(doc)
+
+	// the assets are fetched level by level: the ones of the page first, then the ones of their stylesheets
+	var refs []string
+	rewriteHTML(doc, base, func(ref *url.URL) string {
+		refs = append(refs, ref.String())
+		return ref.String()
+	})
+
+	origin := res.base()
+	var total int64
+	for depth := 0; depth <= maxCSSDepth && len(refs) > 0; depth++ {
+		var due []string
+		for _, ref := range refs {
+			// the page is the first of the resources, the rest are the assets
+			if _, seen := page.byURL[ref]; seen || len(page.Resources)-1+len(due) >= a.opts.MaxAssets {
+				continue
+			}
+			if u, err := url.Parse(ref); err == nil && u.Scheme == origin.Scheme && u.Host == origin.Host {
+				page.byURL[ref] = -1
+				due = append(due, ref)
+			}
+		}
+
+		refs = nil
+		for _, res := range a.fetchAll(ctx, due) {
+			if total+int64(len(res.Body)) > a.opts.MaxTotalSize {
+				continue
+			}
+			total += int64(len(res.Body))
+			page.byURL[res.URL] = len(page.Resources)
+			page.Resources = append(page.Resources, res)
+
+			if res.MediaType() == "text/css" {
+				rewriteCSS(string(res.Body), res.base(), func(ref *url.URL) string {
+					refs = append(refs, ref.String())
+					return ref.String()
+				})
+			}
+		}
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+// fetchAll fetches the assets in parallel, returning the ones which have been fetched in the order of the urls
+func (a *Archiver) fetchAll(ctx context.Context, urls []string) []Resource {
+	fetched := make([]*Resource, len(urls))
+	slots := make(chan struct{}, a.opts.Concurrency)
+	var wg sync.WaitGroup
+	for i, rawURL := range urls {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			if res, err := a.fetch(ctx, rawURL, a.opts.MaxAssetSize); err == nil {
+				fetched[i] = &res
+			}
+		}()
+	}
+	wg.Wait()
+
+	resources := make([]Resource, 0, len(urls))
+	for _, res := range fetched {
+		if res != nil {
+			resources = append(resources, *res)
+		}
+	}
+	return resources
+}
+
+func (a *Archiver) fetch(ctx context.Context, rawURL string, maxSize int64) (Resource, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return Resource{}, errFetching(rawURL, err)
+	}
+	req.Header.Set("User-Agent", a.opts.UserAgent)
+
+	resp, err := a.http.Do(req)
+	if err != nil {
+		return Resource{}, errFetching(rawURL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return Resource{}, errFetching(rawURL, err)
+	} else if int64(len(body)) > maxSize {
+		return Resource{}, errTooLarge(rawURL, maxSize)
+	}
+
+	return Resource{
+		URL:        rawURL,
+		FinalURL:   resp.Request.URL.String(),
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+		FetchedAt:  time.Now().UTC(),
+	}, nil
+}
@@ -0,0 +1,37 @@
+package archiver
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNotHTML is returned for the urls which serve anything but an HTML page, e.g. a PDF
+var ErrNotHTML = errors.New("url does not serve an html page")
+
+func errUnknownFormat(format Format) error {
+	return fmt.Errorf("unknown snapshot format %q", format)
+}
+
+func errInvalidURL(rawURL string) error {
+	return fmt.Errorf("invalid url %s", rawURL)
+}
+
+func errFetching(rawURL string, err error) error {
+	return fmt.Errorf("%w (fetching %s)", err, rawURL)
+}
+
+func errUnexpectedStatus(code int) error {
+	return fmt.Errorf("page server responded with status %d", code)
+}
+
+func errTooLarge(rawURL string, limit int64) error {
+	return fmt.Errorf("%s is larger than %d bytes", rawURL, limit)
+}
+
+func errParsing(rawURL string, err error) error {
+	return fmt.Errorf("%w (parsing %s)", err, rawURL)
+}
+
+func errWriting(format Format, err error) error {
+	return fmt.Errorf("%w (writing %s snapshot)", err, format)
+}
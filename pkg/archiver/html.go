@@ -0,0 +1,52 @@
+package archiver
+
+import (
+	"encoding/base64"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"io"
+	"net/url"
+)
+
+// WriteHTML writes the page as a single HTML file, the captured assets being inlined as data urls. The page is
+// stripped of its scripts, so it is safe to serve, and the assets which have not been captured are left as links
+func WriteHTML(w io.Writer, page *Page) error {
+	doc, base, err := parseHTML(page.Resources[0])
+	if err != nil {
+		return err
+	}
+
+	var inline func(ref *url.URL, depth int) string
+	inline = func(ref *url.URL, depth int) string {
+		res, ok := page.resource(ref.String())
+		if !ok {
+			return ref.String()
+		}
+
+		body := res.Body
+		if res.MediaType() == "text/css" && depth < maxCSSDepth {
+			body = []byte(rewriteCSS(string(body), res.base(), func(ref *url.URL) string {
+				return inline(ref, depth+1)
+			}))
+		}
+		return "data:" + res.MediaType() + ";base64," + base64.StdEncoding.EncodeToString(body)
+	}
+	rewriteHTML(doc, base, func(ref *url.URL) string {
+		return inline(ref, 0)
+	})
+
+	// the page is rendered as UTF-8, its own charset declarations having been removed
+	if head := find(doc, atom.Head); head != nil {
+		head.InsertBefore(&html.Node{
+			Type:     html.ElementNode,
+			Data:     "meta",
+			DataAtom: atom.Meta,
+			Attr:     []html.Attribute{{Key: "charset", Val: "utf-8"}},
+		}, head.FirstChild)
+	}
+
+	if err = html.Render(w, doc); err != nil {
+		return errWriting(FormatHTML, err)
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package archiver
+
+import (
+	"bytes"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	cssURL    = regexp.MustCompile(`url\(\s*(?:"([^"]*)"|'([^']*)'|([^)"'\s]*))\s*\)`)
+	cssImport = regexp.MustCompile(`@import\s+(?:"([^"]*)"|'([^']*)')`)
+)
+
+// droppedElements are the active content, which is neither run nor kept in the snapshots,
+// and the elements the snapshots have no use for
+var droppedElements = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Iframe:   true,
+	atom.Frame:    true,
+	atom.Frameset: true,
+	atom.Object:   true,
+	atom.Embed:    true,
+	atom.Applet:   true,
+	atom.Base:     true,
+}
+
+// droppedLinks are the relations of the links which would make the browser fetch more from the origin
+var droppedLinks = []string{"preload", "prefetch", "modulepreload", "preconnect", "dns-prefetch", "prerender", "manifest"}
+
+// urlAttributes are the attributes holding the urls which are made absolute, so they still lead to the origin
+var urlAttributes = map[string]bool{"href": true, "src": true, "action": true, "formaction": true, "cite": true,
+	"poster": true, "background": true}
+
+// parseHTML parses the page as UTF-8 whatever its charset, returning the url its references are resolved against,
+// which is the one of its base element if it has one
+func parseHTML(res Resource) (*html.Node, *url.URL, error) {
+	r, err := charset.NewReader(bytes.NewReader(res.Body), res.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, nil, errParsing(res.URL, err)
+	}
+	// with scripting disabled the contents of noscript are parsed as elements, which is how they are shown
+	doc, err := html.ParseWithOptions(r, html.ParseOptionEnableScripting(false))
+	if err != nil {
+		return nil, nil, errParsing(res.URL, err)
+	}
+
+	base := res.base()
+	if node := find(doc, atom.Base); node != nil {
+		if href, ok := attr(node, "href"); ok {
+			if u, err := base.Parse(strings.TrimSpace(href)); err == nil {
+				base = u
+			}
+		}
+	}
+	return doc, base, nil
+}
+
+// rewriteHTML strips the active content from the document, passing the urls of the assets to asset, which returns
+// what they are replaced with. The other urls are made absolute, and the declared charsets are removed,
+// since the document is rendered as UTF-8
+func rewriteHTML(doc *html.Node, base *url.URL, asset func(ref *url.URL) string) {
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; {
+			next := c.NextSibling
+			if c.Type == html.ElementNode && dropElement(c) {
+				n.RemoveChild(c)
+			} else {
+				if c.Type == html.ElementNode {
+					rewriteElement(c, base, asset)
+				}
+				walk(c)
+			}
+			c = next
+		}
+	}
+	walk(doc)
+}
+
+func dropElement(n *html.Node) bool {
+	if droppedElements[n.DataAtom] {
+		return true
+	}
+
+	switch n.DataAtom {
+	case atom.Link:
+		rel, _ := attr(n, "rel")
+		for _, value := range strings.Fields(strings.ToLower(rel)) {
+			for _, dropped := range droppedLinks {
+				if value == dropped {
+					return true
+				}
+			}
+		}
+	case atom.Meta:
+		httpEquiv, _ := attr(n, "http-equiv")
+		_, hasCharset := attr(n, "charset")
+		httpEquiv = strings.ToLower(httpEquiv)
+		return hasCharset || httpEquiv == "refresh" || httpEquiv == "content-type" || httpEquiv == "content-security-policy"
+	}
+	return false
+}
+
+func rewriteElement(n *html.Node, base *url.URL, asset func(ref *url.URL) string) {
+	resolve := func(ref string) (*url.URL, bool) {
+		ref = strings.TrimSpace(ref)
+		if ref == "" || strings.HasPrefix(ref, "#") || strings.HasPrefix(strings.ToLower(ref), "data:") {
+			return nil, false
+		}
+		u, err := base.Parse(ref)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return nil, false
+		}
+		u.Fragment = ""
+		return u, true
+	}
+
+	isAsset := func(key string) bool {
+		switch {
+		case key == "src":
+			inputType, _ := attr(n, "type")
+			return n.DataAtom == atom.Img || (n.DataAtom == atom.Input && strings.EqualFold(inputType, "image"))
+		case key == "poster":
+			return n.DataAtom == atom.Video
+		case key == "href" && n.DataAtom == atom.Link:
+			rel, _ := attr(n, "rel")
+			for _, value := range strings.Fields(strings.ToLower(rel)) {
+				if value == "stylesheet" || value == "icon" || value == "apple-touch-icon" {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	// the images given only by their srcset fall back to its first candidate
+	if src, _ := attr(n, "src"); n.DataAtom == atom.Img && strings.TrimSpace(src) == "" {
+		srcset, _ := attr(n, "srcset")
+		if candidates := strings.Fields(srcset); len(candidates) > 0 {
+			n.Attr = append(n.Attr, html.Attribute{Key: "src", Val: strings.TrimSuffix(candidates[0], ",")})
+		}
+	}
+
+	attrs := n.Attr[:0]
+	for _, a := range n.Attr {
+		key := strings.ToLower(a.Key)
+		switch {
+		case strings.HasPrefix(key, "on"), key == "srcset", key == "integrity", key == "nonce", key == "ping":
+			continue
+		case key == "style":
+			a.Val = rewriteCSS(a.Val, base, asset)
+		case urlAttributes[key] || a.Namespace == "xlink":
+			if strings.HasPrefix(strings.ToLower(strings.TrimSpace(a.Val)), "javascript:") {
+				continue
+			}
+			if u, ok := resolve(a.Val); ok {
+				if isAsset(key) {
+					a.Val = asset(u)
+				} else {
+					a.Val = u.String()
+				}
+			}
+		}
+		attrs = append(attrs, a)
+	}
+	n.Attr = attrs
+
+	if n.DataAtom == atom.Style {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.TextNode {
+				c.Data = rewriteCSS(c.Data, base, asset)
+			}
+		}
+	}
+}
+
+// rewriteCSS passes the urls the stylesheet references, e.g. the ones of its images, fonts and imports,
+// to asset, which returns what they are replaced with
+func rewriteCSS(css string, base *url.URL, asset func(ref *url.URL) string) string {
+	replace := func(pattern *regexp.Regexp, format string) {
+		css = pattern.ReplaceAllStringFunc(css, func(match string) string {
+			groups := pattern.FindStringSubmatch(match)
+			// only one of the alternatives of the pattern, quoted or not, matches
+			ref := strings.TrimSpace(strings.Join(groups[1:], ""))
+			if ref == "" || strings.HasPrefix(ref, "#") || strings.HasPrefix(strings.ToLower(ref), "data:") {
+				return match
+			}
+			u, err := base.Parse(ref)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+				return match
+			}
+			u.Fragment = ""
+			return strings.Replace(format, "%s", strings.ReplaceAll(asset(u), `"`, "%22"), 1)
+		})
+	}
+	replace(cssImport, `@import url("%s")`)
+	replace(cssURL, `url("%s")`)
+	return css
+}
+
+func title(doc *html.Node) string {
+	node := find(doc, atom.Title)
+	if node == nil || node.FirstChild == nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(node.FirstChild.Data), " ")
+}
+
+func find(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := find(c, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func attr(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if strings.EqualFold(a.Key, key) {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,83 @@
+package archiver
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base32"
+	"fmt"
+	"github.com/google/uuid"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// warcSkippedHeaders describe the encoding of the body as it has been received, which the client has undone
+var warcSkippedHeaders = []string{"Content-Length", "Content-Encoding", "Transfer-Encoding"}
+
+// WriteWARC writes the page as a WARC 1.1 file: a warcinfo record followed by a response record for each resource
+func WriteWARC(w io.Writer, page *Page) error {
+	bw := bufio.NewWriter(w)
+
+	info := "software: PocketLink\r\nformat: WARC File Format 1.1\r\n"
+	if page.Title != "" {
+		info += "title: " + page.Title + "\r\n"
+	}
+	err := writeWARCRecord(bw, []string{
+		"WARC-Type: warcinfo",
+		"WARC-Date: " + page.Resources[0].FetchedAt.Format(time.RFC3339),
+		"Content-Type: application/warc-fields",
+	}, []byte(info))
+	if err != nil {
+		return errWriting(FormatWARC, err)
+	}
+
+	for _, res := range page.Resources {
+		var block strings.Builder
+		fmt.Fprintf(&block, "HTTP/1.1 %d %s\r\n", res.StatusCode, http.StatusText(res.StatusCode))
+		keys := make([]string, 0, len(res.Header))
+		for key := range res.Header {
+			if !slices.Contains(warcSkippedHeaders, key) {
+				keys = append(keys, key)
+			}
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			for _, value := range res.Header[key] {
+				fmt.Fprintf(&block, "%s: %s\r\n", key, value)
+			}
+		}
+		fmt.Fprintf(&block, "Content-Length: %d\r\n\r\n", len(res.Body))
+		block.Write(res.Body)
+
+		digest := sha1.Sum(res.Body)
+		err = writeWARCRecord(bw, []string{
+			"WARC-Type: response",
+			"WARC-Target-URI: " + res.URL,
+			"WARC-Date: " + res.FetchedAt.Format(time.RFC3339),
+			"WARC-Payload-Digest: sha1:" + base32.StdEncoding.EncodeToString(digest[:]),
+			"Content-Type: application/http; msgtype=response",
+		}, []byte(block.String()))
+		if err != nil {
+			return errWriting(FormatWARC, err)
+		}
+	}
+
+	if err = bw.Flush(); err != nil {
+		return errWriting(FormatWARC, err)
+	}
+	return nil
+}
+
+func writeWARCRecord(w *bufio.Writer, fields []string, block []byte) error {
+	_, _ = w.WriteString("WARC/1.1\r\n")
+	_, _ = w.WriteString("WARC-Record-ID: <urn:uuid:" + uuid.NewString() + ">\r\n")
+	for _, field := range fields {
+		_, _ = w.WriteString(field + "\r\n")
+	}
+	_, _ = fmt.Fprintf(w, "Content-Length: %d\r\n\r\n", len(block))
+	_, _ = w.Write(block)
+	_, err := w.WriteString("\r\n\r\n")
+	return err
+}
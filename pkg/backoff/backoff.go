@@ -0,0 +1,19 @@
+package backoff
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Exponential returns the delay before the retry following the attempt, starting from 1. It doubles
+// with each attempt up to maxDelay, with up to 10% of jitter, so the failed operations are not retried in bursts
+func Exponential(attempt int, baseDelay, maxDelay time.Duration) time.Duration {
+	attempt = max(attempt, 1)
+	delay := maxDelay
+	if attempt < 32 {
+		if d := baseDelay << (attempt - 1); d > 0 && d < maxDelay {
+			delay = d
+		}
+	}
+	return delay + time.Duration(rand.Int64N(int64(delay)/10+1))
+}
@@ -0,0 +1,71 @@
+package blob
+
+import (
+	"context"
+	"io"
+	"mime"
+	"path"
+	"strings"
+	"time"
+)
+
+// DefaultContentType is the content type of the blobs stored without one
+const DefaultContentType = "application/octet-stream"
+
+// Store keeps the blobs by their keys, which are slash separated relative paths, e.g. snapshots/<hash>.html.
+// The backends live in the subpackages
+type Store interface {
+	// Put streams the content read from r to the key, replacing the blob there. The blob is never visible
+	// partially written
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+	// Get opens the blob, ErrNotFound is returned if there is none. The caller must close it
+	Get(ctx context.Context, key string) (*Object, error)
+	// Stat returns the info of the blob, ErrNotFound is returned if there is none
+	Stat(ctx context.Context, key string) (Info, error)
+	// Delete removes the blob, deleting a missing one is not an error
+	Delete(ctx context.Context, key string) error
+	// List calls fn with the blobs whose keys start with prefix, stopping at the first error fn returns.
+	// The blobs are listed in no particular order and without their content types
+	List(ctx context.Context, prefix string, fn func(info Info) error) error
+	// SignedURL returns a url the blob can be downloaded from without credentials until ttl passes.
+	// ErrNotSupported is returned if the store has not been given a way to sign them
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
+
+type Info struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ModifiedAt  time.Time
+}
+
+// Object is an opened blob. Body is an io.ReadSeeker if the backend can seek, e.g. a file of the filesystem
+type Object struct {
+	Info
+	Body io.ReadCloser
+}
+
+func (o *Object) Close() error {
+	return o.Body.Close()
+}
+
+// ValidateKey refuses the keys which are empty, absolute or climb out of the store, e.g. ../secrets
+func ValidateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return errInvalidKey(key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return errInvalidKey(key)
+		}
+	}
+	return nil
+}
+
+// ContentTypeByKey guesses the content type of the blob by the extension of its key
+func ContentTypeByKey(key string) string {
+	if contentType := mime.TypeByExtension(path.Ext(key)); contentType != "" {
+		return contentType
+	}
+	return DefaultContentType
+}
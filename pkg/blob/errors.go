@@ -0,0 +1,18 @@
+package blob
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNotFound         = errors.New("blob not found")
+	ErrInvalidKey       = errors.New("invalid blob key")
+	ErrNotSupported     = errors.New("not supported by blob store")
+	ErrInvalidSignature = errors.New("invalid blob url signature")
+	ErrURLExpired       = errors.New("blob url expired")
+)
+
+func errInvalidKey(key string) error {
+	return fmt.Errorf("%w %q", ErrInvalidKey, key)
+}
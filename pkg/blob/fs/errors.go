@@ -0,0 +1,19 @@
+package fs
+
+import "fmt"
+
+func errPutting(key string, err error) error {
+	return fmt.Errorf("%w (putting blob %s)", err, key)
+}
+
+func errGetting(key string, err error) error {
+	return fmt.Errorf("%w (getting blob %s)", err, key)
+}
+
+func errDeleting(key string, err error) error {
+	return fmt.Errorf("%w (deleting blob %s)", err, key)
+}
+
+func errListing(prefix string, err error) error {
+	return fmt.Errorf("%w (listing blobs %s)", err, prefix)
+}
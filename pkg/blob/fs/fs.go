@@ -0,0 +1,192 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"github.com/adanyl0v/go-pocket-link/pkg/blob"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// tmpPrefix marks the temporary files of the blobs being put, which are not listed
+const tmpPrefix = ".tmp-"
+
+// Store keeps the blobs as files under the directory, the keys being their relative paths. The files do not keep
+// their content types, which are guessed by the extensions of the keys
+type Store struct {
+	dir string
+	// signer signs the urls the app serves the blobs from, the urls are not supported if it is nil
+	signer *blob.URLSigner
+}
+
+func New(dir string, signer *blob.URLSigner) *Store {
+	return &Store{dir: dir, signer: signer}
+}
+
+// Put writes to a temporary file next to the blob first and renames it then, so the blob is replaced atomically
+func (s *Store) Put(ctx context.Context, key string, r io.Reader, _ string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return errPutting(key, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), tmpPrefix+filepath.Base(path)+"-*")
+	if err != nil {
+		return errPutting(key, err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	_, err = io.Copy(tmp, contextReader{ctx: ctx, r: r})
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errPutting(key, err)
+	}
+
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return errPutting(key, err)
+	}
+	return nil
+}
+
+// Get opens the file of the blob, the body of the object being the *os.File, which can seek
+func (s *Store) Get(_ context.Context, key string) (*blob.Object, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, blob.ErrNotFound
+	} else if err != nil {
+		return nil, errGetting(key, err)
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, errGetting(key, err)
+	}
+	return &blob.Object{Info: newInfo(key, stat), Body: file}, nil
+}
+
+func (s *Store) Stat(_ context.Context, key string) (blob.Info, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return blob.Info{}, err
+	}
+
+	stat, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return blob.Info{}, blob.ErrNotFound
+	} else if err != nil {
+		return blob.Info{}, errGetting(key, err)
+	} else if stat.IsDir() {
+		return blob.Info{}, blob.ErrNotFound
+	}
+	return newInfo(key, stat), nil
+}
+
+func (s *Store) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return errDeleting(key, err)
+	}
+	return nil
+}
+
+// List walks the directory the prefix points into, e.g. exports/ for both exports/ and exports/2024
+func (s *Store) List(ctx context.Context, prefix string, fn func(info blob.Info) error) error {
+	root := prefix
+	if !strings.HasSuffix(root, "/") {
+		root = path.Dir(root)
+	}
+	root = filepath.Join(s.dir, filepath.FromSlash(strings.TrimSuffix(root, "/")))
+
+	err := filepath.WalkDir(root, func(name string, entry fs.DirEntry, err error) error {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		} else if err != nil {
+			return err
+		} else if err = ctx.Err(); err != nil {
+			return err
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), tmpPrefix) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.dir, name)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		stat, err := entry.Info()
+		if errors.Is(err, os.ErrNotExist) {
+			// deleted while being listed
+			return nil
+		} else if err != nil {
+			return err
+		}
+		return fn(newInfo(key, stat))
+	})
+	if err != nil {
+		return errListing(prefix, err)
+	}
+	return nil
+}
+
+func (s *Store) SignedURL(_ context.Context, key string, ttl time.Duration) (string, error) {
+	if err := blob.ValidateKey(key); err != nil {
+		return "", err
+	} else if s.signer == nil {
+		return "", blob.ErrNotSupported
+	}
+	return s.signer.Sign(key, ttl), nil
+}
+
+func (s *Store) path(key string) (string, error) {
+	if err := blob.ValidateKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+func newInfo(key string, stat fs.FileInfo) blob.Info {
+	return blob.Info{
+		Key:         key,
+		Size:        stat.Size(),
+		ContentType: blob.ContentTypeByKey(key),
+		ModifiedAt:  stat.ModTime(),
+	}
+}
+
+// contextReader stops reading once ctx is done, so a cancelled Put does not keep copying
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
@@ -0,0 +1,35 @@
+package s3
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrNoBucket = errors.New("no s3 bucket")
+
+func errInvalidEndpoint(endpoint string) error {
+	return fmt.Errorf("invalid s3 endpoint %q", endpoint)
+}
+
+func errUnexpectedResponse(status int, code, message string) error {
+	if code == "" {
+		return fmt.Errorf("unexpected s3 response status %d", status)
+	}
+	return fmt.Errorf("unexpected s3 response status %d: %s: %s", status, code, message)
+}
+
+func errPutting(key string, err error) error {
+	return fmt.Errorf("%w (putting blob %s)", err, key)
+}
+
+func errGetting(key string, err error) error {
+	return fmt.Errorf("%w (getting blob %s)", err, key)
+}
+
+func errDeleting(key string, err error) error {
+	return fmt.Errorf("%w (deleting blob %s)", err, key)
+}
+
+func errListing(prefix string, err error) error {
+	return fmt.Errorf("%w (listing blobs %s)", err, prefix)
+}
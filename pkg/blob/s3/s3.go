@@ -0,0 +1,286 @@
+package s3
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"github.com/adanyl0v/go-pocket-link/pkg/blob"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSignedURLTTL is the longest the presigned urls of S3 may be valid for
+const maxSignedURLTTL = 7 * 24 * time.Hour
+
+type Options struct {
+	// Endpoint is the url of the service, e.g. https://s3.eu-central-1.amazonaws.com or http://localhost:9000
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// PathStyle puts the bucket in the path of the urls instead of the host, which the stand-ins like MinIO expect
+	PathStyle bool
+}
+
+// Store keeps the blobs as the objects of an S3 bucket, speaking the S3 API over plain http, so any
+// S3-compatible service will do. The http.Client is injectable, so the store can talk to a local stand-in
+type Store struct {
+	http      *http.Client
+	endpoint  *url.URL
+	bucket    string
+	signer    signer
+	pathStyle bool
+}
+
+func New(httpClient *http.Client, opts Options) (*Store, error) {
+	endpoint, err := url.Parse(opts.Endpoint)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, errInvalidEndpoint(opts.Endpoint)
+	} else if opts.Bucket == "" {
+		return nil, ErrNoBucket
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &Store{
+		http:     httpClient,
+		endpoint: endpoint,
+		bucket:   opts.Bucket,
+		signer: signer{
+			region:          opts.Region,
+			accessKeyID:     opts.AccessKeyID,
+			secretAccessKey: opts.SecretAccessKey,
+		},
+		pathStyle: opts.PathStyle,
+	}, nil
+}
+
+// Put uploads the blob in a single request, which needs its length upfront. The content of an unknown length
+// is spooled to a temporary file first
+func (s *Store) Put(ctx context.Context, key string, r io.Reader, contentType string) error {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return err
+	}
+
+	body, size, cleanup, err := sized(r)
+	if err != nil {
+		return errPutting(key, err)
+	}
+	defer cleanup()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.String(), body)
+	if err != nil {
+		return errPutting(key, err)
+	}
+	req.URL = u
+	req.ContentLength = size
+	if size == 0 {
+		// an empty body is sent as no body at all
+		req.Body = http.NoBody
+	}
+	if contentType == "" {
+		contentType = blob.DefaultContentType
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := s.do(req, unsignedPayload)
+	if err != nil {
+		return errPutting(key, err)
+	}
+	_ = resp.Body.Close()
+	return nil
+}
+
+func (s *Store) Get(ctx context.Context, key string) (*blob.Object, error) {
+	resp, err := s.object(ctx, http.MethodGet, key)
+	if err != nil {
+		return nil, errGetting(key, err)
+	}
+	return &blob.Object{Info: newInfo(key, resp), Body: resp.Body}, nil
+}
+
+func (s *Store) Stat(ctx context.Context, key string) (blob.Info, error) {
+	resp, err := s.object(ctx, http.MethodHead, key)
+	if err != nil {
+		return blob.Info{}, errGetting(key, err)
+	}
+	_ = resp.Body.Close()
+	return newInfo(key, resp), nil
+}
+
+// Delete removes the object, S3 answering the same whether there has been one or not
+func (s *Store) Delete(ctx context.Context, key string) error {
+	resp, err := s.object(ctx, http.MethodDelete, key)
+	if errors.Is(err, blob.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return errDeleting(key, err)
+	}
+	_ = resp.Body.Close()
+	return nil
+}
+
+// listResult is the page of the objects ListObjectsV2 answers with
+type listResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List pages through the objects with ListObjectsV2, which lists them in the order of their keys
+func (s *Store) List(ctx context.Context, prefix string, fn func(info blob.Info) error) error {
+	u := s.bucketURL()
+	query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+	for {
+		u.RawQuery = canonicalQuery(query)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return errListing(prefix, err)
+		}
+
+		resp, err := s.do(req, emptyPayload)
+		if err != nil {
+			return errListing(prefix, err)
+		}
+		var result listResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		_ = resp.Body.Close()
+		if err != nil {
+			return errListing(prefix, err)
+		}
+
+		for _, object := range result.Contents {
+			info := blob.Info{Key: object.Key, Size: object.Size, ModifiedAt: object.LastModified}
+			if err = fn(info); err != nil {
+				return err
+			}
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return nil
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+}
+
+// SignedURL presigns a GET request of the object, the url leading to the service itself
+func (s *Store) SignedURL(_ context.Context, key string, ttl time.Duration) (string, error) {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return "", err
+	}
+	s.signer.presign(u, min(ttl, maxSignedURLTTL), time.Now())
+	return u.String(), nil
+}
+
+// object sends a request of the object without a body, ErrNotFound is returned if there is no object
+func (s *Store) object(ctx context.Context, method, key string) (*http.Response, error) {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.URL = u
+	return s.do(req, emptyPayload)
+}
+
+// do signs and sends the request, turning the responses other than 2xx into errors
+func (s *Store) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	s.signer.sign(req, payloadHash, time.Now())
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return resp, nil
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, blob.ErrNotFound
+	}
+	// the HEAD responses have no body, so they are left without the code of the error
+	var apiErr struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	_ = xml.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&apiErr)
+	return nil, errUnexpectedResponse(resp.StatusCode, apiErr.Code, apiErr.Message)
+}
+
+func (s *Store) bucketURL() *url.URL {
+	u := *s.endpoint
+	u.RawQuery, u.Fragment = "", ""
+	if s.pathStyle {
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/" + s.bucket + "/"
+	} else {
+		u.Host = s.bucket + "." + u.Host
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/"
+	}
+	u.RawPath = uriEncode(u.Path, false)
+	return &u
+}
+
+func (s *Store) objectURL(key string) (*url.URL, error) {
+	if err := blob.ValidateKey(key); err != nil {
+		return nil, err
+	}
+	u := s.bucketURL()
+	u.Path += key
+	u.RawPath = uriEncode(u.Path, false)
+	return u, nil
+}
+
+func newInfo(key string, resp *http.Response) blob.Info {
+	info := blob.Info{Key: key, Size: resp.ContentLength, ContentType: resp.Header.Get("Content-Type")}
+	if size, err := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64); err == nil {
+		info.Size = size
+	}
+	if modifiedAt, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.ModifiedAt = modifiedAt
+	}
+	return info
+}
+
+// sized returns the content along with its length, spooling it to a temporary file if the length is unknown.
+// cleanup removes the file
+func sized(r io.Reader) (io.Reader, int64, func(), error) {
+	if lr, ok := r.(interface{ Len() int }); ok {
+		return r, int64(lr.Len()), func() {}, nil
+	}
+
+	tmp, err := os.CreateTemp("", "blob-*")
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	cleanup := func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}
+
+	size, err := io.Copy(tmp, r)
+	if err == nil {
+		_, err = tmp.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		cleanup()
+		return nil, 0, nil, err
+	}
+	return tmp, size, cleanup, nil
+}
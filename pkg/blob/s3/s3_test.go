@@ -0,0 +1,310 @@
+package s3
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"github.com/adanyl0v/go-pocket-link/pkg/blob"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testBucket = "pocket-link"
+
+var testSigner = signer{region: "us-east-1", accessKeyID: "test-key", secretAccessKey: "test-secret"}
+
+type fakeObject struct {
+	body        []byte
+	contentType string
+}
+
+// fakeS3 is a path-style stand-in of S3 which checks the signatures of the requests the way S3 does
+type fakeS3 struct {
+	t       *testing.T
+	mu      sync.Mutex
+	objects map[string]fakeObject
+	// pageSize is how many objects ListObjectsV2 answers with at once
+	pageSize int
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *Store) {
+	t.Helper()
+	fake := &fakeS3{t: t, objects: make(map[string]fakeObject), pageSize: 2}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	store, err := New(server.Client(), Options{
+		Endpoint:        server.URL,
+		Region:          testSigner.region,
+		Bucket:          testBucket,
+		AccessKeyID:     testSigner.accessKeyID,
+		SecretAccessKey: testSigner.secretAccessKey,
+		PathStyle:       true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fake, store
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !f.authorized(r) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = io.WriteString(w, `<Error><Code>SignatureDoesNotMatch</Code><Message>bad signature</Message></Error>`)
+		return
+	}
+
+	key, ok := strings.CutPrefix(r.URL.Path, "/"+testBucket+"/")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case key == "" && r.Method == http.MethodGet:
+		f.list(w, r)
+	case r.Method == http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		if r.ContentLength != int64(len(body)) {
+			f.t.Errorf("put %s with content length %d, body of %d bytes", key, r.ContentLength, len(body))
+		}
+		f.objects[key] = fakeObject{body: body, contentType: r.Header.Get("Content-Type")}
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		object, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", object.contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(object.body)))
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(object.body)
+		}
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *fakeS3) list(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("list-type") != "2" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var keys []string
+	for key := range f.objects {
+		if strings.HasPrefix(key, query.Get("prefix")) && key > query.Get("continuation-token") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var result listResult
+	if len(keys) > f.pageSize {
+		keys = keys[:f.pageSize]
+		result.IsTruncated = true
+		result.NextContinuationToken = keys[len(keys)-1]
+	}
+	for _, key := range keys {
+		result.Contents = append(result.Contents, struct {
+			Key          string    `xml:"Key"`
+			Size         int64     `xml:"Size"`
+			LastModified time.Time `xml:"LastModified"`
+		}{Key: key, Size: int64(len(f.objects[key].body)), LastModified: time.Now().UTC()})
+	}
+	_ = xml.NewEncoder(w).Encode(result)
+}
+
+// authorized signs the request again with the same time and headers and compares the signatures
+func (f *fakeS3) authorized(r *http.Request) bool {
+	u := *r.URL
+	u.Scheme, u.Host = "http", r.Host
+
+	if query := u.Query(); query.Has("X-Amz-Signature") {
+		signedAt, err := time.Parse(sigTimeFormat, query.Get("X-Amz-Date"))
+		if err != nil {
+			return false
+		}
+		expires, _ := strconv.Atoi(query.Get("X-Amz-Expires"))
+		if time.Since(signedAt) > time.Duration(expires)*time.Second {
+			return false
+		}
+		signature := query.Get("X-Amz-Signature")
+
+		u.RawQuery = ""
+		testSigner.presign(&u, time.Duration(expires)*time.Second, signedAt)
+		return u.Query().Get("X-Amz-Signature") == signature
+	}
+
+	signedAt, err := time.Parse(sigTimeFormat, r.Header.Get(headerDate))
+	if err != nil {
+		return false
+	}
+	authorization := r.Header.Get("Authorization")
+	_, signedHeaders, _ := strings.Cut(authorization, "SignedHeaders=")
+	signedHeaders, _, _ = strings.Cut(signedHeaders, ",")
+
+	resigned, _ := http.NewRequest(r.Method, u.String(), nil)
+	resigned.URL = &u
+	for _, name := range strings.Split(signedHeaders, ";") {
+		if name != "host" {
+			resigned.Header.Set(name, r.Header.Get(name))
+		}
+	}
+	testSigner.sign(resigned, r.Header.Get(headerContentSHA256), signedAt)
+	return resigned.Header.Get("Authorization") == authorization
+}
+
+func TestStorePutGetStatDelete(t *testing.T) {
+	ctx := context.Background()
+	_, store := newFakeS3(t)
+
+	const key = "snapshots/a b/page.html"
+	if err := store.Put(ctx, key, strings.NewReader("<html></html>"), "text/html"); err != nil {
+		t.Fatal(err)
+	}
+
+	object, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(object.Body)
+	_ = object.Close()
+	if string(body) != "<html></html>" || object.ContentType != "text/html" || object.Size != int64(len(body)) {
+		t.Errorf("got %q of type %s and size %d", body, object.ContentType, object.Size)
+	}
+
+	info, err := store.Stat(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size != int64(len(body)) || info.ModifiedAt.IsZero() {
+		t.Errorf("stat = %+v", info)
+	}
+
+	if err = store.Delete(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = store.Get(ctx, key); !errors.Is(err, blob.ErrNotFound) {
+		t.Errorf("get after delete = %v, want ErrNotFound", err)
+	}
+	if err = store.Delete(ctx, key); err != nil {
+		t.Errorf("deleting a missing blob = %v, want nil", err)
+	}
+}
+
+func TestStorePutSpoolsUnknownLength(t *testing.T) {
+	ctx := context.Background()
+	fake, store := newFakeS3(t)
+
+	// a pipe reader has no length, so the content is spooled before it is sent
+	r, w := io.Pipe()
+	go func() {
+		_, _ = io.WriteString(w, "streamed content")
+		_ = w.Close()
+	}()
+	if err := store.Put(ctx, "exports/a.zip", r, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	object := fake.objects["exports/a.zip"]
+	if string(object.body) != "streamed content" || object.contentType != blob.DefaultContentType {
+		t.Errorf("stored %q of type %s", object.body, object.contentType)
+	}
+}
+
+func TestStoreList(t *testing.T) {
+	ctx := context.Background()
+	_, store := newFakeS3(t)
+
+	for _, key := range []string{"snapshots/1", "snapshots/2", "snapshots/3", "exports/1"} {
+		if err := store.Put(ctx, key, strings.NewReader(key), ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var keys []string
+	err := store.List(ctx, "snapshots/", func(info blob.Info) error {
+		keys = append(keys, info.Key)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(keys, ",") != "snapshots/1,snapshots/2,snapshots/3" {
+		t.Errorf("listed %v", keys)
+	}
+}
+
+func TestStoreSignedURL(t *testing.T) {
+	ctx := context.Background()
+	_, store := newFakeS3(t)
+
+	if err := store.Put(ctx, "exports/a.zip", strings.NewReader("archive"), "application/zip"); err != nil {
+		t.Fatal(err)
+	}
+
+	signedURL, err := store.SignedURL(ctx, "exports/a.zip", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Get(signedURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "archive" {
+		t.Fatalf("signed url answered %d %q", resp.StatusCode, body)
+	}
+
+	// a tampered url must be refused
+	u, _ := url.Parse(signedURL)
+	u.Path = "/" + testBucket + "/exports/b.zip"
+	resp, err = http.Get(u.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("tampered url answered %d, want 403", resp.StatusCode)
+	}
+}
+
+func TestStoreRefusesWrongCredentials(t *testing.T) {
+	fake, store := newFakeS3(t)
+	store.signer.secretAccessKey = "wrong"
+
+	err := store.Put(context.Background(), "a", strings.NewReader("a"), "")
+	if err == nil || !strings.Contains(err.Error(), "SignatureDoesNotMatch") {
+		t.Errorf("put with wrong credentials = %v", err)
+	}
+	if len(fake.objects) != 0 {
+		t.Error("the blob must not be stored")
+	}
+}
+
+func TestNewValidatesOptions(t *testing.T) {
+	if _, err := New(nil, Options{Endpoint: "localhost:9000", Bucket: testBucket}); err == nil {
+		t.Error("endpoint without scheme must be refused")
+	}
+	if _, err := New(nil, Options{Endpoint: "http://localhost:9000"}); !errors.Is(err, ErrNoBucket) {
+		t.Errorf("no bucket = %v, want ErrNoBucket", err)
+	}
+}
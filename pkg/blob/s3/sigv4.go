@@ -0,0 +1,158 @@
+package s3
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	sigAlgorithm    = "AWS4-HMAC-SHA256"
+	sigService      = "s3"
+	sigTimeFormat   = "20060102T150405Z"
+	sigDateFormat   = "20060102"
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+
+	headerContentSHA256 = "X-Amz-Content-Sha256"
+	headerDate          = "X-Amz-Date"
+)
+
+// emptyPayload is the hash of the empty payload, which the requests without a body are signed with
+var emptyPayload = hashHex(nil)
+
+// signer signs the requests with AWS Signature Version 4, which S3 and its stand-ins authenticate
+type signer struct {
+	region          string
+	accessKeyID     string
+	secretAccessKey string
+}
+
+// sign adds the authorization header to the request, signing its host, the headers it has been given
+// and the x-amz ones
+func (s signer) sign(req *http.Request, payloadHash string, now time.Time) {
+	now = now.UTC()
+	req.Header.Set(headerContentSHA256, payloadHash)
+	req.Header.Set(headerDate, now.Format(sigTimeFormat))
+
+	names := []string{"host"}
+	values := map[string]string{"host": req.URL.Host}
+	for name, value := range req.Header {
+		name = strings.ToLower(name)
+		names = append(names, name)
+		values[name] = strings.Join(strings.Fields(strings.Join(value, ",")), " ")
+	}
+	sort.Strings(names)
+
+	var headers strings.Builder
+	for _, name := range names {
+		headers.WriteString(name + ":" + values[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonical := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		headers.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := s.scope(now)
+	signature := s.signature(canonical, scope, now)
+	req.Header.Set("Authorization", sigAlgorithm+" Credential="+s.accessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+// presign adds the signature of a GET request valid for ttl to the query of the url, signing only its host
+func (s signer) presign(u *url.URL, ttl time.Duration, now time.Time) {
+	now = now.UTC()
+	scope := s.scope(now)
+
+	query := u.Query()
+	query.Set("X-Amz-Algorithm", sigAlgorithm)
+	query.Set("X-Amz-Credential", s.accessKeyID+"/"+scope)
+	query.Set("X-Amz-Date", now.Format(sigTimeFormat))
+	query.Set("X-Amz-Expires", formatSeconds(ttl))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	canonical := strings.Join([]string{
+		http.MethodGet,
+		u.EscapedPath(),
+		canonicalQuery(query),
+		"host:" + u.Host + "\n",
+		"host",
+		unsignedPayload,
+	}, "\n")
+
+	query.Set("X-Amz-Signature", s.signature(canonical, scope, now))
+	u.RawQuery = canonicalQuery(query)
+}
+
+func (s signer) scope(now time.Time) string {
+	return now.Format(sigDateFormat) + "/" + s.region + "/" + sigService + "/aws4_request"
+}
+
+func (s signer) signature(canonical, scope string, now time.Time) string {
+	stringToSign := strings.Join([]string{sigAlgorithm, now.Format(sigTimeFormat), scope, hashHex([]byte(canonical))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretAccessKey), now.Format(sigDateFormat))
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, sigService)
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// canonicalQuery encodes the query sorted by the names and the values, the way the signatures expect
+func canonicalQuery(query url.Values) string {
+	pairs := make([]string, 0, len(query))
+	for name, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, uriEncode(name, true)+"="+uriEncode(value, true))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode escapes everything but the unreserved characters, and the slashes unless encodeSlash is set
+func uriEncode(s string, encodeSlash bool) string {
+	const hexDigits = "0123456789ABCDEF"
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			b.WriteByte('%')
+			b.WriteByte(hexDigits[c>>4])
+			b.WriteByte(hexDigits[c&15])
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(d/time.Second), 10)
+}
@@ -0,0 +1,69 @@
+package blob
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	queryExpires   = "expires"
+	querySignature = "signature"
+)
+
+// URLSigner signs the urls the app serves the blobs from, for the stores which cannot serve them themselves,
+// e.g. the filesystem. The urls are the key appended to the base url, with the expiration time and the
+// signature of both in the query
+type URLSigner struct {
+	baseURL string
+	secret  []byte
+}
+
+func NewURLSigner(baseURL, secret string) *URLSigner {
+	return &URLSigner{baseURL: strings.TrimSuffix(baseURL, "/") + "/", secret: []byte(secret)}
+}
+
+func (s *URLSigner) Sign(key string, ttl time.Duration) string {
+	expires := strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)
+	query := url.Values{queryExpires: {expires}, querySignature: {s.signature(key, expires)}}
+	return s.baseURL + escapeKey(key) + "?" + query.Encode()
+}
+
+// Verify checks the signature of the url of the key, given the query of the url
+func (s *URLSigner) Verify(key string, query url.Values) error {
+	expires := query.Get(queryExpires)
+	signature, err := hex.DecodeString(query.Get(querySignature))
+	if err != nil || !hmac.Equal(signature, s.mac(key, expires)) {
+		return ErrInvalidSignature
+	}
+
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	} else if time.Now().Unix() > unix {
+		return ErrURLExpired
+	}
+	return nil
+}
+
+func (s *URLSigner) signature(key, expires string) string {
+	return hex.EncodeToString(s.mac(key, expires))
+}
+
+func (s *URLSigner) mac(key, expires string) []byte {
+	h := hmac.New(sha256.New, s.secret)
+	_, _ = h.Write([]byte(key + "\n" + expires))
+	return h.Sum(nil)
+}
+
+func escapeKey(key string) string {
+	parts := strings.Split(key, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.Join(parts, "/")
+}
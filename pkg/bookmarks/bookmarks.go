@@ -0,0 +1,110 @@
+package bookmarks
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Format string
+
+const (
+	FormatNetscape   Format = "netscape"
+	FormatPocketHTML Format = "pocket_html"
+	FormatPocketCSV  Format = "pocket_csv"
+	FormatPinboard   Format = "pinboard"
+	FormatCSV        Format = "csv"
+)
+
+type Bookmark struct {
+	URL         string
+	Title       string
+	Description string
+	Tags        []string
+	// Folders is the path of the folders containing the bookmark, outermost first
+	Folders []string
+	AddedAt time.Time
+	// Read is set for the bookmarks the export marks read, like the ones Pocket has archived
+	Read bool
+	// Err is set if a field of the bookmark could not be parsed, so the bookmark is reported
+	// rather than failing the whole export
+	Err error
+}
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatNetscape, FormatPocketHTML, FormatPocketCSV, FormatPinboard, FormatCSV:
+		return f, nil
+	default:
+		return "", errUnknownFormat(s)
+	}
+}
+
+// DetectFormat guesses the format of the export by its first bytes
+func DetectFormat(data []byte) (Format, error) {
+	head := bytes.ToLower(bytes.TrimSpace(data[:min(len(data), 1024)]))
+	switch {
+	case bytes.HasPrefix(head, []byte("[")), bytes.HasPrefix(head, []byte("{")):
+		return FormatPinboard, nil
+	case bytes.Contains(head, []byte("netscape-bookmark-file")):
+		return FormatNetscape, nil
+	case bytes.Contains(head, []byte("pocket export")):
+		return FormatPocketHTML, nil
+	case bytes.HasPrefix(head, []byte("<")):
+		return FormatNetscape, nil
+	case bytes.HasPrefix(head, []byte("title,url,time_added")):
+		return FormatPocketCSV, nil
+	case bytes.Contains(firstLine(head), []byte("url")):
+		return FormatCSV, nil
+	default:
+		return "", errUndetectableFormat
+	}
+}
+
+func Parse(format Format, r io.Reader) ([]Bookmark, error) {
+	switch format {
+	case FormatNetscape, FormatPocketHTML:
+		return ParseNetscape(r)
+	case FormatPocketCSV:
+		return ParsePocketCSV(r)
+	case FormatPinboard:
+		return ParsePinboard(r)
+	case FormatCSV:
+		return ParseCSV(r)
+	default:
+		return nil, errUnknownFormat(string(format))
+	}
+}
+
+// parseTimestamp parses either unix seconds or an RFC 3339 time, returning zero time if s is empty
+func parseTimestamp(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	if unix, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(unix, 0).UTC(), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, errParsingTimestamp(s, err)
+	}
+	return t, nil
+}
+
+func splitTags(s string, sep func(rune) bool) []string {
+	tags := strings.FieldsFunc(s, sep)
+	for i := range tags {
+		tags[i] = strings.TrimSpace(tags[i])
+	}
+	return tags
+}
+
+func firstLine(data []byte) []byte {
+	line, _, _ := bytes.Cut(data, []byte("\n"))
+	return line
+}
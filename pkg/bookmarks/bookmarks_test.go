@@ -0,0 +1,72 @@
+package bookmarks
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// checkBookmarks compares the bookmarks field by field, the errors only by whether the bookmark has one
+func checkBookmarks(t *testing.T, got, want []Bookmark) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d bookmarks, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if (got[i].Err != nil) != (want[i].Err != nil) {
+			t.Errorf("bookmark %d: error %v, want %v", i, got[i].Err, want[i].Err)
+		}
+		g, w := got[i], want[i]
+		g.Err, w.Err = nil, nil
+		if !reflect.DeepEqual(g, w) {
+			t.Errorf("bookmark %d:\n got %+v\nwant %+v", i, g, w)
+		}
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    Format
+		wantErr bool
+	}{
+		{"netscape", "<!DOCTYPE NETSCAPE-Bookmark-file-1>\n<DL><p>", FormatNetscape, false},
+		{"pocket html", "<!DOCTYPE html><html><head><title>Pocket Export</title></head>", FormatPocketHTML, false},
+		{"other html", "<html><body><a href=\"https://example.com\">a</a>", FormatNetscape, false},
+		{"pocket csv", "title,url,time_added,tags,status\n", FormatPocketCSV, false},
+		{"csv", "\ufeffname,URL,labels\n", FormatCSV, false},
+		{"pinboard", "  [{\"href\":\"https://example.com\"}]", FormatPinboard, false},
+		{"unknown", "just some text\nwith lines", "", true},
+		{"empty", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DetectFormat([]byte(tt.data))
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("DetectFormat = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"", time.Time{}, false},
+		{" 1700000000 ", time.Unix(1700000000, 0).UTC(), false},
+		{"2024-11-05T10:00:00Z", time.Date(2024, 11, 5, 10, 0, 0, 0, time.UTC), false},
+		{"2024-11-05T12:00:00+02:00", time.Date(2024, 11, 5, 10, 0, 0, 0, time.UTC), false},
+		{"yesterday", time.Time{}, true},
+		{"2024-11-05", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parseTimestamp(tt.value)
+		if (err != nil) != tt.wantErr || !got.Equal(tt.want) {
+			t.Errorf("parseTimestamp(%q) = %s, %v, want %s", tt.value, got, err, tt.want)
+		}
+	}
+}
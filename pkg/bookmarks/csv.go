@@ -0,0 +1,120 @@
+package bookmarks
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// pocketStatusArchive is the status of the bookmarks archived in Pocket, the others being unread
+const pocketStatusArchive = "archive"
+
+// ParsePocketCSV parses Pocket's CSV export with the title, url, time_added, tags and status columns.
+// The archived bookmarks are read
+func ParsePocketCSV(r io.Reader) ([]Bookmark, error) {
+	bookmarks, err := parseCSV(r, csvColumns{
+		url:     []string{"url"},
+		title:   []string{"title"},
+		tags:    []string{"tags"},
+		addedAt: []string{"time_added"},
+		status:  []string{"status"},
+	}, func(r rune) bool { return r == '|' })
+	if err != nil {
+		return nil, errParsing(FormatPocketCSV, err)
+	}
+	return bookmarks, nil
+}
+
+// ParseCSV parses a generic CSV file with a header row. The only required column is url,
+// the optional ones are title, description, tags, folder and created_at
+func ParseCSV(r io.Reader) ([]Bookmark, error) {
+	bookmarks, err := parseCSV(r, csvColumns{
+		url:         []string{"url", "href", "link"},
+		title:       []string{"title", "name"},
+		description: []string{"description", "note", "extended"},
+		tags:        []string{"tags", "labels"},
+		addedAt:     []string{"created_at", "added_at", "time_added", "created"},
+		folder:      []string{"folder", "list"},
+	}, func(r rune) bool { return r == ',' || r == '|' || r == ';' || unicode.IsSpace(r) })
+	if err != nil {
+		return nil, errParsing(FormatCSV, err)
+	}
+	return bookmarks, nil
+}
+
+type csvColumns struct {
+	url, title, description, tags, addedAt, folder, status []string
+}
+
+func parseCSV(r io.Reader, columns csvColumns, tagsSep func(rune) bool) ([]Bookmark, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+
+	find := func(names []string) int {
+		for _, name := range names {
+			if i, ok := index[name]; ok {
+				return i
+			}
+		}
+		return -1
+	}
+
+	urlIdx := find(columns.url)
+	if urlIdx < 0 {
+		return nil, errMissingColumn(columns.url[0])
+	}
+	titleIdx, descriptionIdx := find(columns.title), find(columns.description)
+	tagsIdx, addedAtIdx, folderIdx := find(columns.tags), find(columns.addedAt), find(columns.folder)
+	statusIdx := find(columns.status)
+
+	bookmarks := make([]Bookmark, 0)
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return bookmarks, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		field := func(i int) string {
+			if i < 0 || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		bookmark := Bookmark{
+			URL:         field(urlIdx),
+			Title:       field(titleIdx),
+			Description: field(descriptionIdx),
+			Tags:        splitTags(field(tagsIdx), tagsSep),
+			Read:        strings.EqualFold(field(statusIdx), pocketStatusArchive),
+		}
+		if bookmark.URL == "" {
+			continue
+		}
+
+		if folder := field(folderIdx); folder != "" {
+			bookmark.Folders = strings.Split(folder, "/")
+		}
+
+		if bookmark.AddedAt, err = parseTimestamp(field(addedAtIdx)); err != nil {
+			bookmark.Err = errParsingRow(row, err)
+		}
+
+		bookmarks = append(bookmarks, bookmark)
+	}
+}
@@ -0,0 +1,161 @@
+package bookmarks
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParsePocketCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    []Bookmark
+		wantErr bool
+	}{
+		{
+			name: "statuses",
+			data: "title,url,time_added,tags,status\n" +
+				"Unread one,https://example.com/a,1700000000,go|web,unread\n" +
+				"Archived one,https://example.com/b,1700000100,,archive\n",
+			want: []Bookmark{
+				{URL: "https://example.com/a", Title: "Unread one", Tags: []string{"go", "web"},
+					AddedAt: time.Unix(1700000000, 0).UTC()},
+				{URL: "https://example.com/b", Title: "Archived one", Tags: []string{},
+					AddedAt: time.Unix(1700000100, 0).UTC(), Read: true},
+			},
+		},
+		{
+			name: "bad time is reported by its row",
+			data: "title,url,time_added,tags,status\n" +
+				"Bad,https://example.com/a,last week,,unread\n" +
+				"Good,https://example.com/b,1700000000,,unread\n",
+			want: []Bookmark{
+				{URL: "https://example.com/a", Title: "Bad", Tags: []string{}, Err: errors.New("row 2")},
+				{URL: "https://example.com/b", Title: "Good", Tags: []string{}, AddedAt: time.Unix(1700000000, 0).UTC()},
+			},
+		},
+		{
+			name:    "no url column",
+			data:    "title,time_added\nA,1700000000\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePocketCSV(strings.NewReader(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePocketCSV error = %v, want error %t", err, tt.wantErr)
+			}
+			checkBookmarks(t, got, tt.want)
+		})
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    []Bookmark
+		wantErr bool
+	}{
+		{
+			name: "named columns",
+			data: "url,title,description,tags,folder,created_at\n" +
+				"https://example.com/a,A,About a,go|web,Dev/Go,2024-11-05T10:00:00Z\n",
+			want: []Bookmark{
+				{URL: "https://example.com/a", Title: "A", Description: "About a", Tags: []string{"go", "web"},
+					Folders: []string{"Dev", "Go"}, AddedAt: time.Date(2024, 11, 5, 10, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			name: "aliased columns in any order",
+			data: "\ufeffName, Labels, HREF, Created\n" +
+				"A,\"go, web;db\",https://example.com/a,1700000000\n",
+			want: []Bookmark{
+				{URL: "https://example.com/a", Title: "A", Tags: []string{"go", "web", "db"},
+					AddedAt: time.Unix(1700000000, 0).UTC()},
+			},
+		},
+		{
+			name: "rows without url and short rows",
+			data: "url,title,tags\n" +
+				",No url,\n" +
+				"https://example.com/a\n",
+			want: []Bookmark{
+				{URL: "https://example.com/a", Tags: []string{}},
+			},
+		},
+		{
+			name: "bad timestamp is reported and the rest parsed",
+			data: "url,created_at\n" +
+				"https://example.com/a,2024-13-01T00:00:00Z\n" +
+				"https://example.com/b,\n",
+			want: []Bookmark{
+				{URL: "https://example.com/a", Tags: []string{}, Err: errors.New("row 2")},
+				{URL: "https://example.com/b", Tags: []string{}},
+			},
+		},
+		{
+			name:    "no url column",
+			data:    "title,tags\nA,go\n",
+			wantErr: true,
+		},
+		{
+			name:    "malformed quoting",
+			data:    "url,title\nhttps://example.com/a,\"unterminated\n",
+			wantErr: true,
+		},
+		{
+			name:    "empty",
+			data:    "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCSV(strings.NewReader(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCSV error = %v, want error %t", err, tt.wantErr)
+			}
+			checkBookmarks(t, got, tt.want)
+		})
+	}
+}
+
+func TestParseCSVReportsRow(t *testing.T) {
+	got, err := ParseCSV(strings.NewReader("url,created_at\nhttps://example.com/a,\nhttps://example.com/b,soon\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[1].Err == nil || !strings.Contains(got[1].Err.Error(), "row 3") {
+		t.Errorf("error %v, want it to name row 3", got[1].Err)
+	}
+}
+
+func TestCSVWriterRoundTrip(t *testing.T) {
+	want := []Bookmark{
+		{URL: "https://example.com/a", Title: "A, with comma", Description: "Line\nbreak", Tags: []string{"go", "web"},
+			Folders: []string{"Dev", "Go"}, AddedAt: time.Date(2024, 11, 5, 10, 0, 0, 0, time.UTC)},
+		{URL: "https://example.com/b", Tags: []string{}},
+	}
+
+	var buf bytes.Buffer
+	w := NewCSVWriter(&buf)
+	for _, b := range want {
+		if err := w.Write(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ParseCSV(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkBookmarks(t, got, want)
+}
@@ -0,0 +1,28 @@
+package bookmarks
+
+import (
+	"errors"
+	"fmt"
+)
+
+var errUndetectableFormat = errors.New("failed to detect the format")
+
+func errUnknownFormat(format string) error {
+	return fmt.Errorf("unknown format %s", format)
+}
+
+func errParsingTimestamp(s string, err error) error {
+	return fmt.Errorf("%w (parsing timestamp '%s')", err, s)
+}
+
+func errParsing(format Format, err error) error {
+	return fmt.Errorf("%w (parsing %s)", err, format)
+}
+
+func errMissingColumn(name string) error {
+	return fmt.Errorf("missing %s column", name)
+}
+
+func errParsingRow(row int, err error) error {
+	return fmt.Errorf("%w (row %d)", err, row)
+}
@@ -0,0 +1,103 @@
+package bookmarks
+
+import (
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"io"
+	"strings"
+)
+
+// ParseNetscape parses the Netscape bookmark file format exported by browsers.
+// Pocket's HTML export is a flat variant of it, so it is parsed here as well
+func ParseNetscape(r io.Reader) ([]Bookmark, error) {
+	var (
+		bookmarks     = make([]Bookmark, 0)
+		folders       = make([]string, 0)
+		pendingFolder *string
+		current       *Bookmark
+		folderTitle   *strings.Builder
+	)
+
+	tokenizer := html.NewTokenizer(r)
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			if err := tokenizer.Err(); err != io.EOF {
+				return nil, errParsing(FormatNetscape, err)
+			}
+			return bookmarks, nil
+
+		case html.StartTagToken:
+			token := tokenizer.Token()
+			switch token.DataAtom {
+			case atom.H3:
+				folderTitle = &strings.Builder{}
+			case atom.Dl:
+				// every nested list belongs to the folder whose title preceded it
+				title := ""
+				if pendingFolder != nil {
+					title = *pendingFolder
+					pendingFolder = nil
+				}
+				folders = append(folders, title)
+			case atom.A:
+				current = newNetscapeBookmark(token, folders)
+			}
+
+		case html.TextToken:
+			if folderTitle != nil {
+				folderTitle.Write(tokenizer.Text())
+			} else if current != nil {
+				current.Title += string(tokenizer.Text())
+			}
+
+		case html.EndTagToken:
+			token := tokenizer.Token()
+			switch token.DataAtom {
+			case atom.H3:
+				if folderTitle != nil {
+					title := strings.TrimSpace(folderTitle.String())
+					pendingFolder = &title
+					folderTitle = nil
+				}
+			case atom.Dl:
+				if len(folders) > 0 {
+					folders = folders[:len(folders)-1]
+				}
+			case atom.A:
+				if current != nil {
+					current.Title = strings.TrimSpace(current.Title)
+					if current.URL != "" {
+						bookmarks = append(bookmarks, *current)
+					}
+					current = nil
+				}
+			}
+		}
+	}
+}
+
+func newNetscapeBookmark(token html.Token, folders []string) *Bookmark {
+	bookmark := &Bookmark{Folders: nonEmpty(folders)}
+	for _, attr := range token.Attr {
+		switch strings.ToLower(attr.Key) {
+		case "href":
+			bookmark.URL = strings.TrimSpace(attr.Val)
+		case "add_date", "time_added":
+			bookmark.AddedAt, bookmark.Err = parseTimestamp(attr.Val)
+		case "tags":
+			bookmark.Tags = splitTags(attr.Val, func(r rune) bool { return r == ',' })
+		}
+	}
+	return bookmark
+}
+
+func nonEmpty(folders []string) []string {
+	path := make([]string, 0, len(folders))
+	for _, folder := range folders {
+		if folder != "" {
+			path = append(path, folder)
+		}
+	}
+	return path
+}
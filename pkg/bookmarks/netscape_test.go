@@ -0,0 +1,117 @@
+package bookmarks
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testNetscape = `<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">
+<TITLE>Bookmarks</TITLE>
+<H1>Bookmarks</H1>
+<DL><p>
+    <DT><A HREF="https://example.com/top" ADD_DATE="1700000000">Top &amp; level</A>
+    <DT><H3>Dev</H3>
+    <DL><p>
+        <DT><A HREF=" https://go.dev/ " ADD_DATE="1700000100" TAGS="go, lang">  The Go
+            language </A>
+        <DT><H3>Databases</H3>
+        <DL><p>
+            <DT><A HREF="https://postgresql.org">PostgreSQL</A>
+        </DL><p>
+        <DT><A HREF="">No url</A>
+    </DL><p>
+    <DT><A HREF="https://example.com/bad-date" ADD_DATE="someday">Bad date</A>
+</DL><p>
+`
+
+const testPocketHTML = `<!DOCTYPE html>
+<html>
+<head><title>Pocket Export</title></head>
+<body>
+<h1>Unread</h1>
+<ul>
+<li><a href="https://example.com/a" time_added="1700000000" tags="go,web">A</a></li>
+</ul>
+<h1>Read Archive</h1>
+<ul>
+<li><a href="https://example.com/b" time_added="1700000200" tags="">B</a></li>
+</ul>
+</body>
+</html>`
+
+func TestParseNetscape(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []Bookmark
+	}{
+		{
+			name: "nested folders",
+			data: testNetscape,
+			want: []Bookmark{
+				{URL: "https://example.com/top", Title: "Top & level", Folders: []string{},
+					AddedAt: time.Unix(1700000000, 0).UTC()},
+				{URL: "https://go.dev/", Title: "The Go\n            language", Tags: []string{"go", "lang"},
+					Folders: []string{"Dev"}, AddedAt: time.Unix(1700000100, 0).UTC()},
+				{URL: "https://postgresql.org", Title: "PostgreSQL", Folders: []string{"Dev", "Databases"}},
+				{URL: "https://example.com/bad-date", Title: "Bad date", Folders: []string{}, Err: errors.New("bad date")},
+			},
+		},
+		{
+			name: "pocket export",
+			data: testPocketHTML,
+			want: []Bookmark{
+				{URL: "https://example.com/a", Title: "A", Tags: []string{"go", "web"}, Folders: []string{},
+					AddedAt: time.Unix(1700000000, 0).UTC()},
+				{URL: "https://example.com/b", Title: "B", Tags: []string{}, Folders: []string{},
+					AddedAt: time.Unix(1700000200, 0).UTC()},
+			},
+		},
+		{
+			name: "no bookmarks",
+			data: "<!DOCTYPE NETSCAPE-Bookmark-file-1>\n<DL><p>\n</DL><p>",
+			want: []Bookmark{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseNetscape(strings.NewReader(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkBookmarks(t, got, tt.want)
+		})
+	}
+}
+
+func TestNetscapeWriterRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewNetscapeWriter(&buf)
+	for _, step := range []func() error{
+		func() error { return w.Write(Bookmark{URL: "https://example.com/?a=1&b=2", Title: `"Quoted" <title>`}) },
+		func() error { return w.StartFolder("Reading & list") },
+		func() error {
+			return w.Write(Bookmark{URL: "https://go.dev/", Tags: []string{"go", "lang"}, AddedAt: time.Unix(1700000000, 0).UTC()})
+		},
+		w.Close,
+	} {
+		if err := step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := ParseNetscape(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkBookmarks(t, got, []Bookmark{
+		{URL: "https://example.com/?a=1&b=2", Title: `"Quoted" <title>`, Folders: []string{}},
+		// the bookmarks without a title are titled by their url
+		{URL: "https://go.dev/", Title: "https://go.dev/", Tags: []string{"go", "lang"}, Folders: []string{"Reading & list"},
+			AddedAt: time.Unix(1700000000, 0).UTC()},
+	})
+}
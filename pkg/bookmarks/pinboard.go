@@ -0,0 +1,49 @@
+package bookmarks
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"unicode"
+)
+
+type pinboardPost struct {
+	Href        string `json:"href"`
+	Description string `json:"description"`
+	Extended    string `json:"extended"`
+	Time        string `json:"time"`
+	Tags        string `json:"tags"`
+	ToRead      string `json:"toread"`
+}
+
+// ParsePinboard parses Pinboard's JSON export. Pinboard has no folders, so unread posts
+// are put into the "toread" folder
+func ParsePinboard(r io.Reader) ([]Bookmark, error) {
+	var posts []pinboardPost
+	if err := json.NewDecoder(r).Decode(&posts); err != nil {
+		return nil, errParsing(FormatPinboard, err)
+	}
+
+	bookmarks := make([]Bookmark, 0, len(posts))
+	for i, post := range posts {
+		if strings.TrimSpace(post.Href) == "" {
+			continue
+		}
+
+		bookmark := Bookmark{
+			URL:         strings.TrimSpace(post.Href),
+			Title:       strings.TrimSpace(post.Description),
+			Description: strings.TrimSpace(post.Extended),
+			Tags:        splitTags(post.Tags, unicode.IsSpace),
+		}
+		var err error
+		if bookmark.AddedAt, err = parseTimestamp(post.Time); err != nil {
+			bookmark.Err = errParsingRow(i+1, err)
+		}
+		if post.ToRead == "yes" {
+			bookmark.Folders = []string{"toread"}
+		}
+		bookmarks = append(bookmarks, bookmark)
+	}
+	return bookmarks, nil
+}
@@ -0,0 +1,69 @@
+package bookmarks
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParsePinboard(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    []Bookmark
+		wantErr bool
+	}{
+		{
+			name: "posts",
+			data: `[
+				{"href":"https://example.com/a","description":"A","extended":"About a","time":"2024-11-05T10:00:00Z",
+					"tags":"go  web","toread":"no"},
+				{"href":" https://example.com/b ","description":"B","time":"2024-11-05T11:00:00Z","tags":"","toread":"yes"}
+			]`,
+			want: []Bookmark{
+				{URL: "https://example.com/a", Title: "A", Description: "About a", Tags: []string{"go", "web"},
+					AddedAt: time.Date(2024, 11, 5, 10, 0, 0, 0, time.UTC)},
+				{URL: "https://example.com/b", Title: "B", Tags: []string{}, Folders: []string{"toread"},
+					AddedAt: time.Date(2024, 11, 5, 11, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			name: "posts without href are skipped",
+			data: `[{"href":"","description":"none"},{"href":"https://example.com/a"}]`,
+			want: []Bookmark{{URL: "https://example.com/a", Tags: []string{}}},
+		},
+		{
+			name: "bad time is reported and the rest parsed",
+			data: `[{"href":"https://example.com/a","time":"Nov 5"},{"href":"https://example.com/b","time":"1700000000"}]`,
+			want: []Bookmark{
+				{URL: "https://example.com/a", Tags: []string{}, Err: errors.New("row 1")},
+				{URL: "https://example.com/b", Tags: []string{}, AddedAt: time.Unix(1700000000, 0).UTC()},
+			},
+		},
+		{
+			name: "empty",
+			data: `[]`,
+			want: []Bookmark{},
+		},
+		{
+			name:    "not an array",
+			data:    `{"href":"https://example.com/a"}`,
+			wantErr: true,
+		},
+		{
+			name:    "truncated",
+			data:    `[{"href":"https://example.com/a"`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePinboard(strings.NewReader(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePinboard error = %v, want error %t", err, tt.wantErr)
+			}
+			checkBookmarks(t, got, tt.want)
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package bookmarks
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"time"
+)
+
+const netscapeHeader = `<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<!-- This is an automatically generated file.
+     It will be read and overwritten.
+     DO NOT EDIT! -->
+<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">
+<TITLE>Bookmarks</TITLE>
+<H1>Bookmarks</H1>
+<DL><p>
+`
+
+// NetscapeWriter streams bookmarks in the Netscape bookmark file format
+type NetscapeWriter struct {
+	w      *bufio.Writer
+	depth  int
+	header bool
+}
+
+func NewNetscapeWriter(w io.Writer) *NetscapeWriter {
+	return &NetscapeWriter{w: bufio.NewWriter(w)}
+}
+
+func (nw *NetscapeWriter) StartFolder(title string) error {
+	if err := nw.writeHeader(); err != nil {
+		return err
+	}
+
+	indent := nw.indent()
+	_, err := fmt.Fprintf(nw.w, "%s<DT><H3>%s</H3>\n%s<DL><p>\n", indent, html.EscapeString(title), indent)
+	nw.depth++
+	return err
+}
+
+func (nw *NetscapeWriter) EndFolder() error {
+	if nw.depth == 0 {
+		return nil
+	}
+	nw.depth--
+	_, err := fmt.Fprintf(nw.w, "%s</DL><p>\n", nw.indent())
+	return err
+}
+
+func (nw *NetscapeWriter) Write(b Bookmark) error {
+	if err := nw.writeHeader(); err != nil {
+		return err
+	}
+
+	attrs := fmt.Sprintf(`HREF="%s"`, html.EscapeString(b.URL))
+	if !b.AddedAt.IsZero() {
+		attrs += fmt.Sprintf(` ADD_DATE="%d"`, b.AddedAt.Unix())
+	}
+	if len(b.Tags) > 0 {
+		attrs += fmt.Sprintf(` TAGS="%s"`, html.EscapeString(strings.Join(b.Tags, ",")))
+	}
+
+	title := b.Title
+	if title == "" {
+		title = b.URL
+	}
+
+	_, err := fmt.Fprintf(nw.w, "%s<DT><A %s>%s</A>\n", nw.indent(), attrs, html.EscapeString(title))
+	if err == nil && b.Description != "" {
+		_, err = fmt.Fprintf(nw.w, "%s<DD>%s\n", nw.indent(), html.EscapeString(b.Description))
+	}
+	return err
+}
+
+// Close closes the open folders and flushes the output, it does not close the underlying writer
+func (nw *NetscapeWriter) Close() error {
+	if err := nw.writeHeader(); err != nil {
+		return err
+	}
+
+	for nw.depth > 0 {
+		if err := nw.EndFolder(); err != nil {
+			return err
+		}
+	}
+
+	if _, err := nw.w.WriteString("</DL><p>\n"); err != nil {
+		return err
+	}
+	return nw.w.Flush()
+}
+
+func (nw *NetscapeWriter) writeHeader() error {
+	if nw.header {
+		return nil
+	}
+	nw.header = true
+	_, err := nw.w.WriteString(netscapeHeader)
+	return err
+}
+
+func (nw *NetscapeWriter) indent() string {
+	return strings.Repeat("    ", nw.depth+1)
+}
+
+// CSVWriter streams bookmarks in the generic CSV format read by ParseCSV
+type CSVWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w)}
+}
+
+func (cw *CSVWriter) Write(b Bookmark) error {
+	if !cw.header {
+		cw.header = true
+		if err := cw.w.Write([]string{"url", "title", "description", "tags", "folder", "created_at"}); err != nil {
+			return err
+		}
+	}
+
+	addedAt := ""
+	if !b.AddedAt.IsZero() {
+		addedAt = b.AddedAt.UTC().Format(time.RFC3339)
+	}
+
+	return cw.w.Write([]string{b.URL, b.Title, b.Description, strings.Join(b.Tags, "|"),
+		strings.Join(b.Folders, "/"), addedAt})
+}
+
+// Close flushes the output, it does not close the underlying writer
+func (cw *CSVWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
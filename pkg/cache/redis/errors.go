@@ -13,3 +13,11 @@ func errClosing(err error) error {
 func errKeyDoesNotExist(key string) error {
 	return fmt.Errorf("%s does not exist", key)
 }
+
+func errSubscribing(pattern string, err error) error {
+	return fmt.Errorf("%w (subscribing to %s)", err, pattern)
+}
+
+func errRunningScript(err error) error {
+	return fmt.Errorf("%w (running script)", err)
+}
@@ -0,0 +1,85 @@
+package redis
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+// pipelineCommand labels the latency of the pipelines, which are timed as a whole
+const pipelineCommand = "pipeline"
+
+var (
+	poolHitsDesc = prometheus.NewDesc("redis_pool_hits_total",
+		"Number of times a free connection was found in the pool", nil, nil)
+	poolMissesDesc = prometheus.NewDesc("redis_pool_misses_total",
+		"Number of times a free connection was not found in the pool", nil, nil)
+	poolTimeoutsDesc = prometheus.NewDesc("redis_pool_timeouts_total",
+		"Number of times a wait for a connection timed out", nil, nil)
+	poolConnsDesc = prometheus.NewDesc("redis_pool_connections",
+		"Number of the connections in the pool by their state", []string{"state"}, nil)
+)
+
+// collector times the commands sent by the client and reports the stats of its connection pool
+type collector struct {
+	client   *redis.Client
+	duration *prometheus.HistogramVec
+}
+
+// Collector reports the latency of the commands, labelled with their names, and the stats of the connection pool.
+// The commands are timed from the moment the collector is made, so it must be made once per client
+func (c *DB) Collector() prometheus.Collector {
+	col := &collector{
+		client: c.client,
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "redis_command_duration_seconds",
+			Help:    "Latency of the redis commands",
+			Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"command"}),
+	}
+	c.client.AddHook(col)
+	return col
+}
+
+func (col *collector) Describe(ch chan<- *prometheus.Desc) {
+	col.duration.Describe(ch)
+	ch <- poolHitsDesc
+	ch <- poolMissesDesc
+	ch <- poolTimeoutsDesc
+	ch <- poolConnsDesc
+}
+
+func (col *collector) Collect(ch chan<- prometheus.Metric) {
+	col.duration.Collect(ch)
+
+	stats := col.client.PoolStats()
+	ch <- prometheus.MustNewConstMetric(poolHitsDesc, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(poolMissesDesc, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(poolTimeoutsDesc, prometheus.CounterValue, float64(stats.Timeouts))
+	ch <- prometheus.MustNewConstMetric(poolConnsDesc, prometheus.GaugeValue, float64(stats.IdleConns), "idle")
+	ch <- prometheus.MustNewConstMetric(poolConnsDesc, prometheus.GaugeValue,
+		float64(stats.TotalConns-stats.IdleConns), "in_use")
+}
+
+func (col *collector) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (col *collector) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		col.duration.WithLabelValues(cmd.Name()).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
+func (col *collector) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		col.duration.WithLabelValues(pipelineCommand).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
@@ -3,6 +3,7 @@ package redis
 import (
 	"context"
 	"errors"
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 	"time"
 )
@@ -11,12 +12,17 @@ type DB struct {
 	client *redis.Client
 }
 
+// Connect connects to redis, tracing the commands with the global tracer provider. Their arguments
+// are left out of the spans, since they carry the tokens and the other secrets kept in redis
 func Connect(dsn string) (*DB, error) {
 	opt, err := redis.ParseURL(dsn)
 	if err != nil {
 		return nil, errConnecting(err)
 	}
 	client := redis.NewClient(opt)
+	if err = redisotel.InstrumentTracing(client, redisotel.WithDBStatement(false)); err != nil {
+		return nil, errConnecting(err)
+	}
 	if _, err = client.Ping(context.Background()).Result(); err != nil {
 		return nil, errConnecting(err)
 	}
@@ -65,6 +71,11 @@ func (c *DB) Set(ctx context.Context, key string, value any, expiration time.Dur
 	return c.client.Set(ctx, key, value, expiration).Err()
 }
 
+// SetNX sets the key unless it exists, returning whether it has been set
+func (c *DB) SetNX(ctx context.Context, key string, value any, expiration time.Duration) (bool, error) {
+	return c.client.SetNX(ctx, key, value, expiration).Result()
+}
+
 func (c *DB) Delete(ctx context.Context, keys ...string) error {
 	return c.client.Del(ctx, keys...).Err()
 }
@@ -0,0 +1,23 @@
+package redis
+
+import (
+	"context"
+	"github.com/redis/go-redis/v9"
+)
+
+// Script is a Lua script run atomically by redis. It is sent by its hash, and only loaded when redis lacks it
+type Script struct {
+	script *redis.Script
+}
+
+func NewScript(src string) *Script {
+	return &Script{script: redis.NewScript(src)}
+}
+
+func (c *DB) Run(ctx context.Context, script *Script, keys []string, args ...any) (any, error) {
+	result, err := script.script.Run(ctx, c.client, keys, args...).Result()
+	if err != nil {
+		return nil, errRunningScript(err)
+	}
+	return result, nil
+}
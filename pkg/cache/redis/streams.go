@@ -0,0 +1,92 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+type StreamEntry struct {
+	ID     string
+	Values map[string]any
+}
+
+// Append adds the values to the stream, trimming it to about maxLen entries, and returns the id of the entry.
+// The ttl of the stream is renewed, so the streams nobody appends to anymore expire
+func (c *DB) Append(ctx context.Context, stream string, values map[string]any, maxLen int64, ttl time.Duration) (string, error) {
+	pipe := c.client.TxPipeline()
+	add := pipe.XAdd(ctx, &redis.XAddArgs{
+		Stream: stream,
+		MaxLen: maxLen,
+		Approx: true,
+		Values: values,
+	})
+	pipe.Expire(ctx, stream, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", err
+	}
+	return add.Val(), nil
+}
+
+func (c *DB) HasEntry(ctx context.Context, stream, id string) (bool, error) {
+	entries, err := c.client.XRangeN(ctx, stream, id, id, 1).Result()
+	if err != nil {
+		return false, err
+	}
+	return len(entries) > 0, nil
+}
+
+// Range returns the entries of the stream following the one with afterID, or from its start if afterID is empty.
+// At most count entries are returned unless count is not positive
+func (c *DB) Range(ctx context.Context, stream, afterID string, count int64) ([]StreamEntry, error) {
+	start := "-"
+	if afterID != "" {
+		start = "(" + afterID
+	}
+
+	var messages []redis.XMessage
+	var err error
+	if count > 0 {
+		messages, err = c.client.XRangeN(ctx, stream, start, "+", count).Result()
+	} else {
+		messages, err = c.client.XRange(ctx, stream, start, "+").Result()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]StreamEntry, 0, len(messages))
+	for _, message := range messages {
+		entries = append(entries, StreamEntry{ID: message.ID, Values: message.Values})
+	}
+	return entries, nil
+}
+
+func (c *DB) Publish(ctx context.Context, channel string, message any) error {
+	return c.client.Publish(ctx, channel, message).Err()
+}
+
+// Listen passes the messages of the channels matching the pattern to fn until ctx is done.
+// The messages published while the connection is being restored are lost
+func (c *DB) Listen(ctx context.Context, pattern string, fn func(channel, payload string)) error {
+	pubsub := c.client.PSubscribe(ctx, pattern)
+	defer func() { _ = pubsub.Close() }()
+
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return errSubscribing(pattern, err)
+	}
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case message, ok := <-messages:
+			if !ok {
+				return errSubscribing(pattern, errors.New("channel closed"))
+			}
+			fn(message.Channel, message.Payload)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package cron
+
+import "time"
+
+// maxYears is how far Next looks ahead, the expressions matching no date, like 0 0 30 2 *, never running
+const maxYears = 5
+
+// Schedule tells when a task runs next
+type Schedule interface {
+	// Next returns the first time after t the task runs at, or the zero time if it never runs
+	Next(t time.Time) time.Time
+}
+
+// spec is a five fields expression, each field being a bit set of the values it matches
+type spec struct {
+	minute, hour, dom, month, dow uint64
+	location                      *time.Location
+}
+
+// Next steps from the next minute through the months, the days, the hours and the minutes until
+// all of them match, starting over whenever a field wraps around, e.g. the days into the next month
+func (s *spec) Next(t time.Time) time.Time {
+	t = t.In(s.location).Truncate(time.Minute).Add(time.Minute)
+	limit := t.Year() + maxYears
+
+	// added is set once a field has been stepped, the lower fields being reset to their first value then
+	added := false
+wrap:
+	if t.Year() > limit {
+		return time.Time{}
+	}
+
+	for s.month&(1<<uint(t.Month())) == 0 {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, s.location)
+		}
+		t = t.AddDate(0, 1, 0)
+		if t.Month() == time.January {
+			goto wrap
+		}
+	}
+
+	for !s.dayMatches(t) {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, s.location)
+		}
+		t = t.AddDate(0, 0, 1)
+		if t.Day() == 1 {
+			goto wrap
+		}
+	}
+
+	for s.hour&(1<<uint(t.Hour())) == 0 {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, s.location)
+		}
+		t = t.Add(time.Hour)
+		if t.Hour() == 0 {
+			goto wrap
+		}
+	}
+
+	for s.minute&(1<<uint(t.Minute())) == 0 {
+		added = true
+		t = t.Add(time.Minute)
+		if t.Minute() == 0 {
+			goto wrap
+		}
+	}
+	return t
+}
+
+// dayMatches follows the classic cron, where a day matches either of the day fields if both are restricted
+func (s *spec) dayMatches(t time.Time) bool {
+	domMatches := s.dom&(1<<uint(t.Day())) != 0
+	dowMatches := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.dom&starBit != 0 || s.dow&starBit != 0 {
+		return domMatches && dowMatches
+	}
+	return domMatches || dowMatches
+}
+
+// every runs the task at a fixed interval, e.g. @every 30s. The times are multiples of the interval,
+// so the schedule gives the same times wherever it is evaluated
+type every struct {
+	interval time.Duration
+}
+
+func (e every) Next(t time.Time) time.Time {
+	return t.Truncate(e.interval).Add(e.interval)
+}
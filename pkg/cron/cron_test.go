@@ -0,0 +1,168 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func bitsOf(values ...uint) uint64 {
+	var bits uint64
+	for _, v := range values {
+		bits |= 1 << v
+	}
+	return bits
+}
+
+func TestParseFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		expr   string
+		minute uint64
+		hour   uint64
+		dom    uint64
+		month  uint64
+		dow    uint64
+	}{
+		{
+			name:   "values",
+			expr:   "5 4 3 2 1",
+			minute: bitsOf(5), hour: bitsOf(4), dom: bitsOf(3), month: bitsOf(2), dow: bitsOf(1),
+		},
+		{
+			name:   "ranges and lists",
+			expr:   "0,30 9-11,14 1-3,15 6-8 1-5",
+			minute: bitsOf(0, 30), hour: bitsOf(9, 10, 11, 14), dom: bitsOf(1, 2, 3, 15),
+			month: bitsOf(6, 7, 8), dow: bitsOf(1, 2, 3, 4, 5),
+		},
+		{
+			name:   "steps",
+			expr:   "*/15 0-12/6 5/10 */5 */2",
+			minute: bitsOf(0, 15, 30, 45) | starBit, hour: bitsOf(0, 6, 12), dom: bitsOf(5, 15, 25),
+			month: bitsOf(1, 6, 11) | starBit, dow: bitsOf(0, 2, 4, 6) | starBit,
+		},
+		{
+			name:   "names",
+			expr:   "0 0 * JAN,jul mon-fri",
+			minute: bitsOf(0), hour: bitsOf(0), dom: bitsOf(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20,
+				21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31) | starBit,
+			month: bitsOf(1, 7), dow: bitsOf(1, 2, 3, 4, 5),
+		},
+		{
+			name:   "sunday as 7",
+			expr:   "0 0 1 * 7",
+			minute: bitsOf(0), hour: bitsOf(0), dom: bitsOf(1),
+			month: bitsOf(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12) | starBit, dow: bitsOf(0, 7),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := Parse(tt.expr, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := schedule.(*spec)
+			if s.minute != tt.minute || s.hour != tt.hour || s.dom != tt.dom || s.month != tt.month || s.dow != tt.dow {
+				t.Errorf("fields = %b %b %b %b %b, want %b %b %b %b %b",
+					s.minute, s.hour, s.dom, s.month, s.dow, tt.minute, tt.hour, tt.dom, tt.month, tt.dow)
+			}
+		})
+	}
+}
+
+func TestParseFails(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"1-2-3 * * * *",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"a * * * *",
+		"* * * foo *",
+		"1,,2 * * * *",
+		"@weekday",
+		"@every soon",
+		"@every 500ms",
+	} {
+		if _, err := Parse(expr, nil); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", expr)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	date := func(year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"every minute", "* * * * *", date(2024, 1, 15, 10, 30), date(2024, 1, 15, 10, 31)},
+		{"seconds are dropped", "* * * * *", date(2024, 1, 15, 10, 30).Add(59 * time.Second), date(2024, 1, 15, 10, 31)},
+		{"minute step", "*/15 * * * *", date(2024, 1, 15, 10, 30), date(2024, 1, 15, 10, 45)},
+		{"minute step from a value", "5/20 * * * *", date(2024, 1, 15, 10, 45), date(2024, 1, 15, 11, 5)},
+		{"later hour", "30 2 * * *", date(2024, 1, 15, 10, 30), date(2024, 1, 16, 2, 30)},
+		{"hour range on weekdays", "0 9-17 * * 1-5", date(2024, 1, 19, 17, 30), date(2024, 1, 22, 9, 0)},
+		{"hourly descriptor", "@hourly", date(2024, 1, 15, 10, 0), date(2024, 1, 15, 11, 0)},
+		{"day into next month", "0 0 1 * *", date(2024, 1, 15, 10, 30), date(2024, 2, 1, 0, 0)},
+		{"day into next year", "0 0 1 * *", date(2024, 12, 31, 23, 59), date(2025, 1, 1, 0, 0)},
+		{"31st skips short months", "0 0 31 * *", date(2024, 1, 31, 0, 0), date(2024, 3, 31, 0, 0)},
+		{"31st skips april", "0 12 31 * *", date(2024, 3, 31, 12, 0), date(2024, 5, 31, 12, 0)},
+		{"leap day", "0 0 29 2 *", date(2024, 3, 1, 0, 0), date(2028, 2, 29, 0, 0)},
+		{"impossible date", "0 0 30 2 *", date(2024, 1, 1, 0, 0), time.Time{}},
+		{"month names", "0 12 1 jan,jul *", date(2024, 2, 1, 0, 0), date(2024, 7, 1, 12, 0)},
+		{"yearly descriptor", "@yearly", date(2024, 1, 1, 0, 0), date(2025, 1, 1, 0, 0)},
+		// 2024-01-01 is a Monday
+		{"day of month only", "0 0 13 * *", date(2024, 1, 1, 0, 0), date(2024, 1, 13, 0, 0)},
+		{"day of week only", "0 0 * * 5", date(2024, 1, 1, 0, 0), date(2024, 1, 5, 0, 0)},
+		{"sunday as 7", "0 0 * * 7", date(2024, 1, 1, 0, 0), date(2024, 1, 7, 0, 0)},
+		{"both days match either, the weekday first", "0 0 13 * 5", date(2024, 1, 1, 0, 0), date(2024, 1, 5, 0, 0)},
+		{"both days match either, the next weekday", "0 0 13 * 5", date(2024, 1, 5, 0, 0), date(2024, 1, 12, 0, 0)},
+		{"both days match either, the day of month", "0 0 13 * 5", date(2024, 1, 12, 0, 0), date(2024, 1, 13, 0, 0)},
+		{"a stepped star counts as a star, both days matching", "0 0 */10 * 1", date(2024, 1, 1, 0, 0), date(2024, 3, 11, 0, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MustParse(tt.expr, nil).Next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("Next(%s) = %s, want %s", tt.from, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextInLocation(t *testing.T) {
+	location := time.FixedZone("UTC+3", 3*60*60)
+	from := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC) // 13:30 in the location
+
+	got := MustParse("0 9 * * *", location).Next(from)
+	if want := time.Date(2024, 1, 16, 6, 0, 0, 0, time.UTC); !got.Equal(want) || got.Location() != location {
+		t.Errorf("Next = %s, want %s in %s", got, want, location)
+	}
+}
+
+func TestEveryNext(t *testing.T) {
+	tests := []struct {
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"@every 15m", time.Date(2024, 1, 15, 10, 30, 10, 0, time.UTC), time.Date(2024, 1, 15, 10, 45, 0, 0, time.UTC)},
+		{"@every 15m", time.Date(2024, 1, 15, 10, 45, 0, 0, time.UTC), time.Date(2024, 1, 15, 11, 0, 0, 0, time.UTC)},
+		{"@every 1h", time.Date(2024, 1, 15, 23, 59, 59, 0, time.UTC), time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := MustParse(tt.expr, nil).Next(tt.from); !got.Equal(tt.want) {
+			t.Errorf("%s: Next(%s) = %s, want %s", tt.expr, tt.from, got, tt.want)
+		}
+	}
+}
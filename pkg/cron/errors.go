@@ -0,0 +1,42 @@
+package cron
+
+import (
+	"fmt"
+	"time"
+)
+
+func errParsing(expr string, err error) error {
+	return fmt.Errorf("%w (parsing cron expression '%s')", err, expr)
+}
+
+func errParsingField(n int, err error) error {
+	return fmt.Errorf("%w (parsing field %d)", err, n)
+}
+
+func errFieldsCount(n int) error {
+	return fmt.Errorf("expected 5 fields, got %d", n)
+}
+
+func errUnknownDescriptor(descriptor string) error {
+	return fmt.Errorf("unknown descriptor %s", descriptor)
+}
+
+func errIntervalTooShort(d time.Duration) error {
+	return fmt.Errorf("interval %s is shorter than a second", d)
+}
+
+func errInvalidValue(value string) error {
+	return fmt.Errorf("invalid value '%s'", value)
+}
+
+func errOutOfBounds(value, min, max uint) error {
+	return fmt.Errorf("value %d is out of bounds [%d, %d]", value, min, max)
+}
+
+func errInvalidRange(value string) error {
+	return fmt.Errorf("invalid range '%s'", value)
+}
+
+func errInvalidStep(value string) error {
+	return fmt.Errorf("invalid step '%s'", value)
+}
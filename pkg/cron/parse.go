@@ -0,0 +1,174 @@
+package cron
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// starBit marks the fields given as *, which matters for the day fields only
+const starBit = 1 << 63
+
+type bounds struct {
+	min, max uint
+	names    map[string]uint
+}
+
+var (
+	minutes = bounds{min: 0, max: 59}
+	hours   = bounds{min: 0, max: 23}
+	dom     = bounds{min: 1, max: 31}
+	months  = bounds{min: 1, max: 12, names: map[string]uint{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// dow allows 7 as Sunday along with 0
+	dow = bounds{min: 0, max: 7, names: map[string]uint{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses the standard five fields expression, minute, hour, day of month, month and day of week,
+// each field being *, a value, a range or a list of them, optionally with a step, e.g. */15 or 1-5.
+// The months and the days of week may be given by their names, like jan or mon. The descriptors
+// @yearly, @monthly, @weekly, @daily and @hourly are supported, as well as @every <duration>.
+// The times are matched in the location
+func Parse(expr string, location *time.Location) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if location == nil {
+		location = time.UTC
+	}
+
+	if interval, ok := strings.CutPrefix(expr, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(interval))
+		if err != nil {
+			return nil, errParsing(expr, err)
+		} else if d < time.Second {
+			return nil, errParsing(expr, errIntervalTooShort(d))
+		}
+		return every{interval: d}, nil
+	}
+	if descriptor, ok := descriptors[strings.ToLower(expr)]; ok {
+		expr = descriptor
+	} else if strings.HasPrefix(expr, "@") {
+		return nil, errParsing(expr, errUnknownDescriptor(expr))
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, errParsing(expr, errFieldsCount(len(fields)))
+	}
+
+	s := &spec{location: location}
+	var err error
+	for i, field := range []struct {
+		value  string
+		bounds bounds
+		bits   *uint64
+	}{
+		{fields[0], minutes, &s.minute},
+		{fields[1], hours, &s.hour},
+		{fields[2], dom, &s.dom},
+		{fields[3], months, &s.month},
+		{fields[4], dow, &s.dow},
+	} {
+		if *field.bits, err = parseField(field.value, field.bounds); err != nil {
+			return nil, errParsing(expr, errParsingField(i+1, err))
+		}
+	}
+	// 7 is Sunday as well
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	return s, nil
+}
+
+// MustParse is Parse panicking on error, for the expressions known to be valid
+func MustParse(expr string, location *time.Location) Schedule {
+	s, err := Parse(expr, location)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func parseField(field string, b bounds) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		partBits, err := parsePart(part, b)
+		if err != nil {
+			return 0, err
+		}
+		bits |= partBits
+	}
+	return bits, nil
+}
+
+// parsePart parses *, a value or a range, followed by an optional step
+func parsePart(part string, b bounds) (uint64, error) {
+	rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+	var start, end uint
+	var extra uint64
+	switch {
+	case rangePart == "*" || rangePart == "?":
+		start, end, extra = b.min, b.max, starBit
+	default:
+		low, high, isRange := strings.Cut(rangePart, "-")
+		var err error
+		if start, err = parseValue(low, b); err != nil {
+			return 0, err
+		}
+		end = start
+		if isRange {
+			if end, err = parseValue(high, b); err != nil {
+				return 0, err
+			}
+		} else if hasStep {
+			// a value with a step, like 5/15, runs from the value through the end of the field
+			end = b.max
+		}
+	}
+	if start > end {
+		return 0, errInvalidRange(rangePart)
+	}
+
+	step := uint(1)
+	if hasStep {
+		n, err := strconv.ParseUint(stepPart, 10, 8)
+		if err != nil || n == 0 {
+			return 0, errInvalidStep(stepPart)
+		}
+		step = uint(n)
+	}
+
+	var bits uint64
+	for v := start; v <= end; v += step {
+		bits |= 1 << v
+	}
+	return bits | extra, nil
+}
+
+func parseValue(value string, b bounds) (uint, error) {
+	if n, ok := b.names[strings.ToLower(value)]; ok {
+		return n, nil
+	}
+	n, err := strconv.ParseUint(value, 10, 8)
+	if err != nil {
+		return 0, errInvalidValue(value)
+	}
+	if uint(n) < b.min || uint(n) > b.max {
+		return 0, errOutOfBounds(uint(n), b.min, b.max)
+	}
+	return uint(n), nil
+}
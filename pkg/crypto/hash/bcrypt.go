@@ -0,0 +1,34 @@
+package hash
+
+import "golang.org/x/crypto/bcrypt"
+
+// MaxPasswordLength is the length in bytes of the longest password bcrypt hashes
+const MaxPasswordLength = 72
+
+// PasswordHasher hashes each password with a random salt of its own, kept in the hash. So the hashes of
+// the same password differ, and a password is checked by Compare rather than by hashing it again
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	// Compare reports whether the password is the one of the hash
+	Compare(hash, password string) bool
+}
+
+type bcryptHasher struct {
+	cost int
+}
+
+func NewBcryptHasher(cost int) PasswordHasher {
+	return &bcryptHasher{cost: cost}
+}
+
+func (h *bcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+func (h *bcryptHasher) Compare(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
@@ -0,0 +1,49 @@
+package hash
+
+import (
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+	"testing"
+)
+
+func TestBcryptHasher(t *testing.T) {
+	h := NewBcryptHasher(bcrypt.MinCost)
+
+	first, err := h.Hash("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := h.Hash("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second {
+		t.Error("the hashes of the same password must differ by their salts")
+	}
+	if !strings.HasPrefix(first, "$2a$") {
+		t.Errorf("hash %q is not a bcrypt one", first)
+	}
+
+	for _, hash := range []string{first, second} {
+		if !h.Compare(hash, "secret") {
+			t.Errorf("%q does not match its password", hash)
+		}
+		if h.Compare(hash, "Secret") || h.Compare(hash, "") {
+			t.Errorf("%q matches another password", hash)
+		}
+	}
+	// a hash which is not a bcrypt one, e.g. of the former sha1 hasher, matches nothing
+	if h.Compare(NewSHA1Hasher("salt").Hash("secret"), "secret") {
+		t.Error("a sha1 hash must not match")
+	}
+}
+
+func TestBcryptHasherRefusesLongPasswords(t *testing.T) {
+	h := NewBcryptHasher(bcrypt.MinCost)
+	if _, err := h.Hash(strings.Repeat("a", MaxPasswordLength)); err != nil {
+		t.Errorf("hashing the longest password: %v", err)
+	}
+	if _, err := h.Hash(strings.Repeat("a", MaxPasswordLength+1)); err == nil {
+		t.Error("expected a longer password to be refused")
+	}
+}
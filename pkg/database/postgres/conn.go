@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"context"
+	"database/sql/driver"
+	"github.com/jmoiron/sqlx"
+)
+
+// Conn is a single connection taken from the pool, for the queries relying on the session, like advisory locks
+type Conn struct {
+	conn *sqlx.Conn
+}
+
+func (db *DB) Conn(ctx context.Context) (*Conn, error) {
+	conn, err := db.db.Connx(ctx)
+	if err != nil {
+		return nil, errAcquiringConn(err)
+	}
+	return &Conn{conn: conn}, nil
+}
+
+func (c *Conn) Get(ctx context.Context, dest any, query string, args ...any) error {
+	err := c.conn.GetContext(ctx, dest, query, args...)
+	if err != nil {
+		return errExecutingQuery(query, err)
+	}
+	return nil
+}
+
+func (c *Conn) Exec(ctx context.Context, query string, args ...any) error {
+	_, err := c.conn.ExecContext(ctx, query, args...)
+	if err != nil {
+		return errExecutingQuery(query, err)
+	}
+	return nil
+}
+
+// Close returns the connection to the pool
+func (c *Conn) Close() error {
+	if err := c.conn.Close(); err != nil {
+		return errReleasingConn(err)
+	}
+	return nil
+}
+
+// Discard closes the connection instead of returning it to the pool, for the sessions left in an unknown state
+func (c *Conn) Discard() {
+	_ = c.conn.Raw(func(any) error {
+		return driver.ErrBadConn
+	})
+}
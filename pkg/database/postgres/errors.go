@@ -39,6 +39,18 @@ func errClosing(err error) error {
 	return fmt.Errorf("%w (closing postgres connection)", err)
 }
 
+func errBeginningTx(err error) error {
+	return fmt.Errorf("%w (beginning transaction)", err)
+}
+
+func errCommittingTx(err error) error {
+	return fmt.Errorf("%w (committing transaction)", err)
+}
+
+func errRollingBackTx(err error) error {
+	return fmt.Errorf("%w (rolling back transaction)", err)
+}
+
 func errPreparingQuery(query string, err error) error {
 	return fmt.Errorf("%w (preparing '%s')", err, query)
 }
@@ -46,3 +58,15 @@ func errPreparingQuery(query string, err error) error {
 func errExecutingQuery(query string, err error) error {
 	return fmt.Errorf("%w (executing '%s')", err, query)
 }
+
+func errScanningRow(query string, err error) error {
+	return fmt.Errorf("%w (scanning row of '%s')", err, query)
+}
+
+func errAcquiringConn(err error) error {
+	return fmt.Errorf("%w (acquiring connection)", err)
+}
+
+func errReleasingConn(err error) error {
+	return fmt.Errorf("%w (releasing connection)", err)
+}
@@ -0,0 +1,12 @@
+package postgres
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// Collector reports the stats of the connection pool, e.g. the open, in use and idle connections
+// and how long the queries have waited for them, labelled with the name of the database
+func (db *DB) Collector(name string) prometheus.Collector {
+	return collectors.NewDBStatsCollector(db.db.DB, name)
+}
@@ -1,7 +1,8 @@
 package postgres
 
 import (
-	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/jmoiron/sqlx"
 	"time"
 )
@@ -19,11 +20,19 @@ type ConnOptions struct {
 	ConnMaxIdleTime time.Duration
 }
 
+// Connect connects to the database, tracing the queries with the global tracer provider
 func Connect(dataSourceName string, opts *ConnOptions) (*DB, error) {
-	sqlxDB, err := sqlx.Connect(driverName, dataSourceName)
+	connConfig, err := pgx.ParseConfig(dataSourceName)
 	if err != nil {
 		return nil, errConnecting(err)
 	}
+	connConfig.Tracer = queryTracer{}
+
+	sqlxDB := sqlx.NewDb(stdlib.OpenDB(*connConfig), driverName)
+	if err = sqlxDB.Ping(); err != nil {
+		_ = sqlxDB.Close()
+		return nil, errConnecting(err)
+	}
 	if opts != nil {
 		sqlxDB.SetMaxOpenConns(opts.MaxOpenConns)
 		sqlxDB.SetMaxIdleConns(opts.MaxIdleConns)
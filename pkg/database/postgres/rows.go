@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"context"
+	"github.com/jmoiron/sqlx"
+)
+
+// Rows iterates over a result set without loading it into memory
+type Rows struct {
+	rows  *sqlx.Rows
+	query string
+}
+
+func (db *DB) Query(ctx context.Context, query string, args ...any) (*Rows, error) {
+	rows, err := db.db.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, errExecutingQuery(query, err)
+	}
+	return &Rows{rows: rows, query: query}, nil
+}
+
+func (r *Rows) Next() bool {
+	return r.rows.Next()
+}
+
+// Scan scans the current row into a struct
+func (r *Rows) Scan(dest any) error {
+	if err := r.rows.StructScan(dest); err != nil {
+		return errScanningRow(r.query, err)
+	}
+	return nil
+}
+
+func (r *Rows) Err() error {
+	if err := r.rows.Err(); err != nil {
+		return errExecutingQuery(r.query, err)
+	}
+	return nil
+}
+
+func (r *Rows) Close() error {
+	return r.rows.Close()
+}
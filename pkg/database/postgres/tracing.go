@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"regexp"
+	"strings"
+)
+
+var tracer = otel.Tracer("github.com/adanyl0v/go-pocket-link/pkg/database/postgres")
+
+var (
+	// stringLiteral matches the quoted strings, the doubled quotes included
+	stringLiteral = regexp.MustCompile(`'(?:[^']|'')*'`)
+	// numberLiteral matches the numbers standing on their own, not the ones in the names or the $n parameters
+	numberLiteral = regexp.MustCompile(`(^|[^\w$.])-?\d+(?:\.\d+)?\b`)
+	whitespace    = regexp.MustCompile(`\s+`)
+)
+
+// SanitizeStatement replaces the literals of the statement with ?, so the spans do not carry the values
+// written into the queries, and collapses its whitespace. The parameters are kept as they are
+func SanitizeStatement(query string) string {
+	query = stringLiteral.ReplaceAllString(query, "?")
+	query = numberLiteral.ReplaceAllString(query, "${1}?")
+	return strings.TrimSpace(whitespace.ReplaceAllString(query, " "))
+}
+
+// queryTracer starts a span for each query and prepared statement, which is a child of the span
+// of the context the query is run with
+type queryTracer struct{}
+
+func (queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return startSpan(ctx, data.SQL)
+}
+
+func (queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.Int64("db.rows_affected", data.CommandTag.RowsAffected()))
+	endSpan(span, data.Err)
+}
+
+func (queryTracer) TracePrepareStart(ctx context.Context, _ *pgx.Conn, data pgx.TracePrepareStartData) context.Context {
+	return startSpan(ctx, data.SQL)
+}
+
+func (queryTracer) TracePrepareEnd(ctx context.Context, _ *pgx.Conn, data pgx.TracePrepareEndData) {
+	endSpan(trace.SpanFromContext(ctx), data.Err)
+}
+
+func startSpan(ctx context.Context, query string) context.Context {
+	operation := statementOperation(query)
+	ctx, _ = tracer.Start(ctx, operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationName(operation),
+			semconv.DBQueryText(SanitizeStatement(query)),
+		))
+	return ctx
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// statementOperation returns the first keyword of the statement, e.g. SELECT, naming its spans
+func statementOperation(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "QUERY"
+	}
+	return strings.ToUpper(fields[0])
+}
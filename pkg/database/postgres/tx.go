@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"context"
+	"github.com/jmoiron/sqlx"
+)
+
+type Tx struct {
+	tx *sqlx.Tx
+}
+
+func (db *DB) Begin(ctx context.Context) (*Tx, error) {
+	tx, err := db.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errBeginningTx(err)
+	}
+	return &Tx{tx: tx}, nil
+}
+
+// WithTx runs fn inside a transaction, committing it if fn succeeds
+// and rolling it back otherwise
+func (db *DB) WithTx(ctx context.Context, fn func(tx *Tx) error) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err = fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (tx *Tx) Commit() error {
+	if err := tx.tx.Commit(); err != nil {
+		return errCommittingTx(err)
+	}
+	return nil
+}
+
+func (tx *Tx) Rollback() error {
+	if err := tx.tx.Rollback(); err != nil {
+		return errRollingBackTx(err)
+	}
+	return nil
+}
+
+func (tx *Tx) Get(ctx context.Context, dest any, query string, args ...any) error {
+	err := tx.tx.GetContext(ctx, dest, query, args...)
+	if err != nil {
+		return errExecutingQuery(query, err)
+	}
+	return nil
+}
+
+func (tx *Tx) Select(ctx context.Context, dest any, query string, args ...any) error {
+	err := tx.tx.SelectContext(ctx, dest, query, args...)
+	if err != nil {
+		return errExecutingQuery(query, err)
+	}
+	return nil
+}
+
+func (tx *Tx) Exec(ctx context.Context, query string, args ...any) error {
+	_, err := tx.tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return errExecutingQuery(query, err)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package feed
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported feed format")
+	ErrGone              = errors.New("feed has been removed")
+)
+
+func errUnknownFormat(format Format) error {
+	return fmt.Errorf("unknown feed format %q", format)
+}
+
+func errWriting(format Format, err error) error {
+	return fmt.Errorf("%w (writing %s feed)", err, format)
+}
+
+func errParsing(format Format, err error) error {
+	return fmt.Errorf("%w (parsing %s feed)", err, format)
+}
+
+func errFetching(url string, err error) error {
+	return fmt.Errorf("%w (fetching feed from %s)", err, url)
+}
+
+func errUnexpectedStatus(code int) error {
+	return fmt.Errorf("feed server responded with status %d", code)
+}
+
+func errTooLarge(limit int) error {
+	return fmt.Errorf("feed is larger than %d bytes", limit)
+}
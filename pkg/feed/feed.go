@@ -0,0 +1,85 @@
+package feed
+
+import (
+	"io"
+	"time"
+)
+
+type Format string
+
+const (
+	FormatAtom Format = "atom"
+	FormatRSS  Format = "rss"
+	FormatJSON Format = "json"
+)
+
+var Formats = []Format{FormatAtom, FormatRSS, FormatJSON}
+
+func (f Format) ContentType() string {
+	switch f {
+	case FormatAtom:
+		return "application/atom+xml; charset=utf-8"
+	case FormatRSS:
+		return "application/rss+xml; charset=utf-8"
+	case FormatJSON:
+		return "application/feed+json; charset=utf-8"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// mediaType is the ContentType without the charset, as the feeds link to themselves
+func (f Format) mediaType() string {
+	switch f {
+	case FormatAtom:
+		return "application/atom+xml"
+	case FormatRSS:
+		return "application/rss+xml"
+	default:
+		return "application/feed+json"
+	}
+}
+
+// Feed is written the same way in each of the formats. ID must be a permanent unique URI
+type Feed struct {
+	ID          string
+	Title       string
+	Description string
+	Author      string
+	// HomeURL is the page the feed is about, FeedURL is where the feed itself is served
+	HomeURL string
+	FeedURL string
+	Updated time.Time
+	Items   []Item
+}
+
+type Item struct {
+	ID        string
+	URL       string
+	Title     string
+	Summary   string
+	Tags      []string
+	Published time.Time
+	Updated   time.Time
+}
+
+func Write(w io.Writer, format Format, feed Feed) error {
+	switch format {
+	case FormatAtom:
+		return WriteAtom(w, feed)
+	case FormatRSS:
+		return WriteRSS(w, feed)
+	case FormatJSON:
+		return WriteJSON(w, feed)
+	default:
+		return errUnknownFormat(format)
+	}
+}
+
+// title falls back to the url, since the readers show the items by their titles
+func (i Item) title() string {
+	if i.Title == "" {
+		return i.URL
+	}
+	return i.Title
+}
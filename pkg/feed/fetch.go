@@ -0,0 +1,139 @@
+package feed
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// maxFeedSize is how much of the feed is read, the larger ones are refused
+const maxFeedSize = 5 << 20
+
+const acceptedTypes = "application/feed+json, application/atom+xml, application/rss+xml, " +
+	"application/json;q=0.9, application/xml;q=0.9, text/xml;q=0.9, */*;q=0.8"
+
+// Request is a conditional request if ETag or LastModified of the previous response is set
+type Request struct {
+	URL          string
+	ETag         string
+	LastModified string
+}
+
+type Response struct {
+	Feed Feed
+	// NotModified is set if the feed has not changed since the previous response, Feed is empty then
+	NotModified  bool
+	ETag         string
+	LastModified string
+	// RetryAfter is how long the server asks to wait before the next request, 0 if it does not ask
+	RetryAfter time.Duration
+}
+
+// Fetcher downloads the feeds. The http.Client is injectable, so the feeds
+// can be served by httptest servers
+type Fetcher struct {
+	http *http.Client
+}
+
+func NewFetcher(httpClient *http.Client) *Fetcher {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 15 * time.Second}
+	}
+	return &Fetcher{http: httpClient}
+}
+
+// Fetch downloads and parses the feed. The links of the items are resolved against the url
+// the feed has been served from. ErrGone is returned if the feed has been removed for good
+func (f *Fetcher) Fetch(ctx context.Context, req Request) (Response, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, req.URL, nil)
+	if err != nil {
+		return Response{}, errFetching(req.URL, err)
+	}
+	httpReq.Header.Set("Accept", acceptedTypes)
+	httpReq.Header.Set("User-Agent", "PocketLink-Feeds/1.0")
+	if req.ETag != "" {
+		httpReq.Header.Set("If-None-Match", req.ETag)
+	}
+	if req.LastModified != "" {
+		httpReq.Header.Set("If-Modified-Since", req.LastModified)
+	}
+
+	httpResp, err := f.http.Do(httpReq)
+	if err != nil {
+		return Response{}, errFetching(req.URL, err)
+	}
+	defer func() { _ = httpResp.Body.Close() }()
+
+	resp := Response{
+		ETag:         httpResp.Header.Get("ETag"),
+		LastModified: httpResp.Header.Get("Last-Modified"),
+		RetryAfter:   parseRetryAfter(httpResp.Header.Get("Retry-After")),
+	}
+	switch {
+	case httpResp.StatusCode == http.StatusNotModified:
+		// the validators are not always sent along with 304, the previous ones stay valid then
+		if resp.ETag == "" {
+			resp.ETag = req.ETag
+		}
+		if resp.LastModified == "" {
+			resp.LastModified = req.LastModified
+		}
+		resp.NotModified = true
+		return resp, nil
+	case httpResp.StatusCode == http.StatusGone:
+		return resp, ErrGone
+	case httpResp.StatusCode < 200 || httpResp.StatusCode > 299:
+		return resp, errUnexpectedStatus(httpResp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(httpResp.Body, maxFeedSize+1))
+	if err != nil {
+		return resp, errFetching(req.URL, err)
+	} else if len(body) > maxFeedSize {
+		return resp, errTooLarge(maxFeedSize)
+	}
+
+	if resp.Feed, err = Parse(body); err != nil {
+		return resp, err
+	}
+	resolveURLs(&resp.Feed, httpResp.Request.URL)
+	return resp, nil
+}
+
+func resolveURLs(feed *Feed, base *url.URL) {
+	resolve := func(ref string) string {
+		if ref == "" {
+			return ""
+		}
+		u, err := base.Parse(ref)
+		if err != nil {
+			return ref
+		}
+		return u.String()
+	}
+
+	feed.HomeURL = resolve(feed.HomeURL)
+	if feed.FeedURL == "" {
+		feed.FeedURL = base.String()
+	}
+	for i := range feed.Items {
+		feed.Items[i].URL = resolve(feed.Items[i].URL)
+	}
+}
+
+// parseRetryAfter reads the header either as a number of seconds or as a date
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(time.Until(t), 0)
+	}
+	return 0
+}
@@ -0,0 +1,204 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetchParsesAndResolvesLinks(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/blog/rss", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") != "PocketLink-Feeds/1.0" || !strings.Contains(r.Header.Get("Accept"), "application/rss+xml") {
+			t.Errorf("user agent %q, accept %q", r.Header.Get("User-Agent"), r.Header.Get("Accept"))
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		_, _ = w.Write([]byte(testRSS))
+	})
+	// the links of the items are resolved against the url the feed has been redirected to
+	mux.Handle("/feed", http.RedirectHandler("/blog/rss", http.StatusMovedPermanently))
+	mux.HandleFunc("/atom", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(testAtom))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	fetcher := NewFetcher(server.Client())
+
+	resp, err := fetcher.Fetch(context.Background(), Request{URL: server.URL + "/feed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.NotModified || len(resp.Feed.Items) != 2 {
+		t.Fatalf("response = %+v", resp)
+	}
+	if got := resp.Feed.Items[0].URL; got != server.URL+"/posts/1" {
+		t.Errorf("item url %q, want it resolved", got)
+	}
+	if resp.Feed.FeedURL != server.URL+"/blog/rss" {
+		t.Errorf("feed url %q, want the final url", resp.Feed.FeedURL)
+	}
+
+	resp, err = fetcher.Fetch(context.Background(), Request{URL: server.URL + "/atom"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.Feed.Items[0].URL; got != server.URL+"/entries/1" {
+		t.Errorf("entry url %q, want it resolved", got)
+	}
+	// the feed url the atom feed declares is kept
+	if resp.Feed.FeedURL != "https://example.com/feed.atom" {
+		t.Errorf("feed url %q", resp.Feed.FeedURL)
+	}
+}
+
+func TestFetchConditional(t *testing.T) {
+	const (
+		etag         = `"v1"`
+		lastModified = "Tue, 05 Nov 2024 10:00:00 GMT"
+	)
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == etag || r.Header.Get("If-Modified-Since") == lastModified {
+			// the validators are not sent along with this 304
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", lastModified)
+		_, _ = w.Write([]byte(testAtom))
+	}))
+	defer server.Close()
+
+	fetcher := NewFetcher(server.Client())
+	first, err := fetcher.Fetch(context.Background(), Request{URL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.NotModified || first.ETag != etag || first.LastModified != lastModified {
+		t.Fatalf("first response = %+v", first)
+	}
+
+	tests := []struct {
+		name string
+		req  Request
+	}{
+		{"etag", Request{URL: server.URL, ETag: first.ETag}},
+		{"last modified", Request{URL: server.URL, LastModified: first.LastModified}},
+		{"both", Request{URL: server.URL, ETag: first.ETag, LastModified: first.LastModified}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := fetcher.Fetch(context.Background(), tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !resp.NotModified || len(resp.Feed.Items) != 0 {
+				t.Errorf("response = %+v, want not modified", resp)
+			}
+			// the previous validators stay valid
+			if resp.ETag != tt.req.ETag || resp.LastModified != tt.req.LastModified {
+				t.Errorf("etag %q, last modified %q", resp.ETag, resp.LastModified)
+			}
+		})
+	}
+	if requests != 1+len(tests) {
+		t.Errorf("%d requests", requests)
+	}
+}
+
+func TestFetchFailures(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		wantErr error
+		check   func(t *testing.T, resp Response, err error)
+	}{
+		{
+			name: "gone",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusGone)
+			},
+			wantErr: ErrGone,
+		},
+		{
+			name: "server error with retry after",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", "120")
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+			check: func(t *testing.T, resp Response, err error) {
+				if err == nil || !strings.Contains(err.Error(), "503") {
+					t.Errorf("error %v, want the status", err)
+				}
+				if resp.RetryAfter != 2*time.Minute {
+					t.Errorf("retry after %s", resp.RetryAfter)
+				}
+			},
+		},
+		{
+			name: "not a feed",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte("<html><body>moved to a new site</body></html>"))
+			},
+			wantErr: ErrUnsupportedFormat,
+		},
+		{
+			name: "malformed feed",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(testRSS[:len(testRSS)/2]))
+			},
+			check: func(t *testing.T, resp Response, err error) {
+				if err == nil || errors.Is(err, ErrUnsupportedFormat) {
+					t.Errorf("error %v, want a parsing one", err)
+				}
+			},
+		},
+		{
+			name: "too large",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte("<rss><channel><description>"))
+				_, _ = w.Write([]byte(strings.Repeat("x", maxFeedSize)))
+			},
+			check: func(t *testing.T, resp Response, err error) {
+				if err == nil || !strings.Contains(err.Error(), "larger than") {
+					t.Errorf("error %v, want the size limit", err)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			resp, err := NewFetcher(server.Client()).Fetch(context.Background(), Request{URL: server.URL})
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("error %v, want %v", err, tt.wantErr)
+			}
+			if tt.check != nil {
+				tt.check(t, resp, err)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if got := parseRetryAfter("30"); got != 30*time.Second {
+		t.Errorf("seconds = %s", got)
+	}
+	if got := parseRetryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)); got < 59*time.Minute || got > time.Hour {
+		t.Errorf("date = %s", got)
+	}
+	for _, value := range []string{"", "-5", "soon", "Tue, 05 Nov 2024 10:00:00 GMT"} {
+		if got := parseRetryAfter(value); got != 0 {
+			t.Errorf("parseRetryAfter(%q) = %s, want 0", value, got)
+		}
+	}
+}
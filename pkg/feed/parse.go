@@ -0,0 +1,309 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"golang.org/x/net/html/charset"
+	"io"
+	"strings"
+	"time"
+)
+
+const dublinCoreNamespace = "http://purl.org/dc/elements/1.1/"
+
+// dateLayouts are the ones met in the wild, the RSS dates rarely following RFC 822 exactly
+var dateLayouts = []string{
+	time.RFC3339,
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"Mon, 2 Jan 2006 15:04 -0700",
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 MST",
+	time.RFC822Z,
+	time.RFC822,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+type parsedRSS struct {
+	Channel struct {
+		Title       string          `xml:"title"`
+		Link        string          `xml:"link"`
+		Description string          `xml:"description"`
+		PubDate     string          `xml:"pubDate"`
+		Items       []parsedRSSItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+// parsedRDF is RSS 1.0, the items of which are next to the channel
+type parsedRDF struct {
+	Channel struct {
+		Title       string `xml:"title"`
+		Link        string `xml:"link"`
+		Description string `xml:"description"`
+	} `xml:"channel"`
+	Items []parsedRSSItem `xml:"item"`
+}
+
+type parsedRSSItem struct {
+	GUID        string   `xml:"guid"`
+	About       string   `xml:"about,attr"`
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Description string   `xml:"description"`
+	PubDate     string   `xml:"pubDate"`
+	Date        string   `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Categories  []string `xml:"category"`
+	Subjects    []string `xml:"http://purl.org/dc/elements/1.1/ subject"`
+}
+
+type parsedAtom struct {
+	ID       string            `xml:"id"`
+	Title    string            `xml:"title"`
+	Subtitle string            `xml:"subtitle"`
+	Updated  string            `xml:"updated"`
+	Links    []atomLink        `xml:"link"`
+	Author   atomAuthor        `xml:"author"`
+	Entries  []parsedAtomEntry `xml:"entry"`
+}
+
+type parsedAtomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Links      []atomLink     `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Summary    string         `xml:"summary"`
+	Categories []atomCategory `xml:"category"`
+}
+
+type parsedJSONFeed struct {
+	Version     string               `json:"version"`
+	Title       string               `json:"title"`
+	HomePageURL string               `json:"home_page_url"`
+	FeedURL     string               `json:"feed_url"`
+	Description string               `json:"description"`
+	Items       []parsedJSONFeedItem `json:"items"`
+}
+
+type parsedJSONFeedItem struct {
+	// ID is a string, though some feeds put numbers in it
+	ID            any      `json:"id"`
+	URL           string   `json:"url"`
+	ExternalURL   string   `json:"external_url"`
+	Title         string   `json:"title"`
+	Summary       string   `json:"summary"`
+	ContentText   string   `json:"content_text"`
+	DatePublished string   `json:"date_published"`
+	DateModified  string   `json:"date_modified"`
+	Tags          []string `json:"tags"`
+}
+
+// Parse reads the feed in any of the formats, detecting which one it is by its content.
+// RSS 2.0 and 1.0, Atom 1.0 and JSON Feed 1.0 and 1.1 are supported
+func Parse(data []byte) (Feed, error) {
+	data = bytes.TrimPrefix(bytes.TrimSpace(data), []byte("\xef\xbb\xbf"))
+	if len(data) == 0 {
+		return Feed{}, ErrUnsupportedFormat
+	}
+	if data[0] == '{' {
+		return parseJSON(data)
+	}
+	return parseXML(data)
+}
+
+func parseJSON(data []byte) (Feed, error) {
+	var in parsedJSONFeed
+	if err := json.Unmarshal(data, &in); err != nil {
+		return Feed{}, errParsing(FormatJSON, err)
+	}
+	if !strings.HasPrefix(in.Version, "https://jsonfeed.org/version/") {
+		return Feed{}, ErrUnsupportedFormat
+	}
+
+	out := Feed{
+		ID:          in.FeedURL,
+		Title:       in.Title,
+		Description: in.Description,
+		HomeURL:     in.HomePageURL,
+		FeedURL:     in.FeedURL,
+		Items:       make([]Item, 0, len(in.Items)),
+	}
+	for _, item := range in.Items {
+		parsed := Item{
+			URL:       item.URL,
+			Title:     item.Title,
+			Summary:   item.Summary,
+			Tags:      item.Tags,
+			Published: parseDate(item.DatePublished),
+			Updated:   parseDate(item.DateModified),
+		}
+		if item.ID != nil {
+			parsed.ID = fmt.Sprint(item.ID)
+		}
+		if parsed.URL == "" {
+			parsed.URL = item.ExternalURL
+		}
+		if parsed.Summary == "" {
+			parsed.Summary = item.ContentText
+		}
+		out.Items = append(out.Items, parsed.normalize())
+	}
+	return out, nil
+}
+
+func parseXML(data []byte) (Feed, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = charset.NewReaderLabel
+	decoder.Strict = false
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return Feed{}, ErrUnsupportedFormat
+		} else if err != nil {
+			return Feed{}, errParsing("xml", err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "rss":
+			var in parsedRSS
+			if err = decoder.DecodeElement(&in, &start); err != nil {
+				return Feed{}, errParsing(FormatRSS, err)
+			}
+			out := Feed{
+				ID:          in.Channel.Link,
+				Title:       in.Channel.Title,
+				Description: in.Channel.Description,
+				HomeURL:     in.Channel.Link,
+				Updated:     parseDate(in.Channel.PubDate),
+			}
+			out.Items = rssItems(in.Channel.Items)
+			return out, nil
+		case "RDF":
+			var in parsedRDF
+			if err = decoder.DecodeElement(&in, &start); err != nil {
+				return Feed{}, errParsing(FormatRSS, err)
+			}
+			return Feed{
+				ID:          in.Channel.Link,
+				Title:       in.Channel.Title,
+				Description: in.Channel.Description,
+				HomeURL:     in.Channel.Link,
+				Items:       rssItems(in.Items),
+			}, nil
+		case "feed":
+			var in parsedAtom
+			if err = decoder.DecodeElement(&in, &start); err != nil {
+				return Feed{}, errParsing(FormatAtom, err)
+			}
+			return atomToFeed(in), nil
+		default:
+			return Feed{}, ErrUnsupportedFormat
+		}
+	}
+}
+
+func rssItems(items []parsedRSSItem) []Item {
+	out := make([]Item, 0, len(items))
+	for _, item := range items {
+		parsed := Item{
+			ID:        item.GUID,
+			URL:       strings.TrimSpace(item.Link),
+			Title:     item.Title,
+			Summary:   item.Description,
+			Tags:      append(item.Categories, item.Subjects...),
+			Published: parseDate(item.PubDate),
+		}
+		if parsed.ID == "" {
+			parsed.ID = item.About
+		}
+		if parsed.Published.IsZero() {
+			parsed.Published = parseDate(item.Date)
+		}
+		// the guids of the items without links are usually their permalinks
+		if parsed.URL == "" && strings.HasPrefix(parsed.ID, "http") {
+			parsed.URL = parsed.ID
+		}
+		out = append(out, parsed.normalize())
+	}
+	return out
+}
+
+func atomToFeed(in parsedAtom) Feed {
+	out := Feed{
+		ID:          in.ID,
+		Title:       in.Title,
+		Description: in.Subtitle,
+		Author:      in.Author.Name,
+		HomeURL:     atomLinkHref(in.Links, "alternate"),
+		FeedURL:     atomLinkHref(in.Links, "self"),
+		Updated:     parseDate(in.Updated),
+		Items:       make([]Item, 0, len(in.Entries)),
+	}
+	for _, entry := range in.Entries {
+		parsed := Item{
+			ID:        entry.ID,
+			URL:       atomLinkHref(entry.Links, "alternate"),
+			Title:     entry.Title,
+			Summary:   entry.Summary,
+			Published: parseDate(entry.Published),
+			Updated:   parseDate(entry.Updated),
+		}
+		for _, category := range entry.Categories {
+			parsed.Tags = append(parsed.Tags, category.Term)
+		}
+		out.Items = append(out.Items, parsed.normalize())
+	}
+	return out
+}
+
+// atomLinkHref returns the link with the relation, the links without one being alternate
+func atomLinkHref(links []atomLink, rel string) string {
+	for _, link := range links {
+		if link.Rel == rel || (link.Rel == "" && rel == "alternate") {
+			return strings.TrimSpace(link.Href)
+		}
+	}
+	return ""
+}
+
+// normalize fills the dates and the id the item lacks from the ones it has
+func (i Item) normalize() Item {
+	i.Title = strings.TrimSpace(i.Title)
+	if i.Published.IsZero() {
+		i.Published = i.Updated
+	}
+	if i.Updated.IsZero() {
+		i.Updated = i.Published
+	}
+	if i.ID == "" {
+		i.ID = i.URL
+	}
+	i.ID = strings.TrimSpace(i.ID)
+	return i
+}
+
+// parseDate returns the zero time for the dates it cannot parse, since a missing date is not worth skipping the item
+func parseDate(value string) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
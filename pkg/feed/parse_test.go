@@ -0,0 +1,228 @@
+package feed
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testRSS = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel>
+    <title>Example blog</title>
+    <link>https://example.com/</link>
+    <description>Posts of the example</description>
+    <pubDate>Tue, 05 Nov 2024 10:00:00 GMT</pubDate>
+    <item>
+      <title> First post </title>
+      <link>/posts/1</link>
+      <guid>post-1</guid>
+      <description>The first one</description>
+      <pubDate>Mon, 4 Nov 2024 09:30:00 +0100</pubDate>
+      <category>go</category>
+      <dc:subject>feeds</dc:subject>
+    </item>
+    <item>
+      <guid>https://example.com/posts/2</guid>
+      <dc:date>2024-11-05T08:00:00Z</dc:date>
+    </item>
+  </channel>
+</rss>`
+
+const testAtom = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <id>urn:uuid:60a76c80-d399-11d9-b93c-0003939e0af6</id>
+  <title>Example feed</title>
+  <subtitle>Entries of the example</subtitle>
+  <updated>2024-11-05T10:00:00Z</updated>
+  <author><name>John Doe</name></author>
+  <link rel="self" href="https://example.com/feed.atom"/>
+  <link href="https://example.com/"/>
+  <entry>
+    <id>urn:uuid:1225c695-cfb8-4ebb-aaaa-80da344efa6a</id>
+    <title>Atom entry</title>
+    <link rel="alternate" href="entries/1"/>
+    <link rel="enclosure" href="https://example.com/audio.mp3"/>
+    <updated>2024-11-05T09:00:00Z</updated>
+    <summary>Some text</summary>
+    <category term="go"/>
+  </entry>
+</feed>`
+
+func TestParseRSS(t *testing.T) {
+	feed, err := Parse([]byte(testRSS))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feed.Title != "Example blog" || feed.HomeURL != "https://example.com/" || feed.Description != "Posts of the example" {
+		t.Errorf("feed = %+v", feed)
+	}
+	if !feed.Updated.Equal(time.Date(2024, 11, 5, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("updated %s", feed.Updated)
+	}
+	if len(feed.Items) != 2 {
+		t.Fatalf("%d items, want 2", len(feed.Items))
+	}
+
+	first := feed.Items[0]
+	if first.ID != "post-1" || first.URL != "/posts/1" || first.Title != "First post" || first.Summary != "The first one" {
+		t.Errorf("first item = %+v", first)
+	}
+	if strings.Join(first.Tags, ",") != "go,feeds" {
+		t.Errorf("tags %v, want the categories and the dc subjects", first.Tags)
+	}
+	if !first.Published.Equal(time.Date(2024, 11, 4, 8, 30, 0, 0, time.UTC)) || !first.Updated.Equal(first.Published) {
+		t.Errorf("published %s, updated %s", first.Published, first.Updated)
+	}
+
+	// the item without a link is linked by its permalink guid and dated by dc:date
+	second := feed.Items[1]
+	if second.URL != "https://example.com/posts/2" || !second.Published.Equal(time.Date(2024, 11, 5, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("second item = %+v", second)
+	}
+}
+
+func TestParseRDF(t *testing.T) {
+	feed, err := Parse([]byte(`<?xml version="1.0"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/">
+  <channel rdf:about="https://example.com/"><title>RDF feed</title><link>https://example.com/</link></channel>
+  <item rdf:about="https://example.com/a"><title>A</title><link>https://example.com/a</link></item>
+</rdf:RDF>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feed.Title != "RDF feed" || len(feed.Items) != 1 || feed.Items[0].ID != "https://example.com/a" {
+		t.Errorf("feed = %+v", feed)
+	}
+}
+
+func TestParseAtom(t *testing.T) {
+	// the byte order mark and the leading space are skipped
+	feed, err := Parse([]byte("\xef\xbb\xbf\n" + testAtom))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feed.Title != "Example feed" || feed.Author != "John Doe" || feed.Description != "Entries of the example" {
+		t.Errorf("feed = %+v", feed)
+	}
+	if feed.HomeURL != "https://example.com/" || feed.FeedURL != "https://example.com/feed.atom" {
+		t.Errorf("home %q, feed %q", feed.HomeURL, feed.FeedURL)
+	}
+	if len(feed.Items) != 1 {
+		t.Fatalf("%d items, want 1", len(feed.Items))
+	}
+
+	entry := feed.Items[0]
+	if entry.URL != "entries/1" || entry.Summary != "Some text" || strings.Join(entry.Tags, ",") != "go" {
+		t.Errorf("entry = %+v", entry)
+	}
+	// the entry without published is dated by updated
+	if !entry.Published.Equal(time.Date(2024, 11, 5, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("published %s", entry.Published)
+	}
+}
+
+func TestParseJSONFeed(t *testing.T) {
+	feed, err := Parse([]byte(`{
+		"version": "https://jsonfeed.org/version/1.1",
+		"title": "JSON feed",
+		"feed_url": "https://example.com/feed.json",
+		"items": [{"id": 42, "external_url": "https://example.com/a", "content_text": "text"}]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feed.Title != "JSON feed" || len(feed.Items) != 1 {
+		t.Fatalf("feed = %+v", feed)
+	}
+	if item := feed.Items[0]; item.ID != "42" || item.URL != "https://example.com/a" || item.Summary != "text" {
+		t.Errorf("item = %+v", item)
+	}
+}
+
+func TestParseWrittenFeeds(t *testing.T) {
+	written := Feed{
+		ID:      "https://pocket.link/feeds/1",
+		Title:   "Reading list",
+		HomeURL: "https://pocket.link/",
+		FeedURL: "https://pocket.link/feeds/1",
+		Updated: time.Date(2024, 11, 5, 10, 0, 0, 0, time.UTC),
+		Items: []Item{{
+			ID:        "https://example.com/a",
+			URL:       "https://example.com/a",
+			Title:     "A",
+			Published: time.Date(2024, 11, 5, 9, 0, 0, 0, time.UTC),
+		}},
+	}
+
+	for _, format := range Formats {
+		t.Run(string(format), func(t *testing.T) {
+			var b strings.Builder
+			if err := Write(&b, format, written); err != nil {
+				t.Fatal(err)
+			}
+			feed, err := Parse([]byte(b.String()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if feed.Title != written.Title || len(feed.Items) != 1 || feed.Items[0].URL != "https://example.com/a" {
+				t.Errorf("parsed %+v", feed)
+			}
+			if !feed.Items[0].Published.Equal(written.Items[0].Published) {
+				t.Errorf("published %s", feed.Items[0].Published)
+			}
+		})
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		unsupported bool
+	}{
+		{"empty", "", true},
+		{"blank", " \n\t", true},
+		{"html", "<!DOCTYPE html><html><body>not a feed</body></html>", true},
+		{"other xml", `<?xml version="1.0"?><sitemap></sitemap>`, true},
+		{"plain text", "not a feed", true},
+		{"json without version", `{"title": "x", "items": []}`, true},
+		{"truncated json", `{"version": "https://jsonfeed.org/version/1", "items": [`, false},
+		{"truncated rss", `<rss><channel><title>x</title><item><title>y`, false},
+		{"truncated atom", `<feed xmlns="http://www.w3.org/2005/Atom"><entry><id>1</id>`, false},
+		{"unknown charset", `<?xml version="1.0" encoding="x-unknown"?><rss></rss>`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if errors.Is(err, ErrUnsupportedFormat) != tt.unsupported {
+				t.Errorf("error %v, unsupported format = %v", err, tt.unsupported)
+			}
+		})
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	want := time.Date(2024, 11, 5, 9, 30, 0, 0, time.UTC)
+	for _, value := range []string{
+		"2024-11-05T09:30:00Z",
+		"2024-11-05T10:30:00+01:00",
+		"Tue, 05 Nov 2024 09:30:00 +0000",
+		"Tue, 5 Nov 2024 09:30:00 GMT",
+		"Tue, 5 Nov 2024 09:30 +0000",
+		"5 Nov 2024 09:30:00 +0000",
+		"05 Nov 24 09:30 +0000",
+		" 2024-11-05 09:30:00 ",
+	} {
+		if got := parseDate(value); !got.Equal(want) {
+			t.Errorf("parseDate(%q) = %s, want %s", value, got, want)
+		}
+	}
+	if got := parseDate("yesterday"); !got.IsZero() {
+		t.Errorf("parseDate of an unknown layout = %s, want zero", got)
+	}
+}
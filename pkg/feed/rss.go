@@ -0,0 +1,72 @@
+package feed
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+type rssFeed struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	AtomXMLNS string     `xml:"xmlns:atom,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	AtomLink      atomLink  `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Description string   `xml:"description,omitempty"`
+	Categories  []string `xml:"category"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+// WriteRSS writes the feed as RSS 2.0, linking to itself the way Atom does
+func WriteRSS(w io.Writer, feed Feed) error {
+	description := feed.Description
+	if description == "" {
+		// the channel description is required
+		description = feed.Title
+	}
+
+	out := rssFeed{
+		Version:   "2.0",
+		AtomXMLNS: atomNamespace,
+		Channel: rssChannel{
+			Title:         feed.Title,
+			Link:          feed.HomeURL,
+			Description:   description,
+			LastBuildDate: feed.Updated.UTC().Format(time.RFC1123Z),
+			AtomLink:      atomLink{Href: feed.FeedURL, Rel: "self", Type: FormatRSS.mediaType()},
+			Items:         make([]rssItem, 0, len(feed.Items)),
+		},
+	}
+
+	for _, item := range feed.Items {
+		out.Channel.Items = append(out.Channel.Items, rssItem{
+			Title:       item.title(),
+			Link:        item.URL,
+			GUID:        rssGUID{Value: item.ID},
+			PubDate:     item.Published.UTC().Format(time.RFC1123Z),
+			Description: item.Summary,
+			Categories:  item.Tags,
+		})
+	}
+
+	return writeXML(w, FormatRSS, out)
+}
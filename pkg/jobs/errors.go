@@ -0,0 +1,53 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrDuplicate = errors.New("unique job is already queued")
+	// ErrLeaseLost is returned for the attempts whose lease is over, the job having been claimed again
+	ErrLeaseLost = errors.New("job lease has been lost")
+)
+
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks the error of a handler as one that retrying would not fix, so the job fails right away
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err: err}
+}
+
+func IsPermanent(err error) bool {
+	var permanent permanentError
+	return errors.As(err, &permanent)
+}
+
+func errEncoding(kind string, err error) error {
+	return fmt.Errorf("%w (encoding payload of %s job)", err, kind)
+}
+
+func errDecoding(kind string, err error) error {
+	return fmt.Errorf("%w (decoding payload of %s job)", err, kind)
+}
+
+func errPanicked(kind string, v any) error {
+	return fmt.Errorf("%s job panicked: %v", kind, v)
+}
+
+func errNoAttemptsLeft(attempts int) error {
+	return fmt.Errorf("job has run out of %d attempts", attempts)
+}
@@ -0,0 +1,98 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// DefaultMaxAttempts is how many times a job is attempted unless MaxAttempts is given
+const DefaultMaxAttempts = 5
+
+// Job is a unit of work run by the handler registered for its kind
+type Job struct {
+	ID      string `db:"id"`
+	Kind    string `db:"kind"`
+	Payload []byte `db:"payload"`
+	// Attempt is the number of the running attempt, starting from 1
+	Attempt     int       `db:"attempt"`
+	MaxAttempts int       `db:"max_attempts"`
+	RunAt       time.Time `db:"run_at"`
+	// UniqueKey is set for the unique jobs, only one of which can be queued or running at a time
+	UniqueKey string `db:"unique_key"`
+	LastError string `db:"last_error"`
+	// RequestID is the id of the request the job has been queued while serving, so its logs can be told by it
+	RequestID string    `db:"request_id"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// Decode unmarshals the JSON payload of the job
+func (j Job) Decode(v any) error {
+	if err := json.Unmarshal(j.Payload, v); err != nil {
+		return Permanent(errDecoding(j.Kind, err))
+	}
+	return nil
+}
+
+// Queue stores the jobs until they are completed or run out of attempts. The jobs claimed by a worker
+// are leased to it, so they are not run concurrently and are claimed again if the worker dies
+type Queue interface {
+	// Enqueue queues the job, returning its id. ErrDuplicate is returned if the job is unique
+	// and a job with the same key is already queued or running
+	Enqueue(ctx context.Context, kind string, payload []byte, opts ...Option) (string, error)
+	// Claim leases up to limit due jobs of the kinds for the time of lease, starting their next attempts
+	Claim(ctx context.Context, kinds []string, limit int, lease time.Duration) ([]Job, error)
+	// Extend prolongs the lease of the running attempt of the job
+	Extend(ctx context.Context, job Job, lease time.Duration) error
+	// Complete removes the job once its attempt has succeeded
+	Complete(ctx context.Context, job Job) error
+	// Retry releases the job to be attempted again at runAt
+	Retry(ctx context.Context, job Job, runAt time.Time, cause error) error
+	// Fail marks the job as dead, it is not attempted again
+	Fail(ctx context.Context, job Job, cause error) error
+}
+
+// EnqueueJSON queues the job with the value marshaled to JSON as its payload
+func EnqueueJSON(ctx context.Context, queue Queue, kind string, v any, opts ...Option) (string, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return "", errEncoding(kind, err)
+	}
+	return queue.Enqueue(ctx, kind, payload, opts...)
+}
+
+type Options struct {
+	RunAt       time.Time
+	MaxAttempts int
+	UniqueKey   string
+}
+
+type Option func(*Options)
+
+// NewOptions applies the options to the defaults, the job running right away
+func NewOptions(opts ...Option) Options {
+	o := Options{RunAt: time.Now(), MaxAttempts: DefaultMaxAttempts}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// At delays the job until the time
+func At(t time.Time) Option {
+	return func(o *Options) { o.RunAt = t }
+}
+
+// Delay delays the job for the duration
+func Delay(d time.Duration) Option {
+	return func(o *Options) { o.RunAt = time.Now().Add(d) }
+}
+
+func MaxAttempts(n int) Option {
+	return func(o *Options) { o.MaxAttempts = max(n, 1) }
+}
+
+// Unique makes the job unique by the key
+func Unique(key string) Option {
+	return func(o *Options) { o.UniqueKey = key }
+}
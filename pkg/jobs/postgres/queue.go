@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/adanyl0v/go-pocket-link/pkg/database/postgres"
+	"github.com/adanyl0v/go-pocket-link/pkg/jobs"
+	"github.com/adanyl0v/go-pocket-link/pkg/logctx"
+	"time"
+)
+
+const jobColumns = `j.id, j.kind, j.payload, j.attempt, j.max_attempts, j.run_at, j.unique_key, j.last_error, j.request_id, j.created_at`
+
+// Queue stores the jobs in the jobs table. The jobs are claimed with SELECT ... FOR UPDATE SKIP LOCKED,
+// so any number of workers can claim them concurrently without waiting for each other
+type Queue struct {
+	db *postgres.DB
+}
+
+func NewQueue(db *postgres.DB) *Queue {
+	return &Queue{db: db}
+}
+
+func (q *Queue) Enqueue(ctx context.Context, kind string, payload []byte, opts ...jobs.Option) (string, error) {
+	o := jobs.NewOptions(opts...)
+
+	var id string
+	err := q.db.Get(ctx, &id, `INSERT INTO jobs(kind, payload, max_attempts, run_at, unique_key, request_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (unique_key) WHERE unique_key <> '' AND status <> 'dead' DO NOTHING
+		RETURNING id`, kind, payload, o.MaxAttempts, o.RunAt, o.UniqueKey, logctx.RequestID(ctx))
+	if errors.Is(err, postgres.ErrNoRowsInResultSet) {
+		return "", jobs.ErrDuplicate
+	} else if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// Claim claims the running jobs whose lease is over along with the pending ones,
+// since their workers must have died
+func (q *Queue) Claim(ctx context.Context, kinds []string, limit int, lease time.Duration) ([]jobs.Job, error) {
+	claimed := make([]jobs.Job, 0, limit)
+	err := q.db.Select(ctx, &claimed, `UPDATE jobs j
+		SET status = 'running', attempt = j.attempt + 1, run_at = now() + make_interval(secs => $1), updated_at = now()
+		FROM (SELECT id FROM jobs
+			WHERE kind = ANY($2::text[]) AND status <> 'dead' AND run_at <= now()
+			ORDER BY run_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED) due
+		WHERE j.id = due.id
+		RETURNING `+jobColumns, lease.Seconds(), kinds, limit)
+	if err != nil {
+		return nil, err
+	}
+	return claimed, nil
+}
+
+func (q *Queue) Extend(ctx context.Context, job jobs.Job, lease time.Duration) error {
+	return q.update(ctx, `UPDATE jobs SET run_at = now() + make_interval(secs => $3), updated_at = now()
+		WHERE id = $1 AND attempt = $2 AND status = 'running' RETURNING id`, job.ID, job.Attempt, lease.Seconds())
+}
+
+func (q *Queue) Complete(ctx context.Context, job jobs.Job) error {
+	return q.update(ctx, `DELETE FROM jobs WHERE id = $1 AND attempt = $2 AND status = 'running' RETURNING id`,
+		job.ID, job.Attempt)
+}
+
+func (q *Queue) Retry(ctx context.Context, job jobs.Job, runAt time.Time, cause error) error {
+	return q.update(ctx, `UPDATE jobs SET status = 'pending', run_at = $3, last_error = $4, updated_at = now()
+		WHERE id = $1 AND attempt = $2 AND status = 'running' RETURNING id`, job.ID, job.Attempt, runAt, cause.Error())
+}
+
+func (q *Queue) Fail(ctx context.Context, job jobs.Job, cause error) error {
+	return q.update(ctx, `UPDATE jobs SET status = 'dead', last_error = $3, updated_at = now()
+		WHERE id = $1 AND attempt = $2 AND status = 'running' RETURNING id`, job.ID, job.Attempt, cause.Error())
+}
+
+// update runs the query changing the running attempt, which is not found if its lease has been lost
+func (q *Queue) update(ctx context.Context, query string, args ...any) error {
+	var id string
+	err := q.db.Get(ctx, &id, query, args...)
+	if errors.Is(err, postgres.ErrNoRowsInResultSet) {
+		return jobs.ErrLeaseLost
+	}
+	return err
+}
@@ -0,0 +1,7 @@
+package redis
+
+import "fmt"
+
+func errParsing(field, id string, err error) error {
+	return fmt.Errorf("%w (parsing %s of job %s)", err, field, id)
+}
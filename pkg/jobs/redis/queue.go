@@ -0,0 +1,220 @@
+package redis
+
+import (
+	"context"
+	"github.com/adanyl0v/go-pocket-link/pkg/cache/redis"
+	"github.com/adanyl0v/go-pocket-link/pkg/jobs"
+	"github.com/adanyl0v/go-pocket-link/pkg/logctx"
+	"github.com/google/uuid"
+	"strconv"
+	"time"
+)
+
+// The jobs are hashes at job:<id>. The queued and running ones are in the sorted set queue:<kind>
+// scored by run_at, the running ones until the end of their lease, and the dead ones are in the
+// sorted set dead. unique:<key> holds the id of the unique job with the key while it is queued or running
+
+var enqueueScript = redis.NewScript(`
+if ARGV[6] ~= '' and not redis.call('SET', KEYS[3], ARGV[1], 'NX') then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'kind', ARGV[2], 'payload', ARGV[3], 'status', 'pending', 'attempt', 0,
+	'max_attempts', ARGV[4], 'run_at', ARGV[5], 'unique_key', ARGV[6], 'last_error', '', 'request_id', ARGV[8], 'created_at', ARGV[7])
+redis.call('ZADD', KEYS[2], ARGV[5], ARGV[1])
+return 1
+`)
+
+// claimScript claims the due jobs of the queues in order, returning their ids followed by their fields
+var claimScript = redis.NewScript(`
+local claimed = {}
+local limit = tonumber(ARGV[3])
+local runAt = tonumber(ARGV[1]) + tonumber(ARGV[2])
+for _, queue in ipairs(KEYS) do
+	if #claimed >= limit * 2 then
+		break
+	end
+	local ids = redis.call('ZRANGEBYSCORE', queue, '-inf', ARGV[1], 'LIMIT', 0, limit - #claimed / 2)
+	for _, id in ipairs(ids) do
+		local key = ARGV[4] .. id
+		if redis.call('EXISTS', key) == 1 then
+			redis.call('ZADD', queue, runAt, id)
+			redis.call('HINCRBY', key, 'attempt', 1)
+			redis.call('HSET', key, 'status', 'running', 'run_at', runAt)
+			table.insert(claimed, id)
+			table.insert(claimed, redis.call('HGETALL', key))
+		else
+			redis.call('ZREM', queue, id)
+		end
+	end
+end
+return claimed
+`)
+
+// the scripts changing the running attempt return 0 if its lease has been lost
+const checkAttempt = `
+local job = redis.call('HMGET', KEYS[1], 'status', 'attempt', 'unique_key')
+if job[1] ~= 'running' or job[2] ~= ARGV[2] then
+	return 0
+end
+local function releaseUnique()
+	if job[3] ~= '' and redis.call('GET', ARGV[3] .. job[3]) == ARGV[1] then
+		redis.call('DEL', ARGV[3] .. job[3])
+	end
+end
+`
+
+var extendScript = redis.NewScript(checkAttempt + `
+redis.call('HSET', KEYS[1], 'run_at', ARGV[4])
+redis.call('ZADD', KEYS[2], ARGV[4], ARGV[1])
+return 1
+`)
+
+var completeScript = redis.NewScript(checkAttempt + `
+releaseUnique()
+redis.call('ZREM', KEYS[2], ARGV[1])
+redis.call('DEL', KEYS[1])
+return 1
+`)
+
+var retryScript = redis.NewScript(checkAttempt + `
+redis.call('HSET', KEYS[1], 'status', 'pending', 'run_at', ARGV[4], 'last_error', ARGV[5])
+redis.call('ZADD', KEYS[2], ARGV[4], ARGV[1])
+return 1
+`)
+
+var failScript = redis.NewScript(checkAttempt + `
+releaseUnique()
+redis.call('HSET', KEYS[1], 'status', 'dead', 'last_error', ARGV[5])
+redis.call('ZREM', KEYS[2], ARGV[1])
+redis.call('ZADD', KEYS[3], ARGV[4], ARGV[1])
+return 1
+`)
+
+// Queue stores the jobs in redis, the scripts claiming and changing them being atomic
+type Queue struct {
+	db     *redis.DB
+	prefix string
+}
+
+// NewQueue makes a queue keeping its keys under the prefix
+func NewQueue(db *redis.DB, prefix string) *Queue {
+	return &Queue{db: db, prefix: prefix}
+}
+
+func (q *Queue) Enqueue(ctx context.Context, kind string, payload []byte, opts ...jobs.Option) (string, error) {
+	o := jobs.NewOptions(opts...)
+
+	id := uuid.NewString()
+	result, err := q.db.Run(ctx, enqueueScript, []string{q.jobKey(id), q.queueKey(kind), q.prefix + "unique:" + o.UniqueKey},
+		id, kind, payload, o.MaxAttempts, o.RunAt.UnixMilli(), o.UniqueKey, time.Now().UnixMilli(), logctx.RequestID(ctx))
+	if err != nil {
+		return "", err
+	} else if result == int64(0) {
+		return "", jobs.ErrDuplicate
+	}
+	return id, nil
+}
+
+// Claim claims the jobs of the kinds in the order they are given, so the jobs of the first kinds
+// are run first when more jobs are due than the limit
+func (q *Queue) Claim(ctx context.Context, kinds []string, limit int, lease time.Duration) ([]jobs.Job, error) {
+	keys := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		keys = append(keys, q.queueKey(kind))
+	}
+
+	result, err := q.db.Run(ctx, claimScript, keys, time.Now().UnixMilli(), lease.Milliseconds(), limit, q.prefix+"job:")
+	if err != nil {
+		return nil, err
+	}
+
+	values, _ := result.([]any)
+	claimed := make([]jobs.Job, 0, len(values)/2)
+	for i := 0; i+1 < len(values); i += 2 {
+		id, _ := values[i].(string)
+		fields, _ := values[i+1].([]any)
+		job, err := parseJob(id, fields)
+		if err != nil {
+			return nil, err
+		}
+		claimed = append(claimed, job)
+	}
+	return claimed, nil
+}
+
+func (q *Queue) Extend(ctx context.Context, job jobs.Job, lease time.Duration) error {
+	return q.update(ctx, extendScript, job, time.Now().Add(lease).UnixMilli(), "")
+}
+
+func (q *Queue) Complete(ctx context.Context, job jobs.Job) error {
+	return q.update(ctx, completeScript, job, 0, "")
+}
+
+func (q *Queue) Retry(ctx context.Context, job jobs.Job, runAt time.Time, cause error) error {
+	return q.update(ctx, retryScript, job, runAt.UnixMilli(), cause.Error())
+}
+
+func (q *Queue) Fail(ctx context.Context, job jobs.Job, cause error) error {
+	return q.update(ctx, failScript, job, time.Now().UnixMilli(), cause.Error())
+}
+
+func (q *Queue) update(ctx context.Context, script *redis.Script, job jobs.Job, at int64, lastError string) error {
+	result, err := q.db.Run(ctx, script, []string{q.jobKey(job.ID), q.queueKey(job.Kind), q.prefix + "dead"},
+		job.ID, job.Attempt, q.prefix+"unique:", at, lastError)
+	if err != nil {
+		return err
+	} else if result == int64(0) {
+		return jobs.ErrLeaseLost
+	}
+	return nil
+}
+
+func (q *Queue) jobKey(id string) string {
+	return q.prefix + "job:" + id
+}
+
+func (q *Queue) queueKey(kind string) string {
+	return q.prefix + "queue:" + kind
+}
+
+func parseJob(id string, fields []any) (jobs.Job, error) {
+	job := jobs.Job{ID: id}
+	for i := 0; i+1 < len(fields); i += 2 {
+		name, _ := fields[i].(string)
+		value, _ := fields[i+1].(string)
+
+		var err error
+		switch name {
+		case "kind":
+			job.Kind = value
+		case "payload":
+			job.Payload = []byte(value)
+		case "attempt":
+			job.Attempt, err = strconv.Atoi(value)
+		case "max_attempts":
+			job.MaxAttempts, err = strconv.Atoi(value)
+		case "run_at":
+			job.RunAt, err = parseMilli(value)
+		case "unique_key":
+			job.UniqueKey = value
+		case "last_error":
+			job.LastError = value
+		case "request_id":
+			job.RequestID = value
+		case "created_at":
+			job.CreatedAt, err = parseMilli(value)
+		}
+		if err != nil {
+			return jobs.Job{}, errParsing(name, id, err)
+		}
+	}
+	return job, nil
+}
+
+func parseMilli(value string) (time.Time, error) {
+	ms, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.UnixMilli(ms), nil
+}
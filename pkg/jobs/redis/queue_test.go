@@ -0,0 +1,203 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"github.com/adanyl0v/go-pocket-link/pkg/cache/redis"
+	"github.com/adanyl0v/go-pocket-link/pkg/jobs"
+	"github.com/adanyl0v/go-pocket-link/pkg/logctx"
+	"github.com/alicebob/miniredis/v2"
+	"testing"
+	"time"
+)
+
+func newTestQueue(t *testing.T) *Queue {
+	t.Helper()
+	server := miniredis.RunT(t)
+	db, err := redis.Connect("redis://" + server.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return NewQueue(db, "jobs:")
+}
+
+// claimOne claims the due jobs of the kind, failing unless there is exactly one
+func claimOne(t *testing.T, queue *Queue, kind string, lease time.Duration) jobs.Job {
+	t.Helper()
+	claimed, err := queue.Claim(context.Background(), []string{kind}, 10, lease)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(claimed) != 1 {
+		t.Fatalf("claimed %d jobs, want 1", len(claimed))
+	}
+	return claimed[0]
+}
+
+func assertNoneDue(t *testing.T, queue *Queue, kind string) {
+	t.Helper()
+	claimed, err := queue.Claim(context.Background(), []string{kind}, 10, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(claimed) != 0 {
+		t.Fatalf("claimed %d jobs, want none", len(claimed))
+	}
+}
+
+func TestEnqueueClaimComplete(t *testing.T) {
+	queue := newTestQueue(t)
+	ctx := logctx.WithRequestID(context.Background(), "request-1")
+
+	id, err := queue.Enqueue(ctx, "email", []byte(`{"to":"user@example.com"}`), jobs.MaxAttempts(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	job := claimOne(t, queue, "email", time.Minute)
+	if job.ID != id || job.Kind != "email" || string(job.Payload) != `{"to":"user@example.com"}` ||
+		job.Attempt != 1 || job.MaxAttempts != 3 || job.RequestID != "request-1" {
+		t.Errorf("claimed %+v", job)
+	}
+	assertNoneDue(t, queue, "email")
+
+	if err = queue.Complete(context.Background(), job); err != nil {
+		t.Fatal(err)
+	}
+	assertNoneDue(t, queue, "email")
+}
+
+func TestClaimOnlyDueJobs(t *testing.T) {
+	queue := newTestQueue(t)
+	if _, err := queue.Enqueue(context.Background(), "email", nil, jobs.Delay(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	assertNoneDue(t, queue, "email")
+}
+
+func TestClaimKindsInOrder(t *testing.T) {
+	queue := newTestQueue(t)
+	for _, kind := range []string{"email", "import", "email"} {
+		if _, err := queue.Enqueue(context.Background(), kind, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	claimed, err := queue.Claim(context.Background(), []string{"import", "email"}, 2, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(claimed) != 2 || claimed[0].Kind != "import" || claimed[1].Kind != "email" {
+		t.Errorf("claimed %+v", claimed)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	queue := newTestQueue(t)
+	if _, err := queue.Enqueue(context.Background(), "email", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	job := claimOne(t, queue, "email", time.Minute)
+	if err := queue.Retry(context.Background(), job, time.Now(), errors.New("smtp is unavailable")); err != nil {
+		t.Fatal(err)
+	}
+	if job = claimOne(t, queue, "email", time.Minute); job.Attempt != 2 || job.LastError != "smtp is unavailable" {
+		t.Errorf("claimed attempt %d after %q", job.Attempt, job.LastError)
+	}
+
+	if err := queue.Retry(context.Background(), job, time.Now().Add(time.Hour), errors.New("smtp is unavailable")); err != nil {
+		t.Fatal(err)
+	}
+	assertNoneDue(t, queue, "email")
+}
+
+func TestUniqueJobs(t *testing.T) {
+	queue := newTestQueue(t)
+	enqueue := func() error {
+		_, err := queue.Enqueue(context.Background(), "import", nil, jobs.Unique("import:1"))
+		return err
+	}
+
+	if err := enqueue(); err != nil {
+		t.Fatal(err)
+	}
+	if err := enqueue(); !errors.Is(err, jobs.ErrDuplicate) {
+		t.Fatalf("queued job: Enqueue = %v, want ErrDuplicate", err)
+	}
+
+	// the key is held while the job is running and retried
+	job := claimOne(t, queue, "import", time.Minute)
+	if err := enqueue(); !errors.Is(err, jobs.ErrDuplicate) {
+		t.Fatalf("running job: Enqueue = %v, want ErrDuplicate", err)
+	}
+	if err := queue.Retry(context.Background(), job, time.Now(), errors.New("timeout")); err != nil {
+		t.Fatal(err)
+	}
+	if err := enqueue(); !errors.Is(err, jobs.ErrDuplicate) {
+		t.Fatalf("retried job: Enqueue = %v, want ErrDuplicate", err)
+	}
+
+	// and released once the job is completed or dead
+	if err := queue.Complete(context.Background(), claimOne(t, queue, "import", time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := enqueue(); err != nil {
+		t.Fatalf("completed job: Enqueue = %v", err)
+	}
+	if err := queue.Fail(context.Background(), claimOne(t, queue, "import", time.Minute), errors.New("invalid")); err != nil {
+		t.Fatal(err)
+	}
+	if err := enqueue(); err != nil {
+		t.Fatalf("dead job: Enqueue = %v", err)
+	}
+}
+
+func TestFailedJobsAreNotClaimed(t *testing.T) {
+	queue := newTestQueue(t)
+	if _, err := queue.Enqueue(context.Background(), "email", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := queue.Fail(context.Background(), claimOne(t, queue, "email", time.Millisecond), errors.New("invalid")); err != nil {
+		t.Fatal(err)
+	}
+	// the lease of the dead job would be over by now
+	time.Sleep(5 * time.Millisecond)
+	assertNoneDue(t, queue, "email")
+}
+
+func TestLeaseLoss(t *testing.T) {
+	queue := newTestQueue(t)
+	if _, err := queue.Enqueue(context.Background(), "email", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// the worker of the first attempt stalls past its lease, so the job is claimed again
+	stale := claimOne(t, queue, "email", 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	job := claimOne(t, queue, "email", time.Minute)
+	if job.Attempt != 2 {
+		t.Fatalf("claimed attempt %d, want 2", job.Attempt)
+	}
+
+	ctx := context.Background()
+	for name, err := range map[string]error{
+		"Extend":   queue.Extend(ctx, stale, time.Minute),
+		"Complete": queue.Complete(ctx, stale),
+		"Retry":    queue.Retry(ctx, stale, time.Now(), errors.New("timeout")),
+		"Fail":     queue.Fail(ctx, stale, errors.New("timeout")),
+	} {
+		if !errors.Is(err, jobs.ErrLeaseLost) {
+			t.Errorf("%s of the stale attempt = %v, want ErrLeaseLost", name, err)
+		}
+	}
+
+	// the extended lease keeps the job from being claimed again
+	if err := queue.Extend(ctx, job, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	assertNoneDue(t, queue, "email")
+	if err := queue.Complete(ctx, job); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,180 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"github.com/adanyl0v/go-pocket-link/pkg/backoff"
+	"github.com/adanyl0v/go-pocket-link/pkg/logctx"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// queueTimeout bounds the queue calls made after the job has run, which must not be canceled with it
+const queueTimeout = 10 * time.Second
+
+// Handler runs a job. The errors marked Permanent fail the job, the others retry it until it runs out of attempts
+type Handler func(ctx context.Context, job Job) error
+
+type WorkerOptions struct {
+	// Concurrency is how many jobs are run at once
+	Concurrency int
+	// PollInterval is how often an idle worker looks for due jobs
+	PollInterval time.Duration
+	// Lease is how long a claimed job is not claimed again. It is extended while the job runs
+	Lease time.Duration
+	// BaseDelay is the delay before the first retry, doubled for each next one up to MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// ShutdownTimeout is how long the running jobs are given to finish once the worker is stopped,
+	// after which their contexts are canceled and they are retried
+	ShutdownTimeout time.Duration
+}
+
+// Worker runs the jobs of the kinds it has handlers for
+type Worker struct {
+	queue    Queue
+	opts     WorkerOptions
+	handlers map[string]Handler
+	kinds    []string
+}
+
+func NewWorker(queue Queue, opts WorkerOptions) *Worker {
+	return &Worker{
+		queue:    queue,
+		opts:     opts,
+		handlers: make(map[string]Handler),
+	}
+}
+
+// Handle registers the handler of the kind. The handlers must be registered before the worker runs
+func (w *Worker) Handle(kind string, handler Handler) {
+	if _, ok := w.handlers[kind]; !ok {
+		w.kinds = append(w.kinds, kind)
+	}
+	w.handlers[kind] = handler
+}
+
+// Run runs the jobs until the context is canceled, then waits for the running ones to finish
+func (w *Worker) Run(ctx context.Context) {
+	jobsCtx, cancelJobs := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelJobs()
+
+	var wg sync.WaitGroup
+	for range max(w.opts.Concurrency, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.loop(ctx, jobsCtx)
+		}()
+	}
+
+	<-ctx.Done()
+	timer := time.AfterFunc(w.opts.ShutdownTimeout, cancelJobs)
+	defer timer.Stop()
+	wg.Wait()
+}
+
+// loop claims and runs the jobs one by one, so each loop takes one of the Concurrency slots
+func (w *Worker) loop(ctx, jobsCtx context.Context) {
+	ticker := time.NewTicker(w.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		claimed, err := w.queue.Claim(ctx, w.kinds, 1, w.opts.Lease)
+		if err != nil && ctx.Err() == nil {
+			slog.Error("failed to claim jobs", "error", err)
+		}
+		for _, job := range claimed {
+			w.run(jobsCtx, job)
+		}
+
+		// keep claiming while the jobs are due
+		if len(claimed) > 0 && ctx.Err() == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Worker) run(ctx context.Context, job Job) {
+	if job.RequestID != "" {
+		// the job goes on with the request it has been queued by
+		ctx = logctx.WithRequestID(ctx, job.RequestID)
+	}
+	logger := slog.With("job_id", job.ID, "kind", job.Kind, "attempt", job.Attempt)
+
+	var err error
+	if job.Attempt > job.MaxAttempts {
+		// the worker of the last attempt has died
+		err = Permanent(errNoAttemptsLeft(job.MaxAttempts))
+	} else {
+		err = w.handle(ctx, job)
+	}
+
+	queueCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), queueTimeout)
+	defer cancel()
+
+	switch {
+	case err == nil:
+		err = w.queue.Complete(queueCtx, job)
+	case IsPermanent(err) || job.Attempt >= job.MaxAttempts:
+		logger.ErrorContext(ctx, "job has failed", "error", err)
+		err = w.queue.Fail(queueCtx, job, err)
+	default:
+		logger.WarnContext(ctx, "job attempt has failed", "error", err)
+		runAt := time.Now().Add(backoff.Exponential(job.Attempt, w.opts.BaseDelay, w.opts.MaxDelay))
+		if ctx.Err() != nil {
+			// the attempt has been interrupted by the shutdown, so it is retried right away
+			runAt = time.Now()
+		}
+		err = w.queue.Retry(queueCtx, job, runAt, err)
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to record job result", "error", err)
+	}
+}
+
+// handle runs the handler, extending the lease of the job until it returns. The handler
+// is canceled if the lease is lost, since the job may be run by another worker then
+func (w *Worker) handle(ctx context.Context, job Job) (err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// the lease is not extended once the handler returns, so the result of the job is recorded after the last extension
+	stop, stopped := make(chan struct{}), make(chan struct{})
+	defer func() {
+		close(stop)
+		<-stopped
+	}()
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(w.opts.Lease / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if err := w.queue.Extend(ctx, job, w.opts.Lease); errors.Is(err, ErrLeaseLost) {
+					slog.WarnContext(ctx, "lost job lease", "job_id", job.ID, "kind", job.Kind)
+					cancel()
+					return
+				} else if err != nil && ctx.Err() == nil {
+					slog.ErrorContext(ctx, "failed to extend job lease", "job_id", job.ID, "error", err)
+				}
+			}
+		}
+	}()
+
+	defer func() {
+		if v := recover(); v != nil {
+			err = errPanicked(job.Kind, v)
+		}
+	}()
+	return w.handlers[job.Kind](ctx, job)
+}
@@ -0,0 +1,458 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// memoryQueue keeps the jobs in memory, leasing them and checking the leases the way the backends do,
+// and records the results of the attempts
+type memoryQueue struct {
+	mu     sync.Mutex
+	nextID int
+	jobs   map[string]*memoryJob
+	unique map[string]string
+
+	completed []Job
+	retried   []retriedJob
+	failed    []failedJob
+}
+
+type memoryJob struct {
+	job     Job
+	running bool
+}
+
+type retriedJob struct {
+	job   Job
+	delay time.Duration
+	cause error
+}
+
+type failedJob struct {
+	job   Job
+	cause error
+}
+
+func newMemoryQueue() *memoryQueue {
+	return &memoryQueue{jobs: make(map[string]*memoryJob), unique: make(map[string]string)}
+}
+
+func (q *memoryQueue) Enqueue(_ context.Context, kind string, payload []byte, opts ...Option) (string, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	o := NewOptions(opts...)
+	if _, ok := q.unique[o.UniqueKey]; ok && o.UniqueKey != "" {
+		return "", ErrDuplicate
+	}
+
+	q.nextID++
+	id := strconv.Itoa(q.nextID)
+	q.jobs[id] = &memoryJob{job: Job{ID: id, Kind: kind, Payload: payload, MaxAttempts: o.MaxAttempts,
+		RunAt: o.RunAt, UniqueKey: o.UniqueKey, CreatedAt: time.Now()}}
+	if o.UniqueKey != "" {
+		q.unique[o.UniqueKey] = id
+	}
+	return id, nil
+}
+
+func (q *memoryQueue) Claim(ctx context.Context, kinds []string, limit int, lease time.Duration) ([]Job, error) {
+	// a canceled claim fails as with the backends, so a stopped worker claims nothing more
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	var claimed []Job
+	for _, id := range q.ids() {
+		j := q.jobs[id]
+		if len(claimed) >= limit || !slices.Contains(kinds, j.job.Kind) || j.job.RunAt.After(now) {
+			continue
+		}
+		j.running = true
+		j.job.Attempt++
+		j.job.RunAt = now.Add(lease)
+		claimed = append(claimed, j.job)
+	}
+	return claimed, nil
+}
+
+func (q *memoryQueue) Extend(_ context.Context, job Job, lease time.Duration) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j, err := q.leased(job)
+	if err != nil {
+		return err
+	}
+	j.job.RunAt = time.Now().Add(lease)
+	return nil
+}
+
+func (q *memoryQueue) Complete(_ context.Context, job Job) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, err := q.leased(job); err != nil {
+		return err
+	}
+	q.remove(job)
+	q.completed = append(q.completed, job)
+	return nil
+}
+
+func (q *memoryQueue) Retry(_ context.Context, job Job, runAt time.Time, cause error) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j, err := q.leased(job)
+	if err != nil {
+		return err
+	}
+	j.running = false
+	j.job.RunAt = runAt
+	j.job.LastError = cause.Error()
+	q.retried = append(q.retried, retriedJob{job: job, delay: time.Until(runAt), cause: cause})
+	return nil
+}
+
+func (q *memoryQueue) Fail(_ context.Context, job Job, cause error) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, err := q.leased(job); err != nil {
+		return err
+	}
+	q.remove(job)
+	q.failed = append(q.failed, failedJob{job: job, cause: cause})
+	return nil
+}
+
+// reclaim claims the job again as if its lease was over, so the running attempt loses it
+func (q *memoryQueue) reclaim(id string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.jobs[id].job.Attempt++
+	q.jobs[id].job.RunAt = time.Now().Add(time.Hour)
+}
+
+func (q *memoryQueue) results() (completed []Job, retried []retriedJob, failed []failedJob) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return slices.Clone(q.completed), slices.Clone(q.retried), slices.Clone(q.failed)
+}
+
+func (q *memoryQueue) leased(job Job) (*memoryJob, error) {
+	j, ok := q.jobs[job.ID]
+	if !ok || !j.running || j.job.Attempt != job.Attempt {
+		return nil, ErrLeaseLost
+	}
+	return j, nil
+}
+
+func (q *memoryQueue) remove(job Job) {
+	delete(q.jobs, job.ID)
+	if job.UniqueKey != "" && q.unique[job.UniqueKey] == job.ID {
+		delete(q.unique, job.UniqueKey)
+	}
+}
+
+// ids returns the ids of the jobs in the order they have been queued
+func (q *memoryQueue) ids() []string {
+	ids := make([]string, 0, len(q.jobs))
+	for id := range q.jobs {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(a, b string) int {
+		x, _ := strconv.Atoi(a)
+		y, _ := strconv.Atoi(b)
+		return x - y
+	})
+	return ids
+}
+
+var testWorkerOptions = WorkerOptions{
+	Concurrency:     1,
+	PollInterval:    5 * time.Millisecond,
+	Lease:           time.Second,
+	BaseDelay:       10 * time.Millisecond,
+	MaxDelay:        time.Second,
+	ShutdownTimeout: time.Second,
+}
+
+// startWorker runs the worker until the returned function is called, which waits for it to stop
+func startWorker(queue Queue, opts WorkerOptions, kind string, handler Handler) (stop func()) {
+	worker := NewWorker(queue, opts)
+	worker.Handle(kind, handler)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		worker.Run(ctx)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// waitFor polls the condition until it holds or a second passes
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); !condition(); time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("condition has not been met in time")
+		}
+	}
+}
+
+func TestWorkerCompletesJob(t *testing.T) {
+	queue := newMemoryQueue()
+	if _, err := EnqueueJSON(context.Background(), queue, "email", map[string]string{"to": "user@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var payload map[string]string
+	stop := startWorker(queue, testWorkerOptions, "email", func(ctx context.Context, job Job) error {
+		return job.Decode(&payload)
+	})
+	defer stop()
+
+	waitFor(t, func() bool {
+		completed, _, _ := queue.results()
+		return len(completed) == 1
+	})
+	if payload["to"] != "user@example.com" {
+		t.Errorf("payload = %v", payload)
+	}
+}
+
+func TestWorkerRetriesWithBackoff(t *testing.T) {
+	queue := newMemoryQueue()
+	if _, err := queue.Enqueue(context.Background(), "email", nil, MaxAttempts(3)); err != nil {
+		t.Fatal(err)
+	}
+
+	errUnavailable := errors.New("smtp is unavailable")
+	stop := startWorker(queue, testWorkerOptions, "email", func(ctx context.Context, job Job) error {
+		return errUnavailable
+	})
+	defer stop()
+
+	waitFor(t, func() bool {
+		_, _, failed := queue.results()
+		return len(failed) == 1
+	})
+	_, retried, failed := queue.results()
+
+	// the delay doubles with each attempt, with up to 10% of jitter
+	if len(retried) != 2 {
+		t.Fatalf("retried %d times, want 2", len(retried))
+	}
+	for i, want := range []time.Duration{testWorkerOptions.BaseDelay, 2 * testWorkerOptions.BaseDelay} {
+		if r := retried[i]; r.job.Attempt != i+1 || !errors.Is(r.cause, errUnavailable) ||
+			r.delay < want-5*time.Millisecond || r.delay > want+want/10 {
+			t.Errorf("retry %d: attempt %d after %s with %v, want a delay of about %s", i, r.job.Attempt, r.delay, r.cause, want)
+		}
+	}
+	if f := failed[0]; f.job.Attempt != 3 || !errors.Is(f.cause, errUnavailable) {
+		t.Errorf("failed attempt %d with %v", f.job.Attempt, f.cause)
+	}
+}
+
+func TestWorkerFailsPermanentErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		payload []byte
+		handler Handler
+	}{
+		{"permanent", []byte(`{}`), func(ctx context.Context, job Job) error {
+			return Permanent(errors.New("recipient does not exist"))
+		}},
+		{"undecodable payload", []byte(`{`), func(ctx context.Context, job Job) error {
+			var payload map[string]string
+			return job.Decode(&payload)
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			queue := newMemoryQueue()
+			if _, err := queue.Enqueue(context.Background(), "email", tt.payload); err != nil {
+				t.Fatal(err)
+			}
+
+			stop := startWorker(queue, testWorkerOptions, "email", tt.handler)
+			defer stop()
+
+			waitFor(t, func() bool {
+				_, _, failed := queue.results()
+				return len(failed) == 1
+			})
+			if _, retried, failed := queue.results(); len(retried) != 0 || failed[0].job.Attempt != 1 {
+				t.Errorf("retried %d times, failed attempt %d", len(retried), failed[0].job.Attempt)
+			}
+		})
+	}
+}
+
+func TestWorkerRetriesPanickedJob(t *testing.T) {
+	queue := newMemoryQueue()
+	if _, err := queue.Enqueue(context.Background(), "email", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	stop := startWorker(queue, testWorkerOptions, "email", func(ctx context.Context, job Job) error {
+		if job.Attempt == 1 {
+			panic("nil map")
+		}
+		return nil
+	})
+	defer stop()
+
+	waitFor(t, func() bool {
+		completed, _, _ := queue.results()
+		return len(completed) == 1
+	})
+	if _, retried, _ := queue.results(); len(retried) != 1 || retried[0].cause.Error() != "email job panicked: nil map" {
+		t.Errorf("retries = %+v", retried)
+	}
+}
+
+func TestWorkerFailsJobOutOfAttempts(t *testing.T) {
+	queue := newMemoryQueue()
+	id, err := queue.Enqueue(context.Background(), "email", nil, MaxAttempts(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the worker running the last attempt has died, so the job is claimed once more
+	queue.jobs[id].job.Attempt = 1
+
+	var handled atomic.Bool
+	stop := startWorker(queue, testWorkerOptions, "email", func(ctx context.Context, job Job) error {
+		handled.Store(true)
+		return nil
+	})
+	defer stop()
+
+	waitFor(t, func() bool {
+		_, _, failed := queue.results()
+		return len(failed) == 1
+	})
+	if _, _, failed := queue.results(); handled.Load() || !IsPermanent(failed[0].cause) {
+		t.Errorf("handled: %t, failed with %v", handled.Load(), failed[0].cause)
+	}
+}
+
+func TestWorkerCancelsJobOnLeaseLoss(t *testing.T) {
+	queue := newMemoryQueue()
+	id, err := queue.Enqueue(context.Background(), "email", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := testWorkerOptions
+	opts.Lease = 20 * time.Millisecond
+
+	canceled := make(chan error, 1)
+	stop := startWorker(queue, opts, "email", func(ctx context.Context, job Job) error {
+		// another worker claims the job, so the lease cannot be extended anymore
+		queue.reclaim(id)
+		select {
+		case <-ctx.Done():
+			canceled <- ctx.Err()
+		case <-time.After(time.Second):
+			canceled <- nil
+		}
+		return ctx.Err()
+	})
+	defer stop()
+
+	if err = <-canceled; !errors.Is(err, context.Canceled) {
+		t.Fatalf("handler context error = %v, want context.Canceled", err)
+	}
+	stop()
+
+	// the result of the attempt which has lost the lease is not recorded
+	if completed, retried, failed := queue.results(); len(completed)+len(retried)+len(failed) != 0 {
+		t.Errorf("completed %d, retried %d, failed %d", len(completed), len(retried), len(failed))
+	}
+}
+
+func TestWorkerRequeuesJobsOnShutdown(t *testing.T) {
+	queue := newMemoryQueue()
+	if _, err := queue.Enqueue(context.Background(), "email", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := testWorkerOptions
+	opts.BaseDelay, opts.MaxDelay = time.Hour, time.Hour
+	opts.ShutdownTimeout = 20 * time.Millisecond
+
+	started := make(chan struct{})
+	stop := startWorker(queue, opts, "email", func(ctx context.Context, job Job) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	<-started
+	stop()
+
+	// the interrupted attempt is retried right away rather than after the backoff
+	_, retried, _ := queue.results()
+	if len(retried) != 1 || retried[0].delay > 0 || !errors.Is(retried[0].cause, context.Canceled) {
+		t.Errorf("retries = %+v", retried)
+	}
+}
+
+func TestWorkerFinishesJobsWithinShutdownTimeout(t *testing.T) {
+	queue := newMemoryQueue()
+	if _, err := queue.Enqueue(context.Background(), "email", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	started := make(chan struct{})
+	stop := startWorker(queue, testWorkerOptions, "email", func(ctx context.Context, job Job) error {
+		close(started)
+		time.Sleep(20 * time.Millisecond)
+		return ctx.Err()
+	})
+	<-started
+	stop()
+
+	if completed, retried, _ := queue.results(); len(completed) != 1 || len(retried) != 0 {
+		t.Errorf("completed %d, retried %d", len(completed), len(retried))
+	}
+}
+
+func TestNewOptions(t *testing.T) {
+	runAt := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		name string
+		opts []Option
+		want Options
+	}{
+		{"unique", []Option{At(runAt), Unique("import:1")}, Options{RunAt: runAt, MaxAttempts: DefaultMaxAttempts, UniqueKey: "import:1"}},
+		{"max attempts", []Option{At(runAt), MaxAttempts(3)}, Options{RunAt: runAt, MaxAttempts: 3}},
+		{"at least one attempt", []Option{At(runAt), MaxAttempts(0)}, Options{RunAt: runAt, MaxAttempts: 1}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewOptions(tt.opts...); got != tt.want {
+				t.Errorf("NewOptions = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if o := NewOptions(Delay(time.Hour)); time.Until(o.RunAt) < 59*time.Minute {
+		t.Errorf("delayed job runs at %s", o.RunAt)
+	}
+}
@@ -0,0 +1,201 @@
+package linkcheck
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxRobotsSize is how much of robots.txt is read, RFC 9309 asks for at least 500 KiB
+	maxRobotsSize = 512 << 10
+	// maxBodySize is how much of the body of a GET is read, it is only read to let the connection be reused
+	maxBodySize = 64 << 10
+	// maxCrawlDelay bounds the crawl delays of robots.txt, so a host cannot stall the checks
+	maxCrawlDelay = time.Minute
+)
+
+type Options struct {
+	// UserAgent is sent with the requests, its product token, e.g. PocketLinkBot of PocketLinkBot/1.0,
+	// names the agent in robots.txt
+	UserAgent string
+	// HostInterval is the least time between two requests to a host, raised to its crawl delay
+	HostInterval time.Duration
+	// RobotsTTL is how long robots.txt of a host is cached
+	RobotsTTL time.Duration
+}
+
+// Result is the response to the last request of a check
+type Result struct {
+	StatusCode int
+	// FinalURL is the url the redirects have ended at
+	FinalURL     string
+	ResponseTime time.Duration
+	// RetryAfter is how long the host asks to wait before the next request, 0 if it does not ask
+	RetryAfter time.Duration
+}
+
+// OK tells whether the link works, i.e. it has been answered with a success after the redirects
+func (r Result) OK() bool {
+	return r.StatusCode >= 200 && r.StatusCode < 400
+}
+
+// RateLimited tells whether the host has refused to answer for now
+func (r Result) RateLimited() bool {
+	return r.StatusCode == http.StatusTooManyRequests
+}
+
+type cachedRobots struct {
+	robots    robots
+	expiresAt time.Time
+}
+
+// Checker checks whether the links work, politely: the requests to each host are spaced, and the paths
+// robots.txt disallows are not requested. It is safe for concurrent use, the checks of different hosts
+// running in parallel
+type Checker struct {
+	http    *http.Client
+	opts    Options
+	agent   string
+	limiter *hostLimiter
+
+	mu     sync.Mutex
+	robots map[string]cachedRobots
+}
+
+func New(httpClient *http.Client, opts Options) *Checker {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 15 * time.Second}
+	}
+	agent, _, _ := strings.Cut(opts.UserAgent, "/")
+	return &Checker{
+		http:    httpClient,
+		opts:    opts,
+		agent:   agent,
+		limiter: newHostLimiter(),
+		robots:  make(map[string]cachedRobots),
+	}
+}
+
+// Check requests the url with HEAD, falling back to GET if HEAD fails, since many servers do not
+// support it properly. The responses of any status are results, only the failed requests are errors.
+// ErrDisallowed is returned if robots.txt of the host disallows the url
+func (c *Checker) Check(ctx context.Context, rawURL string) (Result, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Result{}, errInvalidURL(rawURL)
+	}
+
+	rules, err := c.getRobots(ctx, u)
+	if err != nil {
+		return Result{}, err
+	} else if !rules.allowed(u.RequestURI()) {
+		return Result{}, ErrDisallowed
+	}
+	interval := max(c.opts.HostInterval, min(rules.crawlDelay, maxCrawlDelay))
+
+	result, err := c.request(ctx, http.MethodHead, u, interval)
+	if err != nil || (result.StatusCode < 400 || result.RateLimited()) {
+		return result, err
+	}
+	return c.request(ctx, http.MethodGet, u, interval)
+}
+
+func (c *Checker) request(ctx context.Context, method string, u *url.URL, interval time.Duration) (Result, error) {
+	if err := c.limiter.wait(ctx, u.Host, interval); err != nil {
+		return Result{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return Result{}, errInvalidURL(u.String())
+	}
+	req.Header.Set("User-Agent", c.opts.UserAgent)
+
+	start := time.Now()
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return Result{ResponseTime: time.Since(start)}, errRequesting(method, u.String(), err)
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxBodySize))
+	_ = resp.Body.Close()
+
+	return Result{
+		StatusCode:   resp.StatusCode,
+		FinalURL:     resp.Request.URL.String(),
+		ResponseTime: time.Since(start),
+		RetryAfter:   parseRetryAfter(resp.Header.Get("Retry-After")),
+	}, nil
+}
+
+// getRobots returns the cached rules of the host, fetching robots.txt if they have expired. The hosts
+// without robots.txt, or failing to serve it, are not restricted, so their links are still checked
+func (c *Checker) getRobots(ctx context.Context, u *url.URL) (robots, error) {
+	key := u.Scheme + "://" + u.Host
+	c.mu.Lock()
+	cached, ok := c.robots[key]
+	c.mu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.robots, nil
+	}
+
+	if err := c.limiter.wait(ctx, u.Host, c.opts.HostInterval); err != nil {
+		return robots{}, err
+	}
+
+	var rules robots
+	if data, err := c.fetchRobots(ctx, key+"/robots.txt"); err == nil {
+		rules = parseRobots(data, c.agent)
+	} else if ctx.Err() != nil {
+		return robots{}, ctx.Err()
+	}
+
+	now := time.Now()
+	c.mu.Lock()
+	for host, entry := range c.robots {
+		if now.After(entry.expiresAt) {
+			delete(c.robots, host)
+		}
+	}
+	c.robots[key] = cachedRobots{robots: rules, expiresAt: now.Add(c.opts.RobotsTTL)}
+	c.mu.Unlock()
+	return rules, nil
+}
+
+func (c *Checker) fetchRobots(ctx context.Context, robotsURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", c.opts.UserAgent)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, errUnexpectedStatus(resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxRobotsSize))
+}
+
+// parseRetryAfter reads the header either as a number of seconds or as a date
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(time.Until(t), 0)
+	}
+	return 0
+}
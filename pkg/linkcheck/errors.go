@@ -0,0 +1,21 @@
+package linkcheck
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrDisallowed is returned for the urls robots.txt of their hosts disallows
+var ErrDisallowed = errors.New("disallowed by robots.txt")
+
+func errInvalidURL(rawURL string) error {
+	return fmt.Errorf("invalid url %s", rawURL)
+}
+
+func errRequesting(method, rawURL string, err error) error {
+	return fmt.Errorf("%w (requesting %s %s)", err, method, rawURL)
+}
+
+func errUnexpectedStatus(status int) error {
+	return fmt.Errorf("unexpected status %d", status)
+}
@@ -0,0 +1,48 @@
+package linkcheck
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepSize is how many hosts the limiter holds before it forgets the ones whose turn has passed
+const sweepSize = 1024
+
+// hostLimiter spaces the requests to each host, giving the hosts their turns in the order they are asked for
+type hostLimiter struct {
+	mu   sync.Mutex
+	next map[string]time.Time
+}
+
+func newHostLimiter() *hostLimiter {
+	return &hostLimiter{next: make(map[string]time.Time)}
+}
+
+// wait waits for the turn of the host, the next turn being the interval later
+func (l *hostLimiter) wait(ctx context.Context, host string, interval time.Duration) error {
+	l.mu.Lock()
+	now := time.Now()
+	if len(l.next) >= sweepSize {
+		for h, at := range l.next {
+			if at.Before(now) {
+				delete(l.next, h)
+			}
+		}
+	}
+	at := now
+	if next, ok := l.next[host]; ok && next.After(now) {
+		at = next
+	}
+	l.next[host] = at.Add(interval)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(at))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
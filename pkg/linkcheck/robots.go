@@ -0,0 +1,128 @@
+package linkcheck
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// robots holds the rules of a robots.txt which apply to the user agent, as RFC 9309 describes them
+type robots struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+type robotsRule struct {
+	allow   bool
+	pattern string
+}
+
+// parseRobots reads the groups of the agent, or the ones of * if there are none. The groups of
+// the same agent are merged, and the lines the format does not know, like sitemaps, are skipped
+func parseRobots(data []byte, agent string) robots {
+	agent = strings.ToLower(agent)
+
+	var matched, wildcard robots
+	var hasMatched bool
+	// the group is the set of the agents listed in a row, followed by their rules
+	var groupAgents []string
+	inRules := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if inRules {
+				groupAgents, inRules = nil, false
+			}
+			groupAgents = append(groupAgents, strings.ToLower(value))
+			continue
+		case "allow", "disallow", "crawl-delay":
+			inRules = true
+		default:
+			continue
+		}
+
+		for _, groupAgent := range groupAgents {
+			var target *robots
+			switch {
+			case groupAgent == agent:
+				target, hasMatched = &matched, true
+			case groupAgent == "*":
+				target = &wildcard
+			default:
+				continue
+			}
+
+			switch key {
+			case "crawl-delay":
+				if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+					target.crawlDelay = time.Duration(seconds * float64(time.Second))
+				}
+			case "disallow":
+				// an empty disallow allows everything, which is the default anyway
+				if value != "" {
+					target.rules = append(target.rules, robotsRule{pattern: value})
+				}
+			default:
+				target.rules = append(target.rules, robotsRule{allow: true, pattern: value})
+			}
+		}
+	}
+
+	if hasMatched {
+		return matched
+	}
+	return wildcard
+}
+
+// allowed follows the most specific rule matching the path, i.e. the one with the longest pattern,
+// the allow rules winning the ties. The paths matching no rule are allowed
+func (r robots) allowed(path string) bool {
+	allowed, longest := true, -1
+	for _, rule := range r.rules {
+		if len(rule.pattern) < longest || !matchPattern(rule.pattern, path) {
+			continue
+		}
+		if len(rule.pattern) > longest || rule.allow {
+			allowed, longest = rule.allow, len(rule.pattern)
+		}
+	}
+	return allowed
+}
+
+// matchPattern matches the path against the prefix pattern, where * stands for any characters
+// and a trailing $ anchors the pattern to the end of the path
+func matchPattern(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	if anchored {
+		pattern = pattern[:len(pattern)-1]
+	}
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+	for i, part := range parts[1:] {
+		// the last part of an anchored pattern must end the path
+		if anchored && i == len(parts)-2 {
+			return strings.HasSuffix(rest, part)
+		}
+		j := strings.Index(rest, part)
+		if j < 0 {
+			return false
+		}
+		rest = rest[j+len(part):]
+	}
+	return !anchored || rest == ""
+}
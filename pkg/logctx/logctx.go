@@ -0,0 +1,131 @@
+package logctx
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
+	"net/http"
+)
+
+// HeaderRequestID is the header the request ids are accepted in and passed on with
+const HeaderRequestID = "X-Request-ID"
+
+const (
+	KeyRequestID = "request_id"
+	KeyUserID    = "user_id"
+	KeyTraceID   = "trace_id"
+	KeySpanID    = "span_id"
+)
+
+// maxRequestIDLength bounds the accepted ids, which end up in every log line of the request
+const maxRequestIDLength = 128
+
+type contextKey int
+
+const (
+	contextRequestID contextKey = iota
+	contextUserID
+)
+
+// NewRequestID generates the id of a request which has come without one
+func NewRequestID() string {
+	return uuid.NewString()
+}
+
+// ValidRequestID reports whether the id given by a client can be accepted, which is only made of
+// the printable ASCII characters and is not too long
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextRequestID, id)
+}
+
+// RequestID returns the id of the request the context belongs to, empty if there is none
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(contextRequestID).(string)
+	return id
+}
+
+func WithUserID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextUserID, id)
+}
+
+// UserID returns the id of the user the request is made by, empty if it is anonymous
+func UserID(ctx context.Context) string {
+	id, _ := ctx.Value(contextUserID).(string)
+	return id
+}
+
+// Handler adds the request and the user ids of the context to the records, so all the lines
+// logged while serving a request can be told by them, along with the ids of the span they are logged in
+type Handler struct {
+	next slog.Handler
+}
+
+func NewHandler(next slog.Handler) *Handler {
+	return &Handler{next: next}
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	if ctx != nil {
+		if id := RequestID(ctx); id != "" {
+			record.AddAttrs(slog.String(KeyRequestID, id))
+		}
+		if id := UserID(ctx); id != "" {
+			record.AddAttrs(slog.String(KeyUserID, id))
+		}
+		if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+			record.AddAttrs(slog.String(KeyTraceID, span.TraceID().String()), slog.String(KeySpanID, span.SpanID().String()))
+		}
+	}
+	return h.next.Handle(ctx, record)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{next: h.next.WithAttrs(attrs)}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name)}
+}
+
+// Transport passes the request id of the context of the outgoing requests on in HeaderRequestID,
+// so the calls made while serving a request can be traced to it
+type Transport struct {
+	next http.RoundTripper
+}
+
+// NewTransport wraps the transport, http.DefaultTransport if it is nil
+func NewTransport(next http.RoundTripper) *Transport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Transport{next: next}
+}
+
+func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	id := RequestID(r.Context())
+	if id == "" || r.Header.Get(HeaderRequestID) != "" {
+		return t.next.RoundTrip(r)
+	}
+
+	// the request must not be changed by the transport, so the header is set on a copy
+	r = r.Clone(r.Context())
+	r.Header.Set(HeaderRequestID, id)
+	return t.next.RoundTrip(r)
+}
@@ -0,0 +1,7 @@
+package mail
+
+import "fmt"
+
+func errSending(err error) error {
+	return fmt.Errorf("%w (sending mail)", err)
+}
@@ -0,0 +1,64 @@
+package openapi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The codes of the ValidationErrors, naming the keyword of the schema the value has failed
+const (
+	CodeRequired    = "required"
+	CodeType        = "type"
+	CodeEnum        = "enum"
+	CodeFormat      = "format"
+	CodeTooShort    = "too_short"
+	CodeTooLong     = "too_long"
+	CodeTooSmall    = "too_small"
+	CodeTooBig      = "too_big"
+	CodeTooFew      = "too_few_items"
+	CodeTooMany     = "too_many_items"
+	CodeNoMatch     = "no_match"
+	CodeInvalidJSON = "invalid_json"
+)
+
+// ValidationError is a value not matching its schema. Path is where the value is, e.g. mutations[0].type,
+// or the name of the parameter
+type ValidationError struct {
+	Path    string
+	Code    string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + " " + e.Message
+}
+
+// ValidationErrors are all the errors of a validated value
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+func errRoutesMismatch(undocumented, unrouted []string) error {
+	var sb strings.Builder
+	sb.WriteString("routes do not match openapi document")
+	if len(undocumented) > 0 {
+		sb.WriteString(fmt.Sprintf("; undocumented routes: %s", strings.Join(undocumented, ", ")))
+	}
+	if len(unrouted) > 0 {
+		sb.WriteString(fmt.Sprintf("; operations without routes: %s", strings.Join(unrouted, ", ")))
+	}
+	return fmt.Errorf("%s", sb.String())
+}
+
+func errReadingBody(err error) error {
+	return fmt.Errorf("%w (reading request body)", err)
+}
@@ -0,0 +1,277 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"github.com/google/uuid"
+	"path"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	uuidType          = reflect.TypeFor[uuid.UUID]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// Generator makes the schemas of the Go types the way encoding/json encodes them. The named struct types
+// of the responses are added to the components of the document and referenced
+type Generator struct {
+	doc   *Document
+	names map[reflect.Type]string
+	enums map[reflect.Type][]any
+}
+
+func NewGenerator(doc *Document) *Generator {
+	return &Generator{
+		doc:   doc,
+		names: make(map[reflect.Type]string),
+		enums: make(map[reflect.Type][]any),
+	}
+}
+
+// Enum registers the values of an enum, which are of the same named type
+func (g *Generator) Enum(values ...any) {
+	if len(values) > 0 {
+		g.enums[reflect.TypeOf(values[0])] = values
+	}
+}
+
+// Schema returns the schema of the value as it is encoded in the responses. The fields are required unless
+// they are omitted when empty
+func (g *Generator) Schema(v any) *Schema {
+	return g.schema(reflect.TypeOf(v), false)
+}
+
+// Input returns the schema of the requests bound to the value, the fields being required and constrained
+// by their binding tags. The schema is inlined, the inputs not being shared between the operations
+func (g *Generator) Input(v any) *Schema {
+	return g.schema(reflect.TypeOf(v), true)
+}
+
+func (g *Generator) schema(t reflect.Type, input bool) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	if t.Kind() == reflect.Pointer {
+		return Nullable(g.schema(t.Elem(), input))
+	}
+
+	switch {
+	case t == timeType:
+		return DateTime()
+	case t == uuidType:
+		return UUID()
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		// the types encoding themselves may be anything
+		return &Schema{}
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return String()
+	}
+
+	var schema *Schema
+	switch t.Kind() {
+	case reflect.Bool:
+		schema = Boolean()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		schema = Integer()
+	case reflect.Float32, reflect.Float64:
+		schema = Number()
+	case reflect.String:
+		schema = String()
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			schema = String()
+			schema.ContentEncoding = "base64"
+		} else {
+			schema = Array(g.schema(t.Elem(), input))
+		}
+	case reflect.Map:
+		schema = &Schema{Type: Types{TypeObject}, AdditionalProperties: g.schema(t.Elem(), input)}
+	case reflect.Struct:
+		if input || t.Name() == "" {
+			return g.object(t, input)
+		}
+		return g.ref(t)
+	default:
+		return &Schema{}
+	}
+
+	if values, ok := g.enums[t]; ok {
+		schema.Enum = slices.Clone(values)
+	}
+	return schema
+}
+
+// ref adds the schema of the named struct type to the components once, referencing it
+func (g *Generator) ref(t reflect.Type) *Schema {
+	if name, ok := g.names[t]; ok {
+		return Ref(name)
+	}
+
+	name := g.componentName(t)
+	g.names[t] = name
+	// the name is taken before the fields are visited, so the recursive types reference themselves
+	schema := &Schema{}
+	g.doc.Components.Schemas[name] = schema
+	*schema = *g.object(t, false)
+	return Ref(name)
+}
+
+func (g *Generator) object(t reflect.Type, input bool) *Schema {
+	schema := &Schema{Type: Types{TypeObject}, Properties: make(map[string]*Schema)}
+	g.fields(schema, t, input, false)
+	return schema
+}
+
+// fields adds the fields of the struct to the schema. The fields of the embedded structs are promoted
+// unless the outer struct has the fields of the same names, like encoding/json does
+func (g *Generator) fields(schema *Schema, t reflect.Type, input, embedded bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.fields(schema, ft, input, true)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if _, ok := schema.Properties[name]; ok && embedded {
+			continue
+		}
+
+		fieldSchema := g.schema(field.Type, input)
+		var required bool
+		if input {
+			required = applyBinding(fieldSchema, field.Tag.Get("binding"))
+		} else {
+			required = !strings.Contains(opts, "omitempty")
+		}
+
+		schema.Properties[name] = fieldSchema
+		schema.Required = slices.DeleteFunc(schema.Required, func(s string) bool { return s == name })
+		if required {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// componentName names the schema of the type after it, the generic types after their arguments,
+// e.g. Page[domain.Link] is named LinkPage
+func (g *Generator) componentName(t reflect.Type) string {
+	name := t.Name()
+	if i := strings.IndexByte(name, '['); i >= 0 {
+		var args string
+		for _, arg := range strings.Split(name[i+1:len(name)-1], ",") {
+			args += exportedName(arg[strings.LastIndexByte(arg, '.')+1:])
+		}
+		name = args + name[:i]
+	}
+	name = exportedName(name)
+
+	if g.taken(name) {
+		// the types of the same names from the different packages are told apart by the packages
+		name = exportedName(path.Base(t.PkgPath())) + name
+		for base, i := name, 2; g.taken(name); i++ {
+			name = base + strconv.Itoa(i)
+		}
+	}
+	return name
+}
+
+func (g *Generator) taken(name string) bool {
+	_, ok := g.doc.Components.Schemas[name]
+	return ok
+}
+
+// applyBinding constrains the schema by the rules of the binding tag, the rules following dive constraining
+// the items of the slices. It reports whether the value is required
+func applyBinding(schema *Schema, tag string) bool {
+	if tag == "" {
+		return false
+	}
+
+	var required bool
+	target := schema
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = required || target == schema
+		case "dive":
+			if target.Items == nil {
+				return required
+			}
+			target = target.Items
+		case "min", "max":
+			limit, err := strconv.ParseFloat(param, 64)
+			if err == nil {
+				applyLimit(target, name == "min", limit)
+			}
+		case "oneof":
+			values := strings.Fields(param)
+			target.Enum = make([]any, 0, len(values))
+			for _, v := range values {
+				target.Enum = append(target.Enum, v)
+			}
+		}
+	}
+	return required
+}
+
+// applyLimit applies the min and max rules, which limit the length of the strings, the values of the numbers
+// and the number of the items of the slices
+func applyLimit(schema *Schema, isMin bool, limit float64) {
+	n := int(limit)
+	switch {
+	case schema.Type.Has(TypeString):
+		if isMin {
+			schema.MinLength = &n
+		} else {
+			schema.MaxLength = &n
+		}
+	case schema.Type.Has(TypeInteger), schema.Type.Has(TypeNumber):
+		if isMin {
+			schema.Minimum = &limit
+		} else {
+			schema.Maximum = &limit
+		}
+	case schema.Type.Has(TypeArray):
+		if isMin {
+			schema.MinItems = &n
+		} else {
+			schema.MaxItems = &n
+		}
+	}
+}
+
+func exportedName(name string) string {
+	if name == "" {
+		return name
+	}
+	r := []rune(name)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
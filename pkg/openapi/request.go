@@ -0,0 +1,99 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+)
+
+// bodyPath is the path of the errors of the request body itself, the errors of its fields being named after them
+const bodyPath = "body"
+
+// ValidateRequest validates the parameters and the JSON body of the request against the operation, the errors
+// of the request being ValidationErrors. The body is put back after it is read, so the handlers can read it again.
+// The bodies of the other media types, like the forms and the files, are left to the handlers
+func (d *Document) ValidateRequest(op *Operation, r *http.Request, pathParams map[string]string) error {
+	var errs ValidationErrors
+	query := r.URL.Query()
+	for _, param := range op.Parameters {
+		var raw string
+		switch param.In {
+		case InPath:
+			raw = pathParams[param.Name]
+		case InQuery:
+			raw = query.Get(param.Name)
+		case InHeader:
+			raw = r.Header.Get(param.Name)
+		}
+
+		if raw == "" {
+			if param.Required {
+				addError(&errs, param.Name, CodeRequired, "is required")
+			}
+			continue
+		}
+
+		value, ok := parseParam(param.Schema, raw)
+		if !ok {
+			addError(&errs, param.Name, CodeType, "must be "+joinTypes(param.Schema.Type))
+			continue
+		}
+		d.validate(param.Schema, value, param.Name, &errs)
+	}
+
+	if op.RequestBody != nil {
+		if err := d.validateBody(op.RequestBody, r, &errs); err != nil {
+			return err
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (d *Document) validateBody(body *RequestBody, r *http.Request, errs *ValidationErrors) error {
+	content, ok := body.Content[MediaTypeJSON]
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if !ok || mediaType != MediaTypeJSON {
+		return nil
+	}
+
+	var data []byte
+	if r.Body != nil {
+		var err error
+		if data, err = io.ReadAll(r.Body); err != nil {
+			return errReadingBody(err)
+		}
+		_ = r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(data))
+	}
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		if body.Required {
+			addError(errs, bodyPath, CodeRequired, "is required")
+		}
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		addError(errs, bodyPath, CodeInvalidJSON, "is not valid JSON")
+		return nil
+	}
+
+	var bodyErrs ValidationErrors
+	d.validate(content.Schema, value, "", &bodyErrs)
+	for _, err := range bodyErrs {
+		if err.Path == "" {
+			err.Path = bodyPath
+		}
+	}
+	*errs = append(*errs, bodyErrs...)
+	return nil
+}
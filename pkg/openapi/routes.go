@@ -0,0 +1,62 @@
+package openapi
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// routeParam matches the named and the catch-all parameters of the router paths, e.g. :id and *key
+var routeParam = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+
+// Route is a route of a router, its path in the syntax of the router, e.g. /links/:id
+type Route struct {
+	Method string
+	Path   string
+}
+
+// PathOf converts the path of a router into the templated path of the document, e.g. /links/:id into
+// /links/{id}. The catch-all parameters become the plain ones, which is as close as OpenAPI gets
+func PathOf(routePath string) string {
+	return routeParam.ReplaceAllString(routePath, "{$1}")
+}
+
+// ParamNames returns the names of the parameters of the router path in the order they appear
+func ParamNames(routePath string) []string {
+	matches := routeParam.FindAllStringSubmatch(routePath, -1)
+	names := make([]string, 0, len(matches))
+	for _, m := range matches {
+		names = append(names, m[1])
+	}
+	return names
+}
+
+// CheckRoutes reports both the routes the document does not describe and the operations of the document
+// no route serves
+func (d *Document) CheckRoutes(routes []Route) error {
+	routed := make(map[string]bool, len(routes))
+	var undocumented []string
+	for _, route := range routes {
+		path := PathOf(route.Path)
+		routed[strings.ToUpper(route.Method)+" "+path] = true
+		if d.Operation(route.Method, path) == nil {
+			undocumented = append(undocumented, strings.ToUpper(route.Method)+" "+route.Path)
+		}
+	}
+
+	var unrouted []string
+	for path, item := range d.Paths {
+		for method := range *item {
+			if key := strings.ToUpper(method) + " " + path; !routed[key] {
+				unrouted = append(unrouted, key)
+			}
+		}
+	}
+
+	if len(undocumented) == 0 && len(unrouted) == 0 {
+		return nil
+	}
+	slices.Sort(undocumented)
+	slices.Sort(unrouted)
+	return errRoutesMismatch(undocumented, unrouted)
+}
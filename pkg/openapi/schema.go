@@ -0,0 +1,125 @@
+package openapi
+
+import "encoding/json"
+
+const refPrefix = "#/components/schemas/"
+
+const (
+	TypeObject  = "object"
+	TypeArray   = "array"
+	TypeString  = "string"
+	TypeInteger = "integer"
+	TypeNumber  = "number"
+	TypeBoolean = "boolean"
+	TypeNull    = "null"
+)
+
+const (
+	FormatUUID     = "uuid"
+	FormatDateTime = "date-time"
+)
+
+// Schema is the subset of JSON Schema the documents use. The empty schema allows any value
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 Types              `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	ContentEncoding      string             `json:"contentEncoding,omitempty"`
+}
+
+// Types are the types a value of the schema may have, encoded as a single string if there is only one
+type Types []string
+
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+func (t Types) Has(name string) bool {
+	for _, typ := range t {
+		if typ == name {
+			return true
+		}
+	}
+	return false
+}
+
+func Ref(name string) *Schema {
+	return &Schema{Ref: refPrefix + name}
+}
+
+func String() *Schema {
+	return &Schema{Type: Types{TypeString}}
+}
+
+func Integer() *Schema {
+	return &Schema{Type: Types{TypeInteger}}
+}
+
+func Number() *Schema {
+	return &Schema{Type: Types{TypeNumber}}
+}
+
+func Boolean() *Schema {
+	return &Schema{Type: Types{TypeBoolean}}
+}
+
+func UUID() *Schema {
+	return &Schema{Type: Types{TypeString}, Format: FormatUUID}
+}
+
+func DateTime() *Schema {
+	return &Schema{Type: Types{TypeString}, Format: FormatDateTime}
+}
+
+func Array(items *Schema) *Schema {
+	return &Schema{Type: Types{TypeArray}, Items: items}
+}
+
+// Binary is the schema of the content which is not JSON, e.g. a file
+func Binary() *Schema {
+	return &Schema{Type: Types{TypeString}, Format: "binary"}
+}
+
+// Nullable lets the value of the schema be null as well
+func Nullable(schema *Schema) *Schema {
+	switch {
+	case schema.Ref != "" || len(schema.AnyOf) > 0:
+		return &Schema{AnyOf: []*Schema{schema, {Type: Types{TypeNull}}}}
+	case len(schema.Type) == 0:
+		// the empty schema allows null already
+		return schema
+	case !schema.Type.Has(TypeNull):
+		schema.Type = append(schema.Type, TypeNull)
+		if schema.Enum != nil {
+			schema.Enum = append(schema.Enum, nil)
+		}
+	}
+	return schema
+}
+
+// WithEnum whitelists the values of the schema
+func (s *Schema) WithEnum(values ...any) *Schema {
+	s.Enum = values
+	return s
+}
+
+func (s *Schema) WithDescription(description string) *Schema {
+	s.Description = description
+	return s
+}
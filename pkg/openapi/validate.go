@@ -0,0 +1,204 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Validate validates the value decoded from JSON, with the numbers decoded as json.Number, against the schema.
+// The errors are ValidationErrors
+func (d *Document) Validate(schema *Schema, value any) error {
+	var errs ValidationErrors
+	d.validate(schema, value, "", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (d *Document) validate(schema *Schema, value any, path string, errs *ValidationErrors) {
+	schema = d.Resolve(schema)
+	if schema == nil {
+		return
+	}
+
+	if len(schema.AnyOf) > 0 {
+		for _, s := range schema.AnyOf {
+			var sub ValidationErrors
+			if d.validate(s, value, path, &sub); len(sub) == 0 {
+				return
+			}
+		}
+		addError(errs, path, CodeNoMatch, "does not match any of the allowed schemas")
+		return
+	}
+
+	typ := typeOf(value)
+	if len(schema.Type) > 0 && !schema.Type.Has(typ) && !(typ == TypeInteger && schema.Type.Has(TypeNumber)) {
+		addError(errs, path, CodeType, "must be "+joinTypes(schema.Type))
+		return
+	}
+	if schema.Enum != nil && !inEnum(schema.Enum, value) {
+		addError(errs, path, CodeEnum, "must be one of "+joinValues(schema.Enum))
+		return
+	}
+
+	switch v := value.(type) {
+	case string:
+		d.validateString(schema, v, path, errs)
+	case json.Number:
+		validateNumber(schema, v, path, errs)
+	case []any:
+		if schema.MinItems != nil && len(v) < *schema.MinItems {
+			addError(errs, path, CodeTooFew, fmt.Sprintf("must have at least %d items", *schema.MinItems))
+		}
+		if schema.MaxItems != nil && len(v) > *schema.MaxItems {
+			addError(errs, path, CodeTooMany, fmt.Sprintf("must have at most %d items", *schema.MaxItems))
+		}
+		for i, item := range v {
+			d.validate(schema.Items, item, fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	case map[string]any:
+		for _, name := range schema.Required {
+			if _, ok := v[name]; !ok {
+				addError(errs, joinPath(path, name), CodeRequired, "is required")
+			}
+		}
+		for name, property := range v {
+			if s, ok := schema.Properties[name]; ok {
+				d.validate(s, property, joinPath(path, name), errs)
+			} else if schema.AdditionalProperties != nil {
+				d.validate(schema.AdditionalProperties, property, joinPath(path, name), errs)
+			}
+		}
+	}
+}
+
+func (d *Document) validateString(schema *Schema, v string, path string, errs *ValidationErrors) {
+	length := utf8.RuneCountInString(v)
+	if schema.MinLength != nil && length < *schema.MinLength {
+		addError(errs, path, CodeTooShort, fmt.Sprintf("must be at least %d characters long", *schema.MinLength))
+	}
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		addError(errs, path, CodeTooLong, fmt.Sprintf("must be at most %d characters long", *schema.MaxLength))
+	}
+
+	switch schema.Format {
+	case FormatUUID:
+		if _, err := uuid.Parse(v); err != nil {
+			addError(errs, path, CodeFormat, "must be a uuid")
+		}
+	case FormatDateTime:
+		if _, err := time.Parse(time.RFC3339, v); err != nil {
+			addError(errs, path, CodeFormat, "must be an RFC 3339 date-time")
+		}
+	}
+}
+
+func validateNumber(schema *Schema, v json.Number, path string, errs *ValidationErrors) {
+	f, err := v.Float64()
+	if err != nil {
+		addError(errs, path, CodeType, "must be a number")
+		return
+	}
+	if schema.Minimum != nil && f < *schema.Minimum {
+		addError(errs, path, CodeTooSmall, fmt.Sprintf("must be at least %v", *schema.Minimum))
+	}
+	if schema.Maximum != nil && f > *schema.Maximum {
+		addError(errs, path, CodeTooBig, fmt.Sprintf("must be at most %v", *schema.Maximum))
+	}
+}
+
+// parseParam parses the raw value of a parameter into the value of its type, the way it is decoded from JSON
+func parseParam(schema *Schema, raw string) (any, bool) {
+	switch {
+	case schema == nil:
+		return raw, true
+	case schema.Type.Has(TypeInteger):
+		_, err := strconv.ParseInt(raw, 10, 64)
+		return json.Number(raw), err == nil
+	case schema.Type.Has(TypeNumber):
+		_, err := strconv.ParseFloat(raw, 64)
+		return json.Number(raw), err == nil
+	case schema.Type.Has(TypeBoolean):
+		b, err := strconv.ParseBool(raw)
+		return b, err == nil
+	default:
+		return raw, true
+	}
+}
+
+func typeOf(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return TypeNull
+	case bool:
+		return TypeBoolean
+	case json.Number:
+		if strings.ContainsAny(v.String(), ".eE") {
+			return TypeNumber
+		}
+		return TypeInteger
+	case float64:
+		return TypeNumber
+	case string:
+		return TypeString
+	case []any:
+		return TypeArray
+	case map[string]any:
+		return TypeObject
+	default:
+		return ""
+	}
+}
+
+func inEnum(enum []any, value any) bool {
+	for _, e := range enum {
+		if (e == nil && value == nil) || (e != nil && value != nil && fmt.Sprint(e) == fmt.Sprint(value)) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinTypes(types Types) string {
+	names := make([]string, 0, len(types))
+	for _, t := range types {
+		if t == TypeInteger || t == TypeArray || t == TypeObject {
+			names = append(names, "an "+t)
+		} else if t != TypeNull {
+			names = append(names, "a "+t)
+		} else {
+			names = append(names, t)
+		}
+	}
+	return strings.Join(names, " or ")
+}
+
+func joinValues(values []any) string {
+	s := make([]string, 0, len(values))
+	for _, v := range values {
+		if v == nil {
+			s = append(s, "null")
+		} else {
+			s = append(s, fmt.Sprint(v))
+		}
+	}
+	return strings.Join(s, ", ")
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func addError(errs *ValidationErrors, path, code, message string) {
+	*errs = append(*errs, &ValidationError{Path: path, Code: code, Message: message})
+}
@@ -0,0 +1,35 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// Cursor points at the item the page starts after (or ends before). It is bound to the sort
+// it was issued for, so it cannot be reused with another sort
+type Cursor struct {
+	Sort string `json:"s"`
+	Desc bool   `json:"d,omitempty"`
+	Key  any    `json:"k"`
+	ID   string `json:"i"`
+}
+
+func EncodeCursor(c Cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(s string) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, errInvalidCursor(err)
+	}
+
+	var c Cursor
+	if err = json.Unmarshal(data, &c); err != nil {
+		return Cursor{}, errInvalidCursor(err)
+	} else if c.ID == "" {
+		return Cursor{}, errInvalidCursor(nil)
+	}
+	return c, nil
+}
@@ -0,0 +1,48 @@
+package pagination
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidParams is wrapped by all the errors of parsing the pagination, filter and sort parameters
+var ErrInvalidParams = errors.New("invalid pagination parameters")
+
+func errInvalidCursor(err error) error {
+	if err == nil {
+		return fmt.Errorf("%w: invalid cursor", ErrInvalidParams)
+	}
+	return fmt.Errorf("%w: invalid cursor (%s)", ErrInvalidParams, err)
+}
+
+func errCursorSortMismatch(cursorSort, sort string) error {
+	return fmt.Errorf("%w: cursor was issued for sort %s, not %s", ErrInvalidParams, cursorSort, sort)
+}
+
+func errBothCursors() error {
+	return fmt.Errorf("%w: only one of after and before can be set", ErrInvalidParams)
+}
+
+func errInvalidLimit(limit string) error {
+	return fmt.Errorf("%w: invalid limit %s", ErrInvalidParams, limit)
+}
+
+func errUnsortableField(field string) error {
+	return fmt.Errorf("%w: cannot sort by %s", ErrInvalidParams, field)
+}
+
+func errUnsupportedOperator(field string, op Operator) error {
+	return fmt.Errorf("%w: cannot filter %s with %s", ErrInvalidParams, field, op)
+}
+
+func errInvalidValue(field, value string, err error) error {
+	return fmt.Errorf("%w: invalid %s value '%s' (%s)", ErrInvalidParams, field, value, err)
+}
+
+func errUnknownValue(field, value string) error {
+	return fmt.Errorf("%w: unknown %s value '%s'", ErrInvalidParams, field, value)
+}
+
+func errUnknownColumn(field string) error {
+	return fmt.Errorf("no column for field %s", field)
+}
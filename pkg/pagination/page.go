@@ -0,0 +1,66 @@
+package pagination
+
+import "slices"
+
+type Info struct {
+	Limit      int    `json:"limit"`
+	HasNext    bool   `json:"has_next"`
+	HasPrev    bool   `json:"has_prev"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+// Page is the response envelope of the collection endpoints
+type Page[T any] struct {
+	Data []T  `json:"data"`
+	Page Info `json:"page"`
+}
+
+// KeyFunc returns the value of the sort field and the id of the item
+type KeyFunc[T any] func(item T, sort string) (key any, id string)
+
+// NewPage makes a page of the items selected by the query built with Params.Apply
+func NewPage[T any](items []T, p Params, keyOf KeyFunc[T]) Page[T] {
+	hasMore := len(items) > p.Limit
+	if hasMore {
+		items = items[:p.Limit]
+	}
+
+	info := Info{Limit: p.Limit}
+	if p.backwards() {
+		slices.Reverse(items)
+		info.HasPrev = hasMore
+		info.HasNext = true
+	} else {
+		info.HasNext = hasMore
+		info.HasPrev = p.After != nil
+	}
+
+	if len(items) > 0 {
+		if info.HasNext {
+			info.NextCursor = cursorOf(items[len(items)-1], p, keyOf)
+		}
+		if info.HasPrev {
+			info.PrevCursor = cursorOf(items[0], p, keyOf)
+		}
+	}
+
+	if items == nil {
+		items = []T{}
+	}
+	return Page[T]{Data: items, Page: info}
+}
+
+func cursorOf[T any](item T, p Params, keyOf KeyFunc[T]) string {
+	key, id := keyOf(item, p.Sort)
+	return EncodeCursor(Cursor{Sort: p.Sort, Desc: p.Desc, Key: key, ID: id})
+}
+
+// MapPage converts the items of the page keeping its info
+func MapPage[T, U any](p Page[T], fn func(item T) U) Page[U] {
+	data := make([]U, 0, len(p.Data))
+	for _, item := range p.Data {
+		data = append(data, fn(item))
+	}
+	return Page[U]{Data: data, Page: p.Page}
+}
@@ -0,0 +1,202 @@
+package pagination
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ParamLimit  = "limit"
+	ParamSort   = "sort"
+	ParamAfter  = "after"
+	ParamBefore = "before"
+)
+
+type Type int
+
+const (
+	TypeString Type = iota
+	TypeTime
+	TypeInt
+	TypeFloat
+	TypeBool
+	TypeUUID
+)
+
+type Operator string
+
+const (
+	OpEq       Operator = "eq"
+	OpNe       Operator = "ne"
+	OpGt       Operator = "gt"
+	OpGte      Operator = "gte"
+	OpLt       Operator = "lt"
+	OpLte      Operator = "lte"
+	OpContains Operator = "contains"
+)
+
+type Field struct {
+	Type     Type
+	Sortable bool
+	// Operators are the operators the field can be filtered with, the field is not filterable if it is empty
+	Operators []Operator
+	// Values whitelists the values the field can be filtered by, any value is allowed if it is empty
+	Values []string
+}
+
+// Spec whitelists the fields a collection can be sorted and filtered by
+type Spec struct {
+	Fields       map[string]Field
+	DefaultSort  string
+	DefaultDesc  bool
+	DefaultLimit int
+	MaxLimit     int
+}
+
+type Filter struct {
+	Field string
+	Op    Operator
+	Value any
+}
+
+type Params struct {
+	Limit   int
+	Sort    string
+	Desc    bool
+	After   *Cursor
+	Before  *Cursor
+	Filters []Filter
+}
+
+// Parse parses the limit, sort (a field name, prefixed with '-' for the descending order), after and before
+// parameters and the filters, which are either field=value or field[operator]=value. Other parameters are ignored
+func (s Spec) Parse(values url.Values) (Params, error) {
+	params := Params{
+		Limit: s.DefaultLimit,
+		Sort:  s.DefaultSort,
+		Desc:  s.DefaultDesc,
+	}
+
+	if raw := values.Get(ParamLimit); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			return Params{}, errInvalidLimit(raw)
+		}
+		params.Limit = min(limit, s.MaxLimit)
+	}
+
+	if raw := values.Get(ParamSort); raw != "" {
+		name := strings.TrimPrefix(raw, "-")
+		if field, ok := s.Fields[name]; !ok || !field.Sortable {
+			return Params{}, errUnsortableField(name)
+		}
+		params.Sort = name
+		params.Desc = strings.HasPrefix(raw, "-")
+	}
+
+	after, before := values.Get(ParamAfter), values.Get(ParamBefore)
+	if after != "" && before != "" {
+		return Params{}, errBothCursors()
+	}
+
+	var err error
+	if after != "" {
+		if params.After, err = s.parseCursor(after, params); err != nil {
+			return Params{}, err
+		}
+	} else if before != "" {
+		if params.Before, err = s.parseCursor(before, params); err != nil {
+			return Params{}, err
+		}
+	}
+
+	if params.Filters, err = s.parseFilters(values); err != nil {
+		return Params{}, err
+	}
+	return params, nil
+}
+
+func (s Spec) parseCursor(raw string, params Params) (*Cursor, error) {
+	cursor, err := DecodeCursor(raw)
+	if err != nil {
+		return nil, err
+	} else if cursor.Sort != params.Sort || cursor.Desc != params.Desc {
+		return nil, errCursorSortMismatch(sortString(cursor.Sort, cursor.Desc), sortString(params.Sort, params.Desc))
+	}
+
+	// json decodes the key as a string, a number or a bool, so restore its type
+	if cursor.Key, err = parseValue(s.Fields[cursor.Sort].Type, fmt.Sprint(cursor.Key)); err != nil {
+		return nil, errInvalidCursor(err)
+	}
+	return &cursor, nil
+}
+
+func (s Spec) parseFilters(values url.Values) ([]Filter, error) {
+	filters := make([]Filter, 0)
+	for key, vals := range values {
+		name, op := key, OpEq
+		if i := strings.IndexByte(key, '['); i > 0 && strings.HasSuffix(key, "]") {
+			name, op = key[:i], Operator(key[i+1:len(key)-1])
+		}
+
+		field, ok := s.Fields[name]
+		if !ok || len(field.Operators) == 0 {
+			continue
+		} else if !supports(field, op) {
+			return nil, errUnsupportedOperator(name, op)
+		}
+
+		for _, raw := range vals {
+			if len(field.Values) > 0 && !slices.Contains(field.Values, raw) {
+				return nil, errUnknownValue(name, raw)
+			}
+			value, err := parseValue(field.Type, raw)
+			if err != nil {
+				return nil, errInvalidValue(name, raw, err)
+			}
+			filters = append(filters, Filter{Field: name, Op: op, Value: value})
+		}
+	}
+	return filters, nil
+}
+
+func supports(field Field, op Operator) bool {
+	for _, supported := range field.Operators {
+		if supported == op {
+			return true
+		}
+	}
+	return false
+}
+
+func parseValue(t Type, raw string) (any, error) {
+	switch t {
+	case TypeTime:
+		return time.Parse(time.RFC3339Nano, raw)
+	case TypeInt:
+		// the keys of the cursors may be encoded as floats
+		f, err := strconv.ParseFloat(raw, 64)
+		return int64(f), err
+	case TypeFloat:
+		return strconv.ParseFloat(raw, 64)
+	case TypeBool:
+		return strconv.ParseBool(raw)
+	case TypeUUID:
+		id, err := uuid.Parse(raw)
+		return id.String(), err
+	default:
+		return raw, nil
+	}
+}
+
+func sortString(sort string, desc bool) string {
+	if desc {
+		return "-" + sort
+	}
+	return sort
+}
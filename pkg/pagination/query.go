@@ -0,0 +1,86 @@
+package pagination
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Columns maps the field names to SQL expressions. An expression containing %s is a whole condition
+// with %s standing for the filter value, e.g. a subquery, and can only be used with OpEq
+type Columns map[string]string
+
+var operators = map[Operator]string{
+	OpEq:       "=",
+	OpNe:       "<>",
+	OpGt:       ">",
+	OpGte:      ">=",
+	OpLt:       "<",
+	OpLte:      "<=",
+	OpContains: "ILIKE",
+}
+
+// Apply appends the filters, the keyset condition, the order and the limit to the query. The query must end
+// with a WHERE clause, since the conditions are appended with AND, and its arguments must be numbered
+// from $1 to $len(args). One extra row is selected to find out whether there is another page
+func (p Params) Apply(query string, args []any, columns Columns, idColumn string) (string, []any, error) {
+	var sb strings.Builder
+	sb.WriteString(query)
+
+	placeholder := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	for _, filter := range p.Filters {
+		column, ok := columns[filter.Field]
+		if !ok {
+			return "", nil, errUnknownColumn(filter.Field)
+		}
+
+		if strings.Contains(column, "%s") {
+			fmt.Fprintf(&sb, " AND "+column, placeholder(filter.Value))
+			continue
+		}
+
+		value := filter.Value
+		if filter.Op == OpContains {
+			value = "%" + escapeLike(fmt.Sprint(value)) + "%"
+		}
+		fmt.Fprintf(&sb, " AND %s %s %s", column, operators[filter.Op], placeholder(value))
+	}
+
+	sortColumn, ok := columns[p.Sort]
+	if !ok {
+		return "", nil, errUnknownColumn(p.Sort)
+	}
+
+	// paging backwards selects the rows in the reversed order, NewPage restores it
+	desc := p.Desc != p.backwards()
+	comparison, order := ">", "ASC"
+	if desc {
+		comparison, order = "<", "DESC"
+	}
+
+	if cursor := p.cursor(); cursor != nil {
+		fmt.Fprintf(&sb, " AND (%s, %s) %s (%s, %s)", sortColumn, idColumn, comparison,
+			placeholder(cursor.Key), placeholder(cursor.ID))
+	}
+
+	fmt.Fprintf(&sb, " ORDER BY %s %s, %s %s LIMIT %s", sortColumn, order, idColumn, order, placeholder(p.Limit+1))
+	return sb.String(), args, nil
+}
+
+func (p Params) cursor() *Cursor {
+	if p.After != nil {
+		return p.After
+	}
+	return p.Before
+}
+
+func (p Params) backwards() bool {
+	return p.Before != nil
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
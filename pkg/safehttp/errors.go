@@ -0,0 +1,7 @@
+package safehttp
+
+import "fmt"
+
+func errForbiddenAddress(host string) error {
+	return fmt.Errorf("connecting to %s is forbidden", host)
+}
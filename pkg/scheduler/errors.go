@@ -0,0 +1,28 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrUnknownTask = errors.New("unknown task")
+	// ErrLocked is returned when the task is being run already, by this or another instance
+	ErrLocked = errors.New("task is locked")
+	// ErrLockLost is returned by the lock refreshes once the lock is held by another instance
+	ErrLockLost = errors.New("task lock has been lost")
+	// ErrAlreadyRun is returned when the scheduled run has been started by another instance
+	ErrAlreadyRun = errors.New("scheduled run has already been started")
+)
+
+func errDuplicateTask(name string) error {
+	return fmt.Errorf("task %s has already been added", name)
+}
+
+func errAddingTask(name string, err error) error {
+	return fmt.Errorf("%w (adding task %s)", err, name)
+}
+
+func errPanicked(name string, v any) error {
+	return fmt.Errorf("%s task panicked: %v", name, v)
+}
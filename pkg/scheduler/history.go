@@ -0,0 +1,53 @@
+package scheduler
+
+import (
+	"context"
+	"github.com/adanyl0v/go-pocket-link/pkg/pagination"
+	"time"
+)
+
+// The triggers of the runs
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+// The statuses of the runs
+const (
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// Run is a single run of a task
+type Run struct {
+	ID      string `json:"id" db:"id"`
+	Task    string `json:"task" db:"task"`
+	Trigger string `json:"trigger" db:"triggered_by"`
+	// ScheduledAt is the time the scheduled run is due at, nil for the manual runs
+	ScheduledAt *time.Time `json:"scheduled_at" db:"scheduled_at"`
+	// Instance is the instance the task has been run by
+	Instance string `json:"instance" db:"instance"`
+	Status   string `json:"status" db:"status"`
+	// Processed is how many items the run has processed, e.g. how many accounts it has purged
+	Processed  int64      `json:"processed" db:"processed"`
+	Error      string     `json:"error,omitempty" db:"error"`
+	StartedAt  time.Time  `json:"started_at" db:"started_at"`
+	FinishedAt *time.Time `json:"finished_at" db:"finished_at"`
+}
+
+// History stores the runs of the tasks
+type History interface {
+	// Start records the run, setting its id. ErrAlreadyRun is returned if the scheduled run has been
+	// started already, e.g. by another instance. The runs of the task still running are marked as failed,
+	// since they are left by the instances that have died, the lock of the task being held by the caller
+	Start(ctx context.Context, run *Run) error
+	// Finish records the status, the result and the finish time of the run
+	Finish(ctx context.Context, run Run) error
+	// Get returns a page of the runs of the task
+	Get(ctx context.Context, task string, params pagination.Params) (pagination.Page[Run], error)
+	// GetLast returns the last run of each task that has been run
+	GetLast(ctx context.Context) ([]Run, error)
+	// Prune deletes the runs of the task started before the time
+	Prune(ctx context.Context, task string, before time.Time) error
+}
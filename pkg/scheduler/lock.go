@@ -0,0 +1,94 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Locker makes sure each task is run by one instance at a time
+type Locker interface {
+	// TryLock takes the lock of the task without waiting for it, returning ErrLocked if it is held
+	TryLock(ctx context.Context, name string) (Lock, error)
+}
+
+// Lock is held until it is unlocked or lost, e.g. as the connection holding it breaks
+type Lock interface {
+	// Lost is closed once the lock is lost, the task being possibly run by another instance then
+	Lost() <-chan struct{}
+	Unlock(ctx context.Context) error
+}
+
+// refreshedLock refreshes the lock every interval while it is held, e.g. extending its expiry
+type refreshedLock struct {
+	refresh, release func(ctx context.Context) error
+
+	lost         chan struct{}
+	stop         chan struct{}
+	stopped      chan struct{}
+	unlockOnce   sync.Once
+	unlockResult error
+}
+
+// NewLock makes the Lock of the lockers, which call refresh every interval while the lock is held
+// and release once it is unlocked. The lock is lost when refresh fails with ErrLockLost, or keeps
+// failing for longer than the timeout
+func NewLock(interval, timeout time.Duration, refresh, release func(ctx context.Context) error) Lock {
+	l := &refreshedLock{
+		refresh: refresh,
+		release: release,
+		lost:    make(chan struct{}),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go l.keepAlive(interval, timeout)
+	return l
+}
+
+func (l *refreshedLock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// Unlock stops refreshing the lock before releasing it, so it is not refreshed once released
+func (l *refreshedLock) Unlock(ctx context.Context) error {
+	l.unlockOnce.Do(func() {
+		close(l.stop)
+		<-l.stopped
+		l.unlockResult = l.release(ctx)
+	})
+	return l.unlockResult
+}
+
+func (l *refreshedLock) keepAlive(interval, timeout time.Duration) {
+	defer close(l.stopped)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	refreshedAt := time.Now()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		err := l.refresh(ctx)
+		cancel()
+		switch {
+		case err == nil:
+			refreshedAt = time.Now()
+			continue
+		case errors.Is(err, ErrLockLost):
+		case time.Since(refreshedAt) < timeout:
+			slog.Warn("failed to refresh task lock", "error", err)
+			continue
+		}
+
+		slog.Warn("lost task lock", "error", err)
+		close(l.lost)
+		return
+	}
+}
@@ -0,0 +1,74 @@
+package postgres
+
+import (
+	"context"
+	"github.com/adanyl0v/go-pocket-link/pkg/database/postgres"
+	"github.com/adanyl0v/go-pocket-link/pkg/pagination"
+	"github.com/adanyl0v/go-pocket-link/pkg/scheduler"
+	"time"
+)
+
+// History stores the runs in the task_runs table
+type History struct {
+	db *postgres.DB
+}
+
+func NewHistory(db *postgres.DB) *History {
+	return &History{db: db}
+}
+
+func (h *History) Start(ctx context.Context, run *scheduler.Run) error {
+	err := h.db.Update(ctx, `UPDATE task_runs SET status = $2, error = 'interrupted', finished_at = now()
+		WHERE task = $1 AND status = $3`, run.Task, scheduler.StatusFailed, scheduler.StatusRunning)
+	if err != nil {
+		return err
+	}
+
+	err = h.db.Save(ctx, &run.ID, `INSERT INTO task_runs(task, triggered_by, scheduled_at, instance, status, started_at)
+		VALUES (:task, :triggered_by, :scheduled_at, :instance, :status, :started_at) RETURNING id`, run)
+	if postgres.ErrorCode(err) == postgres.ErrCodeUniqueViolation {
+		return scheduler.ErrAlreadyRun
+	}
+	return err
+}
+
+func (h *History) Finish(ctx context.Context, run scheduler.Run) error {
+	return h.db.UpdateNamed(ctx, `UPDATE task_runs SET status = :status, processed = :processed, error = :error,
+		finished_at = :finished_at WHERE id = :id`, run)
+}
+
+// runsColumns are the columns of the fields the runs can be sorted and filtered by
+var runsColumns = pagination.Columns{
+	"started_at": "started_at",
+	"status":     "status",
+	"trigger":    "triggered_by",
+}
+
+func (h *History) Get(ctx context.Context, task string, params pagination.Params) (pagination.Page[scheduler.Run], error) {
+	query, args, err := params.Apply(`SELECT * FROM task_runs WHERE task = $1`, []any{task}, runsColumns, "id")
+	if err != nil {
+		return pagination.Page[scheduler.Run]{}, err
+	}
+
+	runs := make([]scheduler.Run, 0, params.Limit+1)
+	if err = h.db.Select(ctx, &runs, query, args...); err != nil {
+		return pagination.Page[scheduler.Run]{}, err
+	}
+	return pagination.NewPage(runs, params, func(run scheduler.Run, _ string) (any, string) {
+		return run.StartedAt, run.ID
+	}), nil
+}
+
+func (h *History) GetLast(ctx context.Context) ([]scheduler.Run, error) {
+	runs := make([]scheduler.Run, 0)
+	err := h.db.Select(ctx, &runs, `SELECT DISTINCT ON (task) * FROM task_runs ORDER BY task, started_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+func (h *History) Prune(ctx context.Context, task string, before time.Time) error {
+	return h.db.Delete(ctx, `DELETE FROM task_runs WHERE task = $1 AND started_at < $2 AND status <> $3`,
+		task, before, scheduler.StatusRunning)
+}
@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"context"
+	"github.com/adanyl0v/go-pocket-link/pkg/database/postgres"
+	"github.com/adanyl0v/go-pocket-link/pkg/scheduler"
+	"time"
+)
+
+// keepAliveInterval is how often the connection holding the lock is checked
+const keepAliveInterval = 10 * time.Second
+
+// Locker locks the tasks with session advisory locks, keyed by the hashes of their names.
+// Each lock holds a connection of the pool, and is released by postgres if the connection breaks
+type Locker struct {
+	db *postgres.DB
+}
+
+func NewLocker(db *postgres.DB) *Locker {
+	return &Locker{db: db}
+}
+
+func (l *Locker) TryLock(ctx context.Context, name string) (scheduler.Lock, error) {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	key := "scheduler:" + name
+	var locked bool
+	if err = conn.Get(ctx, &locked, `SELECT pg_try_advisory_lock(hashtextextended($1, 0))`, key); err != nil {
+		conn.Discard()
+		return nil, err
+	} else if !locked {
+		_ = conn.Close()
+		return nil, scheduler.ErrLocked
+	}
+
+	refresh := func(ctx context.Context) error {
+		return conn.Exec(ctx, `SELECT 1`)
+	}
+	release := func(ctx context.Context) error {
+		var unlocked bool
+		if err := conn.Get(ctx, &unlocked, `SELECT pg_advisory_unlock(hashtextextended($1, 0))`, key); err != nil || !unlocked {
+			// the session may still hold the lock, so it is not given to the other users of the pool
+			conn.Discard()
+			return err
+		}
+		return conn.Close()
+	}
+	// the lock lives as long as the session, so it is only lost along with the connection
+	return scheduler.NewLock(keepAliveInterval, keepAliveInterval, refresh, release), nil
+}
@@ -0,0 +1,64 @@
+package redis
+
+import (
+	"context"
+	"github.com/adanyl0v/go-pocket-link/pkg/cache/redis"
+	"github.com/adanyl0v/go-pocket-link/pkg/scheduler"
+	"github.com/google/uuid"
+	"time"
+)
+
+// the lock is only extended and deleted by its holder, known by the token it has been set to
+
+var extendScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+var unlockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// Locker locks the tasks with expiring keys, extended every third of the ttl while the lock is held,
+// so the lock of a dead instance is released once the ttl passes
+type Locker struct {
+	db     *redis.DB
+	prefix string
+	ttl    time.Duration
+}
+
+// NewLocker makes a locker keeping its keys under the prefix
+func NewLocker(db *redis.DB, prefix string, ttl time.Duration) *Locker {
+	return &Locker{db: db, prefix: prefix, ttl: ttl}
+}
+
+func (l *Locker) TryLock(ctx context.Context, name string) (scheduler.Lock, error) {
+	key, token := l.prefix+name, uuid.NewString()
+	locked, err := l.db.SetNX(ctx, key, token, l.ttl)
+	if err != nil {
+		return nil, err
+	} else if !locked {
+		return nil, scheduler.ErrLocked
+	}
+
+	refresh := func(ctx context.Context) error {
+		result, err := l.db.Run(ctx, extendScript, []string{key}, token, l.ttl.Milliseconds())
+		if err != nil {
+			return err
+		} else if result == int64(0) {
+			return scheduler.ErrLockLost
+		}
+		return nil
+	}
+	release := func(ctx context.Context) error {
+		_, err := l.db.Run(ctx, unlockScript, []string{key}, token)
+		return err
+	}
+	// the lock is given up before it expires, as another instance may take it then
+	return scheduler.NewLock(l.ttl/3, l.ttl/2, refresh, release), nil
+}
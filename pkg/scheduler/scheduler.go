@@ -0,0 +1,269 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"github.com/adanyl0v/go-pocket-link/pkg/cron"
+	"github.com/adanyl0v/go-pocket-link/pkg/pagination"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Manual is the schedule of the tasks which are only run when triggered
+const Manual = "-"
+
+// historyTimeout bounds the history calls made after the task has run, which must not be canceled with it
+const historyTimeout = 10 * time.Second
+
+// Func runs the task, returning how many items it has processed
+type Func func(ctx context.Context) (int64, error)
+
+// Task describes a registered task
+type Task struct {
+	Name     string `json:"name"`
+	Schedule string `json:"schedule"`
+	// NextRunAt is when the task is run next, nil for the manual tasks
+	NextRunAt *time.Time `json:"next_run_at"`
+}
+
+type task struct {
+	name     string
+	spec     string
+	schedule cron.Schedule
+	timeout  time.Duration
+	fn       Func
+}
+
+type Options struct {
+	// Instance identifies the instance in the run history, e.g. by its hostname
+	Instance string
+	// Location is the time zone the cron expressions are matched in, UTC if nil
+	Location *time.Location
+	// HistoryTTL is how long the runs are kept in the history
+	HistoryTTL time.Duration
+}
+
+// Scheduler runs the tasks on their cron schedules. Every instance runs the scheduler, and the tasks are
+// locked while running, so each of them is run by one instance at a time. The scheduled runs are recorded
+// by the time they are due at, so a run is not repeated by the instances whose clocks lag behind
+type Scheduler struct {
+	locker  Locker
+	history History
+	opts    Options
+	tasks   map[string]*task
+	names   []string
+}
+
+func New(locker Locker, history History, opts Options) *Scheduler {
+	if opts.Location == nil {
+		opts.Location = time.UTC
+	}
+	return &Scheduler{
+		locker:  locker,
+		history: history,
+		opts:    opts,
+		tasks:   make(map[string]*task),
+	}
+}
+
+// Add registers the task, run on the cron schedule, or only when triggered if the schedule is Manual.
+// The runs are canceled after the timeout if it is positive. The tasks must be added before the scheduler runs
+func (s *Scheduler) Add(name, schedule string, timeout time.Duration, fn Func) error {
+	if _, ok := s.tasks[name]; ok {
+		return errDuplicateTask(name)
+	}
+
+	t := &task{name: name, spec: schedule, timeout: timeout, fn: fn}
+	if schedule != Manual {
+		var err error
+		if t.schedule, err = cron.Parse(schedule, s.opts.Location); err != nil {
+			return errAddingTask(name, err)
+		}
+	}
+	s.tasks[name] = t
+	s.names = append(s.names, name)
+	return nil
+}
+
+// Tasks returns the tasks in the order they have been added
+func (s *Scheduler) Tasks() []Task {
+	now := time.Now()
+	tasks := make([]Task, 0, len(s.names))
+	for _, name := range s.names {
+		t := s.tasks[name]
+		info := Task{Name: t.name, Schedule: t.spec}
+		if t.schedule != nil {
+			if next := t.schedule.Next(now); !next.IsZero() {
+				info.NextRunAt = &next
+			}
+		}
+		tasks = append(tasks, info)
+	}
+	return tasks
+}
+
+// HasTask tells whether the task has been added
+func (s *Scheduler) HasTask(name string) bool {
+	_, ok := s.tasks[name]
+	return ok
+}
+
+// Runs returns a page of the runs of the task
+func (s *Scheduler) Runs(ctx context.Context, name string, params pagination.Params) (pagination.Page[Run], error) {
+	if !s.HasTask(name) {
+		return pagination.Page[Run]{}, ErrUnknownTask
+	}
+	return s.history.Get(ctx, name, params)
+}
+
+// LastRuns returns the last run of each task, by the names of the tasks
+func (s *Scheduler) LastRuns(ctx context.Context) (map[string]Run, error) {
+	runs, err := s.history.GetLast(ctx)
+	if err != nil {
+		return nil, err
+	}
+	last := make(map[string]Run, len(runs))
+	for _, run := range runs {
+		last[run.Task] = run
+	}
+	return last, nil
+}
+
+// RunNow runs the task right away, returning the finished run. ErrLocked is returned
+// if the task is being run already, and the error of the task is returned along with the run
+func (s *Scheduler) RunNow(ctx context.Context, name string) (Run, error) {
+	t, ok := s.tasks[name]
+	if !ok {
+		return Run{}, ErrUnknownTask
+	}
+	return s.run(ctx, t, TriggerManual, nil)
+}
+
+// Run runs the scheduled tasks until the context is canceled, then waits for the running ones,
+// which are canceled along with the context
+func (s *Scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, name := range s.names {
+		if t := s.tasks[name]; t.schedule != nil {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.loop(ctx, t)
+			}()
+		}
+	}
+	wg.Wait()
+}
+
+// loop runs the task at each of its scheduled times. The times passed while the task has been running are skipped
+func (s *Scheduler) loop(ctx context.Context, t *task) {
+	for {
+		next := t.schedule.Next(time.Now())
+		if next.IsZero() {
+			slog.Warn("task schedule matches no time, it is not run", "task", t.name, "schedule", t.spec)
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		_, err := s.run(ctx, t, TriggerSchedule, &next)
+		switch {
+		case errors.Is(err, ErrLocked):
+			slog.Debug("task is running on another instance", "task", t.name)
+		case errors.Is(err, ErrAlreadyRun):
+			slog.Debug("scheduled task has been run by another instance", "task", t.name, "scheduled_at", next)
+		case err != nil && ctx.Err() == nil:
+			slog.Error("failed to run task", "task", t.name, "error", err)
+		}
+	}
+}
+
+// run runs the task holding its lock, recording the run in the history. The task is canceled
+// if the lock is lost, since another instance may run it then
+func (s *Scheduler) run(ctx context.Context, t *task, trigger string, scheduledAt *time.Time) (Run, error) {
+	lock, err := s.locker.TryLock(ctx, t.name)
+	if err != nil {
+		return Run{}, err
+	}
+	defer func() {
+		unlockCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), historyTimeout)
+		defer cancel()
+		if err := lock.Unlock(unlockCtx); err != nil {
+			slog.Error("failed to unlock task", "task", t.name, "error", err)
+		}
+	}()
+
+	run := Run{
+		Task:        t.name,
+		Trigger:     trigger,
+		ScheduledAt: scheduledAt,
+		Instance:    s.opts.Instance,
+		Status:      StatusRunning,
+		StartedAt:   time.Now(),
+	}
+	if err = s.history.Start(ctx, &run); err != nil {
+		return Run{}, err
+	}
+	logger := slog.With("task", t.name, "run_id", run.ID, "trigger", trigger)
+	logger.Info("running task")
+
+	run.Processed, err = s.call(ctx, t, lock)
+
+	now := time.Now()
+	run.FinishedAt = &now
+	if err == nil {
+		run.Status = StatusSucceeded
+		logger.Info("task has finished", "processed", run.Processed, "duration", now.Sub(run.StartedAt))
+	} else {
+		run.Status, run.Error = StatusFailed, err.Error()
+		logger.Error("task has failed", "processed", run.Processed, "error", err)
+	}
+
+	historyCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), historyTimeout)
+	defer cancel()
+	if finishErr := s.history.Finish(historyCtx, run); finishErr != nil {
+		logger.Error("failed to record task run", "error", finishErr)
+	}
+	if s.opts.HistoryTTL > 0 {
+		if pruneErr := s.history.Prune(historyCtx, t.name, now.Add(-s.opts.HistoryTTL)); pruneErr != nil {
+			logger.Error("failed to prune task runs", "error", pruneErr)
+		}
+	}
+	return run, err
+}
+
+// call runs the task function, bounded by the timeout of the task and canceled once the lock is lost
+func (s *Scheduler) call(ctx context.Context, t *task, lock Lock) (processed int64, err error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	if t.timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, t.timeout)
+		defer cancelTimeout()
+	}
+
+	go func() {
+		select {
+		case <-lock.Lost():
+			cancel(ErrLockLost)
+		case <-ctx.Done():
+		}
+	}()
+
+	defer func() {
+		if v := recover(); v != nil {
+			err = errPanicked(t.name, v)
+		}
+		if cause := context.Cause(ctx); err != nil && errors.Is(cause, ErrLockLost) {
+			err = cause
+		}
+	}()
+	return t.fn(ctx)
+}
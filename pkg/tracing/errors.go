@@ -0,0 +1,15 @@
+package tracing
+
+import "fmt"
+
+func errCreatingExporter(err error) error {
+	return fmt.Errorf("%w (creating otlp exporter)", err)
+}
+
+func errCreatingResource(err error) error {
+	return fmt.Errorf("%w (creating tracing resource)", err)
+}
+
+func errUnknownProtocol(protocol string) error {
+	return fmt.Errorf("unknown otlp protocol %q", protocol)
+}
@@ -0,0 +1,15 @@
+package urlnorm
+
+import "fmt"
+
+func errParsing(rawURL string, err error) error {
+	return fmt.Errorf("%w (parsing '%s')", err, rawURL)
+}
+
+func errNotAbsolute(rawURL string) error {
+	return fmt.Errorf("'%s' is not an absolute url", rawURL)
+}
+
+func errNormalizingHost(host string, err error) error {
+	return fmt.Errorf("%w (normalizing host '%s')", err, host)
+}
@@ -0,0 +1,225 @@
+package urlnorm
+
+import (
+	"golang.org/x/net/idna"
+	"net"
+	"net/url"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+)
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// DefaultTrackingParams are the query parameters that are removed by default
+var DefaultTrackingParams = []string{
+	"fbclid", "gclid", "dclid", "gbraid", "wbraid", "msclkid", "yclid", "twclid", "igshid",
+	"mc_cid", "mc_eid", "_hsenc", "_hsmi", "_ga", "_gl", "mkt_tok", "vero_id", "oly_anon_id", "oly_enc_id",
+}
+
+// DefaultTrackingParamPrefixes are the query parameter prefixes that are removed by default
+var DefaultTrackingParamPrefixes = []string{"utm_"}
+
+type Options struct {
+	LowercaseHost      bool
+	StripDefaultPort   bool
+	StripFragment      bool
+	StripTrailingSlash bool
+	StripWWW           bool
+	// ForceHTTPS rewrites http urls to https, so both of them are normalized to the same url
+	ForceHTTPS bool
+	// Punycode converts internationalized domain names to their ASCII form
+	Punycode  bool
+	SortQuery bool
+	CleanPath bool
+	// RemoveTrackingParams removes TrackingParams and the params starting with any of TrackingParamPrefixes
+	RemoveTrackingParams  bool
+	TrackingParams        []string
+	TrackingParamPrefixes []string
+}
+
+func DefaultOptions() Options {
+	return Options{
+		LowercaseHost:         true,
+		StripDefaultPort:      true,
+		StripFragment:         true,
+		StripTrailingSlash:    true,
+		StripWWW:              false,
+		ForceHTTPS:            true,
+		Punycode:              true,
+		SortQuery:             true,
+		CleanPath:             true,
+		RemoveTrackingParams:  true,
+		TrackingParams:        slices.Clone(DefaultTrackingParams),
+		TrackingParamPrefixes: slices.Clone(DefaultTrackingParamPrefixes),
+	}
+}
+
+type Normalizer struct {
+	opts           Options
+	trackingParams map[string]struct{}
+}
+
+func New(opts Options) *Normalizer {
+	trackingParams := make(map[string]struct{}, len(opts.TrackingParams))
+	for _, param := range opts.TrackingParams {
+		trackingParams[strings.ToLower(param)] = struct{}{}
+	}
+	return &Normalizer{
+		opts:           opts,
+		trackingParams: trackingParams,
+	}
+}
+
+var defaultNormalizer = New(DefaultOptions())
+
+// Normalize normalizes rawURL with DefaultOptions
+func Normalize(rawURL string) (string, error) {
+	return defaultNormalizer.Normalize(rawURL)
+}
+
+func (n *Normalizer) Normalize(rawURL string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", errParsing(rawURL, err)
+	} else if !u.IsAbs() || u.Host == "" {
+		return "", errNotAbsolute(rawURL)
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+
+	// the scheme is rewritten first, so the default port is stripped for the final scheme only
+	if n.opts.ForceHTTPS && u.Scheme == "http" {
+		u.Scheme = "https"
+	}
+
+	host, err := n.normalizeHost(u)
+	if err != nil {
+		return "", errNormalizingHost(u.Host, err)
+	}
+	u.Host = host
+
+	u.Path = n.normalizePath(u.Path)
+	u.RawPath = ""
+	u.RawQuery = n.normalizeQuery(u.RawQuery)
+	u.ForceQuery = false
+
+	if n.opts.StripFragment {
+		u.Fragment = ""
+		u.RawFragment = ""
+	}
+
+	return u.String(), nil
+}
+
+func (n *Normalizer) normalizeHost(u *url.URL) (string, error) {
+	hostname, port := u.Hostname(), u.Port()
+	hostname = strings.TrimSuffix(hostname, ".")
+
+	if n.opts.Punycode && net.ParseIP(hostname) == nil {
+		ascii, err := idna.Lookup.ToASCII(hostname)
+		if err != nil {
+			return "", err
+		}
+		hostname = ascii
+	}
+
+	if n.opts.LowercaseHost {
+		hostname = strings.ToLower(hostname)
+	}
+
+	if n.opts.StripWWW {
+		hostname = strings.TrimPrefix(hostname, "www.")
+	}
+
+	if n.opts.StripDefaultPort && port == defaultPorts[u.Scheme] {
+		port = ""
+	}
+
+	if strings.Contains(hostname, ":") {
+		hostname = "[" + hostname + "]"
+	}
+	if port == "" {
+		return hostname, nil
+	}
+	return hostname + ":" + port, nil
+}
+
+func (n *Normalizer) normalizePath(p string) string {
+	if p == "" {
+		return "/"
+	}
+
+	if n.opts.CleanPath {
+		trailingSlash := strings.HasSuffix(p, "/")
+		p = path.Clean("/" + p)
+		if trailingSlash && p != "/" {
+			p += "/"
+		}
+	}
+
+	if n.opts.StripTrailingSlash && p != "/" {
+		p = strings.TrimRight(p, "/")
+	}
+	return p
+}
+
+func (n *Normalizer) normalizeQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	type param struct {
+		key string
+		raw string
+	}
+
+	params := make([]param, 0)
+	for _, raw := range strings.Split(rawQuery, "&") {
+		if raw == "" {
+			continue
+		}
+
+		key, _, _ := strings.Cut(raw, "=")
+		if unescaped, err := url.QueryUnescape(key); err == nil {
+			key = unescaped
+		}
+
+		if n.opts.RemoveTrackingParams && n.isTrackingParam(key) {
+			continue
+		}
+		params = append(params, param{key: key, raw: raw})
+	}
+
+	if n.opts.SortQuery {
+		sort.SliceStable(params, func(i, j int) bool {
+			if params[i].key != params[j].key {
+				return params[i].key < params[j].key
+			}
+			return params[i].raw < params[j].raw
+		})
+	}
+
+	raws := make([]string, 0, len(params))
+	for _, p := range params {
+		raws = append(raws, p.raw)
+	}
+	return strings.Join(raws, "&")
+}
+
+func (n *Normalizer) isTrackingParam(key string) bool {
+	key = strings.ToLower(key)
+	if _, ok := n.trackingParams[key]; ok {
+		return true
+	}
+	for _, prefix := range n.opts.TrackingParamPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package urlnorm
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"HTTPS://Example.COM/a/", "https://example.com/a"},
+		{"  https://example.com  ", "https://example.com/"},
+		{"http://example.com/a", "https://example.com/a"},
+		// the default port is the one of the scheme the url ends up with
+		{"http://example.com:80/a", "https://example.com:80/a"},
+		{"http://example.com:443/a", "https://example.com/a"},
+		{"https://example.com:443/a", "https://example.com/a"},
+		{"https://example.com:8443/a", "https://example.com:8443/a"},
+		{"https://example.com./a/./b/../c", "https://example.com/a/c"},
+		{"https://example.com/a?utm_source=x&b=2&a=1&fbclid=y#top", "https://example.com/a?a=1&b=2"},
+		{"https://bücher.example/", "https://xn--bcher-kva.example/"},
+		{"https://[::1]:443/a", "https://[::1]/a"},
+		{"ftp://example.com:21/a", "ftp://example.com:21/a"},
+	}
+	for _, tt := range tests {
+		got, err := Normalize(tt.in)
+		if err != nil {
+			t.Errorf("Normalize(%q): %v", tt.in, err)
+		} else if got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeKeepsHTTPWithoutForceHTTPS(t *testing.T) {
+	opts := DefaultOptions()
+	opts.ForceHTTPS = false
+	n := New(opts)
+
+	for in, want := range map[string]string{
+		"http://example.com:80/a":  "http://example.com/a",
+		"http://example.com:443/a": "http://example.com:443/a",
+	} {
+		if got, err := n.Normalize(in); err != nil || got != want {
+			t.Errorf("Normalize(%q) = %q, %v, want %q", in, got, err, want)
+		}
+	}
+}
+
+func TestNormalizeRefusesRelative(t *testing.T) {
+	for _, in := range []string{"", "/a/b", "example.com/a", "https://", "%zz"} {
+		if got, err := Normalize(in); err == nil {
+			t.Errorf("Normalize(%q) = %q, want an error", in, got)
+		}
+	}
+}
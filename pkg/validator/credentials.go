@@ -12,7 +12,7 @@ func NewCredentialsValidator() *CredentialsValidator {
 }
 
 func (v *CredentialsValidator) ValidateName(name string) error {
-	if err := checkInputLength(name, 3, 256); err != nil {
+	if err := checkInputLength(FieldName, name, 3, 256); err != nil {
 		return err
 	}
 
@@ -24,20 +24,20 @@ func (v *CredentialsValidator) ValidateName(name string) error {
 			}
 		} else if !unicode.IsNumber(r) {
 			if !unicode.IsSpace(r) {
-				return errInvalidCharacters
+				return errInvalidCharacters(FieldName)
 			}
 		}
 	}
 
 	if !containsUpper {
-		return errMustContainUpper
+		return errMustContainUpper(FieldName)
 	}
 
 	return nil
 }
 
 func (v *CredentialsValidator) ValidateEmail(email string) error {
-	if err := checkInputLength(email, 4, 256); err != nil {
+	if err := checkInputLength(FieldEmail, email, 4, 256); err != nil {
 		return err
 	}
 
@@ -54,7 +54,7 @@ func (v *CredentialsValidator) ValidateEmail(email string) error {
 }
 
 func (v *CredentialsValidator) ValidatePassword(password string) error {
-	if err := checkInputLength(password, 8, 256); err != nil {
+	if err := checkInputLength(FieldPassword, password, 8, 256); err != nil {
 		return err
 	}
 
@@ -71,25 +71,25 @@ func (v *CredentialsValidator) ValidatePassword(password string) error {
 			containsNumber = true
 		} else {
 			if !unicode.IsSpace(r) {
-				return errInvalidCharacters
+				return errInvalidCharacters(FieldPassword)
 			}
 		}
 	}
 
 	if !containsUpper {
-		return errMustContainUpper
+		return errMustContainUpper(FieldPassword)
 	} else if !containsNumber {
-		return errMustContainNumber
+		return errMustContainNumber(FieldPassword)
 	}
 
 	return nil
 }
 
-func checkInputLength(input string, min, max int) error {
+func checkInputLength(field, input string, min, max int) error {
 	if l := len(input); l < min {
-		return errInputLengthLesserThanMin(min)
+		return errInputLengthLesserThanMin(field, min)
 	} else if l > max {
-		return errInputLengthBiggerThanMax(max)
+		return errInputLengthBiggerThanMax(field, max)
 	}
 	return nil
 }
@@ -3,22 +3,88 @@ package validator
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
-var (
-	errMustContainUpper  = errors.New("input must contain uppercase")
-	errMustContainNumber = errors.New("input must contain numbers")
-	errInvalidCharacters = errors.New("input must contain only letters and numbers")
+// The codes of the FieldErrors, telling the clients which rule the input has failed
+const (
+	CodeTooShort          = "too_short"
+	CodeTooLong           = "too_long"
+	CodeMissingUpper      = "missing_uppercase"
+	CodeMissingNumber     = "missing_number"
+	CodeInvalidCharacters = "invalid_characters"
+	CodeInvalidEmail      = "invalid_email"
 )
 
-func errInputLengthLesserThanMin(min int) error {
-	return fmt.Errorf("input length must be bigger than %d", min)
+const (
+	FieldName     = "name"
+	FieldEmail    = "email"
+	FieldPassword = "password"
+)
+
+// FieldError is an input failing one of the rules, Field naming the input and Code the rule
+type FieldError struct {
+	Field   string
+	Code    string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + " " + e.Message
+}
+
+// Errors are the errors of all the invalid inputs of a request
+type Errors []*FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Join collects the FieldErrors of the validations into Errors, nil if all of them have passed.
+// The other errors are returned as they are, the first one winning
+func Join(errs ...error) error {
+	var fieldErrs Errors
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		var fieldErr *FieldError
+		if !errors.As(err, &fieldErr) {
+			return err
+		}
+		fieldErrs = append(fieldErrs, fieldErr)
+	}
+
+	if len(fieldErrs) == 0 {
+		return nil
+	}
+	return fieldErrs
+}
+
+func errMustContainUpper(field string) error {
+	return &FieldError{Field: field, Code: CodeMissingUpper, Message: "must contain uppercase"}
+}
+
+func errMustContainNumber(field string) error {
+	return &FieldError{Field: field, Code: CodeMissingNumber, Message: "must contain numbers"}
+}
+
+func errInvalidCharacters(field string) error {
+	return &FieldError{Field: field, Code: CodeInvalidCharacters, Message: "must contain only letters and numbers"}
+}
+
+func errInputLengthLesserThanMin(field string, min int) error {
+	return &FieldError{Field: field, Code: CodeTooShort, Message: fmt.Sprintf("length must be bigger than %d", min)}
 }
 
-func errInputLengthBiggerThanMax(max int) error {
-	return fmt.Errorf("input length must be lesser than %d", max)
+func errInputLengthBiggerThanMax(field string, max int) error {
+	return &FieldError{Field: field, Code: CodeTooLong, Message: fmt.Sprintf("length must be lesser than %d", max)}
 }
 
 func errInvalidEmail(email string) error {
-	return fmt.Errorf("invalid email %s", email)
+	return &FieldError{Field: FieldEmail, Code: CodeInvalidEmail, Message: fmt.Sprintf("is invalid: %s", email)}
 }
@@ -0,0 +1,72 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// maxResponseBody is how much of the response is kept for the delivery logs
+const maxResponseBody = 4 << 10
+
+type Request struct {
+	URL        string
+	Secret     string
+	Event      string
+	DeliveryID string
+	Body       []byte
+}
+
+type Response struct {
+	// StatusCode is 0 if no response has been received
+	StatusCode int
+	Body       string
+	Duration   time.Duration
+}
+
+// Client delivers the webhooks. The http.Client is injectable, so the deliveries
+// can be pointed at httptest servers
+type Client struct {
+	http *http.Client
+}
+
+func NewClient(httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Client{http: httpClient}
+}
+
+// Deliver posts the signed body. Any response out of the 2xx range is an error,
+// returned along with the response
+func (c *Client) Deliver(ctx context.Context, req Request) (Response, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return Response{}, errSending(req.URL, err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("User-Agent", "PocketLink-Webhooks/1.0")
+	httpReq.Header.Set(HeaderEvent, req.Event)
+	httpReq.Header.Set(HeaderDelivery, req.DeliveryID)
+	httpReq.Header.Set(HeaderSignature, Sign(req.Secret, time.Now(), req.Body))
+
+	start := time.Now()
+	httpResp, err := c.http.Do(httpReq)
+	if err != nil {
+		return Response{Duration: time.Since(start)}, errSending(req.URL, err)
+	}
+	defer func() { _ = httpResp.Body.Close() }()
+
+	body, _ := io.ReadAll(io.LimitReader(httpResp.Body, maxResponseBody))
+	resp := Response{
+		StatusCode: httpResp.StatusCode,
+		Body:       string(body),
+		Duration:   time.Since(start),
+	}
+	if httpResp.StatusCode < 200 || httpResp.StatusCode > 299 {
+		return resp, errUnexpectedStatus(httpResp.StatusCode)
+	}
+	return resp, nil
+}
@@ -0,0 +1,86 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDeliverSendsSignedRequest(t *testing.T) {
+	body := []byte(`{"event":"link.saved"}`)
+
+	var received *http.Request
+	var receivedBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		receivedBody, _ = io.ReadAll(r.Body)
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	resp, err := NewClient(server.Client()).Deliver(context.Background(), Request{
+		URL:        server.URL,
+		Secret:     "secret",
+		Event:      "link.saved",
+		DeliveryID: "delivery-1",
+		Body:       body,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || resp.Body != "ok" {
+		t.Errorf("response = %+v", resp)
+	}
+
+	if received.Method != http.MethodPost || string(receivedBody) != string(body) {
+		t.Errorf("received %s %q", received.Method, receivedBody)
+	}
+	if received.Header.Get(HeaderEvent) != "link.saved" || received.Header.Get(HeaderDelivery) != "delivery-1" {
+		t.Errorf("event %q, delivery %q", received.Header.Get(HeaderEvent), received.Header.Get(HeaderDelivery))
+	}
+	if err = Verify("secret", received.Header.Get(HeaderSignature), receivedBody, time.Minute); err != nil {
+		t.Errorf("signature of the delivery does not verify: %v", err)
+	}
+}
+
+func TestDeliverFailsOnNon2xx(t *testing.T) {
+	for _, status := range []int{http.StatusMovedPermanently, http.StatusBadRequest, http.StatusInternalServerError} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+			_, _ = io.WriteString(w, strings.Repeat("x", maxResponseBody+100))
+		}))
+
+		// the redirects are not followed, so a moved receiver is retried rather than silently switched
+		client := server.Client()
+		client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+
+		resp, err := NewClient(client).Deliver(context.Background(), Request{URL: server.URL, Secret: "s", Body: []byte(`{}`)})
+		server.Close()
+		if err == nil {
+			t.Errorf("status %d: expected an error", status)
+		}
+		if resp.StatusCode != status {
+			t.Errorf("status %d: response status %d", status, resp.StatusCode)
+		}
+		if len(resp.Body) != maxResponseBody {
+			t.Errorf("status %d: kept %d bytes of the body, want %d", status, len(resp.Body), maxResponseBody)
+		}
+	}
+}
+
+func TestDeliverFailsWithoutResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	server.Close()
+
+	resp, err := NewClient(nil).Deliver(context.Background(), Request{URL: server.URL, Secret: "s"})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if resp.StatusCode != 0 {
+		t.Errorf("status %d, want 0 without a response", resp.StatusCode)
+	}
+}
@@ -0,0 +1,20 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrMalformedSignature = errors.New("malformed webhook signature")
+	ErrSignatureExpired   = errors.New("webhook signature has expired")
+	ErrSignatureMismatch  = errors.New("webhook signature does not match")
+)
+
+func errSending(url string, err error) error {
+	return fmt.Errorf("%w (sending webhook to %s)", err, url)
+}
+
+func errUnexpectedStatus(code int) error {
+	return fmt.Errorf("receiver responded with status %d", code)
+}
@@ -0,0 +1,66 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	HeaderSignature = "X-Webhook-Signature"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+
+	signatureVersion = "v1"
+)
+
+// Sign signs the timestamp and the body with HMAC-SHA256, so a receiver can both check
+// the payload and reject the replayed ones. The result is the value of the HeaderSignature
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + ts + "," + signatureVersion + "=" + hex.EncodeToString(mac(secret, ts, body))
+}
+
+// Verify checks the HeaderSignature of a received payload, rejecting the ones signed
+// more than tolerance ago if tolerance is positive
+func Verify(secret, header string, body []byte, tolerance time.Duration) error {
+	var ts, signature string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			ts = value
+		case signatureVersion:
+			signature = value
+		}
+	}
+	if ts == "" || signature == "" {
+		return ErrMalformedSignature
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return ErrMalformedSignature
+	} else if tolerance > 0 && time.Since(time.Unix(unix, 0)).Abs() > tolerance {
+		return ErrSignatureExpired
+	}
+
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return ErrMalformedSignature
+	} else if !hmac.Equal(expected, mac(secret, ts, body)) {
+		return ErrSignatureMismatch
+	}
+	return nil
+}
+
+func mac(secret, ts string, body []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(ts))
+	h.Write([]byte("."))
+	h.Write(body)
+	return h.Sum(nil)
+}
@@ -0,0 +1,51 @@
+package webhook
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSignFormat(t *testing.T) {
+	header := Sign("secret", time.Unix(1700000000, 0), []byte(`{"a":1}`))
+	// the signature is the HMAC-SHA256 of "<timestamp>.<body>", which the receivers recompute,
+	// e.g. printf '1700000000.{"a":1}' | openssl dgst -sha256 -hmac secret
+	if want := "t=1700000000,v1=49f24e537407743fa4a0242bb63b94b9a47ee99cbbe071ccd8a22550ae411686"; header != want {
+		t.Errorf("header %q, want %q", header, want)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"event":"link.saved"}`)
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		header    string
+		secret    string
+		body      []byte
+		tolerance time.Duration
+		want      error
+	}{
+		{"valid", Sign("secret", now, body), "secret", body, 5 * time.Minute, nil},
+		{"spaces around parts", " t=" + Sign("secret", now, body)[2:], "secret", body, 5 * time.Minute, nil},
+		{"wrong secret", Sign("secret", now, body), "other", body, 5 * time.Minute, ErrSignatureMismatch},
+		{"tampered body", Sign("secret", now, body), "secret", []byte(`{}`), 5 * time.Minute, ErrSignatureMismatch},
+		{"too old", Sign("secret", now.Add(-6*time.Minute), body), "secret", body, 5 * time.Minute, ErrSignatureExpired},
+		{"too far in future", Sign("secret", now.Add(6*time.Minute), body), "secret", body, 5 * time.Minute, ErrSignatureExpired},
+		{"within tolerance", Sign("secret", now.Add(-4*time.Minute), body), "secret", body, 5 * time.Minute, nil},
+		{"old without tolerance", Sign("secret", now.Add(-24*time.Hour), body), "secret", body, 0, nil},
+		{"no timestamp", "v1=abcd", "secret", body, 0, ErrMalformedSignature},
+		{"no signature", "t=1700000000", "secret", body, 0, ErrMalformedSignature},
+		{"bad timestamp", "t=soon,v1=abcd", "secret", body, 0, ErrMalformedSignature},
+		{"bad hex", "t=1700000000,v1=zz", "secret", body, 0, ErrMalformedSignature},
+		{"empty", "", "secret", body, 0, ErrMalformedSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Verify(tt.secret, tt.header, tt.body, tt.tolerance); !errors.Is(err, tt.want) {
+				t.Errorf("Verify = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}